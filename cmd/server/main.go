@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // time zones for the due-date views, even without a system database
 
 	"go.uber.org/zap"
 
+	"taskmanager/internal/config"
 	"taskmanager/internal/handler"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/jwtauth"
 	"taskmanager/internal/repository"
+	"taskmanager/internal/search"
 	"taskmanager/internal/service"
 )
 
@@ -19,9 +26,89 @@ func main() {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
 
-	repo := repository.NewInMemoryTaskRepository(logger)
-	svc := service.NewTaskService(repo, logger)
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+
+	// Every tenant has storage, a search index and attachment contents of its
+	// own; a single-tenant server has one set, used without a tenant.
+	var (
+		repo      repository.TaskRepository
+		blobs     repository.BlobStore
+		searchSvc service.SearchService
+	)
+	if len(cfg.Tenants) == 0 {
+		st, err := openStorage(cfg, logger)
+		if err != nil {
+			logger.Fatal("failed to initialise storage", zap.Error(err))
+		}
+		defer st.close()
+		repo, blobs = st.repo, st.blobs
+		searchSvc = service.NewSearchService(st.repo.Index(), st.repo, logger)
+	} else {
+		repos := make(map[string]repository.TaskRepository)
+		stores := make(map[string]repository.BlobStore)
+		indexes := make(map[string]*search.Index)
+		for _, tenant := range cfg.Tenants {
+			st, err := openStorage(cfg.ForTenant(tenant), logger.With(zap.String("tenant", tenant)))
+			if err != nil {
+				logger.Fatal("failed to initialise storage", zap.String("tenant", tenant), zap.Error(err))
+			}
+			defer st.close()
+			repos[tenant], stores[tenant], indexes[tenant] = st.repo, st.blobs, st.repo.Index()
+		}
+		tenants := repository.NewTenantRepository(repos)
+		repo, blobs = tenants, repository.NewTenantBlobStore(stores)
+		searchSvc = service.NewTenantSearchService(indexes, tenants, logger)
+		logger.Info("serving tenants", zap.Strings("tenants", cfg.Tenants))
+	}
+
+	svc := service.NewTaskService(repo, logger,
+		service.WithCascadePolicy(cfg.SubtaskCompletePolicy, cfg.SubtaskDeletePolicy),
+		service.WithWorkflow(cfg.Workflow),
+		service.WithTaskQuotas(cfg.TaskQuota, cfg.TenantTaskQuotas))
+	labelSvc := service.NewLabelService(repo, logger)
+	dependencySvc := service.NewDependencyService(repo, logger)
+	commentSvc := service.NewCommentService(repo, logger)
+	userSvc := service.NewUserService(repo, svc, logger)
+	projectSvc := service.NewProjectService(repo, logger)
+	attachmentSvc := service.NewAttachmentService(repo, blobs, cfg.AttachmentMaxSize, logger)
+	// Deleted tasks stay in the trash until the reaper purges them; contents
+	// of deleted attachments and purged tasks are swept in the background.
+	// Both run in every tenant.
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	jobs := []context.Context{reaperCtx}
+	if len(cfg.Tenants) > 0 {
+		jobs = nil
+		for _, tenant := range cfg.Tenants {
+			jobs = append(jobs, identity.WithTenant(reaperCtx, tenant))
+		}
+	}
+	var background sync.WaitGroup
+	for _, ctx := range jobs {
+		if cfg.TrashRetention > 0 {
+			reaper := service.NewTrashReaper(svc, cfg.TrashRetention, cfg.TrashReapInterval, logger)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				reaper.Run(ctx)
+			}()
+		}
+		sweeper := service.NewBlobSweeper(attachmentSvc, cfg.AttachmentSweep, logger)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			sweeper.Run(ctx)
+		}()
+	}
 	taskHandler := handler.NewTaskHandler(svc, logger)
+	searchHandler := handler.NewSearchHandler(searchSvc, logger)
+	labelHandler := handler.NewLabelHandler(labelSvc, logger)
+	dependencyHandler := handler.NewDependencyHandler(dependencySvc, logger)
+	commentHandler := handler.NewCommentHandler(commentSvc, logger)
+	userHandler := handler.NewUserHandler(userSvc, logger)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentSvc, cfg.AttachmentMaxSize, logger)
 	serviceHandler := handler.NewServiceHandler()
 	healthHandler := handler.NewHealthHandler()
 
@@ -29,10 +116,32 @@ func main() {
 	serviceHandler.RegisterRoutes(mux)
 	healthHandler.RegisterRoutes(mux)
 	taskHandler.RegisterRoutes(mux)
+	searchHandler.RegisterRoutes(mux)
+	labelHandler.RegisterRoutes(mux)
+	dependencyHandler.RegisterRoutes(mux, taskHandler)
+	commentHandler.RegisterRoutes(taskHandler)
+	attachmentHandler.RegisterRoutes(taskHandler)
+	userHandler.RegisterRoutes(mux)
+	// Project tasks are served by the /tasks routes registered above.
+	handler.NewProjectHandler(projectSvc, mux, logger).RegisterRoutes(mux)
 
+	// Authenticated principals are the actor and may pin the tenant, so
+	// credentials are checked inside the header middleware and override it.
+	h := http.Handler(mux)
+	if cfg.AuthEnabled() {
+		auths, err := newAuthenticators(cfg, mux, logger)
+		if err != nil {
+			logger.Fatal("failed to set up authentication", zap.Error(err))
+		}
+		h = handler.RequireAuth(auths, logger, h)
+	}
+	h = handler.Actor(h)
+	if len(cfg.Tenants) > 0 {
+		h = handler.Tenant(h)
+	}
 	srv := &http.Server{
 		Addr:    ":8080",
-		Handler: mux,
+		Handler: handler.RequestID(h),
 		// HTTP/2 is enabled by default for TLS servers in Go's stdlib.
 		// For plaintext, Go 1.6+ supports h2c via third-party, but for now we use HTTP/1.1 for local dev.
 		ReadTimeout:  15 * time.Second,
@@ -57,5 +166,118 @@ func main() {
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		logger.Fatal("ListenAndServe failed", zap.Error(err))
 	}
+	stopReaper()
+	background.Wait()
 	logger.Info("Server exited cleanly")
 }
+
+// newAuthenticators builds the authenticators of the credentials cfg enables,
+// registering the key management routes on mux if API keys are among them.
+// Bearer tokens come first: API keys are tried on any token.
+func newAuthenticators(cfg config.Config, mux *http.ServeMux, logger *zap.Logger) ([]handler.Authenticator, error) {
+	var auths []handler.Authenticator
+	if jwt := cfg.JWT; jwt.Issuer != "" {
+		var keys jwtauth.KeySet
+		if jwt.JWKSFile != "" {
+			static, err := jwtauth.LoadKeySetFile(jwt.JWKSFile)
+			if err != nil {
+				return nil, err
+			}
+			keys = static
+		} else {
+			keys = jwtauth.NewRemoteKeySet(jwt.JWKSURL, &http.Client{Timeout: 10 * time.Second}, jwt.JWKSRefresh, logger)
+		}
+		verifier := jwtauth.NewVerifier(keys, jwtauth.Options{Issuer: jwt.Issuer, Audience: jwt.Audience, Leeway: jwt.Leeway})
+		auths = append(auths, handler.JWTAuth(verifier, handler.ClaimMapping{
+			Subject: jwt.SubjectClaim,
+			Tenant:  jwt.TenantClaim,
+			Scopes:  jwt.ScopeClaim,
+		}))
+		logger.Info("bearer token authentication enabled", zap.String("issuer", jwt.Issuer))
+	}
+	if cfg.APIKeysEnabled() {
+		keyRepo, err := repository.OpenFileAPIKeyRepository(cfg.APIKeysFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		apiKeySvc := service.NewAPIKeyService(keyRepo, cfg.AdminAPIKey, logger)
+		handler.NewAPIKeyHandler(apiKeySvc, logger).RegisterRoutes(mux)
+		auths = append(auths, handler.APIKeyAuth(apiKeySvc))
+		logger.Info("API key authentication enabled", zap.String("keys_file", cfg.APIKeysFile))
+	}
+	return auths, nil
+}
+
+// storage is the data of a tenant, or of a single-tenant server.
+type storage struct {
+	// repo confines task operations below /projects/{id}/tasks to the project
+	// and keeps the search index in step.
+	repo  *search.IndexedRepository
+	blobs repository.BlobStore
+	close func()
+}
+
+// openStorage opens the storage configured by cfg and builds its search index,
+// which lives in memory and is rebuilt from storage on startup.
+func openStorage(cfg config.Config, logger *zap.Logger) (*storage, error) {
+	repo, closeRepo, err := newTaskRepository(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	indexed := search.NewIndexedRepository(repository.NewProjectScopedRepository(repo), search.NewIndex())
+	if err := indexed.Rebuild(context.Background()); err != nil {
+		closeRepo()
+		return nil, fmt.Errorf("build search index: %w", err)
+	}
+	logger.Info("search index built", zap.Int("tasks", indexed.Index().Len()))
+	blobs, err := repository.NewFileBlobStore(cfg.AttachmentDir, logger)
+	if err != nil {
+		closeRepo()
+		return nil, fmt.Errorf("initialise attachment storage: %w", err)
+	}
+	return &storage{repo: indexed, blobs: blobs, close: closeRepo}, nil
+}
+
+// newTaskRepository builds the TaskRepository selected by cfg.StorageDriver and
+// returns a function releasing its resources.
+func newTaskRepository(cfg config.Config, logger *zap.Logger) (repository.TaskRepository, func(), error) {
+	switch cfg.StorageDriver {
+	case config.StorageSQLite:
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		repo, err := repository.OpenSQLiteTaskRepository(ctx, cfg.SQLitePath, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Info("using sqlite storage", zap.String("path", cfg.SQLitePath))
+		return repo, func() {
+			if err := repo.Close(); err != nil {
+				logger.Error("failed to close sqlite database", zap.Error(err))
+			}
+		}, nil
+	default:
+		if cfg.JournalDir != "" {
+			policy, err := repository.ParseSyncPolicy(cfg.JournalSync)
+			if err != nil {
+				return nil, nil, err
+			}
+			repo, err := repository.OpenJournaledTaskRepository(repository.JournalOptions{
+				Dir:           cfg.JournalDir,
+				Sync:          policy,
+				SyncInterval:  cfg.JournalSyncInterval,
+				SnapshotEvery: cfg.JournalSnapshotEvery,
+			}, logger)
+			if err != nil {
+				return nil, nil, err
+			}
+			logger.Info("using journaled in-memory storage", zap.String("dir", cfg.JournalDir))
+			return repo, func() {
+				if err := repo.Close(); err != nil {
+					logger.Error("failed to close journal", zap.Error(err))
+				}
+			}, nil
+		}
+		logger.Info("using in-memory storage")
+		return repository.NewInMemoryTaskRepository(logger), func() {}, nil
+	}
+}
@@ -0,0 +1,166 @@
+// Package apperror defines the error taxonomy shared by the repository, service
+// and handler layers. Lower layers classify failures with a Kind; the handler
+// layer maps kinds to HTTP status codes in one place.
+package apperror
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind classifies an error.
+type Kind int
+
+const (
+	// KindInternal is an unexpected failure, such as a storage error. Untyped
+	// errors are treated as internal.
+	KindInternal Kind = iota
+	// KindNotFound means the addressed resource does not exist.
+	KindNotFound
+	// KindAlreadyExists means a resource with the same identity already exists.
+	KindAlreadyExists
+	// KindValidation means the input violates business rules; see Error.Fields.
+	KindValidation
+	// KindConflict means the request conflicts with the current state, e.g. a
+	// stale version or a concurrent modification.
+	KindConflict
+	// KindForbidden means the caller is not allowed to perform the operation.
+	KindForbidden
+	// KindUnauthenticated means the caller could not be identified, e.g. for
+	// lack of valid credentials.
+	KindUnauthenticated
+)
+
+var kindNames = map[Kind]string{
+	KindInternal:        "internal",
+	KindNotFound:        "not_found",
+	KindAlreadyExists:   "already_exists",
+	KindValidation:      "validation",
+	KindConflict:        "conflict",
+	KindForbidden:       "forbidden",
+	KindUnauthenticated: "unauthenticated",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// FieldError describes a validation problem with a single field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a classified application error.
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil && e.Message != "" {
+		return e.Message + ": " + e.Err.Error()
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause, if any.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Kinder is implemented by error types that classify themselves without being
+// an *Error, such as repository.VersionConflictError.
+type Kinder interface {
+	ErrorKind() Kind
+}
+
+// KindOf returns the kind of the outermost classified error in err's chain, or
+// KindInternal if there is none.
+func KindOf(err error) Kind {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			return e.Kind
+		}
+		if k, ok := err.(Kinder); ok {
+			return k.ErrorKind()
+		}
+		err = errors.Unwrap(err)
+	}
+	return KindInternal
+}
+
+// FieldsOf returns the validation field details carried by err, if any.
+func FieldsOf(err error) []FieldError {
+	var e *Error
+	for errors.As(err, &e) {
+		if len(e.Fields) > 0 {
+			return e.Fields
+		}
+		err = e.Err
+	}
+	return nil
+}
+
+// New returns an error of the given kind.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap classifies err with kind, keeping it reachable through errors.Is/As.
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// NotFound returns a KindNotFound error.
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// AlreadyExists returns a KindAlreadyExists error.
+func AlreadyExists(message string) *Error {
+	return New(KindAlreadyExists, message)
+}
+
+// Conflict returns a KindConflict error.
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// Forbidden returns a KindForbidden error.
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// Unauthenticated returns a KindUnauthenticated error.
+func Unauthenticated(message string) *Error {
+	return New(KindUnauthenticated, message)
+}
+
+// Internal wraps err as a KindInternal error.
+func Internal(message string, err error) *Error {
+	return Wrap(KindInternal, message, err)
+}
+
+// Validation returns a KindValidation error for the given field problems. Its
+// message joins the field messages.
+func Validation(fields ...FieldError) *Error {
+	messages := make([]string, len(fields))
+	for i, f := range fields {
+		messages[i] = f.Message
+	}
+	return &Error{Kind: KindValidation, Message: strings.Join(messages, "; "), Fields: fields}
+}
+
+// InvalidField returns a validation error for a single field.
+func InvalidField(field, message string) *Error {
+	return Validation(FieldError{Field: field, Message: message})
+}
@@ -0,0 +1,46 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type selfClassified struct{}
+
+func (selfClassified) Error() string   { return "self classified" }
+func (selfClassified) ErrorKind() Kind { return KindConflict }
+
+func TestKindOf(t *testing.T) {
+	assert.Equal(t, KindNotFound, KindOf(NotFound("missing")))
+	assert.Equal(t, KindAlreadyExists, KindOf(fmt.Errorf("wrapped: %w", AlreadyExists("dup"))))
+	assert.Equal(t, KindConflict, KindOf(fmt.Errorf("wrapped: %w", selfClassified{})))
+	assert.Equal(t, KindForbidden, KindOf(Forbidden("not yours")))
+	assert.Equal(t, KindUnauthenticated, KindOf(Unauthenticated("who are you")))
+	assert.Equal(t, KindInternal, KindOf(errors.New("plain")))
+	assert.Equal(t, KindInternal, KindOf(nil))
+
+	// The outermost classification wins.
+	assert.Equal(t, KindInternal, KindOf(Internal("storage", NotFound("missing"))))
+}
+
+func TestValidation(t *testing.T) {
+	err := Validation(
+		FieldError{Field: "title", Message: "title is required"},
+		FieldError{Field: "description", Message: "description is too long"},
+	)
+	assert.Equal(t, KindValidation, err.Kind)
+	assert.Equal(t, "title is required; description is too long", err.Error())
+	assert.Len(t, FieldsOf(fmt.Errorf("create: %w", err)), 2)
+	assert.Nil(t, FieldsOf(errors.New("plain")))
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("disk full")
+	err := Internal("insert task", cause)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "insert task: disk full", err.Error())
+	assert.Equal(t, "internal", err.Kind.String())
+}
@@ -0,0 +1,336 @@
+// Package config loads runtime configuration for the server from the environment.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"time"
+)
+
+// Storage drivers supported by the server.
+const (
+	StorageMemory = "memory"
+	StorageSQLite = "sqlite"
+)
+
+// Config holds the server configuration.
+//
+// Environment variables:
+//   - STORAGE_DRIVER: "memory" (default) or "sqlite"
+//   - SQLITE_PATH: database file used by the sqlite driver (default "taskmanager.db")
+//   - JOURNAL_DIR: enables the write-ahead journal of the memory driver when set
+//   - JOURNAL_SYNC: "always" (default), "interval" or "never"
+//   - JOURNAL_SYNC_INTERVAL: flush period for the interval policy (default 1s)
+//   - JOURNAL_SNAPSHOT_EVERY: journal records between snapshots (default 1000, 0 disables)
+//   - SUBTASK_COMPLETE_POLICY: "block" (default), "cascade" or "orphan"; applied to
+//     incomplete subtasks when their parent is completed
+//   - SUBTASK_DELETE_POLICY: "block" (default), "cascade" or "orphan"; applied to
+//     subtasks when their parent is deleted
+//   - WORKFLOW_FILE: JSON workflow definition of task statuses (default
+//     model.DefaultWorkflow)
+//   - TRASH_RETENTION: how long deleted tasks stay in the trash before they are
+//     purged (default 720h, 0 keeps them until purged by hand)
+//   - TRASH_REAP_INTERVAL: how often the trash is checked for expired tasks (default 1h)
+//   - ATTACHMENT_DIR: directory holding attachment contents (default "attachments")
+//   - ATTACHMENT_MAX_SIZE: largest attachment in bytes (default 10485760)
+//   - ATTACHMENT_SWEEP_INTERVAL: how often contents no attachment refers to are
+//     deleted (default 1h)
+//   - TENANTS: comma-separated IDs of the tenants hosted, each optionally with a
+//     task quota of its own ("acme,globex=500"); unset serves a single tenant
+//   - TASK_QUOTA: most live tasks per tenant (default 0, no limit)
+//   - API_KEYS_FILE: JSON file holding the issued API keys; requires an API key
+//     on every request when set
+//   - ADMIN_API_KEY: bootstrap key, at least 32 characters, for managing API
+//     keys; requires an API key on every request when set
+//   - JWT_ISSUER: issuer of the bearer tokens accepted; requires a token or API
+//     key on every request when set
+//   - JWKS_URL: where the issuer publishes its signing keys; either this or
+//     JWKS_FILE is required with JWT_ISSUER
+//   - JWKS_FILE: JSON Web Key Set file holding the issuer's signing keys
+//   - JWKS_REFRESH_INTERVAL: how long keys fetched from JWKS_URL are cached (default 1h)
+//   - JWT_AUDIENCE: audience tokens must be meant for (default any)
+//   - JWT_LEEWAY: allowed clock skew when checking token expiry (default 1m)
+//   - JWT_SUBJECT_CLAIM: claim naming the user (default "sub")
+//   - JWT_TENANT_CLAIM: claim naming the user's tenant (default none)
+//   - JWT_SCOPE_CLAIM: claim listing the user's scopes (default "scope")
+type Config struct {
+	StorageDriver         string
+	SQLitePath            string
+	JournalDir            string
+	JournalSync           string
+	JournalSyncInterval   time.Duration
+	JournalSnapshotEvery  int
+	SubtaskCompletePolicy model.CascadePolicy
+	SubtaskDeletePolicy   model.CascadePolicy
+	Workflow              *model.Workflow
+	TrashRetention        time.Duration
+	TrashReapInterval     time.Duration
+	AttachmentDir         string
+	AttachmentMaxSize     int64
+	AttachmentSweep       time.Duration
+	// Tenants lists the hosted tenants in ascending order, none for a
+	// single-tenant server.
+	Tenants []string
+	// TaskQuota caps the live tasks of a tenant unless TenantTaskQuotas has
+	// an entry for it; 0 means no limit.
+	TaskQuota        int
+	TenantTaskQuotas map[string]int
+	APIKeysFile      string
+	AdminAPIKey      string
+	JWT              JWTConfig
+}
+
+// JWTConfig configures bearer token authentication. It is off unless Issuer
+// is set, and then exactly one of JWKSURL and JWKSFile is.
+type JWTConfig struct {
+	Issuer       string
+	JWKSURL      string
+	JWKSFile     string
+	JWKSRefresh  time.Duration
+	Audience     string
+	Leeway       time.Duration
+	SubjectClaim string
+	TenantClaim  string
+	ScopeClaim   string
+}
+
+// minAdminKeyLength keeps the bootstrap admin key out of reach of guessing.
+const minAdminKeyLength = 32
+
+// APIKeysEnabled reports whether API keys are accepted.
+func (c Config) APIKeysEnabled() bool {
+	return c.APIKeysFile != "" || c.AdminAPIKey != ""
+}
+
+// AuthEnabled reports whether requests must present an API key or a bearer
+// token.
+func (c Config) AuthEnabled() bool {
+	return c.APIKeysEnabled() || c.JWT.Issuer != ""
+}
+
+// ForTenant returns the configuration of the storage of tenant: its journal
+// and attachment contents in subdirectories named after it and its SQLite
+// database in a file of its own next to SQLitePath, e.g. taskmanager-acme.db.
+func (c Config) ForTenant(tenant string) Config {
+	if c.JournalDir != "" {
+		c.JournalDir = filepath.Join(c.JournalDir, tenant)
+	}
+	c.AttachmentDir = filepath.Join(c.AttachmentDir, tenant)
+	if c.SQLitePath != ":memory:" {
+		ext := filepath.Ext(c.SQLitePath)
+		c.SQLitePath = strings.TrimSuffix(c.SQLitePath, ext) + "-" + tenant + ext
+	}
+	return c
+}
+
+// Load reads the configuration from the process environment.
+func Load() (Config, error) {
+	return FromEnv(os.Getenv)
+}
+
+// FromEnv reads the configuration using the given lookup function, which makes it
+// easy to test without touching the real environment.
+func FromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		StorageDriver:         StorageMemory,
+		SQLitePath:            "taskmanager.db",
+		JournalSync:           "always",
+		JournalSyncInterval:   time.Second,
+		JournalSnapshotEvery:  1000,
+		SubtaskCompletePolicy: model.CascadeBlock,
+		SubtaskDeletePolicy:   model.CascadeBlock,
+		Workflow:              model.DefaultWorkflow(),
+		TrashRetention:        30 * 24 * time.Hour,
+		TrashReapInterval:     time.Hour,
+		AttachmentDir:         "attachments",
+		AttachmentMaxSize:     10 << 20,
+		AttachmentSweep:       time.Hour,
+		JWT: JWTConfig{
+			JWKSRefresh:  time.Hour,
+			Leeway:       time.Minute,
+			SubjectClaim: "sub",
+			ScopeClaim:   "scope",
+		},
+	}
+	if v := strings.TrimSpace(getenv("STORAGE_DRIVER")); v != "" {
+		cfg.StorageDriver = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(getenv("SQLITE_PATH")); v != "" {
+		cfg.SQLitePath = v
+	}
+	cfg.JournalDir = strings.TrimSpace(getenv("JOURNAL_DIR"))
+	if v := strings.TrimSpace(getenv("JOURNAL_SYNC")); v != "" {
+		cfg.JournalSync = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(getenv("JOURNAL_SYNC_INTERVAL")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("invalid JOURNAL_SYNC_INTERVAL %q", v)
+		}
+		cfg.JournalSyncInterval = d
+	}
+	if v := strings.TrimSpace(getenv("JOURNAL_SNAPSHOT_EVERY")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, fmt.Errorf("invalid JOURNAL_SNAPSHOT_EVERY %q", v)
+		}
+		cfg.JournalSnapshotEvery = n
+	}
+	if v := strings.TrimSpace(getenv("TRASH_RETENTION")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return Config{}, fmt.Errorf("invalid TRASH_RETENTION %q", v)
+		}
+		cfg.TrashRetention = d
+	}
+	if v := strings.TrimSpace(getenv("TRASH_REAP_INTERVAL")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("invalid TRASH_REAP_INTERVAL %q", v)
+		}
+		cfg.TrashReapInterval = d
+	}
+	if v := strings.TrimSpace(getenv("ATTACHMENT_DIR")); v != "" {
+		cfg.AttachmentDir = v
+	}
+	if v := strings.TrimSpace(getenv("ATTACHMENT_MAX_SIZE")); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return Config{}, fmt.Errorf("invalid ATTACHMENT_MAX_SIZE %q", v)
+		}
+		cfg.AttachmentMaxSize = n
+	}
+	if v := strings.TrimSpace(getenv("ATTACHMENT_SWEEP_INTERVAL")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("invalid ATTACHMENT_SWEEP_INTERVAL %q", v)
+		}
+		cfg.AttachmentSweep = d
+	}
+	for _, p := range []struct {
+		name string
+		dst  *model.CascadePolicy
+	}{
+		{"SUBTASK_COMPLETE_POLICY", &cfg.SubtaskCompletePolicy},
+		{"SUBTASK_DELETE_POLICY", &cfg.SubtaskDeletePolicy},
+	} {
+		if v := strings.TrimSpace(getenv(p.name)); v != "" {
+			policy, err := model.ParseCascadePolicy(strings.ToLower(v))
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid %s: %w", p.name, err)
+			}
+			*p.dst = policy
+		}
+	}
+	if v := strings.TrimSpace(getenv("TASK_QUOTA")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return Config{}, fmt.Errorf("invalid TASK_QUOTA %q", v)
+		}
+		cfg.TaskQuota = n
+	}
+	if v := strings.TrimSpace(getenv("TENANTS")); v != "" {
+		if err := parseTenants(&cfg, v); err != nil {
+			return Config{}, fmt.Errorf("invalid TENANTS: %w", err)
+		}
+	}
+	cfg.APIKeysFile = strings.TrimSpace(getenv("API_KEYS_FILE"))
+	if v := strings.TrimSpace(getenv("ADMIN_API_KEY")); v != "" {
+		if len(v) < minAdminKeyLength {
+			return Config{}, fmt.Errorf("ADMIN_API_KEY must be at least %d characters", minAdminKeyLength)
+		}
+		cfg.AdminAPIKey = v
+	}
+	if err := parseJWT(&cfg.JWT, getenv); err != nil {
+		return Config{}, err
+	}
+	if path := strings.TrimSpace(getenv("WORKFLOW_FILE")); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("read WORKFLOW_FILE: %w", err)
+		}
+		if cfg.Workflow, err = model.ParseWorkflow(data); err != nil {
+			return Config{}, fmt.Errorf("invalid WORKFLOW_FILE %s: %w", path, err)
+		}
+	}
+
+	switch cfg.StorageDriver {
+	case StorageMemory, StorageSQLite:
+	default:
+		return Config{}, fmt.Errorf("unknown STORAGE_DRIVER %q (want %q or %q)", cfg.StorageDriver, StorageMemory, StorageSQLite)
+	}
+	switch cfg.JournalSync {
+	case "always", "interval", "never":
+	default:
+		return Config{}, fmt.Errorf("unknown JOURNAL_SYNC %q (want always, interval or never)", cfg.JournalSync)
+	}
+	return cfg, nil
+}
+
+// parseTenants fills in the tenants of cfg and their quotas from a TENANTS
+// value: tenant IDs separated by commas, each optionally followed by =quota.
+func parseTenants(cfg *Config, value string) error {
+	cfg.TenantTaskQuotas = make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		tenant, quota, hasQuota := strings.Cut(strings.TrimSpace(entry), "=")
+		if !identity.ValidTenant(tenant) {
+			return fmt.Errorf("tenant %q must be 1-63 lowercase letters, digits, - and _", tenant)
+		}
+		if slices.Contains(cfg.Tenants, tenant) {
+			return fmt.Errorf("tenant %q is listed twice", tenant)
+		}
+		if hasQuota {
+			n, err := strconv.Atoi(quota)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid task quota %q of tenant %s", quota, tenant)
+			}
+			cfg.TenantTaskQuotas[tenant] = n
+		}
+		cfg.Tenants = append(cfg.Tenants, tenant)
+	}
+	slices.Sort(cfg.Tenants)
+	return nil
+}
+
+// parseJWT fills in jwt from the JWT_ and JWKS_ variables.
+func parseJWT(jwt *JWTConfig, getenv func(string) string) error {
+	jwt.Issuer = strings.TrimSpace(getenv("JWT_ISSUER"))
+	jwt.JWKSURL = strings.TrimSpace(getenv("JWKS_URL"))
+	jwt.JWKSFile = strings.TrimSpace(getenv("JWKS_FILE"))
+	jwt.Audience = strings.TrimSpace(getenv("JWT_AUDIENCE"))
+	jwt.TenantClaim = strings.TrimSpace(getenv("JWT_TENANT_CLAIM"))
+	for name, dst := range map[string]*string{"JWT_SUBJECT_CLAIM": &jwt.SubjectClaim, "JWT_SCOPE_CLAIM": &jwt.ScopeClaim} {
+		if v := strings.TrimSpace(getenv(name)); v != "" {
+			*dst = v
+		}
+	}
+	if v := strings.TrimSpace(getenv("JWKS_REFRESH_INTERVAL")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid JWKS_REFRESH_INTERVAL %q", v)
+		}
+		jwt.JWKSRefresh = d
+	}
+	if v := strings.TrimSpace(getenv("JWT_LEEWAY")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid JWT_LEEWAY %q", v)
+		}
+		jwt.Leeway = d
+	}
+	switch {
+	case jwt.Issuer == "" && (jwt.JWKSURL != "" || jwt.JWKSFile != ""):
+		return fmt.Errorf("JWKS_URL and JWKS_FILE need JWT_ISSUER")
+	case jwt.Issuer != "" && (jwt.JWKSURL == "") == (jwt.JWKSFile == ""):
+		return fmt.Errorf("JWT_ISSUER needs either JWKS_URL or JWKS_FILE")
+	case jwt.JWKSURL != "" && !strings.HasPrefix(jwt.JWKSURL, "https://") && !strings.HasPrefix(jwt.JWKSURL, "http://"):
+		return fmt.Errorf("invalid JWKS_URL %q", jwt.JWKSURL)
+	}
+	return nil
+}
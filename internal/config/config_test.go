@@ -0,0 +1,259 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMap(m map[string]string) func(string) string {
+	return func(k string) string { return m[k] }
+}
+
+func TestFromEnv_Defaults(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, StorageMemory, cfg.StorageDriver)
+	assert.Equal(t, "taskmanager.db", cfg.SQLitePath)
+}
+
+func TestFromEnv_SQLite(t *testing.T) {
+	cfg, err := FromEnv(envMap(map[string]string{
+		"STORAGE_DRIVER": "SQLite",
+		"SQLITE_PATH":    "/data/tasks.db",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, StorageSQLite, cfg.StorageDriver)
+	assert.Equal(t, "/data/tasks.db", cfg.SQLitePath)
+}
+
+func TestFromEnv_UnknownDriver(t *testing.T) {
+	_, err := FromEnv(envMap(map[string]string{"STORAGE_DRIVER": "postgres"}))
+	assert.ErrorContains(t, err, "unknown STORAGE_DRIVER")
+}
+
+func TestFromEnv_Journal(t *testing.T) {
+	cfg, err := FromEnv(envMap(map[string]string{
+		"JOURNAL_DIR":            "/data/journal",
+		"JOURNAL_SYNC":           "interval",
+		"JOURNAL_SYNC_INTERVAL":  "250ms",
+		"JOURNAL_SNAPSHOT_EVERY": "10",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "/data/journal", cfg.JournalDir)
+	assert.Equal(t, "interval", cfg.JournalSync)
+	assert.Equal(t, 250*time.Millisecond, cfg.JournalSyncInterval)
+	assert.Equal(t, 10, cfg.JournalSnapshotEvery)
+}
+
+func TestFromEnv_InvalidJournalSettings(t *testing.T) {
+	for name, env := range map[string]map[string]string{
+		"sync policy":    {"JOURNAL_SYNC": "sometimes"},
+		"sync interval":  {"JOURNAL_SYNC_INTERVAL": "soon"},
+		"snapshot every": {"JOURNAL_SNAPSHOT_EVERY": "-1"},
+	} {
+		_, err := FromEnv(envMap(env))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFromEnv_SubtaskPolicies(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, model.CascadeBlock, cfg.SubtaskCompletePolicy)
+	assert.Equal(t, model.CascadeBlock, cfg.SubtaskDeletePolicy)
+
+	cfg, err = FromEnv(envMap(map[string]string{
+		"SUBTASK_COMPLETE_POLICY": "Cascade",
+		"SUBTASK_DELETE_POLICY":   "orphan",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, model.CascadeCascade, cfg.SubtaskCompletePolicy)
+	assert.Equal(t, model.CascadeOrphan, cfg.SubtaskDeletePolicy)
+
+	_, err = FromEnv(envMap(map[string]string{"SUBTASK_DELETE_POLICY": "purge"}))
+	assert.ErrorContains(t, err, "SUBTASK_DELETE_POLICY")
+}
+
+func TestFromEnv_Trash(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	require.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, cfg.TrashRetention)
+	assert.Equal(t, time.Hour, cfg.TrashReapInterval)
+
+	cfg, err = FromEnv(envMap(map[string]string{"TRASH_RETENTION": "0", "TRASH_REAP_INTERVAL": "5m"}))
+	require.NoError(t, err)
+	assert.Zero(t, cfg.TrashRetention)
+	assert.Equal(t, 5*time.Minute, cfg.TrashReapInterval)
+
+	for name, env := range map[string]map[string]string{
+		"retention": {"TRASH_RETENTION": "-1h"},
+		"interval":  {"TRASH_REAP_INTERVAL": "0s"},
+	} {
+		_, err := FromEnv(envMap(env))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFromEnv_Attachments(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "attachments", cfg.AttachmentDir)
+	assert.Equal(t, int64(10<<20), cfg.AttachmentMaxSize)
+	assert.Equal(t, time.Hour, cfg.AttachmentSweep)
+
+	cfg, err = FromEnv(envMap(map[string]string{
+		"ATTACHMENT_DIR":            "/var/lib/taskmanager/files",
+		"ATTACHMENT_MAX_SIZE":       "1024",
+		"ATTACHMENT_SWEEP_INTERVAL": "10m",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "/var/lib/taskmanager/files", cfg.AttachmentDir)
+	assert.Equal(t, int64(1024), cfg.AttachmentMaxSize)
+	assert.Equal(t, 10*time.Minute, cfg.AttachmentSweep)
+
+	for name, env := range map[string]map[string]string{
+		"size":     {"ATTACHMENT_MAX_SIZE": "0"},
+		"not size": {"ATTACHMENT_MAX_SIZE": "10MB"},
+		"interval": {"ATTACHMENT_SWEEP_INTERVAL": "-1m"},
+	} {
+		_, err := FromEnv(envMap(env))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFromEnv_Workflow(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultWorkflow(), cfg.Workflow)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workflow.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"states": ["open", "closed"],
+		"initial": "open",
+		"done": "closed",
+		"terminal": ["closed"],
+		"transitions": {"open": ["closed"]}
+	}`), 0o644))
+	cfg, err = FromEnv(envMap(map[string]string{"WORKFLOW_FILE": path}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"open", "closed"}, cfg.Workflow.States)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"states": ["open"], "initial": "open", "done": "open"}`), 0o644))
+	_, err = FromEnv(envMap(map[string]string{"WORKFLOW_FILE": path}))
+	assert.ErrorContains(t, err, "invalid WORKFLOW_FILE")
+	_, err = FromEnv(envMap(map[string]string{"WORKFLOW_FILE": filepath.Join(dir, "missing.json")}))
+	assert.Error(t, err)
+}
+
+func TestFromEnv_Tenants(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Tenants)
+	assert.Zero(t, cfg.TaskQuota)
+
+	cfg, err = FromEnv(envMap(map[string]string{"TENANTS": "globex=500, acme", "TASK_QUOTA": "10000"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme", "globex"}, cfg.Tenants)
+	assert.Equal(t, 10000, cfg.TaskQuota)
+	assert.Equal(t, map[string]int{"globex": 500}, cfg.TenantTaskQuotas)
+
+	for env, want := range map[string]string{
+		"TENANTS=Acme":      "invalid TENANTS",
+		"TENANTS=acme,,b":   "invalid TENANTS",
+		"TENANTS=acme,acme": "listed twice",
+		"TENANTS=acme=-1":   "invalid task quota",
+		"TENANTS=../etc":    "invalid TENANTS",
+		"TASK_QUOTA=lots":   "invalid TASK_QUOTA",
+		"TASK_QUOTA=-5":     "invalid TASK_QUOTA",
+	} {
+		name, value, _ := strings.Cut(env, "=")
+		_, err := FromEnv(envMap(map[string]string{name: value}))
+		assert.ErrorContains(t, err, want, env)
+	}
+}
+
+func TestFromEnv_APIKeys(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	require.NoError(t, err)
+	assert.False(t, cfg.AuthEnabled())
+
+	cfg, err = FromEnv(envMap(map[string]string{"API_KEYS_FILE": "/data/api_keys.json"}))
+	require.NoError(t, err)
+	assert.Equal(t, "/data/api_keys.json", cfg.APIKeysFile)
+	assert.True(t, cfg.AuthEnabled())
+
+	admin := strings.Repeat("k", 32)
+	cfg, err = FromEnv(envMap(map[string]string{"ADMIN_API_KEY": admin}))
+	require.NoError(t, err)
+	assert.Equal(t, admin, cfg.AdminAPIKey)
+	assert.True(t, cfg.AuthEnabled())
+
+	_, err = FromEnv(envMap(map[string]string{"ADMIN_API_KEY": "hunter2"}))
+	assert.ErrorContains(t, err, "ADMIN_API_KEY must be at least 32 characters")
+}
+
+func TestFromEnv_JWT(t *testing.T) {
+	cfg, err := FromEnv(envMap(nil))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.JWT.Issuer)
+	assert.Equal(t, "sub", cfg.JWT.SubjectClaim)
+	assert.Equal(t, "scope", cfg.JWT.ScopeClaim)
+	assert.Equal(t, time.Hour, cfg.JWT.JWKSRefresh)
+
+	cfg, err = FromEnv(envMap(map[string]string{
+		"JWT_ISSUER":            "https://login.example.com",
+		"JWKS_URL":              "https://login.example.com/keys",
+		"JWKS_REFRESH_INTERVAL": "15m",
+		"JWT_AUDIENCE":          "taskmanager",
+		"JWT_LEEWAY":            "0s",
+		"JWT_SUBJECT_CLAIM":     "preferred_username",
+		"JWT_TENANT_CLAIM":      "org",
+		"JWT_SCOPE_CLAIM":       "scp",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, JWTConfig{
+		Issuer: "https://login.example.com", JWKSURL: "https://login.example.com/keys", JWKSRefresh: 15 * time.Minute,
+		Audience: "taskmanager", SubjectClaim: "preferred_username", TenantClaim: "org", ScopeClaim: "scp",
+	}, cfg.JWT)
+	assert.True(t, cfg.AuthEnabled())
+	assert.False(t, cfg.APIKeysEnabled())
+
+	for env, want := range map[string]string{
+		"JWT_ISSUER=https://login.example.com":                      "needs either JWKS_URL or JWKS_FILE",
+		"JWT_ISSUER=x,JWKS_URL=https://x/keys,JWKS_FILE=keys.json":  "needs either JWKS_URL or JWKS_FILE",
+		"JWKS_FILE=keys.json":                                       "need JWT_ISSUER",
+		"JWT_ISSUER=x,JWKS_URL=ftp://x/keys":                        "invalid JWKS_URL",
+		"JWT_ISSUER=x,JWKS_FILE=keys.json,JWKS_REFRESH_INTERVAL=0s": "invalid JWKS_REFRESH_INTERVAL",
+		"JWT_ISSUER=x,JWKS_FILE=keys.json,JWT_LEEWAY=soon":          "invalid JWT_LEEWAY",
+	} {
+		vars := map[string]string{}
+		for _, kv := range strings.Split(env, ",") {
+			name, value, _ := strings.Cut(kv, "=")
+			vars[name] = value
+		}
+		_, err := FromEnv(envMap(vars))
+		assert.ErrorContains(t, err, want, env)
+	}
+}
+
+func TestConfig_ForTenant(t *testing.T) {
+	cfg := Config{SQLitePath: "/data/tasks.db", JournalDir: "/data/journal", AttachmentDir: "attachments"}
+	acme := cfg.ForTenant("acme")
+	assert.Equal(t, "/data/tasks-acme.db", acme.SQLitePath)
+	assert.Equal(t, filepath.Join("/data/journal", "acme"), acme.JournalDir)
+	assert.Equal(t, filepath.Join("attachments", "acme"), acme.AttachmentDir)
+	assert.Equal(t, "/data/tasks.db", cfg.SQLitePath, "the configuration is copied")
+
+	cfg = Config{SQLitePath: ":memory:"}
+	assert.Equal(t, ":memory:", cfg.ForTenant("acme").SQLitePath)
+	assert.Empty(t, cfg.ForTenant("acme").JournalDir)
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+	"taskmanager/internal/identity"
+)
+
+// ActorHeader names the user a request is made on behalf of. It is trusted as
+// is, so it must be set by an authenticating gateway in front of the API.
+const ActorHeader = "X-Actor"
+
+// Actor is middleware that stores the ActorHeader of every request in its
+// context, where the task history picks it up. Missing or malformed values
+// leave the actor unknown.
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); validRequestID(actor) {
+			r = r.WithContext(identity.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
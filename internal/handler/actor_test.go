@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taskmanager/internal/identity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActor(t *testing.T) {
+	var seen string
+	h := Actor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = identity.Actor(r.Context())
+	}))
+
+	for incoming, want := range map[string]string{"alice": "alice", "": "", "bad actor": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(ActorHeader, incoming)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, want, seen, "header %q", incoming)
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// APIKeyHandler handles HTTP requests for the /admin/keys endpoints.
+type APIKeyHandler struct {
+	service service.APIKeyService
+	logger  *zap.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(service service.APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{service: service, logger: logger}
+}
+
+// RegisterRoutes registers the /admin/keys routes to the given mux.
+func (h *APIKeyHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/keys", h.handleKeys)
+	mux.HandleFunc("/admin/keys/", h.handleKeyByID)
+}
+
+// handleKeys handles POST (issue) and GET (list) on /admin/keys.
+func (h *APIKeyHandler) handleKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req model.APIKey
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		issued, err := h.service.IssueKey(r.Context(), &req)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		// The secret is in the body, which must not be cached anywhere.
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusCreated, issued)
+	case http.MethodGet:
+		keys, err := h.service.ListKeys(r.Context())
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, keys)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// handleKeyByID handles DELETE (revoke) on /admin/keys/{id}.
+func (h *APIKeyHandler) handleKeyByID(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/admin/keys/")
+	if id == "" || strings.Contains(id, "/") {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", "DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	if err := h.service.RevokeKey(r.Context(), id); err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
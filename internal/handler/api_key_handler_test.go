@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testAdminKey is the bootstrap admin key of setupAuth.
+const testAdminKey = "test-admin-key-0123456789abcdef0123"
+
+// setupAuth serves tasks and key management behind RequireAPIKey, the way
+// the server chains its middleware.
+func setupAuth(t *testing.T) http.Handler {
+	t.Helper()
+	keyRepo, err := repository.OpenFileAPIKeyRepository("", zap.NewNop())
+	require.NoError(t, err)
+	keys := service.NewAPIKeyService(keyRepo, testAdminKey, zap.NewNop())
+	mux := http.NewServeMux()
+	NewServiceHandler().RegisterRoutes(mux)
+	NewHealthHandler().RegisterRoutes(mux)
+	NewTaskHandler(service.NewTaskService(repository.NewInMemoryTaskRepository(zap.NewNop()), zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	NewAPIKeyHandler(keys, zap.NewNop()).RegisterRoutes(mux)
+	return Actor(RequireAPIKey(keys, zap.NewNop(), mux))
+}
+
+func serveWithKey(h http.Handler, key, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// issueKey issues a key with scopes through the admin endpoint.
+func issueKey(t *testing.T, h http.Handler, name string, scopes ...string) *model.IssuedAPIKey {
+	t.Helper()
+	body, err := json.Marshal(&model.APIKey{Name: name, Scopes: scopes})
+	require.NoError(t, err)
+	w := serveWithKey(h, testAdminKey, http.MethodPost, "/admin/keys", string(body))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var issued model.IssuedAPIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&issued))
+	return &issued
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	h := setupAuth(t)
+
+	w := serveWithKey(h, testAdminKey, http.MethodPost, "/admin/keys", `{"name":"CI","scopes":["tasks:read"],"hash":"x"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var issued map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&issued))
+	assert.NotContains(t, issued, "hash")
+	assert.Equal(t, "apikey:admin", issued["created_by"])
+	assert.True(t, strings.HasPrefix(issued["key"].(string), "tm_"))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, serveWithKey(h, testAdminKey, http.MethodPost, "/admin/keys", `{"name":"CI"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveWithKey(h, testAdminKey, http.MethodPost, "/admin/keys", `{`).Code)
+
+	w = serveWithKey(h, testAdminKey, http.MethodGet, "/admin/keys", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"key"`, "secrets are shown once")
+	var keys []model.APIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&keys))
+	require.Len(t, keys, 1)
+	assert.Equal(t, []string{model.ScopeTasksRead}, keys[0].Scopes)
+
+	w = serveWithKey(h, testAdminKey, http.MethodGet, "/admin/keys/"+keys[0].ID, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "DELETE", w.Header().Get("Allow"))
+	assert.Equal(t, http.StatusNoContent, serveWithKey(h, testAdminKey, http.MethodDelete, "/admin/keys/"+keys[0].ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWithKey(h, testAdminKey, http.MethodDelete, "/admin/keys/"+keys[0].ID, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(h, issued["key"].(string), http.MethodGet, "/tasks", "").Code)
+}
+
+func TestAPIKeyHandler_TenantAdmin(t *testing.T) {
+	h := setupAuth(t)
+	issue := func(key, body string) *httptest.ResponseRecorder {
+		return serveWithKey(h, key, http.MethodPost, "/admin/keys", body)
+	}
+	w := issue(testAdminKey, `{"name":"Acme admin","scopes":["admin"],"tenant":"acme"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var acmeAdmin model.IssuedAPIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&acmeAdmin))
+	w = issue(testAdminKey, `{"name":"Globex CI","scopes":["tasks:read"],"tenant":"globex"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var globexKey model.IssuedAPIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&globexKey))
+
+	// A tenant's admin issues keys of its tenant only.
+	assert.Equal(t, http.StatusForbidden, issue(acmeAdmin.Key, `{"name":"Sneaky","scopes":["tasks:write"],"tenant":"globex"}`).Code)
+	assert.Equal(t, http.StatusForbidden, issue(acmeAdmin.Key, `{"name":"Sneaky","scopes":["tasks:write"]}`).Code)
+	w = issue(acmeAdmin.Key, `{"name":"Acme CI","scopes":["tasks:write"],"tenant":"acme"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var acmeKey model.IssuedAPIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&acmeKey))
+
+	// It sees and revokes keys of its tenant only.
+	w = serveWithKey(h, acmeAdmin.Key, http.MethodGet, "/admin/keys", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var keys []model.APIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&keys))
+	ids := []string{}
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	assert.ElementsMatch(t, []string{acmeAdmin.ID, acmeKey.ID}, ids)
+
+	assert.Equal(t, http.StatusNotFound, serveWithKey(h, acmeAdmin.Key, http.MethodDelete, "/admin/keys/"+globexKey.ID, "").Code)
+	assert.Equal(t, http.StatusOK, serveWithKey(h, globexKey.Key, http.MethodGet, "/tasks", "").Code, "the key of the other tenant still works")
+	assert.Equal(t, http.StatusNoContent, serveWithKey(h, acmeAdmin.Key, http.MethodDelete, "/admin/keys/"+acmeKey.ID, "").Code)
+
+	// The unconfined admin still manages every key.
+	w = serveWithKey(h, testAdminKey, http.MethodGet, "/admin/keys", "")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&keys))
+	assert.Len(t, keys, 2)
+	assert.Equal(t, http.StatusNoContent, serveWithKey(h, testAdminKey, http.MethodDelete, "/admin/keys/"+globexKey.ID, "").Code)
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// multipartOverhead is the room left in an upload body for the multipart
+// framing around the file.
+const multipartOverhead = 64 << 10
+
+// AttachmentHandler handles HTTP requests for the /tasks/{id}/attachments
+// sub-resource.
+type AttachmentHandler struct {
+	service service.AttachmentService
+	maxSize int64
+	logger  *zap.Logger
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler accepting uploads of
+// files of at most maxSize bytes.
+func NewAttachmentHandler(service service.AttachmentService, maxSize int64, logger *zap.Logger) *AttachmentHandler {
+	return &AttachmentHandler{service: service, maxSize: maxSize, logger: logger}
+}
+
+// RegisterRoutes mounts the attachment routes on tasks.
+func (h *AttachmentHandler) RegisterRoutes(tasks *TaskHandler) {
+	tasks.Mount("attachments", h.handleAttachments)
+}
+
+// handleAttachments serves GET (list) and POST (upload) on
+// /tasks/{id}/attachments, GET and DELETE on
+// /tasks/{id}/attachments/{attachment_id} and GET on
+// /tasks/{id}/attachments/{attachment_id}/content.
+func (h *AttachmentHandler) handleAttachments(w http.ResponseWriter, r *http.Request, id, rest string) {
+	if rest == "" {
+		h.handleAttachmentList(w, r, id)
+		return
+	}
+	attachmentID, sub, _ := strings.Cut(rest, "/")
+	switch sub {
+	case "":
+	case "content":
+		h.handleContent(w, r, id, attachmentID)
+		return
+	default:
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		attachment, err := h.service.GetAttachment(r.Context(), id, attachmentID)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, attachment)
+	case http.MethodDelete:
+		if err := h.service.DeleteAttachment(r.Context(), id, attachmentID); err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// handleAttachmentList lists the attachments of task id or uploads a new one.
+func (h *AttachmentHandler) handleAttachmentList(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		attachments, err := h.service.ListAttachments(r.Context(), id)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, attachments)
+	case http.MethodPost:
+		h.upload(w, r, id)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// upload streams the "file" part of a multipart/form-data body into a new
+// attachment on task id. Other parts are ignored.
+func (h *AttachmentHandler) upload(w http.ResponseWriter, r *http.Request, id string) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeErrorFields(h.logger, w, r, http.StatusBadRequest, "expected a multipart/form-data body", nil)
+		return
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, `missing "file" part`, nil)
+			return
+		}
+		if err != nil {
+			h.writeUploadError(w, r, fmt.Errorf("%w: %w", errMalformedUpload, err))
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		attachment, err := h.service.AddAttachment(r.Context(), id, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			h.writeUploadError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
+
+// errMalformedUpload marks errors reading the multipart framing of an upload.
+var errMalformedUpload = errors.New("malformed multipart body")
+
+// writeUploadError reports a failed upload. A body over the limit is reported
+// like a file over the limit; a truncated or garbled body is a bad request.
+func (h *AttachmentHandler) writeUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeErrorFields(h.logger, w, r, http.StatusRequestEntityTooLarge, "request body is too large", nil)
+	case errors.Is(err, errMalformedUpload), errors.Is(err, io.ErrUnexpectedEOF):
+		writeErrorFields(h.logger, w, r, http.StatusBadRequest, "malformed multipart body", nil)
+	default:
+		writeServiceError(h.logger, w, r, err)
+	}
+}
+
+// handleContent downloads the content of an attachment. It is always served
+// as a download under its original name, with the sniffed content type, so
+// that browsers never render uploaded files inline.
+func (h *AttachmentHandler) handleContent(w http.ResponseWriter, r *http.Request, id, attachmentID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	attachment, content, err := h.service.OpenAttachment(r.Context(), id, attachmentID)
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	defer content.Close()
+	// The content never changes, so its digest is a strong entity tag.
+	etag := `"` + attachment.SHA256 + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		h.logger.Warn("failed to send attachment", zap.String("id", attachmentID), zap.Error(err))
+	}
+}
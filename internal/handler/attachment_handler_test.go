@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupAttachmentHandler(t *testing.T, maxSize int64) *http.ServeMux {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	blobs, err := repository.NewFileBlobStore(t.TempDir(), zap.NewNop())
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	tasks := NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop())
+	tasks.RegisterRoutes(mux)
+	attachments := service.NewAttachmentService(repo, blobs, maxSize, zap.NewNop())
+	NewAttachmentHandler(attachments, maxSize, zap.NewNop()).RegisterRoutes(tasks)
+	require.Equal(t, http.StatusCreated, serve(mux, http.MethodPost, "/tasks", `{"id":"t","title":"Broken build"}`).Code)
+	return mux
+}
+
+// upload posts content as the "file" part of a multipart form.
+func upload(t *testing.T, mux *http.ServeMux, target, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("comment", "ignored"))
+	fw, err := mw.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAttachmentHandler_UploadAndDownload(t *testing.T) {
+	mux := setupAttachmentHandler(t, 1024)
+
+	w := upload(t, mux, "/tasks/t/attachments", "build log.txt", "error: exit 1\n")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created model.Attachment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, "build log.txt", created.Filename)
+	assert.Equal(t, "text/plain; charset=utf-8", created.ContentType)
+	assert.Equal(t, int64(14), created.Size)
+
+	w = serve(mux, http.MethodGet, "/tasks/t/attachments", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []model.Attachment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list, 1)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	target := "/tasks/t/attachments/" + created.ID
+	w = serve(mux, http.MethodGet, target, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.SHA256)
+
+	w = serve(mux, http.MethodGet, target+"/content", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "error: exit 1\n", w.Body.String())
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "14", w.Header().Get("Content-Length"))
+	assert.Equal(t, `attachment; filename="build log.txt"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"`+created.SHA256+`"`, etag)
+
+	req := httptest.NewRequest(http.MethodGet, target+"/content", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodPut, target, "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodPost, target+"/content", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, target+"/x", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(mux, http.MethodDelete, target, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, target+"/content", "").Code)
+}
+
+func TestAttachmentHandler_UploadErrors(t *testing.T) {
+	mux := setupAttachmentHandler(t, 16)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload(t, mux, "/tasks/t/attachments", "big.log", strings.Repeat("x", 17)).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, upload(t, mux, "/tasks/t/attachments", "..", "x").Code)
+	assert.Equal(t, http.StatusNotFound, upload(t, mux, "/tasks/missing/attachments", "a.txt", "x").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodPost, "/tasks/t/attachments", `{"file":"x"}`).Code)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("note", "no file"))
+	require.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/tasks/t/attachments", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodDelete, "/tasks/t/attachments", "").Code)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader carries an API key for clients that cannot set Authorization.
+const APIKeyHeader = "X-API-Key"
+
+// authChallenge is the WWW-Authenticate value of 401 responses.
+const authChallenge = `Bearer realm="taskmanager"`
+
+// Authenticator checks one kind of credentials, such as API keys or bearer
+// tokens of an identity provider.
+type Authenticator interface {
+	// Accepts reports whether token looks like a credential of its kind.
+	Accepts(token string) bool
+	// Authenticate returns the principal token stands for. Invalid
+	// credentials fail with an error of kind Unauthenticated.
+	Authenticate(ctx context.Context, token string) (*identity.Principal, error)
+}
+
+// RequireAuth is middleware that admits only requests presenting credentials
+// one of auths accepts, as a bearer token in the Authorization header or in
+// APIKeyHeader, for a principal with the scope the route needs: see
+// requiredScope. The principal becomes the actor of the request, replacing any
+// ActorHeader, and a principal confined to a tenant acts in it whatever
+// TenantHeader says.
+func RequireAuth(auths []Authenticator, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := requiredScope(r)
+		if scope == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := presentedToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", authChallenge)
+			writeErrorFields(logger, w, r, http.StatusUnauthorized, "authentication required", nil)
+			return
+		}
+		principal, err := authenticate(r.Context(), auths, token)
+		if err != nil {
+			if apperror.KindOf(err) == apperror.KindUnauthenticated {
+				w.Header().Set("WWW-Authenticate", authChallenge)
+			}
+			writeServiceError(logger, w, r, err)
+			return
+		}
+		if !principal.HasScope(scope) {
+			writeErrorFields(logger, w, r, http.StatusForbidden, "missing scope "+scope, nil)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(identity.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// RequireAPIKey is RequireAuth for API keys alone.
+func RequireAPIKey(keys service.APIKeyService, logger *zap.Logger, next http.Handler) http.Handler {
+	return RequireAuth([]Authenticator{APIKeyAuth(keys)}, logger, next)
+}
+
+// errUnknownCredentials is returned for tokens no authenticator accepts.
+var errUnknownCredentials = apperror.Unauthenticated("unsupported credentials")
+
+// authenticate tries the authenticators accepting token in turn. Unless one
+// succeeds it returns the first error.
+func authenticate(ctx context.Context, auths []Authenticator, token string) (*identity.Principal, error) {
+	var first error
+	for _, auth := range auths {
+		if !auth.Accepts(token) {
+			continue
+		}
+		principal, err := auth.Authenticate(ctx, token)
+		if err == nil {
+			return principal, nil
+		}
+		if apperror.KindOf(err) != apperror.KindUnauthenticated {
+			return nil, err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if first == nil {
+		return nil, errUnknownCredentials
+	}
+	return nil, first
+}
+
+// requiredScope returns the scope a request needs, "" for the public service
+// and health endpoints. Key management needs the admin scope, reads of
+// anything else tasks:read and changes tasks:write.
+func requiredScope(r *http.Request) string {
+	switch path := r.URL.Path; {
+	case path == "/" || path == "/healthz":
+		return ""
+	case path == "/admin" || strings.HasPrefix(path, "/admin/"):
+		return model.ScopeAdmin
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return model.ScopeTasksRead
+	}
+	return model.ScopeTasksWrite
+}
+
+// presentedToken returns the credentials of a request, "" if it has none.
+func presentedToken(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(APIKeyHeader))
+}
+
+// apiKeyAuth authenticates API keys.
+type apiKeyAuth struct {
+	keys service.APIKeyService
+}
+
+// APIKeyAuth returns an Authenticator of the API keys of keys. It accepts any
+// token, as the bootstrap admin key can have any form, so it goes last.
+func APIKeyAuth(keys service.APIKeyService) Authenticator {
+	return apiKeyAuth{keys: keys}
+}
+
+func (a apiKeyAuth) Accepts(token string) bool {
+	return true
+}
+
+// Authenticate makes the key a principal named apikey:<id>.
+func (a apiKeyAuth) Authenticate(ctx context.Context, token string) (*identity.Principal, error) {
+	key, err := a.keys.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &identity.Principal{Subject: "apikey:" + key.ID, Tenant: key.Tenant, Scopes: key.Scopes}, nil
+}
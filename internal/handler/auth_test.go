@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	h := setupAuth(t)
+	reader := issueKey(t, h, "Dashboard", model.ScopeTasksRead)
+	writer := issueKey(t, h, "Worker", model.ScopeTasksRead, model.ScopeTasksWrite)
+
+	// Without a valid key only the service and health endpoints answer.
+	assert.Equal(t, http.StatusOK, serveWithKey(h, "", http.MethodGet, "/", "").Code)
+	assert.Equal(t, http.StatusOK, serveWithKey(h, "", http.MethodGet, "/healthz", "").Code)
+	w := serveWithKey(h, "", http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="taskmanager"`, w.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, w.Body.String(), "urn:taskmanager:problem:unauthorized")
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(h, "tm_0000_guess", http.MethodDelete, "/tasks/t1", "").Code)
+
+	// Reads need tasks:read, changes tasks:write and key management admin.
+	assert.Equal(t, http.StatusOK, serveWithKey(h, reader.Key, http.MethodGet, "/tasks", "").Code)
+	w = serveWithKey(h, reader.Key, http.MethodPost, "/tasks", `{"id":"t1","title":"Deploy"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "tasks:write")
+	assert.Equal(t, http.StatusForbidden, serveWithKey(h, reader.Key, http.MethodDelete, "/tasks/t1", "").Code)
+	assert.Equal(t, http.StatusCreated, serveWithKey(h, writer.Key, http.MethodPost, "/tasks", `{"id":"t1","title":"Deploy"}`).Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(h, writer.Key, http.MethodGet, "/admin/keys", "").Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(h, testAdminKey, http.MethodGet, "/tasks", "").Code, "admin does not imply task access")
+
+	// The key is also accepted in its own header.
+	r := httptest.NewRequest(http.MethodGet, "/tasks/t1", nil)
+	r.Header.Set(APIKeyHeader, reader.Key)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireAPIKey_Identity(t *testing.T) {
+	keyRepo, err := repository.OpenFileAPIKeyRepository("", zap.NewNop())
+	require.NoError(t, err)
+	keys := service.NewAPIKeyService(keyRepo, "", zap.NewNop())
+	ctx := t.Context()
+	free, err := keys.IssueKey(ctx, &model.APIKey{Name: "Any tenant", Scopes: []string{model.ScopeTasksRead}})
+	require.NoError(t, err)
+	bound, err := keys.IssueKey(ctx, &model.APIKey{Name: "Acme", Scopes: []string{model.ScopeTasksRead}, Tenant: "acme"})
+	require.NoError(t, err)
+
+	var actor, tenant string
+	h := Tenant(Actor(RequireAPIKey(keys, zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, tenant = identity.Actor(r.Context()), identity.Tenant(r.Context())
+	}))))
+	serve := func(key string) {
+		r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		r.Header.Set("Authorization", "bearer "+key)
+		r.Header.Set(ActorHeader, "mallory")
+		r.Header.Set(TenantHeader, "globex")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve(free.Key)
+	assert.Equal(t, "apikey:"+free.ID, actor, "the key, not the header, is the actor")
+	assert.Equal(t, "globex", tenant)
+	serve(bound.Key)
+	assert.Equal(t, "apikey:"+bound.ID, actor)
+	assert.Equal(t, "acme", tenant, "a tenant-bound key overrides the header")
+}
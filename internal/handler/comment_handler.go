@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// CommentHandler handles HTTP requests for the /tasks/{id}/comments
+// sub-resource.
+type CommentHandler struct {
+	service service.CommentService
+	logger  *zap.Logger
+}
+
+// NewCommentHandler creates a new CommentHandler.
+func NewCommentHandler(service service.CommentService, logger *zap.Logger) *CommentHandler {
+	return &CommentHandler{service: service, logger: logger}
+}
+
+// RegisterRoutes mounts the comment routes on tasks.
+func (h *CommentHandler) RegisterRoutes(tasks *TaskHandler) {
+	tasks.Mount("comments", h.handleComments)
+}
+
+// commentBody is the body of POST /tasks/{id}/comments and of PATCH on a
+// comment. The author and timestamps are set by the server.
+type commentBody struct {
+	Body string `json:"body"`
+}
+
+// handleComments serves GET (list) and POST (create) on /tasks/{id}/comments
+// and GET, PATCH and DELETE on /tasks/{id}/comments/{comment_id}.
+func (h *CommentHandler) handleComments(w http.ResponseWriter, r *http.Request, id, commentID string) {
+	if commentID == "" {
+		h.handleCommentList(w, r, id)
+		return
+	}
+	if strings.Contains(commentID, "/") {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		comment, err := h.service.GetComment(r.Context(), id, commentID)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, comment)
+	case http.MethodPatch:
+		var req commentBody
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		comment, err := h.service.EditComment(r.Context(), id, commentID, req.Body)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, comment)
+	case http.MethodDelete:
+		if err := h.service.DeleteComment(r.Context(), id, commentID); err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// handleCommentList lists the comments on task id, a page at a time with a
+// Link header to the next page, or posts a new one.
+func (h *CommentHandler) handleCommentList(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		q, fields := parseCommentQuery(r.URL.Query())
+		if len(fields) > 0 {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid query parameters", fields)
+			return
+		}
+		q.TaskID = id
+		page, err := h.service.ListComments(r.Context(), q)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		if page.Next != nil {
+			w.Header().Set("Link", nextPageLink(r, page.Next.Encode()))
+		}
+		writeJSON(w, http.StatusOK, page.Comments)
+	case http.MethodPost:
+		var req commentBody
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		comment, err := h.service.AddComment(r.Context(), id, req.Body)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, comment)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// parseCommentQuery reads the limit and cursor parameters of a comment listing.
+func parseCommentQuery(values url.Values) (model.CommentQuery, []apperror.FieldError) {
+	var (
+		q      model.CommentQuery
+		fields []apperror.FieldError
+	)
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			fields = append(fields, apperror.FieldError{Field: "limit", Message: "limit must be a positive integer"})
+		} else {
+			q.Limit = n
+		}
+	}
+	if v := values.Get("cursor"); v != "" {
+		c, err := model.ParseCommentCursor(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldsOf(err)...)
+		} else {
+			q.After = c
+		}
+	}
+	return q, fields
+}
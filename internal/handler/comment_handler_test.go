@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupCommentHandler(t *testing.T) *http.ServeMux {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	mux := http.NewServeMux()
+	tasks := NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop())
+	tasks.RegisterRoutes(mux)
+	NewCommentHandler(service.NewCommentService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(tasks)
+	require.Equal(t, http.StatusCreated, serve(mux, http.MethodPost, "/tasks", `{"id":"t","title":"Discussed"}`).Code)
+	return mux
+}
+
+func TestCommentHandler_CRUD(t *testing.T) {
+	mux := setupCommentHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks/t/comments", strings.NewReader(`{"body":"Looks good"}`))
+	req.Header.Set(ActorHeader, "alice")
+	w := httptest.NewRecorder()
+	Actor(mux).ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created model.Comment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, "t", created.TaskID)
+	assert.Equal(t, "alice", created.Author)
+	assert.False(t, created.Edited)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(mux, http.MethodPost, "/tasks/t/comments", `{"body":""}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodPost, "/tasks/t/comments", `{`).Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodPost, "/tasks/missing/comments", `{"body":"Hi"}`).Code)
+
+	target := "/tasks/t/comments/" + created.ID
+	w = serve(mux, http.MethodPatch, target, `{"body":"Looks great"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var edited model.Comment
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&edited))
+	assert.True(t, edited.Edited)
+	assert.Equal(t, "Looks great", edited.Body)
+
+	w = serve(mux, http.MethodGet, target, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"edited":true`)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodPut, target, "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodDelete, "/tasks/t/comments", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, target+"/x", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(mux, http.MethodDelete, target, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, target, "").Code)
+}
+
+func TestCommentHandler_List(t *testing.T) {
+	mux := setupCommentHandler(t)
+	for _, body := range []string{"one", "two", "three"} {
+		require.Equal(t, http.StatusCreated, serve(mux, http.MethodPost, "/tasks/t/comments", `{"body":"`+body+`"}`).Code)
+	}
+
+	var bodies []string
+	target := "/tasks/t/comments?limit=2"
+	for pages := 0; target != ""; pages++ {
+		require.Less(t, pages, 3)
+		w := serve(mux, http.MethodGet, target, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var comments []model.Comment
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&comments))
+		for _, c := range comments {
+			bodies = append(bodies, c.Body)
+		}
+		target = ""
+		if link := w.Header().Get("Link"); link != "" {
+			target = link[1:strings.Index(link, ">")]
+		}
+	}
+	assert.Equal(t, []string{"one", "two", "three"}, bodies)
+
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodGet, "/tasks/t/comments?limit=0", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodGet, "/tasks/t/comments?cursor=bogus!", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, "/tasks/missing/comments", "").Code)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// DependencyHandler handles HTTP requests for task dependencies: the
+// /tasks/{id}/blockers and /tasks/{id}/dependents sub-resources and the
+// graph views below /tasks.
+type DependencyHandler struct {
+	service service.DependencyService
+	logger  *zap.Logger
+}
+
+// NewDependencyHandler creates a new DependencyHandler.
+func NewDependencyHandler(service service.DependencyService, logger *zap.Logger) *DependencyHandler {
+	return &DependencyHandler{service: service, logger: logger}
+}
+
+// RegisterRoutes registers the graph views to the given mux and mounts the
+// per-task routes on tasks.
+func (h *DependencyHandler) RegisterRoutes(mux *http.ServeMux, tasks *TaskHandler) {
+	mux.HandleFunc("/tasks/order", h.handleOrder)
+	mux.HandleFunc("/tasks/unblocked", h.handleUnblocked)
+	mux.HandleFunc("/tasks/critical-path", h.handleCriticalPath)
+	tasks.Mount("blockers", h.handleBlockers)
+	tasks.Mount("dependents", h.handleDependents)
+}
+
+// handleBlockers serves GET /tasks/{id}/blockers, the tasks blocking id, and
+// PUT and DELETE /tasks/{id}/blockers/{blocker_id}, which add and remove a
+// dependency. PUT responds 201 with the new dependency, or 200 if it existed.
+func (h *DependencyHandler) handleBlockers(w http.ResponseWriter, r *http.Request, id, blockerID string) {
+	if blockerID == "" {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+			return
+		}
+		tasks, err := h.service.ListBlockers(r.Context(), id)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tasks)
+		return
+	}
+	if strings.Contains(blockerID, "/") {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		dep, created, err := h.service.AddDependency(r.Context(), id, blockerID)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, dep)
+	case http.MethodDelete:
+		if err := h.service.RemoveDependency(r.Context(), id, blockerID); err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "PUT, DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// handleDependents serves GET /tasks/{id}/dependents, the tasks blocked by id.
+func (h *DependencyHandler) handleDependents(w http.ResponseWriter, r *http.Request, id, rest string) {
+	if rest != "" {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	tasks, err := h.service.ListDependents(r.Context(), id)
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleOrder serves GET /tasks/order, the incomplete tasks in an order that
+// respects their dependencies.
+func (h *DependencyHandler) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if !h.allowGet(w, r) {
+		return
+	}
+	tasks, err := h.service.TopologicalOrder(r.Context())
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleUnblocked serves GET /tasks/unblocked, the incomplete tasks that can
+// be worked on now.
+func (h *DependencyHandler) handleUnblocked(w http.ResponseWriter, r *http.Request) {
+	if !h.allowGet(w, r) {
+		return
+	}
+	tasks, err := h.service.UnblockedTasks(r.Context())
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleCriticalPath serves GET /tasks/critical-path.
+func (h *DependencyHandler) handleCriticalPath(w http.ResponseWriter, r *http.Request) {
+	if !h.allowGet(w, r) {
+		return
+	}
+	path, err := h.service.CriticalPath(r.Context())
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, path)
+}
+
+// allowGet writes a 405 response and returns false unless r is a GET.
+func (h *DependencyHandler) allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet {
+		return true
+	}
+	w.Header().Set("Allow", "GET")
+	writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	return false
+}
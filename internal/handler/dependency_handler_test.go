@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupDependencyHandler(t *testing.T) *http.ServeMux {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	mux := http.NewServeMux()
+	tasks := NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop())
+	tasks.RegisterRoutes(mux)
+	NewDependencyHandler(service.NewDependencyService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux, tasks)
+	for _, body := range []string{
+		`{"id":"design","title":"Design","estimate_minutes":120}`,
+		`{"id":"build","title":"Build","estimate_minutes":480}`,
+		`{"id":"docs","title":"Docs","estimate_minutes":60}`,
+	} {
+		require.Equal(t, http.StatusCreated, serve(mux, http.MethodPost, "/tasks", body).Code)
+	}
+	return mux
+}
+
+func decodeTaskIDs(t *testing.T, body []byte) []string {
+	t.Helper()
+	var tasks []model.Task
+	require.NoError(t, json.Unmarshal(body, &tasks))
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestDependencyHandler_Blockers(t *testing.T) {
+	mux := setupDependencyHandler(t)
+
+	w := serve(mux, http.MethodPut, "/tasks/build/blockers/design", "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var dep model.Dependency
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&dep))
+	assert.Equal(t, model.Dependency{TaskID: "build", BlockerID: "design", CreatedAt: dep.CreatedAt}, dep)
+	assert.Equal(t, http.StatusOK, serve(mux, http.MethodPut, "/tasks/build/blockers/design", "").Code)
+
+	w = serve(mux, http.MethodGet, "/tasks/build/blockers", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"design"}, decodeTaskIDs(t, w.Body.Bytes()))
+	w = serve(mux, http.MethodGet, "/tasks/design/dependents", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"build"}, decodeTaskIDs(t, w.Body.Bytes()))
+
+	w = serve(mux, http.MethodPut, "/tasks/design/blockers/build", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "cycle")
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodPut, "/tasks/build/blockers/missing", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(mux, http.MethodPut, "/tasks/build/blockers/build", "").Code)
+
+	w = serve(mux, http.MethodPatch, "/tasks/build", `{"completed":true}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "waiting for design")
+
+	assert.Equal(t, http.StatusNoContent, serve(mux, http.MethodDelete, "/tasks/build/blockers/design", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodDelete, "/tasks/build/blockers/design", "").Code)
+	assert.Equal(t, http.StatusOK, serve(mux, http.MethodPatch, "/tasks/build", `{"completed":true}`).Code)
+
+	w = serve(mux, http.MethodPost, "/tasks/build/blockers", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Allow"))
+}
+
+func TestDependencyHandler_GraphViews(t *testing.T) {
+	mux := setupDependencyHandler(t)
+	require.Equal(t, http.StatusCreated, serve(mux, http.MethodPut, "/tasks/build/blockers/design", "").Code)
+	require.Equal(t, http.StatusCreated, serve(mux, http.MethodPut, "/tasks/docs/blockers/build", "").Code)
+
+	w := serve(mux, http.MethodGet, "/tasks/order", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"design", "build", "docs"}, decodeTaskIDs(t, w.Body.Bytes()))
+
+	w = serve(mux, http.MethodGet, "/tasks/unblocked", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"design"}, decodeTaskIDs(t, w.Body.Bytes()))
+
+	w = serve(mux, http.MethodGet, "/tasks/critical-path", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var path struct {
+		EstimateMinutes int          `json:"estimate_minutes"`
+		Unestimated     int          `json:"unestimated"`
+		Tasks           []model.Task `json:"tasks"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&path))
+	assert.Equal(t, 660, path.EstimateMinutes)
+	assert.Len(t, path.Tasks, 3)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodPost, "/tasks/unblocked", "").Code)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// statusFor maps a service error to its HTTP status code. This is the only
+// place that translates the apperror taxonomy into HTTP semantics.
+func statusFor(r *http.Request, err error) int {
+	if errors.Is(err, service.ErrAttachmentTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	switch apperror.KindOf(err) {
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindAlreadyExists:
+		return http.StatusConflict
+	case apperror.KindValidation:
+		return http.StatusUnprocessableEntity
+	case apperror.KindConflict:
+		// A stale version is a failed precondition when the client sent one.
+		if errors.Is(err, service.ErrVersionConflict) && hasPreconditions(r) {
+			return http.StatusPreconditionFailed
+		}
+		return http.StatusConflict
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// hasPreconditions reports whether the request carries conditional headers.
+func hasPreconditions(r *http.Request) bool {
+	return r.Header.Get("If-Match") != "" || r.Header.Get("If-None-Match") != ""
+}
+
+// writeServiceError maps err to a status code and writes it as a problem.
+// Internal errors are logged with their cause but reported to the client
+// without details.
+func writeServiceError(logger *zap.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	status := statusFor(r, err)
+	if status == http.StatusInternalServerError {
+		logger.Error("internal error", zap.String("path", r.URL.Path), zap.Error(err))
+		writeErrorFields(logger, w, r, status, "internal server error", nil)
+		return
+	}
+	writeErrorFields(logger, w, r, status, err.Error(), apperror.FieldsOf(err))
+}
+
+// writeErrorFields writes a problem+json error response with optional per-field
+// details and logs it.
+func writeErrorFields(logger *zap.Logger, w http.ResponseWriter, r *http.Request, status int, message string, fields []apperror.FieldError) {
+	writeProblem(w, r, status, message, fields)
+	logger.Warn("http error", zap.Int("status", status), zap.String("message", message),
+		zap.String("request_id", RequestIDFromContext(r.Context())))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusFor(t *testing.T) {
+	plain := httptest.NewRequest(http.MethodPut, "/tasks/1", nil)
+	conditional := httptest.NewRequest(http.MethodPut, "/tasks/1", nil)
+	conditional.Header.Set("If-Match", `"1"`)
+	conflict := &repository.VersionConflictError{ID: "1", Expected: 1, Actual: 2}
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		err  error
+		want int
+	}{
+		{"not found", plain, repository.ErrTaskNotFound, http.StatusNotFound},
+		{"already exists", plain, repository.ErrTaskAlreadyExists, http.StatusConflict},
+		{"validation", plain, apperror.InvalidField("title", "title is required"), http.StatusUnprocessableEntity},
+		{"forbidden", plain, apperror.Forbidden("not yours"), http.StatusForbidden},
+		{"unauthenticated", plain, apperror.Unauthenticated("who are you"), http.StatusUnauthorized},
+		{"version conflict", plain, conflict, http.StatusConflict},
+		{"version conflict with precondition", conditional, conflict, http.StatusPreconditionFailed},
+		{"wrapped not found", plain, fmt.Errorf("lookup: %w", repository.ErrTaskNotFound), http.StatusNotFound},
+		{"storage failure", plain, errors.New("disk I/O error"), http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, statusFor(tc.r, tc.err), tc.name)
+	}
+}
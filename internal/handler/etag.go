@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+)
+
+// formatETag renders a task version as a strong entity tag.
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagMatches evaluates an If-Match or If-None-Match header value against the
+// entity tag of the given version. With weak set, W/ tags compare equal to their
+// strong counterparts (RFC 7232 weak comparison); otherwise weak tags never match.
+func etagMatches(header string, version int64, weak bool) bool {
+	want := formatETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatETag(t *testing.T) {
+	assert.Equal(t, `"42"`, formatETag(42))
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"3"`, 3, false))
+	assert.True(t, etagMatches(`"1", "3"`, 3, false))
+	assert.True(t, etagMatches(`*`, 3, false))
+	assert.False(t, etagMatches(`"2"`, 3, false))
+	assert.False(t, etagMatches(`W/"3"`, 3, false), "weak tags never match strongly")
+	assert.True(t, etagMatches(`W/"3"`, 3, true))
+	assert.False(t, etagMatches(`3`, 3, true), "tags must be quoted")
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 )
 
@@ -17,10 +16,10 @@ func (h *HealthHandler) RegisterRoutes(mux *http.ServeMux) {
 }
 
 func (h *HealthHandler) handleHealthz(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(map[string]bool{"ok": true}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeProblem(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
 }
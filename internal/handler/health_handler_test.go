@@ -20,3 +20,16 @@ func TestHealthHandler(t *testing.T) {
 	require.Equal(t, http.StatusOK, rw.Code, "expected 200 OK")
 	assert.Equal(t, "{\"ok\":true}\n", rw.Body.String(), "unexpected body")
 }
+
+func TestHealthHandler_MethodNotAllowed(t *testing.T) {
+	h := NewHealthHandler()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest("POST", "/healthz", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	assert.Equal(t, "application/problem+json", rw.Header().Get("Content-Type"))
+	assert.Equal(t, "GET, HEAD", rw.Header().Get("Allow"))
+}
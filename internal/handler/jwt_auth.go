@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/jwtauth"
+)
+
+// ClaimMapping names the token claims a principal is made of.
+type ClaimMapping struct {
+	// Subject is the claim naming the user, usually "sub". It becomes the
+	// actor, so it must be printable ASCII of at most 128 characters.
+	Subject string
+	// Tenant is the claim naming the tenant the user belongs to. When set,
+	// every token must carry a valid tenant in it and acts in that tenant
+	// only; when empty, tokens act in the tenant their requests name.
+	Tenant string
+	// Scopes is the claim listing the user's scopes, as a space-separated
+	// string like the OAuth 2.0 scope claim or as an array.
+	Scopes string
+}
+
+// jwtAuth authenticates bearer tokens of an identity provider.
+type jwtAuth struct {
+	verifier *jwtauth.Verifier
+	claims   ClaimMapping
+}
+
+// JWTAuth returns an Authenticator of the JWTs verifier checks, made into
+// principals as claims says.
+func JWTAuth(verifier *jwtauth.Verifier, claims ClaimMapping) Authenticator {
+	return jwtAuth{verifier: verifier, claims: claims}
+}
+
+// Accepts reports whether token has the three parts of a signed JWT.
+func (a jwtAuth) Accepts(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func (a jwtAuth) Authenticate(ctx context.Context, token string) (*identity.Principal, error) {
+	claims, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	principal := &identity.Principal{Subject: claims.String(a.claims.Subject), Scopes: claims.Strings(a.claims.Scopes)}
+	if !validRequestID(principal.Subject) {
+		return nil, fmt.Errorf("%w: claim %s does not name a user", jwtauth.ErrInvalidToken, a.claims.Subject)
+	}
+	if a.claims.Tenant != "" {
+		principal.Tenant = claims.String(a.claims.Tenant)
+		if !identity.ValidTenant(principal.Tenant) {
+			return nil, fmt.Errorf("%w: claim %s does not name a tenant", jwtauth.ErrInvalidToken, a.claims.Tenant)
+		}
+	}
+	return principal, nil
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"taskmanager/internal/identity"
+	"taskmanager/internal/jwtauth"
+	"taskmanager/internal/jwtauth/jwtauthtest"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// setupJWTAuth serves tasks to the tokens of a stand-in provider, with tenants
+// taken from their org claim, and to API keys.
+func setupJWTAuth(t *testing.T) (*jwtauthtest.Issuer, http.Handler) {
+	t.Helper()
+	iss := jwtauthtest.NewIssuer(t)
+	keys := jwtauth.NewRemoteKeySet(iss.JWKSURL(), iss.Client(), time.Hour, zap.NewNop())
+	verifier := jwtauth.NewVerifier(keys, jwtauth.Options{Issuer: iss.URL(), Audience: "taskmanager"})
+	keyRepo, err := repository.OpenFileAPIKeyRepository("", zap.NewNop())
+	require.NoError(t, err)
+	repo := repository.NewTenantRepository(map[string]repository.TaskRepository{
+		"acme":   repository.NewInMemoryTaskRepository(zap.NewNop()),
+		"globex": repository.NewInMemoryTaskRepository(zap.NewNop()),
+	})
+	mux := http.NewServeMux()
+	NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	auths := []Authenticator{
+		JWTAuth(verifier, ClaimMapping{Subject: "sub", Tenant: "org", Scopes: "scope"}),
+		APIKeyAuth(service.NewAPIKeyService(keyRepo, testAdminKey, zap.NewNop())),
+	}
+	return iss, Tenant(Actor(RequireAuth(auths, zap.NewNop(), mux)))
+}
+
+// tokenClaims returns claims of a token of iss for user in org with scope.
+func tokenClaims(iss *jwtauthtest.Issuer, user, org, scope string) map[string]any {
+	return map[string]any{
+		"iss": iss.URL(), "aud": "taskmanager", "sub": user, "org": org, "scope": scope,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestJWTAuth(t *testing.T) {
+	iss, h := setupJWTAuth(t)
+	alice := iss.Sign(jwtauth.RS256, tokenClaims(iss, "alice", "acme", "openid tasks:read tasks:write"))
+	bob := iss.Sign(jwtauth.ES256, tokenClaims(iss, "bob", "globex", "tasks:read"))
+
+	w := serveWithKey(h, alice, http.MethodPost, "/tasks", `{"id":"t1","title":"Plan"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"created_by":"alice"`)
+	assert.Equal(t, http.StatusOK, serveWithKey(h, alice, http.MethodGet, "/tasks/t1", "").Code)
+
+	// Bob's token keeps him in his own tenant and read-only there.
+	r := httptest.NewRequest(http.MethodGet, "/tasks/t1", nil)
+	r.Header.Set("Authorization", "Bearer "+bob)
+	r.Header.Set(TenantHeader, "acme")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusForbidden, serveWithKey(h, bob, http.MethodPost, "/tasks", `{"title":"Nope"}`).Code)
+	assert.Equal(t, 1, iss.Fetches(), "keys are cached")
+}
+
+func TestJWTAuth_Rejects(t *testing.T) {
+	iss, h := setupJWTAuth(t)
+	expired := tokenClaims(iss, "alice", "acme", "tasks:read")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	for name, token := range map[string]string{
+		"expired":     iss.Sign(jwtauth.RS256, expired),
+		"no tenant":   iss.Sign(jwtauth.RS256, tokenClaims(iss, "alice", "", "tasks:read")),
+		"bad tenant":  iss.Sign(jwtauth.RS256, tokenClaims(iss, "alice", "../acme", "tasks:read")),
+		"no subject":  iss.Sign(jwtauth.RS256, tokenClaims(iss, "", "acme", "tasks:read")),
+		"bad subject": iss.Sign(jwtauth.RS256, tokenClaims(iss, "alice smith", "acme", "tasks:read")),
+		"not a jwt":   "a.b.c",
+	} {
+		w := serveWithKey(h, token, http.MethodGet, "/tasks", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+		assert.Equal(t, `Bearer realm="taskmanager"`, w.Header().Get("WWW-Authenticate"), name)
+	}
+}
+
+func TestJWTAuth_ProviderDown(t *testing.T) {
+	iss, h := setupJWTAuth(t)
+	iss.SetDown(true)
+	token := iss.Sign(jwtauth.RS256, tokenClaims(iss, "alice", "acme", "tasks:read"))
+	w := serveWithKey(h, token, http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "an unreachable provider is not the client's fault")
+	assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestRequireAuth_APIKeysAlongsideTokens(t *testing.T) {
+	_, h := setupJWTAuth(t)
+	var principal *identity.Principal
+	keyRepo, err := repository.OpenFileAPIKeyRepository("", zap.NewNop())
+	require.NoError(t, err)
+	dotted := "admin.key.with-dots-0123456789abcdef"
+	auths := []Authenticator{
+		JWTAuth(jwtauth.NewVerifier(jwtauth.NewStaticKeySet(), jwtauth.Options{}), ClaimMapping{Subject: "sub"}),
+		APIKeyAuth(service.NewAPIKeyService(keyRepo, dotted, zap.NewNop())),
+	}
+	probe := RequireAuth(auths, zap.NewNop(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = identity.PrincipalOf(r.Context())
+	}))
+
+	w := serveWithKey(probe, dotted, http.MethodGet, "/admin/keys", "")
+	assert.Equal(t, http.StatusOK, w.Code, "a key that looks like a JWT falls through to the API keys")
+	require.NotNil(t, principal)
+	assert.Equal(t, "apikey:admin", principal.Subject)
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(h, "tm_0000_guess", http.MethodGet, "/tasks", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serveWithKey(probe, "", http.MethodGet, "/tasks", "").Code)
+}
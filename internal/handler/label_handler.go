@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// LabelHandler handles HTTP requests for /labels endpoints.
+type LabelHandler struct {
+	service service.LabelService
+	logger  *zap.Logger
+}
+
+// NewLabelHandler creates a new LabelHandler.
+func NewLabelHandler(service service.LabelService, logger *zap.Logger) *LabelHandler {
+	return &LabelHandler{service: service, logger: logger}
+}
+
+// RegisterRoutes registers the /labels routes to the given mux.
+func (h *LabelHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/labels", h.handleLabels)
+	mux.HandleFunc("/labels/", h.handleLabelByName)
+}
+
+// handleLabels handles POST (create) and GET (list) on /labels.
+func (h *LabelHandler) handleLabels(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req model.Label
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		created, err := h.service.CreateLabel(r.Context(), &req)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	case http.MethodGet:
+		labels, err := h.service.ListLabels(r.Context())
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, labels)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// labelPatch is the body of PATCH /labels/{name}. Only the color can change;
+// a label is renamed by creating a new one.
+type labelPatch struct {
+	Color string `json:"color"`
+}
+
+// handleLabelByName handles GET, PATCH and DELETE on /labels/{name}.
+func (h *LabelHandler) handleLabelByName(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/labels/")
+	if name == "" || strings.Contains(name, "/") {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		label, err := h.service.GetLabel(r.Context(), name)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, label)
+	case http.MethodPatch:
+		var req labelPatch
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		label, err := h.service.UpdateLabelColor(r.Context(), name, req.Color)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, label)
+	case http.MethodDelete:
+		if err := h.service.DeleteLabel(r.Context(), name); err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
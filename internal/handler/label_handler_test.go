@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupLabelHandler() *http.ServeMux {
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	mux := http.NewServeMux()
+	NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	NewLabelHandler(service.NewLabelService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	return mux
+}
+
+func serve(mux *http.ServeMux, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestLabelHandler_CRUD(t *testing.T) {
+	mux := setupLabelHandler()
+
+	w := serve(mux, http.MethodPost, "/labels", `{"name":"backend","color":"#ff0000"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = serve(mux, http.MethodPost, "/labels", `{"name":"docs"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created model.Label
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, model.DefaultLabelColor, created.Color)
+
+	assert.Equal(t, http.StatusConflict, serve(mux, http.MethodPost, "/labels", `{"name":"docs"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(mux, http.MethodPost, "/labels", `{"name":"a/b"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodPost, "/labels", `{`).Code)
+
+	w = serve(mux, http.MethodGet, "/labels", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var labels []model.Label
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&labels))
+	require.Len(t, labels, 2)
+	assert.Equal(t, "backend", labels[0].Name)
+
+	w = serve(mux, http.MethodPatch, "/labels/docs", `{"color":"#00ff00"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(mux, http.MethodGet, "/labels/docs", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, "#00ff00", created.Color)
+
+	assert.Equal(t, http.StatusNoContent, serve(mux, http.MethodDelete, "/labels/docs", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, "/labels/docs", "").Code)
+	w = serve(mux, http.MethodPut, "/labels/backend", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PATCH, DELETE", w.Header().Get("Allow"))
+}
+
+func TestLabelHandler_AttachDetachAndFilter(t *testing.T) {
+	mux := setupLabelHandler()
+	for _, name := range []string{"backend", "urgent"} {
+		require.Equal(t, http.StatusCreated, serve(mux, http.MethodPost, "/labels", `{"name":"`+name+`"}`).Code)
+	}
+	for _, body := range []string{
+		`{"id":"t1","title":"One","labels":["backend","urgent"]}`,
+		`{"id":"t2","title":"Two","labels":["urgent"]}`,
+		`{"id":"t3","title":"Three"}`,
+	} {
+		require.Equal(t, http.StatusCreated, serve(mux, http.MethodPost, "/tasks", body).Code)
+	}
+	w := serve(mux, http.MethodPost, "/tasks", `{"title":"Bad","labels":["nope"]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	listIDs := func(query string) []string {
+		w := serve(mux, http.MethodGet, "/tasks?"+query, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var tasks []model.Task
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+		ids := []string{}
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"t1"}, listIDs("label=backend&label=urgent"))
+	assert.Equal(t, []string{"t1", "t2"}, listIDs("label=backend&label=urgent&label_match=any"))
+
+	w = serve(mux, http.MethodPut, "/tasks/t3/labels/backend", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	assert.Equal(t, []string{"t1", "t3"}, listIDs("label=backend"))
+
+	r := httptest.NewRequest(http.MethodDelete, "/tasks/t3/labels/backend", nil)
+	r.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = serve(mux, http.MethodDelete, "/tasks/t3/labels/backend", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var task model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	assert.Empty(t, task.Labels)
+
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodPut, "/tasks/t3/labels/nope", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodPut, "/tasks/missing/labels/backend", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(mux, http.MethodGet, "/tasks/t3/unknown", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodGet, "/tasks/t3/labels/backend", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodPatch, "/tasks/t1", `{"labels":[]}`).Code,
+		"labels are read-only in patches")
+
+	// Deleting a label detaches it everywhere.
+	require.Equal(t, http.StatusNoContent, serve(mux, http.MethodDelete, "/labels/urgent", "").Code)
+	assert.Empty(t, listIDs("label=urgent"))
+	w = serve(mux, http.MethodGet, "/tasks/t1", "")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	assert.Equal(t, []string{"backend"}, task.Labels)
+}
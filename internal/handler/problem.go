@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"taskmanager/internal/apperror"
+)
+
+// problemContentType is the media type of RFC 7807 error responses.
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix namespaces the problem type URIs of this service.
+const problemTypePrefix = "urn:taskmanager:problem:"
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	Errors    []apperror.FieldError `json:"errors,omitempty"`
+	RequestID string                `json:"request_id,omitempty"`
+}
+
+// problemTypes names the problem type of each status code the API produces.
+var problemTypes = map[int]string{
+	http.StatusBadRequest:           "bad-request",
+	http.StatusUnauthorized:         "unauthorized",
+	http.StatusForbidden:            "forbidden",
+	http.StatusNotFound:             "not-found",
+	http.StatusMethodNotAllowed:     "method-not-allowed",
+	http.StatusConflict:             "conflict",
+	http.StatusPreconditionFailed:   "precondition-failed",
+	http.StatusUnsupportedMediaType: "unsupported-media-type",
+	http.StatusUnprocessableEntity:  "validation-error",
+	http.StatusInternalServerError:  "internal-error",
+}
+
+// newProblem builds the problem document for status on request r.
+func newProblem(r *http.Request, status int, detail string, fields []apperror.FieldError) *Problem {
+	typ := "about:blank"
+	if name, ok := problemTypes[status]; ok {
+		typ = problemTypePrefix + name
+	}
+	return &Problem{
+		Type:      typ,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  requestPath(r),
+		Errors:    fields,
+		RequestID: RequestIDFromContext(r.Context()),
+	}
+}
+
+// writeProblem writes an application/problem+json response.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string, fields []apperror.FieldError) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(newProblem(r, status, detail, fields))
+}
+
+// writeJSON writes v as an application/json response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProblem(t *testing.T) {
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusUnprocessableEntity, "title is required",
+			[]apperror.FieldError{{Field: "title", Message: "title is required"}})
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rw.Code)
+	assert.Equal(t, "application/problem+json", rw.Header().Get("Content-Type"))
+	var p Problem
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&p))
+	assert.Equal(t, Problem{
+		Type:      "urn:taskmanager:problem:validation-error",
+		Title:     "Unprocessable Entity",
+		Status:    http.StatusUnprocessableEntity,
+		Detail:    "title is required",
+		Instance:  "/tasks",
+		Errors:    []apperror.FieldError{{Field: "title", Message: "title is required"}},
+		RequestID: "req-123",
+	}, p)
+}
+
+func TestWriteProblem_UnmappedStatus(t *testing.T) {
+	rw := httptest.NewRecorder()
+	writeProblem(rw, httptest.NewRequest(http.MethodGet, "/x", nil), http.StatusTeapot, "", nil)
+
+	var p Problem
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&p))
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "I'm a teapot", p.Title)
+	assert.Empty(t, p.RequestID)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// ProjectHandler handles HTTP requests for /projects endpoints. The task
+// routes of a project, below /projects/{id}/tasks, are those of /tasks served
+// in the project's scope.
+type ProjectHandler struct {
+	service service.ProjectService
+	tasks   http.Handler
+	logger  *zap.Logger
+}
+
+// NewProjectHandler creates a new ProjectHandler that serves project tasks
+// with tasks, the handler of the /tasks routes.
+func NewProjectHandler(service service.ProjectService, tasks http.Handler, logger *zap.Logger) *ProjectHandler {
+	return &ProjectHandler{service: service, tasks: tasks, logger: logger}
+}
+
+// RegisterRoutes registers the /projects routes to the given mux.
+func (h *ProjectHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/projects", h.handleProjects)
+	mux.HandleFunc("/projects/", h.handleProjectByID)
+}
+
+// handleProjects handles POST (create) and GET (list) on /projects.
+func (h *ProjectHandler) handleProjects(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req model.Project
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		created, err := h.service.CreateProject(r.Context(), &req)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	case http.MethodGet:
+		projects, err := h.service.ListProjects(r.Context())
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, projects)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// handleProjectByID handles GET, PATCH and DELETE on /projects/{id} and
+// dispatches /projects/{id}/tasks and the paths below it.
+func (h *ProjectHandler) handleProjectByID(w http.ResponseWriter, r *http.Request) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/projects/"), "/")
+	if id == "" {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	if rest != "" {
+		h.serveTasks(w, r, id, rest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		project, err := h.service.GetProject(r.Context(), id)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, project)
+	case http.MethodPatch:
+		var req model.ProjectPatch
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		project, err := h.service.UpdateProject(r.Context(), id, &req)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, project)
+	case http.MethodDelete:
+		if err := h.service.DeleteProject(r.Context(), id); err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// serveTasks serves /projects/{id}/{rest}, where rest is a /tasks route, with
+// the task handler in the scope of project id.
+func (h *ProjectHandler) serveTasks(w http.ResponseWriter, r *http.Request, id, rest string) {
+	// Only task routes are scoped; nothing may climb out of them.
+	if p := path.Clean("/" + rest); p != "/tasks" && !strings.HasPrefix(p, "/tasks/") {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	if _, err := h.service.GetProject(r.Context(), id); err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	ctx := service.InProject(r.Context(), id)
+	ctx = context.WithValue(ctx, requestPathKey{}, r.URL.Path)
+	scoped := r.Clone(ctx)
+	scoped.URL.Path = "/" + rest
+	scoped.URL.RawPath = ""
+	h.tasks.ServeHTTP(w, scoped)
+}
+
+type requestPathKey struct{}
+
+// requestPath returns the path the client requested. It differs from
+// r.URL.Path for the task routes of a project, which are served as /tasks.
+func requestPath(r *http.Request) string {
+	if p, ok := r.Context().Value(requestPathKey{}).(string); ok {
+		return p
+	}
+	return r.URL.Path
+}
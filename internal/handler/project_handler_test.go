@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupProjectHandler() http.Handler {
+	repo := repository.NewProjectScopedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()))
+	mux := http.NewServeMux()
+	NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	NewProjectHandler(service.NewProjectService(repo, zap.NewNop()), mux, zap.NewNop()).RegisterRoutes(mux)
+	return Actor(mux)
+}
+
+func decodeTask(t *testing.T, body string) model.Task {
+	t.Helper()
+	var task model.Task
+	require.NoError(t, json.NewDecoder(strings.NewReader(body)).Decode(&task))
+	return task
+}
+
+func TestProjectHandler_CRUD(t *testing.T) {
+	h := setupProjectHandler()
+
+	w := serveAs(h, "", http.MethodPost, "/projects", `{"id":"web","name":"Website","settings":{"id_prefix":"WEB"}}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusConflict, serveAs(h, "", http.MethodPost, "/projects", `{"id":"web","name":"Website"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serveAs(h, "", http.MethodPost, "/projects", `{"id":"a b"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity,
+		serveAs(h, "", http.MethodPost, "/projects", `{"id":"api","name":"API","settings":{"id_prefix":"WEB"}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(h, "", http.MethodPost, "/projects", `{`).Code)
+
+	w = serveAs(h, "", http.MethodPatch, "/projects/web", `{"description":"The public site"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var project model.Project
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&project))
+	assert.Equal(t, "Website", project.Name)
+	assert.Equal(t, "The public site", project.Description)
+	assert.Equal(t, "WEB", project.Settings.IDPrefix)
+
+	w = serveAs(h, "", http.MethodGet, "/projects", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var projects []model.Project
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&projects))
+	assert.Len(t, projects, 1)
+
+	w = serveAs(h, "", http.MethodPut, "/projects/web", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PATCH, DELETE", w.Header().Get("Allow"))
+
+	require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/projects/web/tasks", `{"title":"Fix header"}`).Code)
+	assert.Equal(t, http.StatusConflict, serveAs(h, "", http.MethodDelete, "/projects/web", "").Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(h, "", http.MethodDelete, "/projects/web/tasks/WEB-1", "").Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(h, "", http.MethodPost, "/projects/web/tasks/WEB-1/purge", "").Code)
+	assert.Equal(t, http.StatusNoContent, serveAs(h, "", http.MethodDelete, "/projects/web", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/projects/web", "").Code)
+}
+
+func TestProjectHandler_ScopedTasks(t *testing.T) {
+	h := setupProjectHandler()
+	for _, body := range []string{`{"id":"web","name":"Website","settings":{"id_prefix":"WEB"}}`, `{"id":"ops","name":"Operations"}`} {
+		require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/projects", body).Code)
+	}
+	for i := 0; i < 3; i++ {
+		w := serveAs(h, "", http.MethodPost, "/projects/web/tasks", `{"title":"Web task"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		task := decodeTask(t, w.Body.String())
+		assert.Equal(t, "web", task.ProjectID)
+	}
+	require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/tasks", `{"id":"loose","title":"Loose"}`).Code)
+
+	w := serveAs(h, "", http.MethodGet, "/projects/web/tasks?limit=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var tasks []model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+	assert.Equal(t, "WEB-1", tasks[0].ID)
+	assert.Len(t, tasks, 2)
+	assert.Contains(t, w.Header().Get("Link"), "</projects/web/tasks?", "next links keep the project path")
+
+	w = serveAs(h, "", http.MethodGet, "/projects/ops/tasks/WEB-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"instance":"/projects/ops/tasks/WEB-1"`)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/projects/web/tasks/loose", "").Code)
+	assert.Equal(t, http.StatusOK, serveAs(h, "", http.MethodGet, "/projects/web/tasks/WEB-1", "").Code)
+	assert.Equal(t, http.StatusOK, serveAs(h, "", http.MethodGet, "/tasks/WEB-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/projects/missing/tasks", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/projects/web/tasksx", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/projects/web/other", "").Code)
+
+	w = serveAs(h, "", http.MethodGet, "/tasks?project=", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	tasks = nil
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "loose", tasks[0].ID)
+}
+
+func TestProjectHandler_MoveAndCopy(t *testing.T) {
+	h := setupProjectHandler()
+	require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/projects", `{"id":"web","name":"Website","settings":{"id_prefix":"WEB"}}`).Code)
+	require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/tasks", `{"id":"loose","title":"Loose"}`).Code)
+	require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/tasks", `{"id":"sub","title":"Sub","parent_id":"loose"}`).Code)
+
+	w := serveAs(h, "", http.MethodGet, "/tasks/loose/move", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "POST", w.Header().Get("Allow"))
+	assert.Equal(t, http.StatusUnprocessableEntity, serveAs(h, "", http.MethodPost, "/tasks/loose/move", `{}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serveAs(h, "", http.MethodPost, "/tasks/loose/move", `{"project_id":"missing"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(h, "", http.MethodPost, "/tasks/loose/move", `{`).Code)
+
+	w = serveAs(h, "", http.MethodPost, "/tasks/loose/copy", `{"project_id":"web"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	copied := decodeTask(t, w.Body.String())
+	assert.Equal(t, "WEB-1", copied.ID)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, serveAs(h, "", http.MethodGet, "/projects/web/tasks/WEB-2", "").Code, "subtasks are copied")
+
+	move := func(ifMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/tasks/loose/move", strings.NewReader(`{"project_id":"web"}`))
+		r.Header.Set("If-Match", ifMatch)
+		h.ServeHTTP(w, r)
+		return w
+	}
+	etag := serveAs(h, "", http.MethodGet, "/tasks/loose", "").Header().Get("ETag")
+	assert.Equal(t, http.StatusPreconditionFailed, move(`"99"`).Code)
+	w = move(etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	moved := decodeTask(t, w.Body.String())
+	assert.Equal(t, "web", moved.ProjectID)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, serveAs(h, "", http.MethodGet, "/projects/web/tasks/sub", "").Code)
+
+	// Moving out of the project from its scope.
+	w = serveAs(h, "", http.MethodPost, "/projects/web/tasks/loose/move", `{"project_id":""}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/projects/web/tasks/loose", "").Code)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// SearchHandler handles the /tasks/search endpoint.
+type SearchHandler struct {
+	service service.SearchService
+	logger  *zap.Logger
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(service service.SearchService, logger *zap.Logger) *SearchHandler {
+	return &SearchHandler{service: service, logger: logger}
+}
+
+// RegisterRoutes registers the search route to the given mux. The exact path
+// takes precedence over the /tasks/ prefix of TaskHandler.
+func (h *SearchHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/tasks/search", h.handleSearch)
+}
+
+// searchResponse is the body of GET /tasks/search.
+type searchResponse struct {
+	Query   string                      `json:"query"`
+	Results []*service.TaskSearchResult `json:"results"`
+}
+
+// handleSearch handles GET /tasks/search?q=...&limit=...
+func (h *SearchHandler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeErrorFields(h.logger, w, r, http.StatusBadRequest, "missing query parameter q", nil)
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "limit must be a positive integer", nil)
+			return
+		}
+		limit = n
+	}
+	results, err := h.service.SearchTasks(r.Context(), query, limit)
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, searchResponse{Query: query, Results: results})
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockSearchService is a testify mock for SearchService.
+type MockSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSearchService) SearchTasks(ctx context.Context, query string, limit int) ([]*service.TaskSearchResult, error) {
+	args := m.Called(ctx, query, limit)
+	return args.Get(0).([]*service.TaskSearchResult), args.Error(1)
+}
+
+func newSearchMux(svc service.SearchService) *http.ServeMux {
+	mux := http.NewServeMux()
+	NewTaskHandler(new(MockTaskService), zap.NewNop()).RegisterRoutes(mux)
+	NewSearchHandler(svc, zap.NewNop()).RegisterRoutes(mux)
+	return mux
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	ss := new(MockSearchService)
+	results := []*service.TaskSearchResult{{
+		Task:       &model.Task{ID: "task-1", Title: "Write report"},
+		Score:      1.5,
+		Highlights: map[string]string{"title": "Write <mark>report</mark>"},
+	}}
+	ss.On("SearchTasks", mock.Anything, "report", 5).Return(results, nil)
+
+	w := httptest.NewRecorder()
+	newSearchMux(ss).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/search?q=report&limit=5", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Query   string `json:"query"`
+		Results []struct {
+			Task       model.Task        `json:"task"`
+			Score      float64           `json:"score"`
+			Highlights map[string]string `json:"highlights"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "report", resp.Query)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "task-1", resp.Results[0].Task.ID)
+	assert.Equal(t, 1.5, resp.Results[0].Score)
+	assert.Equal(t, "Write <mark>report</mark>", resp.Results[0].Highlights["title"])
+	ss.AssertExpectations(t)
+}
+
+func TestSearchHandler_BadRequests(t *testing.T) {
+	ss := new(MockSearchService)
+	mux := newSearchMux(ss)
+	for _, target := range []string{"/tasks/search", "/tasks/search?q=x&limit=0", "/tasks/search?q=x&limit=ten"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks/search?q=x", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	ss.AssertNotCalled(t, "SearchTasks", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSearchHandler_ValidationError(t *testing.T) {
+	ss := new(MockSearchService)
+	ss.On("SearchTasks", mock.Anything, "the", 0).
+		Return([]*service.TaskSearchResult(nil), apperror.InvalidField("q", "query must contain at least one searchable word"))
+
+	w := httptest.NewRecorder()
+	newSearchMux(ss).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/search?q=the", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "q", resp.Errors[0].Field)
+}
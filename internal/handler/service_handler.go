@@ -1,11 +1,11 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 )
 
-// ServiceHandler handles the root endpoint.
+// ServiceHandler handles the root endpoint. Being registered on "/", it also
+// answers every path no other handler claims, with a 404 problem.
 type ServiceHandler struct{}
 
 func NewServiceHandler() *ServiceHandler {
@@ -17,10 +17,14 @@ func (h *ServiceHandler) RegisterRoutes(mux *http.ServeMux) {
 }
 
 func (h *ServiceHandler) handleRoot(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(map[string]string{"service": "taskmanager"}); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	if r.URL.Path != "/" {
+		writeProblem(w, r, http.StatusNotFound, "no route for "+r.URL.Path, nil)
 		return
 	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeProblem(w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"service": "taskmanager"})
 }
@@ -20,3 +20,29 @@ func TestServiceHandler(t *testing.T) {
 	require.Equal(t, http.StatusOK, rw.Code, "expected 200 OK")
 	assert.Equal(t, "{\"service\":\"taskmanager\"}\n", rw.Body.String(), "unexpected body")
 }
+
+func TestServiceHandler_UnknownPath(t *testing.T) {
+	h := NewServiceHandler()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest("GET", "/nope", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "application/problem+json", rw.Header().Get("Content-Type"))
+	assert.Contains(t, rw.Body.String(), `"type":"urn:taskmanager:problem:not-found"`)
+	assert.Contains(t, rw.Body.String(), `"instance":"/nope"`)
+}
+
+func TestServiceHandler_MethodNotAllowed(t *testing.T) {
+	h := NewServiceHandler()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest("DELETE", "/", nil)
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	assert.Equal(t, "application/problem+json", rw.Header().Get("Content-Type"))
+}
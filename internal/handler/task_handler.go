@@ -1,32 +1,63 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"taskmanager/internal/apperror"
 	"taskmanager/internal/model"
 	"taskmanager/internal/service"
-	"time"
 
 	"go.uber.org/zap"
 )
 
-// TaskHandler handles HTTP requests for /tasks endpoints.
 // TaskHandler handles HTTP requests for /tasks endpoints.
 type TaskHandler struct {
 	service service.TaskService
 	logger  *zap.Logger
+	// mounted holds sub-resources served by other handlers; see Mount.
+	mounted map[string]SubresourceFunc
 }
 
+// SubresourceFunc serves a task sub-resource mounted with TaskHandler.Mount.
+// id is the task ID and rest the path below /tasks/{id}/{name}/, if any.
+type SubresourceFunc func(w http.ResponseWriter, r *http.Request, id, rest string)
+
 // NewTaskHandler creates a new TaskHandler.
 func NewTaskHandler(service service.TaskService, logger *zap.Logger) *TaskHandler {
-	return &TaskHandler{service: service, logger: logger}
+	return &TaskHandler{service: service, logger: logger, mounted: make(map[string]SubresourceFunc)}
+}
+
+// Mount routes /tasks/{id}/{name} and the paths below it to fn, so handlers
+// for other services can add task sub-resources.
+func (h *TaskHandler) Mount(name string, fn SubresourceFunc) {
+	h.mounted[name] = fn
 }
 
 // RegisterRoutes registers the /tasks routes to the given mux.
 func (h *TaskHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/tasks", h.handleTasks)
 	mux.HandleFunc("/tasks/", h.handleTaskByID)
+	mux.HandleFunc("/tasks/overdue", h.handleDueView(dueOverdue))
+	mux.HandleFunc("/tasks/due-today", h.handleDueView(dueToday))
+	mux.HandleFunc("/tasks/upcoming", h.handleDueView(dueUpcoming))
+	mux.HandleFunc("/tasks/board", h.handleBoard)
+	mux.HandleFunc("/workflow", h.handleWorkflow)
+}
+
+// handleWorkflow serves GET /workflow, the state machine of task statuses.
+func (h *TaskHandler) handleWorkflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.service.Workflow())
 }
 
 // handleTasks handles POST (create) and GET (list) on /tasks.
@@ -37,15 +68,21 @@ func (h *TaskHandler) handleTasks(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		h.listTasks(w, r)
 	default:
-		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
 	}
 }
 
-// handleTaskByID handles GET, PUT, DELETE on /tasks/{id}.
+// handleTaskByID handles GET, PUT, PATCH, DELETE on /tasks/{id} and routes
+// the task's sub-resources below /tasks/{id}/.
+// Writes honour If-Match/If-None-Match against the task's ETag (its version).
 func (h *TaskHandler) handleTaskByID(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/tasks/")
+	id, sub, hasSub := strings.Cut(strings.TrimPrefix(r.URL.Path, "/tasks/"), "/")
 	if id == "" {
-		h.writeError(w, http.StatusBadRequest, "invalid task id")
+		h.writeError(w, r, http.StatusBadRequest, "invalid task id")
+		return
+	}
+	if hasSub {
+		h.handleTaskSubresource(w, r, id, sub)
 		return
 	}
 	switch r.Method {
@@ -53,77 +90,586 @@ func (h *TaskHandler) handleTaskByID(w http.ResponseWriter, r *http.Request) {
 		h.getTask(w, r, id)
 	case http.MethodPut:
 		h.updateTask(w, r, id)
+	case http.MethodPatch:
+		h.patchTask(w, r, id)
 	case http.MethodDelete:
 		h.deleteTask(w, r, id)
 	default:
-		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleTaskSubresource routes /tasks/{id}/{sub}.
+func (h *TaskHandler) handleTaskSubresource(w http.ResponseWriter, r *http.Request, id, sub string) {
+	if name, ok := strings.CutPrefix(sub, "labels/"); ok && name != "" {
+		h.handleTaskLabel(w, r, id, name)
+		return
+	}
+	name, rest, _ := strings.Cut(sub, "/")
+	if fn, ok := h.mounted[name]; ok {
+		fn(w, r, id, rest)
+		return
+	}
+	if name == "history" {
+		h.handleHistory(w, r, id, rest)
+		return
+	}
+	switch sub {
+	case "children":
+		h.listChildren(w, r, id)
+	case "subtree":
+		h.getSubtree(w, r, id)
+	case "occurrences":
+		h.listOccurrences(w, r, id)
+	case "move":
+		h.moveTask(w, r, id)
+	case "copy":
+		h.copyTask(w, r, id)
+	case "position":
+		h.positionTask(w, r, id)
+	case "restore":
+		h.undeleteTask(w, r, id)
+	case "purge":
+		h.purgeTask(w, r, id)
+	default:
+		h.writeError(w, r, http.StatusNotFound, "not found")
 	}
 }
 
+// listChildren serves GET /tasks/{id}/children, a page of the task's direct
+// subtasks that takes the same parameters as GET /tasks.
+func (h *TaskHandler) listChildren(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q, fields := parseTaskQuery(r.URL.Query())
+	if len(fields) > 0 {
+		h.writeErrorFields(w, r, http.StatusBadRequest, "invalid query parameters", fields)
+		return
+	}
+	page, err := h.service.ListChildren(r.Context(), id, q)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	h.writePage(w, r, page)
+}
+
+// getSubtree serves GET /tasks/{id}/subtree, the task with all its subtasks
+// nested below it and the roll-up progress of every node.
+func (h *TaskHandler) getSubtree(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	tree, err := h.service.GetSubtree(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tree)
+}
+
+// listOccurrences serves GET /tasks/{id}/occurrences?limit=N, a preview of the
+// upcoming occurrences of a recurring task.
+func (h *TaskHandler) listOccurrences(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			h.writeErrorFields(w, r, http.StatusBadRequest, "invalid query parameters",
+				[]apperror.FieldError{{Field: "limit", Message: "limit must be a positive integer"}})
+			return
+		}
+		limit = n
+	}
+	occurrences, err := h.service.Occurrences(r.Context(), id, limit)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, occurrences)
+}
+
+// handleHistory serves GET /tasks/{id}/history, the change history of the task,
+// and POST /tasks/{id}/history/{revision}/restore, which returns the task to
+// that revision and responds with the restored task.
+func (h *TaskHandler) handleHistory(w http.ResponseWriter, r *http.Request, id, rest string) {
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		entries, err := h.service.History(r.Context(), id)
+		if err != nil {
+			h.writeServiceError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+	rev, action, _ := strings.Cut(rest, "/")
+	if action != "restore" {
+		h.writeError(w, r, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	revision, err := strconv.ParseInt(rev, 10, 64)
+	if err != nil || revision <= 0 {
+		h.writeErrorFields(w, r, http.StatusBadRequest, "invalid revision",
+			[]apperror.FieldError{{Field: "revision", Message: "revision must be a positive integer"}})
+		return
+	}
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	task, err := h.service.RestoreTask(r.Context(), id, revision, version)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(task.Version))
+	writeJSON(w, http.StatusOK, task)
+}
+
+// handleTaskLabel attaches (PUT) or detaches (DELETE) the label name on task id
+// and responds with the updated task. Both are idempotent.
+func (h *TaskHandler) handleTaskLabel(w http.ResponseWriter, r *http.Request, id, name string) {
+	var apply func(ctx context.Context, id, name string, version int64) (*model.Task, error)
+	switch r.Method {
+	case http.MethodPut:
+		apply = h.service.AttachLabel
+	case http.MethodDelete:
+		apply = h.service.DetachLabel
+	default:
+		w.Header().Set("Allow", "PUT, DELETE")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	task, err := apply(r.Context(), id, name, version)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(task.Version))
+	writeJSON(w, http.StatusOK, task)
+}
+
 func (h *TaskHandler) createTask(w http.ResponseWriter, r *http.Request) {
 	var req model.Task
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		h.writeError(w, r, http.StatusBadRequest, "invalid JSON")
 		return
 	}
 	created, err := h.service.CreateTask(r.Context(), &req)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, err.Error())
+		h.writeServiceError(w, r, err)
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(created)
+	w.Header().Set("ETag", formatETag(created.Version))
+	writeJSON(w, http.StatusCreated, created)
 }
 
+// listTasks returns one page of tasks as a JSON array. When more tasks follow,
+// a Link header with rel="next" carries the URL of the next page.
 func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.ListTasks(r.Context())
+	q, fields := parseTaskQuery(r.URL.Query())
+	if len(fields) > 0 {
+		h.writeErrorFields(w, r, http.StatusBadRequest, "invalid query parameters", fields)
+		return
+	}
+	page, err := h.service.ListTasks(r.Context(), q)
 	if err != nil {
-		h.writeError(w, http.StatusInternalServerError, err.Error())
+		h.writeServiceError(w, r, err)
 		return
 	}
-	json.NewEncoder(w).Encode(tasks)
+	h.writePage(w, r, page)
+}
+
+// writePage writes a page of tasks as a JSON array, with a Link header to the
+// next page when there is one.
+func (h *TaskHandler) writePage(w http.ResponseWriter, r *http.Request, page *model.TaskPage) {
+	if page.Next != nil {
+		w.Header().Set("Link", nextPageLink(r, page.Next.Encode()))
+	}
+	writeJSON(w, http.StatusOK, page.Tasks)
+}
+
+// dueView names one of the computed due-date listings.
+type dueView int
+
+const (
+	dueOverdue dueView = iota
+	dueToday
+	dueUpcoming
+)
+
+// defaultUpcomingDays is the window of /tasks/upcoming without a days parameter.
+const defaultUpcomingDays = 7
+
+// handleDueView serves GET on a due-date listing. Besides the usual list
+// parameters it takes tz (an IANA zone name, default UTC) that defines the
+// caller's calendar days, and for upcoming tasks the number of days ahead.
+func (h *TaskHandler) handleDueView(view dueView) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		values := r.URL.Query()
+		q, fields := parseTaskQuery(values)
+		loc, tzErr := parseLocation(values)
+		if tzErr != nil {
+			fields = append(fields, *tzErr)
+		}
+		days := defaultUpcomingDays
+		if v := values.Get("days"); v != "" && view == dueUpcoming {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				fields = append(fields, apperror.FieldError{Field: "days", Message: "days must be a non-negative integer"})
+			} else {
+				days = n
+			}
+		}
+		if len(fields) > 0 {
+			h.writeErrorFields(w, r, http.StatusBadRequest, "invalid query parameters", fields)
+			return
+		}
+
+		var (
+			page *model.TaskPage
+			err  error
+		)
+		switch view {
+		case dueOverdue:
+			page, err = h.service.OverdueTasks(r.Context(), q)
+		case dueToday:
+			page, err = h.service.DueTodayTasks(r.Context(), loc, q)
+		default:
+			page, err = h.service.UpcomingTasks(r.Context(), loc, days, q)
+		}
+		if err != nil {
+			h.writeServiceError(w, r, err)
+			return
+		}
+		h.writePage(w, r, page)
+	}
 }
 
 func (h *TaskHandler) getTask(w http.ResponseWriter, r *http.Request, id string) {
 	task, err := h.service.GetTask(r.Context(), id)
 	if err != nil {
-		h.writeError(w, http.StatusNotFound, "task not found")
+		h.writeServiceError(w, r, err)
 		return
 	}
-	json.NewEncoder(w).Encode(task)
+	w.Header().Set("ETag", formatETag(task.Version))
+	if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, task.Version, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
 }
 
 func (h *TaskHandler) updateTask(w http.ResponseWriter, r *http.Request, id string) {
 	var req model.Task
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		h.writeError(w, r, http.StatusBadRequest, "invalid JSON")
 		return
 	}
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	if version != 0 {
+		req.Version = version
+	}
 	updated, err := h.service.UpdateTask(r.Context(), id, &req)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, err.Error())
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(updated.Version))
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// maxPatchBytes bounds the size of PATCH documents.
+const maxPatchBytes = 1 << 20
+
+// patchTask applies an RFC 7396 merge patch (application/merge-patch+json or
+// application/json) or an RFC 6902 JSON Patch (application/json-patch+json).
+func (h *TaskHandler) patchTask(w http.ResponseWriter, r *http.Request, id string) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var parse func([]byte) (*model.TaskPatch, error)
+	switch mediaType {
+	case "application/merge-patch+json", "application/json", "":
+		parse = model.ParseMergePatch
+	case "application/json-patch+json":
+		parse = model.ParseJSONPatch
+	default:
+		w.Header().Set("Accept-Patch", "application/merge-patch+json, application/json-patch+json")
+		h.writeError(w, r, http.StatusUnsupportedMediaType, "unsupported patch format")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBytes))
+	if err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	patch, err := parse(body)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidPatch) {
+			h.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writeServiceError(w, r, err)
+		return
+	}
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	patched, err := h.service.PatchTask(r.Context(), id, patch, version)
+	if err != nil {
+		h.writeServiceError(w, r, err)
 		return
 	}
-	json.NewEncoder(w).Encode(updated)
+	w.Header().Set("ETag", formatETag(patched.Version))
+	writeJSON(w, http.StatusOK, patched)
 }
 
 func (h *TaskHandler) deleteTask(w http.ResponseWriter, r *http.Request, id string) {
-	if err := h.service.DeleteTask(r.Context(), id); err != nil {
-		h.writeError(w, http.StatusNotFound, "task not found")
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteTask(r.Context(), id, version); err != nil {
+		h.writeServiceError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// writeError writes a JSON error response.
-func (h *TaskHandler) writeError(w http.ResponseWriter, status int, message string) {
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":      status,
-			"message":   message,
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
-		},
-	})
-	h.logger.Warn("http error", zap.Int("status", status), zap.String("message", message))
+// undeleteTask serves POST /tasks/{id}/restore, which takes a task out of the
+// trash and responds with it.
+func (h *TaskHandler) undeleteTask(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	version, ok := h.checkTrashPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	task, err := h.service.UndeleteTask(r.Context(), id, version)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(task.Version))
+	writeJSON(w, http.StatusOK, task)
+}
+
+// purgeTask serves POST /tasks/{id}/purge, which permanently deletes a task in
+// the trash.
+func (h *TaskHandler) purgeTask(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	version, ok := h.checkTrashPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	if err := h.service.PurgeTask(r.Context(), id, version); err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// projectRequest is the body of POST /tasks/{id}/move and /tasks/{id}/copy.
+type projectRequest struct {
+	// ProjectID is the target project, empty for none.
+	ProjectID *string `json:"project_id"`
+}
+
+// decodeProjectRequest reads the target project of a move or copy, or writes an
+// error response and returns false.
+func (h *TaskHandler) decodeProjectRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return "", false
+	}
+	var req projectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid JSON")
+		return "", false
+	}
+	if req.ProjectID == nil {
+		h.writeServiceError(w, r, apperror.InvalidField("project_id", "project_id is required; use an empty string for no project"))
+		return "", false
+	}
+	return *req.ProjectID, true
+}
+
+// moveTask serves POST /tasks/{id}/move, which moves a task and its subtasks
+// to another project and responds with the moved task.
+func (h *TaskHandler) moveTask(w http.ResponseWriter, r *http.Request, id string) {
+	projectID, ok := h.decodeProjectRequest(w, r)
+	if !ok {
+		return
+	}
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	task, err := h.service.MoveTask(r.Context(), id, projectID, version)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(task.Version))
+	writeJSON(w, http.StatusOK, task)
+}
+
+// copyTask serves POST /tasks/{id}/copy, which copies a task and its subtasks
+// to a project and responds with the copied task.
+func (h *TaskHandler) copyTask(w http.ResponseWriter, r *http.Request, id string) {
+	projectID, ok := h.decodeProjectRequest(w, r)
+	if !ok {
+		return
+	}
+	task, err := h.service.CopyTask(r.Context(), id, projectID)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(task.Version))
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// handleBoard serves GET /tasks/board, the tasks in columns by status. It
+// takes the filters of GET /tasks; limit caps the tasks listed per column.
+func (h *TaskHandler) handleBoard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q, fields := parseTaskQuery(r.URL.Query())
+	if len(fields) > 0 {
+		h.writeErrorFields(w, r, http.StatusBadRequest, "invalid query parameters", fields)
+		return
+	}
+	board, err := h.service.Board(r.Context(), q)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, board)
+}
+
+// positionTask serves POST /tasks/{id}/position, which places a task on the
+// board and responds with the moved task.
+func (h *TaskHandler) positionTask(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		h.writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var move model.BoardMove
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		h.writeError(w, r, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	version, ok := h.checkPreconditions(w, r, id)
+	if !ok {
+		return
+	}
+	task, err := h.service.MoveOnBoard(r.Context(), id, move, version)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(task.Version))
+	writeJSON(w, http.StatusOK, task)
+}
+
+// checkPreconditions evaluates If-Match and If-None-Match for a write on task id.
+// It returns the version the write must be based on (0 when no precondition was
+// given) or writes a 412/404 response and returns false.
+func (h *TaskHandler) checkPreconditions(w http.ResponseWriter, r *http.Request, id string) (int64, bool) {
+	return h.checkPreconditionsOn(w, r, id, h.service.GetTask)
+}
+
+// checkTrashPreconditions is checkPreconditions for a task in the trash.
+func (h *TaskHandler) checkTrashPreconditions(w http.ResponseWriter, r *http.Request, id string) (int64, bool) {
+	return h.checkPreconditionsOn(w, r, id, h.service.GetTrashedTask)
+}
+
+// checkPreconditionsOn evaluates the preconditions against the task loaded by get.
+func (h *TaskHandler) checkPreconditionsOn(w http.ResponseWriter, r *http.Request, id string,
+	get func(ctx context.Context, id string) (*model.Task, error)) (int64, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifMatch == "" && ifNoneMatch == "" {
+		return 0, true
+	}
+	current, err := get(r.Context(), id)
+	if err != nil {
+		if ifMatch != "" && apperror.KindOf(err) == apperror.KindNotFound {
+			h.writeError(w, r, http.StatusPreconditionFailed, "precondition failed: task does not exist")
+		} else {
+			h.writeServiceError(w, r, err)
+		}
+		return 0, false
+	}
+	if ifMatch != "" && !etagMatches(ifMatch, current.Version, false) {
+		w.Header().Set("ETag", formatETag(current.Version))
+		h.writeError(w, r, http.StatusPreconditionFailed, "precondition failed: task has been modified")
+		return 0, false
+	}
+	if ifNoneMatch != "" && etagMatches(ifNoneMatch, current.Version, true) {
+		w.Header().Set("ETag", formatETag(current.Version))
+		h.writeError(w, r, http.StatusPreconditionFailed, "precondition failed: task matches If-None-Match")
+		return 0, false
+	}
+	return current.Version, true
+}
+
+// writeServiceError maps err to a status code and writes it.
+func (h *TaskHandler) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	writeServiceError(h.logger, w, r, err)
+}
+
+// writeError writes a problem+json error response.
+func (h *TaskHandler) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeErrorFields(h.logger, w, r, status, message, nil)
+}
+
+// writeErrorFields writes a problem+json error response with per-field details.
+func (h *TaskHandler) writeErrorFields(w http.ResponseWriter, r *http.Request, status int, message string, fields []apperror.FieldError) {
+	writeErrorFields(h.logger, w, r, status, message, fields)
 }
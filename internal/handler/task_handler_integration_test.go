@@ -3,12 +3,16 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"taskmanager/internal/model"
 	"taskmanager/internal/repository"
+	"taskmanager/internal/search"
 	"taskmanager/internal/service"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -111,11 +115,17 @@ func TestIntegration_CreateTask_ValidationError(t *testing.T) {
 	r := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body))
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
-	require.Equal(t, http.StatusBadRequest, w.Code, "expected 400 Bad Request")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, "expected 422 Unprocessable Entity")
 	var resp map[string]interface{}
 	err := json.NewDecoder(w.Body).Decode(&resp)
 	require.NoError(t, err, "decoding error response")
-	assert.Contains(t, resp, "error", "expected error in response")
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "urn:taskmanager:problem:validation-error", resp["type"])
+	assert.Equal(t, float64(http.StatusUnprocessableEntity), resp["status"])
+	assert.Equal(t, "/tasks", resp["instance"])
+	fields := resp["errors"].([]interface{})
+	require.Len(t, fields, 1)
+	assert.Equal(t, "title", fields[0].(map[string]interface{})["field"])
 }
 
 func TestIntegration_CreateTask_WithUserSuppliedID(t *testing.T) {
@@ -133,3 +143,481 @@ func TestIntegration_CreateTask_WithUserSuppliedID(t *testing.T) {
 	assert.Equal(t, userID, created.ID, "expected ID to match user supplied ID")
 	assert.Equal(t, "Integration with user ID", created.Title, "expected title to match")
 }
+
+// TestIntegration_ReservedTaskIDs checks that no task can take the ID of a
+// view under /tasks/, where it could never be reached by ID.
+func TestIntegration_ReservedTaskIDs(t *testing.T) {
+	mux := setupIntegrationHandler()
+	for _, route := range []string{
+		"/tasks/search", "/tasks/overdue", "/tasks/due-today", "/tasks/upcoming",
+		"/tasks/order", "/tasks/unblocked", "/tasks/critical-path", "/tasks/board",
+	} {
+		id := strings.TrimPrefix(route, "/tasks/")
+		w := serve(mux, http.MethodPost, "/tasks", fmt.Sprintf(`{"id":%q,"title":"Shadowed"}`, id))
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, route)
+		var problem Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+		require.Len(t, problem.Errors, 1, route)
+		assert.Equal(t, "id", problem.Errors[0].Field, route)
+	}
+}
+
+func TestIntegration_ETagPreconditions(t *testing.T) {
+	mux := setupIntegrationHandler()
+	body, _ := json.Marshal(&model.Task{Title: "Versioned"})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	var created model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, int64(1), created.Version)
+
+	// Conditional GET with the current ETag is not modified.
+	r := httptest.NewRequest(http.MethodGet, "/tasks/"+created.ID, nil)
+	r.Header.Set("If-None-Match", `W/"1"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// First writer succeeds and bumps the version.
+	update, _ := json.Marshal(&model.Task{Title: "First"})
+	r = httptest.NewRequest(http.MethodPut, "/tasks/"+created.ID, bytes.NewReader(update))
+	r.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// Second writer based on version 1 is rejected.
+	update, _ = json.Marshal(&model.Task{Title: "Second"})
+	r = httptest.NewRequest(http.MethodPut, "/tasks/"+created.ID, bytes.NewReader(update))
+	r.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// A stale version in the body without headers is a conflict.
+	update, _ = json.Marshal(&model.Task{Title: "Third", Version: 1})
+	r = httptest.NewRequest(http.MethodPut, "/tasks/"+created.ID, bytes.NewReader(update))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// If-None-Match: * never allows overwriting an existing task.
+	r = httptest.NewRequest(http.MethodPut, "/tasks/"+created.ID, bytes.NewReader(update))
+	r.Header.Set("If-None-Match", "*")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Delete is conditional too.
+	r = httptest.NewRequest(http.MethodDelete, "/tasks/"+created.ID, nil)
+	r.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	r = httptest.NewRequest(http.MethodDelete, "/tasks/"+created.ID, nil)
+	r.Header.Set("If-Match", `"1", "2"`)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// If-Match on a missing task fails the precondition.
+	r = httptest.NewRequest(http.MethodDelete, "/tasks/"+created.ID, nil)
+	r.Header.Set("If-Match", "*")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestIntegration_PatchTask(t *testing.T) {
+	mux := setupIntegrationHandler()
+	body, _ := json.Marshal(&model.Task{Title: "Patch me", Description: "Some text", Completed: true})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+
+	// Merge patch: only title changes, description is cleared explicitly.
+	r := httptest.NewRequest(http.MethodPatch, "/tasks/"+created.ID, bytes.NewBufferString(`{"title":"Patched","description":null}`))
+	r.Header.Set("Content-Type", "application/merge-patch+json")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&patched))
+	assert.Equal(t, "Patched", patched.Title)
+	assert.Empty(t, patched.Description)
+	assert.True(t, patched.Completed, "completed must survive a patch that does not mention it")
+
+	// JSON Patch with a failing test operation.
+	r = httptest.NewRequest(http.MethodPatch, "/tasks/"+created.ID,
+		bytes.NewBufferString(`[{"op":"test","path":"/title","value":"Nope"},{"op":"replace","path":"/completed","value":false}]`))
+	r.Header.Set("Content-Type", "application/json-patch+json")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// JSON Patch that holds.
+	r = httptest.NewRequest(http.MethodPatch, "/tasks/"+created.ID,
+		bytes.NewBufferString(`[{"op":"test","path":"/title","value":"Patched"},{"op":"replace","path":"/completed","value":false}]`))
+	r.Header.Set("Content-Type", "application/json-patch+json")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&patched))
+	assert.False(t, patched.Completed)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	// Read-only fields and unsupported media types are rejected.
+	r = httptest.NewRequest(http.MethodPatch, "/tasks/"+created.ID, bytes.NewBufferString(`{"id":"other"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	r = httptest.NewRequest(http.MethodPatch, "/tasks/"+created.ID, bytes.NewBufferString(`title=x`))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestIntegration_PutReplacesTask(t *testing.T) {
+	mux := setupIntegrationHandler()
+	body, _ := json.Marshal(&model.Task{Title: "Replace me", Description: "Old", Completed: true})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/tasks/"+created.ID, bytes.NewBufferString(`{"title":"Replaced"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var replaced model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&replaced))
+	assert.Equal(t, "Replaced", replaced.Title)
+	assert.Empty(t, replaced.Description)
+	assert.False(t, replaced.Completed)
+}
+
+func TestIntegration_CreateTask_DuplicateID(t *testing.T) {
+	mux := setupIntegrationHandler()
+	body, _ := json.Marshal(&model.Task{ID: "dup-1", Title: "First"})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestIntegration_ListTasks_Pagination(t *testing.T) {
+	mux := setupIntegrationHandler()
+	for i := 0; i < 5; i++ {
+		body, _ := json.Marshal(&model.Task{Title: fmt.Sprintf("Task %d", i)})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	var titles []string
+	next := "/tasks?sort=title&order=desc&limit=2"
+	for pages := 0; next != ""; pages++ {
+		require.Less(t, pages, 5, "pagination does not terminate")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, next, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var tasks []model.Task
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		next = ""
+		if link := w.Header().Get("Link"); link != "" {
+			next = link[strings.Index(link, "<")+1 : strings.Index(link, ">")]
+		}
+	}
+	assert.Equal(t, []string{"Task 4", "Task 3", "Task 2", "Task 1", "Task 0"}, titles)
+}
+
+func TestIntegration_SearchFollowsWrites(t *testing.T) {
+	repo := search.NewIndexedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()), search.NewIndex())
+	mux := http.NewServeMux()
+	NewTaskHandler(service.NewTaskService(repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	NewSearchHandler(service.NewSearchService(repo.Index(), repo, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	searchIDs := func(q string) []string {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/search?q="+q, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Results []struct {
+				Task model.Task `json:"task"`
+			} `json:"results"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		ids := []string{}
+		for _, r := range resp.Results {
+			ids = append(ids, r.Task.ID)
+		}
+		return ids
+	}
+
+	body, _ := json.Marshal(&model.Task{ID: "s1", Title: "Migrate database", Description: "Move to the managed cluster"})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, []string{"s1"}, searchIDs("migration"))
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/tasks/s1", strings.NewReader(`{"title":"Upgrade database"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, searchIDs("migration"))
+	assert.Equal(t, []string{"s1"}, searchIDs("upgr"))
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/tasks/s1", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, searchIDs("database"))
+}
+
+func TestIntegration_StatusWorkflow(t *testing.T) {
+	mux := setupIntegrationHandler()
+	w := serve(mux, http.MethodPost, "/tasks", `{"id":"wf","title":"Workflow"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"todo"`)
+
+	w = serve(mux, http.MethodPatch, "/tasks/wf", `{"status":"in_review"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var problem Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "status", problem.Errors[0].Field)
+	assert.Contains(t, problem.Detail, "allowed next states: in_progress, done, cancelled")
+
+	w = serve(mux, http.MethodPatch, "/tasks/wf", `{"status":"in_progress"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(mux, http.MethodPatch, "/tasks/wf", `{"completed":true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var task model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	assert.Equal(t, model.StatusDone, task.Status)
+	assert.True(t, task.Completed)
+
+	w = serve(mux, http.MethodGet, "/tasks?status=done&status=cancelled", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"wf"`)
+
+	w = serve(mux, http.MethodGet, "/workflow", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var wf model.Workflow
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&wf))
+	assert.Equal(t, model.DefaultWorkflow(), &wf)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodPost, "/workflow", "").Code)
+}
+
+func TestIntegration_Recurrence(t *testing.T) {
+	mux := setupIntegrationHandler()
+	w := serve(mux, http.MethodPost, "/tasks", `{"id":"rec","title":"Water plants","due_at":"2025-03-28T08:00:00Z",`+
+		`"recurrence":{"rule":"FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3","tz":"Europe/Berlin"}}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = serve(mux, http.MethodGet, "/tasks/rec/occurrences?limit=5", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var occurrences []model.Occurrence
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&occurrences))
+	require.Len(t, occurrences, 2)
+	assert.Equal(t, "2025-03-31T07:00:00Z", occurrences[0].DueAt.Format(time.RFC3339), "9:00 in Berlin after the DST change")
+	assert.Equal(t, "2025-04-04T07:00:00Z", occurrences[1].DueAt.Format(time.RFC3339))
+
+	assert.Equal(t, http.StatusBadRequest, serve(mux, http.MethodGet, "/tasks/rec/occurrences?limit=0", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(mux, http.MethodGet, "/tasks/rec/occurrences?limit=101", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, http.MethodPost, "/tasks/rec/occurrences", "").Code)
+
+	w = serve(mux, http.MethodPut, "/tasks/rec", `{"title":"Water plants","completed":true,"due_at":"2025-03-28T08:00:00Z",`+
+		`"recurrence":{"rule":"FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3","tz":"Europe/Berlin"}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var task model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	require.NotEmpty(t, task.Recurrence.NextID)
+
+	w = serve(mux, http.MethodGet, "/tasks/"+task.Recurrence.NextID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var next model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&next))
+	assert.Equal(t, "Water plants", next.Title)
+	assert.Equal(t, model.StatusTodo, next.Status)
+	assert.Equal(t, occurrences[0].DueAt.UTC(), next.DueAt.UTC())
+	assert.Equal(t, 2, next.Recurrence.Occurrence)
+}
+
+func TestIntegration_History(t *testing.T) {
+	mux := Actor(setupIntegrationHandler())
+	send := func(method, target, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/tasks", `{"id":"h","title":"Draft"}`, ActorHeader, "alice").Code)
+	require.Equal(t, http.StatusOK, send(http.MethodPatch, "/tasks/h", `{"title":"Final","priority":"high"}`, ActorHeader, "bob").Code)
+
+	w := send(http.MethodGet, "/tasks/h/history", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var entries []model.HistoryEntry
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Equal(t, "bob", entries[1].Actor)
+	assert.Len(t, entries[1].Changes, 2)
+
+	w = send(http.MethodPost, "/tasks/h/history/1/restore", "", "If-Match", `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = send(http.MethodPost, "/tasks/h/history/1/restore", "", "If-Match", `"2"`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	var task model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	assert.Equal(t, "Draft", task.Title)
+	assert.Empty(t, task.Priority)
+
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/tasks/h/history/x/restore", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/tasks/h/history/7/restore", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/tasks/h/history/1", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodGet, "/tasks/h/history/1/restore", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodPost, "/tasks/h/history", "").Code)
+
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/tasks/h", "").Code)
+	w = send(http.MethodGet, "/tasks/h/history", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	entries = nil
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&entries))
+	require.Len(t, entries, 4)
+	assert.Equal(t, model.HistoryDelete, entries[3].Action)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/tasks/missing/history", "").Code)
+}
+
+func TestIntegration_Trash(t *testing.T) {
+	mux := setupIntegrationHandler()
+	send := func(method, target, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	listIDs := func(target string) []string {
+		w := send(http.MethodGet, target, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var tasks []model.Task
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+		ids := []string{}
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/tasks", `{"id":"keep","title":"Keep"}`).Code)
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/tasks", `{"id":"oops","title":"Oops"}`).Code)
+
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, "/tasks/oops/restore", "").Code, "live tasks cannot be restored")
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, "/tasks/oops/purge", "").Code, "live tasks cannot be purged")
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/tasks/oops", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/tasks/oops", "").Code)
+	assert.Equal(t, []string{"keep"}, listIDs("/tasks"))
+	assert.Equal(t, []string{"oops"}, listIDs("/tasks?trashed=true"))
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/tasks?trashed=yes", "").Code)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodGet, "/tasks/oops/restore", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodDelete, "/tasks/oops/purge", "").Code)
+	assert.Equal(t, http.StatusPreconditionFailed, send(http.MethodPost, "/tasks/oops/restore", "", "If-Match", `"1"`).Code)
+	w := send(http.MethodPost, "/tasks/oops/restore", "", "If-Match", `"2"`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	var task model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	assert.Nil(t, task.DeletedAt)
+	assert.ElementsMatch(t, []string{"keep", "oops"}, listIDs("/tasks"))
+
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/tasks/oops", "").Code)
+	require.Equal(t, http.StatusNoContent, send(http.MethodPost, "/tasks/oops/purge", "").Code)
+	assert.Empty(t, listIDs("/tasks?trashed=true"))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/tasks/oops/restore", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/tasks/missing/purge", "").Code)
+}
+
+func TestIntegration_Board(t *testing.T) {
+	wf := model.DefaultWorkflow()
+	wf.WIPLimits = map[string]int{model.StatusInProgress: 1}
+	mux := http.NewServeMux()
+	svc := service.NewTaskService(repository.NewInMemoryTaskRepository(zap.NewNop()), zap.NewNop(), service.WithWorkflow(wf))
+	NewTaskHandler(svc, zap.NewNop()).RegisterRoutes(mux)
+	send := func(method, target, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	board := func(target string) model.Board {
+		w := send(http.MethodGet, target, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var b model.Board
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&b))
+		return b
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		require.Equal(t, http.StatusCreated, send(http.MethodPost, "/tasks", fmt.Sprintf(`{"id":%q,"title":"Task"}`, id)).Code)
+	}
+
+	b := board("/tasks/board?limit=2")
+	require.Len(t, b.Columns, 5)
+	todo := b.Columns[0]
+	assert.Equal(t, model.StatusTodo, todo.Status)
+	assert.Equal(t, 3, todo.Count)
+	require.Len(t, todo.Tasks, 2)
+	assert.Equal(t, "a", todo.Tasks[0].ID)
+	assert.Equal(t, 1, b.Columns[1].WIPLimit)
+	assert.Len(t, board("/tasks/board?status=done").Columns, 1)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/tasks/board?limit=x", "").Code)
+	w := send(http.MethodPost, "/tasks/board", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Allow"))
+
+	w = send(http.MethodPost, "/tasks/c/position", `{"before":"a"}`, "If-Match", `"1"`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	order := []string{}
+	for _, task := range board("/tasks/board?status=todo").Columns[0].Tasks {
+		order = append(order, task.ID)
+	}
+	assert.Equal(t, []string{"c", "a", "b"}, order)
+
+	assert.Equal(t, http.StatusPreconditionFailed, send(http.MethodPost, "/tasks/c/position", `{}`, "If-Match", `"1"`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/tasks/c/position", `{`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, send(http.MethodPost, "/tasks/c/position", `{"after":"missing"}`).Code)
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/tasks/missing/position", `{}`).Code)
+	w = send(http.MethodGet, "/tasks/c/position", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "POST", w.Header().Get("Allow"))
+
+	require.Equal(t, http.StatusOK, send(http.MethodPost, "/tasks/a/position", `{"status":"in_progress"}`).Code)
+	w = send(http.MethodPost, "/tasks/b/position", `{"status":"in_progress"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "WIP limit")
+	assert.Equal(t, http.StatusConflict, send(http.MethodPatch, "/tasks/b", `{"status":"in_progress"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPatch, "/tasks/b", `{"rank":"0"}`).Code,
+		"ranks are set by moving tasks on the board")
+}
@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
+
 	"net/http"
 	"net/http/httptest"
+	"taskmanager/internal/apperror"
 	"taskmanager/internal/model"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -28,18 +31,104 @@ func (m *MockTaskService) GetTask(ctx context.Context, id string) (*model.Task,
 	args := m.Called(ctx, id)
 	return args.Get(0).(*model.Task), args.Error(1)
 }
-func (m *MockTaskService) ListTasks(ctx context.Context) ([]*model.Task, error) {
-	args := m.Called(ctx)
-	return args.Get(0).([]*model.Task), args.Error(1)
+func (m *MockTaskService) ListTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(*model.TaskPage), args.Error(1)
+}
+func (m *MockTaskService) OverdueTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(*model.TaskPage), args.Error(1)
+}
+func (m *MockTaskService) DueTodayTasks(ctx context.Context, loc *time.Location, q model.TaskQuery) (*model.TaskPage, error) {
+	args := m.Called(ctx, loc, q)
+	return args.Get(0).(*model.TaskPage), args.Error(1)
+}
+func (m *MockTaskService) UpcomingTasks(ctx context.Context, loc *time.Location, days int, q model.TaskQuery) (*model.TaskPage, error) {
+	args := m.Called(ctx, loc, days, q)
+	return args.Get(0).(*model.TaskPage), args.Error(1)
 }
 func (m *MockTaskService) UpdateTask(ctx context.Context, id string, task *model.Task) (*model.Task, error) {
 	args := m.Called(ctx, id, task)
 	return args.Get(0).(*model.Task), args.Error(1)
 }
-func (m *MockTaskService) DeleteTask(ctx context.Context, id string) error {
+func (m *MockTaskService) PatchTask(ctx context.Context, id string, patch *model.TaskPatch, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, patch, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+func (m *MockTaskService) AttachLabel(ctx context.Context, id, name string, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, name, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+func (m *MockTaskService) DetachLabel(ctx context.Context, id, name string, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, name, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+func (m *MockTaskService) DeleteTask(ctx context.Context, id string, version int64) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+func (m *MockTaskService) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
 	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+func (m *MockTaskService) UndeleteTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+func (m *MockTaskService) PurgeTask(ctx context.Context, id string, version int64) error {
+	args := m.Called(ctx, id, version)
 	return args.Error(0)
 }
+func (m *MockTaskService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	args := m.Called(ctx, retention)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockTaskService) ListChildren(ctx context.Context, id string, q model.TaskQuery) (*model.TaskPage, error) {
+	args := m.Called(ctx, id, q)
+	return args.Get(0).(*model.TaskPage), args.Error(1)
+}
+func (m *MockTaskService) GetSubtree(ctx context.Context, id string) (*model.TaskTree, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.TaskTree), args.Error(1)
+}
+
+func (m *MockTaskService) Occurrences(ctx context.Context, id string, limit int) ([]model.Occurrence, error) {
+	args := m.Called(ctx, id, limit)
+	return args.Get(0).([]model.Occurrence), args.Error(1)
+}
+func (m *MockTaskService) History(ctx context.Context, id string) ([]*model.HistoryEntry, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*model.HistoryEntry), args.Error(1)
+}
+func (m *MockTaskService) RestoreTask(ctx context.Context, id string, revision, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, revision, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+
+func (m *MockTaskService) MoveTask(ctx context.Context, id, projectID string, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, projectID, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+
+func (m *MockTaskService) CopyTask(ctx context.Context, id, projectID string) (*model.Task, error) {
+	args := m.Called(ctx, id, projectID)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+
+func (m *MockTaskService) Board(ctx context.Context, q model.TaskQuery) (*model.Board, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(*model.Board), args.Error(1)
+}
+
+func (m *MockTaskService) MoveOnBoard(ctx context.Context, id string, move model.BoardMove, version int64) (*model.Task, error) {
+	args := m.Called(ctx, id, move, version)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
+
+func (m *MockTaskService) Workflow() *model.Workflow {
+	args := m.Called()
+	return args.Get(0).(*model.Workflow)
+}
 
 func TestTaskHandler_CreateTask_Success(t *testing.T) {
 	ts := new(MockTaskService)
@@ -67,15 +156,15 @@ func TestTaskHandler_CreateTask_ValidationError(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 	task := &model.Task{Title: "", Completed: false}
-	ts.On("CreateTask", mock.Anything, task).Return((*model.Task)(nil), errors.New("title is required"))
+	ts.On("CreateTask", mock.Anything, task).Return((*model.Task)(nil), apperror.InvalidField("title", "title is required"))
 	body, _ := json.Marshal(task)
 	r := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(body))
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 	var resp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&resp)
-	assert.Contains(t, resp["error"].(map[string]interface{})["message"], "title is required")
+	assert.Contains(t, resp["detail"], "title is required")
 	ts.AssertExpectations(t)
 }
 
@@ -86,17 +175,70 @@ func TestTaskHandler_ListTasks(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 	tasks := []*model.Task{{ID: "task-1", Title: "A", Completed: false}}
-	ts.On("ListTasks", mock.Anything).Return(tasks, nil)
+	ts.On("ListTasks", mock.Anything, model.TaskQuery{}).Return(&model.TaskPage{Tasks: tasks}, nil)
 	r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Link"))
 	var resp []model.Task
 	json.NewDecoder(w.Body).Decode(&resp)
 	assert.Len(t, resp, 1)
 	ts.AssertExpectations(t)
 }
 
+func TestTaskHandler_ListTasks_QueryParameters(t *testing.T) {
+	ts := new(MockTaskService)
+	h := NewTaskHandler(ts, zap.NewNop())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	completed := true
+	want := model.TaskQuery{
+		Filter: model.TaskFilter{
+			Completed:     &completed,
+			CreatedAfter:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			TitleContains: "report",
+		},
+		Sort:  model.SortByTitle,
+		Desc:  true,
+		Limit: 10,
+	}
+	next := want.CursorAt(&model.Task{ID: "task-10", Title: "report 10"})
+	ts.On("ListTasks", mock.Anything, want).Return(&model.TaskPage{Tasks: []*model.Task{}, Next: next}, nil)
+
+	r := httptest.NewRequest(http.MethodGet,
+		"/tasks?completed=true&created_after=2025-01-01T00:00:00Z&title=report&sort=title&order=desc&limit=10", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	link := w.Header().Get("Link")
+	assert.Contains(t, link, "cursor="+next.Encode())
+	assert.Contains(t, link, "sort=title")
+	assert.Contains(t, link, `rel="next"`)
+	ts.AssertExpectations(t)
+}
+
+func TestTaskHandler_ListTasks_InvalidParameters(t *testing.T) {
+	ts := new(MockTaskService)
+	h := NewTaskHandler(ts, zap.NewNop())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	r := httptest.NewRequest(http.MethodGet, "/tasks?completed=maybe&limit=-1&sort=color&cursor=!!", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp Problem
+	json.NewDecoder(w.Body).Decode(&resp)
+	var fields []string
+	for _, f := range resp.Errors {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"completed", "limit", "cursor"}, fields)
+	ts.AssertNotCalled(t, "ListTasks", mock.Anything, mock.Anything)
+}
+
 func TestTaskHandler_GetTask_Success(t *testing.T) {
 	ts := new(MockTaskService)
 	logger := zap.NewNop()
@@ -123,14 +265,14 @@ func TestTaskHandler_GetTask_NotFound(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 	id := "task-1"
-	ts.On("GetTask", mock.Anything, id).Return((*model.Task)(nil), errors.New("not found"))
+	ts.On("GetTask", mock.Anything, id).Return((*model.Task)(nil), apperror.NotFound("task not found"))
 	r := httptest.NewRequest(http.MethodGet, "/tasks/"+id, nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	var resp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&resp)
-	assert.Contains(t, resp["error"].(map[string]interface{})["message"], "not found")
+	assert.Contains(t, resp["detail"], "not found")
 	ts.AssertExpectations(t)
 }
 
@@ -163,15 +305,15 @@ func TestTaskHandler_UpdateTask_ValidationError(t *testing.T) {
 	h.RegisterRoutes(mux)
 	id := "task-1"
 	update := &model.Task{Title: ""}
-	ts.On("UpdateTask", mock.Anything, id, update).Return((*model.Task)(nil), errors.New("title is required"))
+	ts.On("UpdateTask", mock.Anything, id, update).Return((*model.Task)(nil), apperror.InvalidField("title", "title is required"))
 	body, _ := json.Marshal(update)
 	r := httptest.NewRequest(http.MethodPut, "/tasks/"+id, bytes.NewReader(body))
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 	var resp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&resp)
-	assert.Contains(t, resp["error"].(map[string]interface{})["message"], "title is required")
+	assert.Contains(t, resp["detail"], "title is required")
 	ts.AssertExpectations(t)
 }
 
@@ -182,7 +324,7 @@ func TestTaskHandler_DeleteTask_Success(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 	id := "task-1"
-	ts.On("DeleteTask", mock.Anything, id).Return(nil)
+	ts.On("DeleteTask", mock.Anything, id, int64(0)).Return(nil)
 	r := httptest.NewRequest(http.MethodDelete, "/tasks/"+id, nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
@@ -197,13 +339,133 @@ func TestTaskHandler_DeleteTask_NotFound(t *testing.T) {
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 	id := "task-1"
-	ts.On("DeleteTask", mock.Anything, id).Return(errors.New("not found"))
+	ts.On("DeleteTask", mock.Anything, id, int64(0)).Return(apperror.NotFound("task not found"))
 	r := httptest.NewRequest(http.MethodDelete, "/tasks/"+id, nil)
 	w := httptest.NewRecorder()
 	mux.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	var resp map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&resp)
-	assert.Contains(t, resp["error"].(map[string]interface{})["message"], "not found")
+	assert.Contains(t, resp["detail"], "not found")
+	ts.AssertExpectations(t)
+}
+
+func TestTaskHandler_DueViews(t *testing.T) {
+	ts := new(MockTaskService)
+	h := NewTaskHandler(ts, zap.NewNop())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	page := &model.TaskPage{Tasks: []*model.Task{{ID: "task-1", Title: "A"}}}
+	ts.On("OverdueTasks", mock.Anything, model.TaskQuery{Limit: 5}).Return(page, nil)
+	ts.On("DueTodayTasks", mock.Anything, tokyo, model.TaskQuery{}).Return(page, nil)
+	ts.On("UpcomingTasks", mock.Anything, time.UTC, defaultUpcomingDays, model.TaskQuery{}).Return(page, nil)
+	ts.On("UpcomingTasks", mock.Anything, tokyo, 30, model.TaskQuery{}).Return(page, nil)
+
+	for _, target := range []string{
+		"/tasks/overdue?limit=5",
+		"/tasks/due-today?tz=Asia/Tokyo",
+		"/tasks/upcoming",
+		"/tasks/upcoming?days=30&tz=Asia/Tokyo",
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, w.Code, target)
+		var resp []model.Task
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Len(t, resp, 1, target)
+	}
+	ts.AssertExpectations(t)
+}
+
+func TestTaskHandler_DueViews_InvalidParameters(t *testing.T) {
+	ts := new(MockTaskService)
+	h := NewTaskHandler(ts, zap.NewNop())
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/upcoming?tz=Mars/Olympus&days=-1", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	var fields []string
+	for _, f := range resp.Errors {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"tz", "days"}, fields)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks/overdue", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Allow"))
 	ts.AssertExpectations(t)
 }
+
+func TestTaskHandler_ListChildren(t *testing.T) {
+	ts := new(MockTaskService)
+	mux := http.NewServeMux()
+	NewTaskHandler(ts, zap.NewNop()).RegisterRoutes(mux)
+	want := model.TaskQuery{Limit: 1}
+	next := want.CursorAt(&model.Task{ID: "child-1"})
+	ts.On("ListChildren", mock.Anything, "task-1", want).
+		Return(&model.TaskPage{Tasks: []*model.Task{{ID: "child-1", ParentID: "task-1"}}, Next: next}, nil)
+	ts.On("ListChildren", mock.Anything, "missing", model.TaskQuery{}).
+		Return((*model.TaskPage)(nil), apperror.NotFound("task not found"))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/task-1/children?limit=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Link"), "/tasks/task-1/children?")
+	var resp []model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "task-1", resp[0].ParentID)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/missing/children", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks/task-1/children", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET", w.Header().Get("Allow"))
+	ts.AssertExpectations(t)
+}
+
+func TestTaskHandler_GetSubtree(t *testing.T) {
+	ts := new(MockTaskService)
+	mux := http.NewServeMux()
+	NewTaskHandler(ts, zap.NewNop()).RegisterRoutes(mux)
+	tree := model.BuildTaskTree(&model.Task{ID: "task-1"}, []*model.Task{{ID: "child-1", ParentID: "task-1", Completed: true}})
+	ts.On("GetSubtree", mock.Anything, "task-1").Return(tree, nil)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/task-1/subtree", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Task     model.Task     `json:"task"`
+		Progress model.Progress `json:"progress"`
+		Children []struct {
+			Task model.Task `json:"task"`
+		} `json:"children"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "task-1", resp.Task.ID)
+	assert.Equal(t, model.Progress{Subtasks: 1, Completed: 1, Percent: 100}, resp.Progress)
+	require.Len(t, resp.Children, 1)
+	assert.Equal(t, "child-1", resp.Children[0].Task.ID)
+	ts.AssertExpectations(t)
+}
+
+func TestTaskHandler_DeleteWithSubtasksConflicts(t *testing.T) {
+	ts := new(MockTaskService)
+	mux := http.NewServeMux()
+	NewTaskHandler(ts, zap.NewNop()).RegisterRoutes(mux)
+	ts.On("DeleteTask", mock.Anything, "task-1", int64(0)).Return(apperror.Conflict("task has subtasks: 2 subtasks"))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/tasks/task-1", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "subtasks")
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"time"
+)
+
+// parseTaskQuery builds a task query from the GET /tasks query string:
+//
+//	completed=true|false
+//	status, repeatable (any of)
+//	created_after, created_before, updated_after, updated_before,
+//	start_after, start_before, due_after, due_before (RFC 3339)
+//	priority=none|low|medium|high|urgent, repeatable (any of)
+//	title (case-insensitive substring)
+//	label, repeatable; label_match=all|any (default all)
+//	parent_id (subtasks of the given task; empty for top-level tasks)
+//	assignee (tasks assigned to the given user; empty for unassigned tasks)
+//	project (tasks of the given project; empty for tasks outside any project)
+//	trashed=true|false (the trash instead of the live tasks)
+//	deleted_after, deleted_before (RFC 3339, for trashed tasks)
+//	sort=created_at|updated_at|title|priority|start_at|due_at|rank, order=asc|desc
+//	limit, cursor
+//
+// It reports every malformed parameter.
+func parseTaskQuery(values url.Values) (model.TaskQuery, []apperror.FieldError) {
+	var (
+		q      model.TaskQuery
+		fields []apperror.FieldError
+	)
+	invalid := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+
+	if v := values.Get("completed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid("completed", "completed must be true or false")
+		} else {
+			q.Filter.Completed = &b
+		}
+	}
+	if v := values.Get("trashed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid("trashed", "trashed must be true or false")
+		} else {
+			q.Filter.Trashed = b
+		}
+	}
+	for _, v := range values["status"] {
+		if v != "" {
+			q.Filter.Statuses = append(q.Filter.Statuses, v)
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"created_after", &q.Filter.CreatedAfter},
+		{"created_before", &q.Filter.CreatedBefore},
+		{"updated_after", &q.Filter.UpdatedAfter},
+		{"updated_before", &q.Filter.UpdatedBefore},
+		{"start_after", &q.Filter.StartAfter},
+		{"start_before", &q.Filter.StartBefore},
+		{"due_after", &q.Filter.DueAfter},
+		{"due_before", &q.Filter.DueBefore},
+		{"deleted_after", &q.Filter.DeletedAfter},
+		{"deleted_before", &q.Filter.DeletedBefore},
+	} {
+		if v := values.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				invalid(p.name, p.name+" must be an RFC 3339 timestamp")
+				continue
+			}
+			*p.dst = t
+		}
+	}
+	for _, v := range values["priority"] {
+		switch v {
+		case "":
+		case "none":
+			q.Filter.Priorities = append(q.Filter.Priorities, model.PriorityNone)
+		default:
+			q.Filter.Priorities = append(q.Filter.Priorities, model.Priority(v))
+		}
+	}
+	q.Filter.TitleContains = values.Get("title")
+	for _, v := range values["label"] {
+		if v != "" {
+			q.Filter.Labels = append(q.Filter.Labels, v)
+		}
+	}
+	if values.Has("parent_id") {
+		parent := values.Get("parent_id")
+		q.Filter.ParentID = &parent
+	}
+	if values.Has("assignee") {
+		assignee := values.Get("assignee")
+		q.Filter.Assignee = &assignee
+	}
+	if values.Has("project") {
+		project := values.Get("project")
+		q.Filter.ProjectID = &project
+	}
+	switch values.Get("label_match") {
+	case "", "all":
+	case "any":
+		q.Filter.LabelMatch = model.LabelMatchAny
+	default:
+		invalid("label_match", "label_match must be all or any")
+	}
+
+	q.Sort = model.TaskSortField(values.Get("sort"))
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		invalid("order", "order must be asc or desc")
+	}
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			invalid("limit", "limit must be a positive integer")
+		} else {
+			q.Limit = n
+		}
+	}
+	if v := values.Get("cursor"); v != "" {
+		c, err := model.ParseTaskCursor(v)
+		if err != nil {
+			fields = append(fields, apperror.FieldsOf(err)...)
+		} else {
+			q.After = c
+		}
+	}
+	if len(fields) == 0 {
+		fields = apperror.FieldsOf(q.Validate())
+	}
+	return q, fields
+}
+
+// parseLocation reads the tz query parameter, an IANA time zone name such as
+// "Europe/Berlin". It defaults to UTC.
+func parseLocation(values url.Values) (*time.Location, *apperror.FieldError) {
+	name := values.Get("tz")
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, &apperror.FieldError{Field: "tz", Message: "unknown time zone " + name}
+	}
+	return loc, nil
+}
+
+// nextPageLink returns a Link header value pointing at the page starting at
+// the encoded cursor, keeping the request's other query parameters.
+func nextPageLink(r *http.Request, cursor string) string {
+	values := r.URL.Query()
+	values.Set("cursor", cursor)
+	next := url.URL{Path: requestPath(r), RawQuery: values.Encode()}
+	return "<" + next.String() + `>; rel="next"`
+}
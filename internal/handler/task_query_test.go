@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskQuery_PlanningParameters(t *testing.T) {
+	values, _ := url.ParseQuery("priority=high&priority=none&due_before=2025-03-10T00:00:00Z&start_after=2025-03-01T00:00:00%2B01:00&sort=due_at")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	assert.Equal(t, []model.Priority{model.PriorityHigh, model.PriorityNone}, q.Filter.Priorities)
+	assert.True(t, q.Filter.DueBefore.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, q.Filter.StartAfter.Equal(time.Date(2025, 2, 28, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, model.SortByDueAt, q.Sort)
+}
+
+func TestParseTaskQuery_InvalidPriority(t *testing.T) {
+	values, _ := url.ParseQuery("priority=critical")
+	_, fields := parseTaskQuery(values)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "priority", fields[0].Field)
+}
+
+func TestParseTaskQuery_Labels(t *testing.T) {
+	values, _ := url.ParseQuery("label=backend&label=team:web&label_match=any")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	assert.Equal(t, []string{"backend", "team:web"}, q.Filter.Labels)
+	assert.Equal(t, model.LabelMatchAny, q.Filter.LabelMatch)
+
+	values, _ = url.ParseQuery("label=ok&label=bad%3Bname&label_match=some")
+	_, fields = parseTaskQuery(values)
+	require.Len(t, fields, 1, "label names are validated only once the query parses")
+	assert.Equal(t, "label_match", fields[0].Field)
+
+	values, _ = url.ParseQuery("label=bad%3Bname")
+	_, fields = parseTaskQuery(values)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "label", fields[0].Field)
+}
+
+func TestParseLocation(t *testing.T) {
+	loc, ferr := parseLocation(url.Values{})
+	assert.Nil(t, ferr)
+	assert.Equal(t, time.UTC, loc)
+
+	loc, ferr = parseLocation(url.Values{"tz": {"Europe/Berlin"}})
+	assert.Nil(t, ferr)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	for _, name := range []string{"Nowhere/Special", "Local"} {
+		_, ferr = parseLocation(url.Values{"tz": {name}})
+		require.NotNil(t, ferr, name)
+		assert.Equal(t, "tz", ferr.Field)
+	}
+}
+
+func TestParseTaskQuery_ParentID(t *testing.T) {
+	values, _ := url.ParseQuery("parent_id=task-1")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	require.NotNil(t, q.Filter.ParentID)
+	assert.Equal(t, "task-1", *q.Filter.ParentID)
+
+	values, _ = url.ParseQuery("parent_id=")
+	q, _ = parseTaskQuery(values)
+	require.NotNil(t, q.Filter.ParentID, "an empty parent_id selects top-level tasks")
+	assert.Empty(t, *q.Filter.ParentID)
+
+	q, _ = parseTaskQuery(url.Values{})
+	assert.Nil(t, q.Filter.ParentID)
+}
+
+func TestParseTaskQuery_Assignee(t *testing.T) {
+	values, _ := url.ParseQuery("assignee=alice")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	require.NotNil(t, q.Filter.Assignee)
+	assert.Equal(t, "alice", *q.Filter.Assignee)
+
+	values, _ = url.ParseQuery("assignee=")
+	q, _ = parseTaskQuery(values)
+	require.NotNil(t, q.Filter.Assignee, "an empty assignee selects unassigned tasks")
+	assert.Empty(t, *q.Filter.Assignee)
+
+	values, _ = url.ParseQuery("assignee=a%20b")
+	_, fields = parseTaskQuery(values)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "assignee", fields[0].Field)
+}
+
+func TestParseTaskQuery_Project(t *testing.T) {
+	values, _ := url.ParseQuery("project=web")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	require.NotNil(t, q.Filter.ProjectID)
+	assert.Equal(t, "web", *q.Filter.ProjectID)
+
+	values, _ = url.ParseQuery("project=")
+	q, _ = parseTaskQuery(values)
+	require.NotNil(t, q.Filter.ProjectID, "an empty project selects tasks outside any project")
+	assert.Empty(t, *q.Filter.ProjectID)
+
+	values, _ = url.ParseQuery("project=a%20b")
+	_, fields = parseTaskQuery(values)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "project", fields[0].Field)
+}
+
+func TestParseTaskQuery_Status(t *testing.T) {
+	values, _ := url.ParseQuery("status=in_progress&status=in_review&status=")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	assert.Equal(t, []string{"in_progress", "in_review"}, q.Filter.Statuses)
+}
+
+func TestParseTaskQuery_Trash(t *testing.T) {
+	values, _ := url.ParseQuery("trashed=true&deleted_after=2025-03-01T00:00:00Z&deleted_before=2025-03-10T12:00:00Z")
+	q, fields := parseTaskQuery(values)
+	require.Empty(t, fields)
+	assert.True(t, q.Filter.Trashed)
+	assert.True(t, q.Filter.DeletedAfter.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, q.Filter.DeletedBefore.Equal(time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)))
+
+	q, _ = parseTaskQuery(url.Values{})
+	assert.False(t, q.Filter.Trashed, "live tasks are listed by default")
+
+	values, _ = url.ParseQuery("trashed=maybe")
+	_, fields = parseTaskQuery(values)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "trashed", fields[0].Field)
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"taskmanager/internal/identity"
+)
+
+// TenantHeader names the tenant a request is made in. Like ActorHeader it is
+// trusted as is, so it must be set by an authenticating gateway that derives it
+// from the caller's credentials, e.g. an organisation claim of their token.
+const TenantHeader = "X-Tenant"
+
+// Tenant is middleware that stores the TenantHeader of every request in its
+// context, where the repositories pick it up to select the tenant's data.
+// Missing or malformed values leave the tenant unknown, which storage refuses
+// on a multi-tenant server.
+func Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenant := r.Header.Get(TenantHeader); identity.ValidTenant(tenant) {
+			r = r.WithContext(identity.WithTenant(r.Context(), tenant))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/identity"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTenant(t *testing.T) {
+	var seen string
+	h := Tenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = identity.Tenant(r.Context())
+	}))
+
+	for incoming, want := range map[string]string{"acme": "acme", "": "", "Acme": "", "../acme": ""} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(TenantHeader, incoming)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, want, seen, "header %q", incoming)
+	}
+}
+
+func TestTenant_Isolation(t *testing.T) {
+	repo := repository.NewTenantRepository(map[string]repository.TaskRepository{
+		"acme":   repository.NewInMemoryTaskRepository(zap.NewNop()),
+		"globex": repository.NewInMemoryTaskRepository(zap.NewNop()),
+	})
+	mux := http.NewServeMux()
+	NewTaskHandler(service.NewTaskService(repo, zap.NewNop(), service.WithTaskQuotas(0, map[string]int{"globex": 1})), zap.NewNop()).RegisterRoutes(mux)
+	h := Tenant(mux)
+	send := func(tenant, method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set(TenantHeader, tenant)
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, send("acme", http.MethodPost, "/tasks", `{"id":"t1","title":"Acme plan"}`).Code)
+	assert.Equal(t, http.StatusNotFound, send("globex", http.MethodGet, "/tasks/t1", "").Code)
+	assert.Equal(t, "[]\n", send("globex", http.MethodGet, "/tasks", "").Body.String())
+	require.Equal(t, http.StatusCreated, send("globex", http.MethodPost, "/tasks", `{"id":"t1","title":"Globex plan"}`).Code)
+	w := send("globex", http.MethodPost, "/tasks", `{"title":"Over quota"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "task quota")
+
+	assert.Equal(t, http.StatusForbidden, send("", http.MethodGet, "/tasks", "").Code)
+	assert.Equal(t, http.StatusForbidden, send("initech", http.MethodGet, "/tasks/t1", "").Code)
+}
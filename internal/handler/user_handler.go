@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"taskmanager/internal/model"
+	"taskmanager/internal/service"
+
+	"go.uber.org/zap"
+)
+
+// UserHandler handles HTTP requests for /users endpoints.
+type UserHandler struct {
+	service service.UserService
+	logger  *zap.Logger
+}
+
+// NewUserHandler creates a new UserHandler.
+func NewUserHandler(service service.UserService, logger *zap.Logger) *UserHandler {
+	return &UserHandler{service: service, logger: logger}
+}
+
+// RegisterRoutes registers the /users routes to the given mux.
+func (h *UserHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/users", h.handleUsers)
+	mux.HandleFunc("/users/", h.handleUserByID)
+}
+
+// handleUsers handles POST (create) and GET (list) on /users.
+func (h *UserHandler) handleUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req model.User
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		created, err := h.service.CreateUser(r.Context(), &req)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	case http.MethodGet:
+		users, err := h.service.ListUsers(r.Context())
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, users)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// handleUserByID handles GET, PATCH and DELETE on /users/{id} and dispatches
+// /users/{id}/tasks.
+func (h *UserHandler) handleUserByID(w http.ResponseWriter, r *http.Request) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/users/"), "/")
+	if id == "" {
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch rest {
+	case "":
+	case "tasks":
+		h.listTasks(w, r, id)
+		return
+	default:
+		writeErrorFields(h.logger, w, r, http.StatusNotFound, "not found", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		user, err := h.service.GetUser(r.Context(), id)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, user)
+	case http.MethodPatch:
+		var req model.UserPatch
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid JSON", nil)
+			return
+		}
+		user, err := h.service.UpdateUser(r.Context(), id, &req)
+		if err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, user)
+	case http.MethodDelete:
+		if err := h.service.DeleteUser(r.Context(), id); err != nil {
+			writeServiceError(h.logger, w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PATCH, DELETE")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+// listTasks serves GET /users/{id}/tasks, the tasks assigned to the user. It
+// takes the query parameters of GET /tasks.
+func (h *UserHandler) listTasks(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeErrorFields(h.logger, w, r, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	q, fields := parseTaskQuery(r.URL.Query())
+	if len(fields) > 0 {
+		writeErrorFields(h.logger, w, r, http.StatusBadRequest, "invalid query parameters", fields)
+		return
+	}
+	page, err := h.service.ListTasks(r.Context(), id, q)
+	if err != nil {
+		writeServiceError(h.logger, w, r, err)
+		return
+	}
+	if page.Next != nil {
+		w.Header().Set("Link", nextPageLink(r, page.Next.Encode()))
+	}
+	writeJSON(w, http.StatusOK, page.Tasks)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupUserHandler() http.Handler {
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	tasks := service.NewTaskService(repo, zap.NewNop())
+	mux := http.NewServeMux()
+	NewTaskHandler(tasks, zap.NewNop()).RegisterRoutes(mux)
+	NewUserHandler(service.NewUserService(repo, tasks, zap.NewNop()), zap.NewNop()).RegisterRoutes(mux)
+	return Actor(mux)
+}
+
+func serveAs(h http.Handler, actor, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("X-Actor", actor)
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestUserHandler_CRUD(t *testing.T) {
+	h := setupUserHandler()
+
+	w := serveAs(h, "", http.MethodPost, "/users", `{"id":"alice","name":"Alice"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusConflict, serveAs(h, "", http.MethodPost, "/users", `{"id":"alice","name":"Alice"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serveAs(h, "", http.MethodPost, "/users", `{"id":"a b"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(h, "", http.MethodPost, "/users", `{`).Code)
+
+	w = serveAs(h, "", http.MethodPatch, "/users/alice", `{"email":"alice@example.com"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var user model.User
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&user))
+	assert.Equal(t, "Alice", user.Name)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	w = serveAs(h, "", http.MethodGet, "/users", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var users []model.User
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&users))
+	assert.Len(t, users, 1)
+
+	w = serveAs(h, "", http.MethodPut, "/users/alice", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PATCH, DELETE", w.Header().Get("Allow"))
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/users/alice/other", "").Code)
+
+	assert.Equal(t, http.StatusNoContent, serveAs(h, "", http.MethodDelete, "/users/alice", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/users/alice", "").Code)
+}
+
+func TestUserHandler_AssignedTasks(t *testing.T) {
+	h := setupUserHandler()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		require.Equal(t, http.StatusCreated, serveAs(h, "", http.MethodPost, "/users", `{"id":"`+id+`","name":"`+id+`"}`).Code)
+	}
+
+	w := serveAs(h, "alice", http.MethodPost, "/tasks", `{"title":"Write docs","assignee":"bob"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var task model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&task))
+	assert.Equal(t, "alice", task.CreatedBy)
+	require.Equal(t, http.StatusCreated, serveAs(h, "alice", http.MethodPost, "/tasks", `{"title":"Unassigned"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serveAs(h, "alice", http.MethodPost, "/tasks", `{"title":"T","assignee":"dave"}`).Code)
+
+	w = serveAs(h, "", http.MethodGet, "/users/bob/tasks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var tasks []model.Task
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, task.ID, tasks[0].ID)
+
+	w = serveAs(h, "", http.MethodGet, "/tasks?assignee=", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Unassigned", tasks[0].Title)
+
+	assert.Equal(t, http.StatusNotFound, serveAs(h, "", http.MethodGet, "/users/dave/tasks", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serveAs(h, "", http.MethodGet, "/users/bob/tasks?limit=0", "").Code)
+
+	target := "/tasks/" + task.ID
+	w = serveAs(h, "carol", http.MethodPatch, target, `{"assignee":"carol"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusOK, serveAs(h, "bob", http.MethodPatch, target, `{"assignee":"carol"}`).Code)
+	assert.Equal(t, http.StatusConflict, serveAs(h, "", http.MethodDelete, "/users/carol", "").Code)
+}
@@ -0,0 +1,87 @@
+// Package identity carries who is making a request through its context, so
+// that the service layer can attribute changes without knowing about HTTP.
+package identity
+
+import (
+	"context"
+	"slices"
+)
+
+type (
+	actorKey     struct{}
+	tenantKey    struct{}
+	principalKey struct{}
+)
+
+// WithActor returns a copy of ctx that carries actor, the name of the user or
+// system making the request.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored by WithActor, or "" if there is none.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithTenant returns a copy of ctx that carries tenant, the organisation the
+// request is made in. Its data is kept apart from every other tenant's.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant stored by WithTenant, or "" if there is none.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Principal is who an authenticated request is made by: the holder of an API
+// key or the subject of a bearer token.
+type Principal struct {
+	// Subject names the principal; it is the actor of its requests.
+	Subject string
+	// Tenant is the tenant the principal is confined to, "" if it may act in
+	// any tenant.
+	Tenant string
+	// Scopes lists what the principal may do, see the model.Scope constants.
+	Scopes []string
+}
+
+// HasScope reports whether the principal may do what scope allows.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// WithPrincipal returns a copy of ctx that carries p, with p's subject as the
+// actor and p's tenant, if it has one, as the tenant.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	ctx = WithActor(context.WithValue(ctx, principalKey{}, p), p.Subject)
+	if p.Tenant != "" {
+		ctx = WithTenant(ctx, p.Tenant)
+	}
+	return ctx
+}
+
+// PrincipalOf returns the principal stored by WithPrincipal, or nil if the
+// request is not authenticated.
+func PrincipalOf(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// ValidTenant reports whether id is a well-formed tenant ID: 1-63 lowercase
+// letters, digits, - and _, starting with a letter or digit. Tenant IDs name
+// files and directories, so nothing else is allowed.
+func ValidTenant(id string) bool {
+	if id == "" || len(id) > 63 || id[0] == '-' || id[0] == '_' {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
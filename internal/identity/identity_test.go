@@ -0,0 +1,48 @@
+package identity
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActor(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, Actor(ctx))
+	assert.Equal(t, "alice", Actor(WithActor(ctx, "alice")))
+}
+
+func TestTenant(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, Tenant(ctx))
+	ctx = WithTenant(WithActor(ctx, "alice"), "acme")
+	assert.Equal(t, "acme", Tenant(ctx))
+	assert.Equal(t, "alice", Actor(ctx))
+}
+
+func TestPrincipal(t *testing.T) {
+	ctx := WithTenant(context.Background(), "globex")
+	assert.Nil(t, PrincipalOf(ctx))
+
+	p := &Principal{Subject: "alice", Scopes: []string{"tasks:read"}}
+	withP := WithPrincipal(ctx, p)
+	assert.Same(t, p, PrincipalOf(withP))
+	assert.Equal(t, "alice", Actor(withP))
+	assert.Equal(t, "globex", Tenant(withP), "principals without a tenant keep the request's")
+	assert.True(t, p.HasScope("tasks:read"))
+	assert.False(t, p.HasScope("tasks:write"))
+
+	bound := WithPrincipal(ctx, &Principal{Subject: "ci", Tenant: "acme"})
+	assert.Equal(t, "acme", Tenant(bound))
+}
+
+func TestValidTenant(t *testing.T) {
+	for _, id := range []string{"acme", "acme-eu", "org_2", "7"} {
+		assert.True(t, ValidTenant(id), "tenant %q", id)
+	}
+	for _, id := range []string{"", "Acme", "-acme", "_acme", "a.b", "../etc", "a b", strings.Repeat("x", 64)} {
+		assert.False(t, ValidTenant(id), "tenant %q", id)
+	}
+}
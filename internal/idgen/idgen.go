@@ -6,3 +6,18 @@ import "github.com/google/uuid"
 func GenerateTaskID() string {
 	return uuid.NewString()
 }
+
+// GenerateCommentID returns a new UUID string for comment IDs.
+func GenerateCommentID() string {
+	return uuid.NewString()
+}
+
+// GenerateAttachmentID returns a new UUID string for attachment IDs.
+func GenerateAttachmentID() string {
+	return uuid.NewString()
+}
+
+// GenerateRequestID returns a new unique ID for correlating a request.
+func GenerateRequestID() string {
+	return uuid.NewString()
+}
@@ -15,3 +15,15 @@ func TestGenerateTaskID_UniqueAndValid(t *testing.T) {
 		assert.Equal(t, 36, len(id), "id length is not 36: %s", id)
 	}
 }
+
+func TestGenerateRequestID_Unique(t *testing.T) {
+	assert.NotEqual(t, GenerateRequestID(), GenerateRequestID())
+}
+
+func TestGenerateCommentID_Unique(t *testing.T) {
+	assert.NotEqual(t, GenerateCommentID(), GenerateCommentID())
+}
+
+func TestGenerateAttachmentID_Unique(t *testing.T) {
+	assert.NotEqual(t, GenerateAttachmentID(), GenerateAttachmentID())
+}
@@ -0,0 +1,179 @@
+// Package jwtauth verifies JSON Web Tokens issued by an OIDC provider: RS256
+// and ES256 signatures checked against the provider's JSON Web Key Set, and
+// the issuer, audience and validity period of the claims.
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Signature algorithms accepted in tokens.
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// minRSABits rejects RSA keys too short to be trusted.
+const minRSABits = 2048
+
+// PublicKey is a signing key of an issuer.
+type PublicKey struct {
+	// ID is the key ID tokens name in their kid header, possibly empty.
+	ID string
+	// Algorithm is the algorithm the key is for, empty if the JWK leaves it
+	// open; it is then implied by the key type.
+	Algorithm string
+	// Key is an *rsa.PublicKey or an *ecdsa.PublicKey on P-256.
+	Key crypto.PublicKey
+}
+
+// KeySet provides the signing keys of an issuer.
+type KeySet interface {
+	// Key returns the key with ID kid, or with an empty kid the only key of
+	// the set. It fails with an error matching ErrInvalidToken if there is
+	// no such key.
+	Key(ctx context.Context, kid string) (*PublicKey, error)
+}
+
+// StaticKeySet is a KeySet that never changes, typically loaded from a file.
+type StaticKeySet struct {
+	keys []*PublicKey
+}
+
+// LoadKeySetFile reads a JWKS document from path.
+func LoadKeySetFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+	keys, err := ParseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &StaticKeySet{keys: keys}, nil
+}
+
+// NewStaticKeySet returns a KeySet of keys.
+func NewStaticKeySet(keys ...*PublicKey) *StaticKeySet {
+	return &StaticKeySet{keys: keys}
+}
+
+// Key returns the key with ID kid.
+func (s *StaticKeySet) Key(ctx context.Context, kid string) (*PublicKey, error) {
+	return findKey(s.keys, kid)
+}
+
+// errNoKey is matched by the errors of findKey.
+var errNoKey = fmt.Errorf("%w: unknown signing key", ErrInvalidToken)
+
+// findKey returns the key of keys with ID kid, or with an empty kid the only key.
+func findKey(keys []*PublicKey, kid string) (*PublicKey, error) {
+	if kid == "" {
+		if len(keys) == 1 {
+			return keys[0], nil
+		}
+		return nil, fmt.Errorf("%w: token names no key and the key set has %d", errNoKey, len(keys))
+	}
+	for _, key := range keys {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", errNoKey, kid)
+}
+
+// jwk is a JSON Web Key as defined by RFC 7517 and RFC 7518, with the members
+// of RSA and elliptic curve public keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseKeySet decodes a JWKS document. Keys not meant for signatures and keys
+// of other types or algorithms are skipped, as a provider may publish them
+// alongside its signing keys; a usable key that is malformed is an error.
+func ParseKeySet(data []byte) ([]*PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+	var keys []*PublicKey
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch {
+		case k.Kty == "RSA" && (k.Alg == "" || k.Alg == RS256):
+			key, err = parseRSAKey(k)
+		case k.Kty == "EC" && k.Crv == "P-256" && (k.Alg == "" || k.Alg == ES256):
+			key, err = parseECKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys = append(keys, &PublicKey{ID: k.Kid, Algorithm: k.Alg, Key: key})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no RS256 or ES256 signing keys")
+	}
+	return keys, nil
+}
+
+func parseRSAKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if key.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key of %d bits is shorter than %d", key.N.BitLen(), minRSABits)
+	}
+	if key.E < 3 || key.E%2 == 0 {
+		return nil, errors.New("invalid exponent")
+	}
+	return key, nil
+}
+
+func parseECKey(k jwk) (*ecdsa.PublicKey, error) {
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 coordinates")
+	}
+	// crypto/ecdh checks that the point is on the curve.
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid P-256 point: %w", err)
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
@@ -0,0 +1,64 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"taskmanager/internal/jwtauth/jwtauthtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySet(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	keys, err := ParseKeySet(iss.JWKS())
+	require.NoError(t, err)
+	require.Len(t, keys, 2, "the encryption key is skipped")
+	assert.Equal(t, "RS256-1", keys[0].ID)
+	assert.IsType(t, &rsa.PublicKey{}, keys[0].Key)
+	assert.Equal(t, ES256, keys[1].Algorithm)
+	assert.IsType(t, &ecdsa.PublicKey{}, keys[1].Key)
+
+	for doc, want := range map[string]string{
+		`{`:           "decode JWKS",
+		`{"keys":[]}`: "no RS256 or ES256 signing keys",
+		`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AA"}]}`:                                             "no RS256 or ES256 signing keys",
+		`{"keys":[{"kty":"RSA","kid":"short","n":"AQAB","e":"AQAB"}]}`:                                  "shorter than 2048",
+		`{"keys":[{"kty":"EC","kid":"bad","crv":"P-256","x":"AA","y":"AA"}]}`:                           "invalid P-256 coordinates",
+		`{"keys":[{"kty":"EC","kid":"off","crv":"P-256","x":"` + zeros32 + `","y":"` + zeros32 + `"}]}`: "invalid P-256 point",
+	} {
+		_, err := ParseKeySet([]byte(doc))
+		assert.ErrorContains(t, err, want, doc)
+	}
+}
+
+// zeros32 is 32 zero bytes in base64url, a coordinate of no P-256 point.
+const zeros32 = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+
+func TestStaticKeySet(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, iss.JWKS(), 0o600))
+	set, err := LoadKeySetFile(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key, err := set.Key(ctx, "ES256-1")
+	require.NoError(t, err)
+	assert.Equal(t, "ES256-1", key.ID)
+	_, err = set.Key(ctx, "RS256-9")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = set.Key(ctx, "")
+	assert.ErrorIs(t, err, ErrInvalidToken, "without a kid the set must have a single key")
+	key, err = NewStaticKeySet(key).Key(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ES256-1", key.ID)
+
+	_, err = LoadKeySetFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "read JWKS")
+}
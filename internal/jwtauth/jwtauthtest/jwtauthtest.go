@@ -0,0 +1,207 @@
+// Package jwtauthtest is a stand-in OIDC provider for tests: it signs tokens
+// with an RSA and an ECDSA key and publishes them on a local JWKS endpoint.
+package jwtauthtest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Issuer signs tokens and serves the JWKS with its current keys at JWKSURL.
+type Issuer struct {
+	t      testing.TB
+	server *httptest.Server
+
+	mu         sync.Mutex
+	generation int
+	rsaKey     *rsa.PrivateKey
+	ecKey      *ecdsa.PrivateKey
+	fetches    int
+	down       bool
+	stalled    chan struct{}
+}
+
+// NewIssuer starts an Issuer that is stopped when the test ends.
+func NewIssuer(t testing.TB) *Issuer {
+	t.Helper()
+	iss := &Issuer{t: t}
+	iss.Rotate()
+	iss.server = httptest.NewServer(http.HandlerFunc(iss.serveJWKS))
+	t.Cleanup(iss.server.Close)
+	return iss
+}
+
+// URL is the issuer identifier, the iss claim of its tokens.
+func (iss *Issuer) URL() string {
+	return iss.server.URL
+}
+
+// JWKSURL is where the issuer publishes its keys.
+func (iss *Issuer) JWKSURL() string {
+	return iss.server.URL + "/.well-known/jwks.json"
+}
+
+// Client returns the HTTP client to fetch the JWKS with.
+func (iss *Issuer) Client() *http.Client {
+	return iss.server.Client()
+}
+
+// Rotate replaces both signing keys with new ones under new key IDs; the old
+// keys are no longer published.
+func (iss *Issuer) Rotate() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		iss.t.Fatalf("generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		iss.t.Fatalf("generate ECDSA key: %v", err)
+	}
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.generation++
+	iss.rsaKey, iss.ecKey = rsaKey, ecKey
+}
+
+// SetDown makes the JWKS endpoint fail, or work again.
+func (iss *Issuer) SetDown(down bool) {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.down = down
+}
+
+// Stall makes requests for the JWKS hang until the returned function is
+// called, like those to an overloaded provider.
+func (iss *Issuer) Stall() (release func()) {
+	stalled := make(chan struct{})
+	iss.mu.Lock()
+	iss.stalled = stalled
+	iss.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			iss.mu.Lock()
+			iss.stalled = nil
+			iss.mu.Unlock()
+			close(stalled)
+		})
+	}
+}
+
+// Fetches counts the requests for the JWKS so far.
+func (iss *Issuer) Fetches() int {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	return iss.fetches
+}
+
+// JWKS returns the JWKS document with the current keys.
+func (iss *Issuer) JWKS() []byte {
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	return iss.jwks()
+}
+
+// Sign returns a token with claims signed with the current key for alg,
+// "RS256" or "ES256".
+func (iss *Issuer) Sign(alg string, claims map[string]any) string {
+	iss.mu.Lock()
+	kid := iss.kid(alg)
+	iss.mu.Unlock()
+	return iss.SignHeader(map[string]any{"alg": alg, "typ": "JWT", "kid": kid}, claims)
+}
+
+// SignHeader returns a token with header and claims, signed with the current
+// key for the alg of header. Other algorithms get an empty signature.
+func (iss *Issuer) SignHeader(header, claims map[string]any) string {
+	signed := segment(iss.t, header) + "." + segment(iss.t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	var sig []byte
+	switch header["alg"] {
+	case "RS256":
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, iss.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			iss.t.Fatalf("sign RS256: %v", err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, iss.ecKey, digest[:])
+		if err != nil {
+			iss.t.Fatalf("sign ES256: %v", err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (iss *Issuer) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	iss.mu.Lock()
+	iss.fetches++
+	stalled := iss.stalled
+	iss.mu.Unlock()
+	if stalled != nil {
+		<-stalled
+	}
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	if iss.down {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(iss.jwks())
+}
+
+// kid returns the ID of the current key for alg. The caller holds the lock.
+func (iss *Issuer) kid(alg string) string {
+	return fmt.Sprintf("%s-%d", alg, iss.generation)
+}
+
+// jwks encodes the current keys. The caller holds the lock.
+func (iss *Issuer) jwks() []byte {
+	b64 := base64.RawURLEncoding.EncodeToString
+	e := big32(iss.rsaKey.E)
+	x, y := make([]byte, 32), make([]byte, 32)
+	iss.ecKey.X.FillBytes(x)
+	iss.ecKey.Y.FillBytes(y)
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "use": "sig", "alg": "RS256", "kid": iss.kid("RS256"), "n": b64(iss.rsaKey.N.Bytes()), "e": b64(e)},
+		{"kty": "EC", "use": "sig", "alg": "ES256", "kid": iss.kid("ES256"), "crv": "P-256", "x": b64(x), "y": b64(y)},
+		{"kty": "oct", "use": "enc", "kid": "ignored", "k": "c2VjcmV0"},
+	}})
+	if err != nil {
+		iss.t.Fatalf("encode JWKS: %v", err)
+	}
+	return data
+}
+
+// big32 encodes an RSA exponent in as few big-endian bytes as possible.
+func big32(n int) []byte {
+	b := []byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+func segment(t testing.TB, v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encode token segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
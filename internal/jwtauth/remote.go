@@ -0,0 +1,132 @@
+package jwtauth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxJWKSSize bounds the JWKS documents read from a provider.
+const maxJWKSSize = 1 << 20
+
+// minRefreshInterval is the least time between two fetches of a RemoteKeySet,
+// so tokens naming unknown keys cannot make it hammer the provider.
+const minRefreshInterval = 30 * time.Second
+
+// RemoteKeySet is a KeySet fetched from a JWKS URL. Keys are cached for the
+// refresh interval and fetched again after it. A token naming a key the cache
+// does not have triggers a fetch too, which picks up keys the provider has
+// rotated in. When a fetch fails the cached keys stay in use.
+//
+// One fetch runs at a time and without the lock, so a slow provider only
+// holds up requests for keys the cache does not have.
+type RemoteKeySet struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu        sync.Mutex
+	keys      []*PublicKey
+	fetched   time.Time     // last successful fetch
+	attempted time.Time     // last fetch, successful or not
+	err       error         // error of the last fetch
+	updating  chan struct{} // closed when the running fetch ends, nil if none runs
+}
+
+// NewRemoteKeySet creates a RemoteKeySet for the JWKS at url, fetched with
+// client and cached for refresh. Nothing is fetched until a key is needed.
+func NewRemoteKeySet(url string, client *http.Client, refresh time.Duration, logger *zap.Logger) *RemoteKeySet {
+	return &RemoteKeySet{url: url, client: client, refresh: refresh, logger: logger, now: time.Now}
+}
+
+// Key returns the key with ID kid. A cached key is returned at once, even
+// while newer keys are fetched; for any other key Key waits for a fetch, if
+// one may run. It fails with an error not matching ErrInvalidToken if the keys
+// have never been fetched successfully or ctx ends while waiting.
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (*PublicKey, error) {
+	s.mu.Lock()
+	now := s.now()
+	if key, err := findKey(s.keys, kid); err == nil {
+		if now.Sub(s.fetched) >= s.refresh {
+			s.update(ctx, now)
+		}
+		s.mu.Unlock()
+		return key, nil
+	}
+	done := s.update(ctx, now)
+	s.mu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", s.err)
+	}
+	return findKey(s.keys, kid)
+}
+
+// update starts a fetch of the keys unless one is running or the last one
+// was too recent. It returns a channel closed when the running fetch ends, nil
+// if none runs. The caller holds the lock.
+func (s *RemoteKeySet) update(ctx context.Context, now time.Time) <-chan struct{} {
+	if s.updating != nil {
+		return s.updating
+	}
+	if !s.attempted.IsZero() && now.Sub(s.attempted) < minRefreshInterval {
+		return nil
+	}
+	s.attempted = now
+	done := make(chan struct{})
+	s.updating = done
+	// The fetch serves every waiting request, so it outlives the one that
+	// started it.
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer close(done)
+		keys, err := s.fetch(ctx)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.updating = nil
+		if err != nil {
+			s.err = err
+			s.logger.Warn("failed to fetch JWKS", zap.String("url", s.url), zap.Bool("cached", s.keys != nil), zap.Error(err))
+			return
+		}
+		s.keys, s.fetched, s.err = keys, now, nil
+		s.logger.Info("JWKS fetched", zap.String("url", s.url), zap.Int("keys", len(keys)))
+	}()
+	return done
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) ([]*PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint answered %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
@@ -0,0 +1,123 @@
+package jwtauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/jwtauth/jwtauthtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newRemoteFixture returns a key set of iss refreshed hourly and a function
+// advancing its clock.
+func newRemoteFixture(iss *jwtauthtest.Issuer) (*RemoteKeySet, func(time.Duration)) {
+	set := NewRemoteKeySet(iss.JWKSURL(), iss.Client(), time.Hour, zap.NewNop())
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	set.now = func() time.Time { return now }
+	return set, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRemoteKeySet_Caching(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	set, advance := newRemoteFixture(iss)
+	ctx := context.Background()
+	assert.Zero(t, iss.Fetches(), "nothing is fetched up front")
+
+	for range 3 {
+		_, err := set.Key(ctx, "RS256-1")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, iss.Fetches())
+
+	advance(time.Hour)
+	_, err := set.Key(ctx, "ES256-1")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return iss.Fetches() == 2 }, time.Second, time.Millisecond,
+		"keys are fetched again after the refresh interval")
+}
+
+func TestRemoteKeySet_SlowProvider(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	set, advance := newRemoteFixture(iss)
+	ctx := context.Background()
+	_, err := set.Key(ctx, "RS256-1")
+	require.NoError(t, err)
+
+	release := iss.Stall()
+	defer release()
+	advance(time.Hour)
+	// The refresh hangs, but cached keys are served meanwhile.
+	for _, kid := range []string{"RS256-1", "ES256-1", "RS256-1"} {
+		key, err := set.Key(ctx, kid)
+		require.NoError(t, err)
+		assert.Equal(t, kid, key.ID)
+	}
+	assert.Eventually(t, func() bool { return iss.Fetches() == 2 }, time.Second, time.Millisecond)
+
+	// An unknown key waits for the running refresh, as long as its request lasts.
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = set.Key(short, "RS256-2")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	iss.Rotate()
+	waiting := make(chan error)
+	go func() {
+		_, err := set.Key(ctx, "RS256-2")
+		waiting <- err
+	}()
+	release()
+	require.NoError(t, <-waiting, "the refresh running while the key rotated sees it")
+	assert.Equal(t, 2, iss.Fetches(), "waiters share the running refresh")
+}
+
+func TestRemoteKeySet_Rotation(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	set, advance := newRemoteFixture(iss)
+	ctx := context.Background()
+	_, err := set.Key(ctx, "RS256-1")
+	require.NoError(t, err)
+
+	iss.Rotate()
+	advance(time.Minute)
+	key, err := set.Key(ctx, "RS256-2")
+	require.NoError(t, err, "an unknown key triggers a fetch")
+	assert.Equal(t, "RS256-2", key.ID)
+	assert.Equal(t, 2, iss.Fetches())
+
+	_, err = set.Key(ctx, "RS256-1")
+	assert.ErrorIs(t, err, ErrInvalidToken, "rotated out")
+	_, err = set.Key(ctx, "RS256-7")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, 2, iss.Fetches(), "unknown keys cannot force fetches in quick succession")
+}
+
+func TestRemoteKeySet_Failures(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	set, advance := newRemoteFixture(iss)
+	ctx := context.Background()
+
+	iss.SetDown(true)
+	_, err := set.Key(ctx, "RS256-1")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken, "an unreachable provider is not the token's fault")
+	assert.ErrorContains(t, err, "503")
+
+	iss.SetDown(false)
+	advance(minRefreshInterval)
+	_, err = set.Key(ctx, "RS256-1")
+	require.NoError(t, err)
+
+	// Cached keys outlive an outage.
+	iss.SetDown(true)
+	advance(2 * time.Hour)
+	_, err = set.Key(ctx, "RS256-1")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return iss.Fetches() == 3 }, time.Second, time.Millisecond)
+	_, err = set.Key(ctx, "RS256-1")
+	require.NoError(t, err)
+}
@@ -0,0 +1,196 @@
+package jwtauth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+)
+
+// ErrInvalidToken is matched by errors returned for tokens that are malformed,
+// badly signed, expired or not meant for this service.
+var ErrInvalidToken = apperror.Unauthenticated("invalid bearer token")
+
+// Options configures a Verifier.
+type Options struct {
+	// Issuer must equal the iss claim of every token.
+	Issuer string
+	// Audience, if set, must be among the aud claim of every token.
+	Audience string
+	// Leeway allows for clock skew between the issuer and this server when
+	// checking exp and nbf.
+	Leeway time.Duration
+}
+
+// Verifier checks tokens of one issuer.
+type Verifier struct {
+	keys KeySet
+	opts Options
+	now  func() time.Time
+}
+
+// NewVerifier creates a Verifier checking signatures against keys.
+func NewVerifier(keys KeySet, opts Options) *Verifier {
+	return &Verifier{keys: keys, opts: opts, now: time.Now}
+}
+
+// Claims are the claims of a verified token, decoded from JSON with numbers
+// kept as json.Number.
+type Claims map[string]any
+
+// String returns claim name if it is a string, "" otherwise.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns claim name as a list: a string claim split at spaces, like
+// the OAuth 2.0 scope claim, or the strings of an array claim.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// Time returns claim name as a time if it is a NumericDate, seconds since the
+// Unix epoch. Fractions of a second are dropped.
+func (c Claims) Time(name string) (time.Time, bool) {
+	n, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	secs, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(secs), 0).UTC(), true
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+// Verify checks token, a JWS in compact serialization, and returns its claims.
+// The signature must be RS256 or ES256 by a key of the key set, the issuer and
+// audience must match, and the token must have an expiry that has not passed
+// and a not-before time, if any, that has. Errors about the token match
+// ErrInvalidToken; others mean the keys could not be loaded.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a signed JWT", ErrInvalidToken)
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if h.Alg != RS256 && h.Alg != ES256 {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Alg)
+	}
+	if len(h.Crit) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical headers %v", ErrInvalidToken, h.Crit)
+	}
+	key, err := v.keys.Key(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+	if err := verifySignature(h.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// checkClaims checks the registered claims of a token with a valid signature.
+func (v *Verifier) checkClaims(claims Claims) error {
+	if iss := claims.String("iss"); iss != v.opts.Issuer {
+		return fmt.Errorf("issuer %q is not %q", iss, v.opts.Issuer)
+	}
+	if v.opts.Audience != "" && !slices.Contains(claims.Strings("aud"), v.opts.Audience) {
+		return fmt.Errorf("token is not meant for audience %q", v.opts.Audience)
+	}
+	now := v.now()
+	exp, ok := claims.Time("exp")
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if !now.Before(exp.Add(v.opts.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(v.opts.Leeway).Before(nbf) {
+		return errors.New("token not valid yet")
+	}
+	return nil
+}
+
+// verifySignature checks sig over signed with key for alg.
+func verifySignature(alg string, key *PublicKey, signed string, sig []byte) error {
+	if key.Algorithm != "" && key.Algorithm != alg {
+		return fmt.Errorf("key %q is for %s, not %s", key.ID, key.Algorithm, alg)
+	}
+	digest := sha256.Sum256([]byte(signed))
+	switch pub := key.Key.(type) {
+	case *rsa.PublicKey:
+		if alg != RS256 || rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return errors.New("bad signature")
+		}
+	case *ecdsa.PublicKey:
+		// JWS encodes ES256 signatures as r and s, 32 bytes each.
+		if alg != ES256 || len(sig) != 64 {
+			return errors.New("bad signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errors.New("bad signature")
+		}
+	default:
+		return fmt.Errorf("key %q has unsupported type %T", key.ID, key.Key)
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment into v.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,130 @@
+package jwtauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/jwtauth/jwtauthtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var verifierNow = time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+// newVerifierFixture returns a verifier of iss's tokens for the audience
+// "taskmanager", at verifierNow.
+func newVerifierFixture(iss *jwtauthtest.Issuer) *Verifier {
+	keys := NewRemoteKeySet(iss.JWKSURL(), iss.Client(), time.Hour, zap.NewNop())
+	v := NewVerifier(keys, Options{Issuer: iss.URL(), Audience: "taskmanager", Leeway: time.Minute})
+	v.now = func() time.Time { return verifierNow }
+	return v
+}
+
+// validClaims returns claims of iss's that v accepts.
+func validClaims(iss *jwtauthtest.Issuer) map[string]any {
+	return map[string]any{
+		"iss":   iss.URL(),
+		"sub":   "alice",
+		"aud":   []string{"taskmanager", "reports"},
+		"exp":   verifierNow.Add(time.Hour).Unix(),
+		"iat":   verifierNow.Unix(),
+		"scope": "openid tasks:read",
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	v := newVerifierFixture(iss)
+	ctx := context.Background()
+
+	for _, alg := range []string{RS256, ES256} {
+		claims, err := v.Verify(ctx, iss.Sign(alg, validClaims(iss)))
+		require.NoError(t, err, alg)
+		assert.Equal(t, "alice", claims.String("sub"))
+		assert.Equal(t, []string{"openid", "tasks:read"}, claims.Strings("scope"))
+		assert.Equal(t, []string{"taskmanager", "reports"}, claims.Strings("aud"))
+		iat, ok := claims.Time("iat")
+		assert.True(t, ok)
+		assert.Equal(t, verifierNow, iat)
+	}
+
+	single := validClaims(iss)
+	single["aud"] = "taskmanager"
+	_, err := v.Verify(ctx, iss.Sign(ES256, single))
+	assert.NoError(t, err, "aud may be a single string")
+
+	// Within the leeway.
+	skewed := validClaims(iss)
+	skewed["exp"] = verifierNow.Add(-30 * time.Second).Unix()
+	skewed["nbf"] = verifierNow.Add(30 * time.Second).Unix()
+	_, err = v.Verify(ctx, iss.Sign(RS256, skewed))
+	assert.NoError(t, err)
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	iss := jwtauthtest.NewIssuer(t)
+	other := jwtauthtest.NewIssuer(t)
+	v := newVerifierFixture(iss)
+	ctx := context.Background()
+	with := func(name string, value any) map[string]any {
+		claims := validClaims(iss)
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	parts := strings.Split(iss.Sign(RS256, validClaims(iss)), ".")
+	forged, err := json.Marshal(with("sub", "mallory"))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		token string
+		want  string
+	}{
+		"garbage":          {"tm_0123_abcd", "not a signed JWT"},
+		"bad header":       {"e30." + parts[1] + "." + parts[2], "unsupported algorithm"},
+		"alg none":         {iss.SignHeader(map[string]any{"alg": "none", "kid": "RS256-1"}, validClaims(iss)), "unsupported algorithm"},
+		"alg HS256":        {iss.SignHeader(map[string]any{"alg": "HS256", "kid": "RS256-1"}, validClaims(iss)), "unsupported algorithm"},
+		"crit":             {iss.SignHeader(map[string]any{"alg": "RS256", "kid": "RS256-1", "crit": []string{"exp"}}, validClaims(iss)), "critical headers"},
+		"unknown kid":      {iss.SignHeader(map[string]any{"alg": "RS256", "kid": "RS256-9"}, validClaims(iss)), "unknown signing key"},
+		"key of other alg": {iss.SignHeader(map[string]any{"alg": "RS256", "kid": "ES256-1"}, validClaims(iss)), "is for ES256"},
+		"tampered claims":  {parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2], "bad signature"},
+		"forged signature": {parts[0] + "." + parts[1] + "." + strings.Split(other.Sign(RS256, validClaims(iss)), ".")[2], "bad signature"},
+		"other issuer":     {iss.Sign(ES256, with("iss", other.URL())), "issuer"},
+		"no issuer":        {iss.Sign(ES256, with("iss", nil)), "issuer"},
+		"wrong audience":   {iss.Sign(ES256, with("aud", "reports")), "audience"},
+		"expired":          {iss.Sign(ES256, with("exp", verifierNow.Add(-2*time.Minute).Unix())), "token expired"},
+		"no expiry":        {iss.Sign(ES256, with("exp", nil)), "no expiry"},
+		"not yet valid":    {iss.Sign(ES256, with("nbf", verifierNow.Add(2*time.Minute).Unix())), "not valid yet"},
+	} {
+		_, err := v.Verify(ctx, tc.token)
+		require.Error(t, err, name)
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+		assert.Equal(t, apperror.KindUnauthenticated, apperror.KindOf(err), name)
+		assert.ErrorContains(t, err, tc.want, name)
+	}
+}
+
+func TestClaims(t *testing.T) {
+	var claims Claims
+	require.NoError(t, decodeSegment("eyJzY3AiOlsiYSIsMSwiYiJdLCJleHAiOjE3NDg3Njg0MDAuNX0", &claims))
+	assert.Equal(t, []string{"a", "b"}, claims.Strings("scp"))
+	exp, ok := claims.Time("exp")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC), exp)
+	assert.Empty(t, claims.String("exp"))
+	_, ok = claims.Time("scp")
+	assert.False(t, ok)
+	assert.Nil(t, claims.Strings("missing"))
+	_, isNumber := claims["exp"].(json.Number)
+	assert.True(t, isNumber)
+}
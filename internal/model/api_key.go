@@ -0,0 +1,97 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"time"
+	"unicode/utf8"
+)
+
+// Scopes an API key can carry.
+const (
+	// ScopeTasksRead allows reading tasks and everything around them: labels,
+	// users, projects, search and the task views.
+	ScopeTasksRead = "tasks:read"
+	// ScopeTasksWrite allows every change to them.
+	ScopeTasksWrite = "tasks:write"
+	// ScopeAdmin allows managing API keys.
+	ScopeAdmin = "admin"
+)
+
+// scopes lists the known scopes.
+var scopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeAdmin}
+
+// APIKey is a credential for calling the API. Only a hash of its secret is
+// stored; the secret is shown once, when the key is issued.
+//
+// Fields:
+//   - ID: public identifier, part of the key itself; set by the service
+//   - Name: required, at most 100 characters, what the key is used for
+//   - Scopes: what the key allows, at least one of the Scope constants, sorted
+//   - Tenant: optional tenant the key is confined to; keys without one act in
+//     the tenant their requests name
+//   - Hash: hex SHA-256 of the secret; never serialized
+//   - CreatedBy: the actor that issued the key, empty if unknown; set by the service
+//   - CreatedAt: timestamp when the key was issued
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Hash      string    `json:"-"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IssuedAPIKey is a newly issued API key together with the key to present,
+// which is not stored anywhere.
+type IssuedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// Clone returns a copy of the key that shares no mutable state with k.
+func (k *APIKey) Clone() *APIKey {
+	c := *k
+	c.Scopes = slices.Clone(k.Scopes)
+	return &c
+}
+
+// HasScope reports whether the key allows scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Validate checks the name, scopes and tenant of the key and sorts its
+// scopes, dropping duplicates.
+func (k *APIKey) Validate() error {
+	var fields []apperror.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+	if strings.TrimSpace(k.Name) == "" {
+		invalid("name", "name is required")
+	} else if utf8.RuneCountInString(k.Name) > 100 {
+		invalid("name", "name must be at most 100 characters")
+	}
+	slices.Sort(k.Scopes)
+	k.Scopes = slices.Compact(k.Scopes)
+	if len(k.Scopes) == 0 {
+		invalid("scopes", "at least one scope is required")
+	}
+	for _, scope := range k.Scopes {
+		if !slices.Contains(scopes, scope) {
+			invalid("scopes", "unknown scope "+scope+"; scopes are "+strings.Join(scopes, ", "))
+			break
+		}
+	}
+	if k.Tenant != "" && !identity.ValidTenant(k.Tenant) {
+		invalid("tenant", "tenant must be 1-63 lowercase letters, digits, - and _")
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
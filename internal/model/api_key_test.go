@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKey_Validate(t *testing.T) {
+	key := &APIKey{Name: "CI", Scopes: []string{ScopeTasksWrite, ScopeTasksRead, ScopeTasksWrite}, Tenant: "acme"}
+	require.NoError(t, key.Validate())
+	assert.Equal(t, []string{ScopeTasksRead, ScopeTasksWrite}, key.Scopes)
+	assert.True(t, key.HasScope(ScopeTasksRead))
+	assert.False(t, key.HasScope(ScopeAdmin))
+
+	for _, tc := range []struct {
+		key   APIKey
+		field string
+	}{
+		{APIKey{Scopes: []string{ScopeAdmin}}, "name"},
+		{APIKey{Name: strings.Repeat("x", 101), Scopes: []string{ScopeAdmin}}, "name"},
+		{APIKey{Name: "CI"}, "scopes"},
+		{APIKey{Name: "CI", Scopes: []string{"tasks:delete"}}, "scopes"},
+		{APIKey{Name: "CI", Scopes: []string{ScopeAdmin}, Tenant: "Acme"}, "tenant"},
+	} {
+		err := tc.key.Validate()
+		require.Error(t, err)
+		assert.Equal(t, tc.field, apperror.FieldsOf(err)[0].Field)
+	}
+}
+
+func TestAPIKey_HashIsNotSerialized(t *testing.T) {
+	data, err := json.Marshal(&IssuedAPIKey{APIKey: &APIKey{ID: "k1", Hash: "secret-hash"}, Key: "tm_k1_x"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-hash")
+	assert.Contains(t, string(data), `"key":"tm_k1_x"`)
+	assert.Contains(t, string(data), `"id":"k1"`)
+}
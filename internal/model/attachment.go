@@ -0,0 +1,53 @@
+package model
+
+import (
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+	"unicode"
+)
+
+// Attachment is a file attached to a task. Its content lives in a blob store
+// under its SHA-256 digest, so identical files are stored once however often
+// they are attached.
+//
+// Fields:
+//   - ID: unique identifier, set by the server
+//   - TaskID: the task the file is attached to
+//   - Filename: required, at most 255 bytes, without path separators
+//   - ContentType: media type sniffed from the content
+//   - Size: length of the content in bytes
+//   - SHA256: hex-encoded SHA-256 digest of the content
+//   - Uploader: the actor who attached the file, empty if unknown
+//   - CreatedAt: timestamp when the file was attached
+type Attachment struct {
+	ID          string    `json:"id"`
+	TaskID      string    `json:"task_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	Uploader    string    `json:"uploader,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Clone returns a copy of the attachment.
+func (a *Attachment) Clone() *Attachment {
+	c := *a
+	return &c
+}
+
+// ValidateFilename checks that name can be used as the file name of an
+// attachment.
+func ValidateFilename(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return apperror.InvalidField("filename", "filename is required")
+	}
+	if len(name) > 255 {
+		return apperror.InvalidField("filename", "filename must be at most 255 bytes")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.ContainsFunc(name, unicode.IsControl) {
+		return apperror.InvalidField("filename", "filename may not contain path separators or control characters")
+	}
+	return nil
+}
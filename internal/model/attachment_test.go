@@ -0,0 +1,23 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFilename(t *testing.T) {
+	for _, name := range []string{"build.log", "screen shot.png", "résumé.pdf", strings.Repeat("x", 255)} {
+		assert.NoError(t, ValidateFilename(name), name)
+	}
+	for _, name := range []string{"", ".", "..", "../passwd", `C:\boot.ini`, "a\x00b", "line\nbreak", strings.Repeat("x", 256)} {
+		err := ValidateFilename(name)
+		require.Error(t, err, name)
+		assert.Equal(t, apperror.KindValidation, apperror.KindOf(err), name)
+		assert.Equal(t, "filename", apperror.FieldsOf(err)[0].Field, name)
+	}
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Board is the kanban view of tasks: one column per workflow state, in the
+// workflow's order. Within a column tasks are ordered by Task.Rank.
+type Board struct {
+	// ProjectID is the project whose tasks the board shows, empty for the
+	// tasks outside any project.
+	ProjectID string         `json:"project_id,omitempty"`
+	Columns   []*BoardColumn `json:"columns"`
+}
+
+// BoardColumn holds the tasks in one status.
+//
+// Fields:
+//   - Status: the workflow state of the column
+//   - WIPLimit: the most tasks the column may hold, 0 for no limit
+//   - Count: the number of tasks in the column, including those beyond Tasks
+//   - Tasks: the first tasks of the column in rank order
+type BoardColumn struct {
+	Status   string  `json:"status"`
+	WIPLimit int     `json:"wip_limit,omitempty"`
+	Count    int     `json:"count"`
+	Tasks    []*Task `json:"tasks"`
+}
+
+// BoardMove places a task on the board: in the column of Status (the task's
+// current status when empty), directly above the task Before or directly below
+// the task After. Without either the task goes to the bottom of the column.
+type BoardMove struct {
+	Status string `json:"status,omitempty"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Validate checks that at most one neighbour is given.
+func (m *BoardMove) Validate() error {
+	if m.Before != "" && m.After != "" {
+		return fmt.Errorf("give either before or after, not both")
+	}
+	return nil
+}
+
+// Ranks are strings over rankDigits that order tasks within a board column by
+// plain byte comparison. Placing a task between two others only needs a key
+// between theirs, see RankBetween, so a move rewrites the moved task alone.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// TimeRank is the rank of a task created at t: its Unix time in nanoseconds as
+// 19 decimal digits, so tasks join the bottom of their column in creation order.
+// Tasks stored before ranks existed rank as their creation time, see RankKey.
+func TimeRank(t time.Time) string {
+	return fmt.Sprintf("%019d", max(t.UnixNano(), 0))
+}
+
+// RankKey returns the rank the task is ordered by.
+func (t *Task) RankKey() string {
+	if t.Rank == "" {
+		return TimeRank(t.CreatedAt)
+	}
+	return t.Rank
+}
+
+// RankBetween returns a short rank ordered strictly between lo and hi. An empty
+// lo is below every rank and an empty hi above every rank. It fails when no
+// such rank exists: lo is not below hi, or hi is lo followed by zeros.
+func RankBetween(lo, hi string) (string, error) {
+	for _, r := range []string{lo, hi} {
+		if strings.Trim(r, rankDigits) != "" {
+			return "", fmt.Errorf("invalid rank %q", r)
+		}
+	}
+	if hi != "" && lo >= hi {
+		return "", fmt.Errorf("rank %q is not below %q", lo, hi)
+	}
+	base := len(rankDigits)
+	var key strings.Builder
+	bounded := hi != ""
+	for i := 0; ; i++ {
+		l := 0
+		if i < len(lo) {
+			l = strings.IndexByte(rankDigits, lo[i])
+		}
+		h := base
+		if bounded {
+			if i >= len(hi) {
+				return "", fmt.Errorf("no rank between %q and %q", lo, hi)
+			}
+			h = strings.IndexByte(rankDigits, hi[i])
+		}
+		switch {
+		case l == h:
+			key.WriteByte(rankDigits[l])
+		case h-l > 1:
+			// The midpoint is never 0, so keys never end in a zero and
+			// there is always room below them.
+			key.WriteByte(rankDigits[(l+h)/2])
+			return key.String(), nil
+		default:
+			// Adjacent digits: keep lo's, and anything after it stays below hi.
+			key.WriteByte(rankDigits[l])
+			bounded = false
+		}
+	}
+}
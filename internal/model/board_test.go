@@ -0,0 +1,70 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		lo, hi, want string
+	}{
+		{"", "", "i"},
+		{"i", "", "r"},
+		{"", "i", "9"},
+		{"a", "c", "b"},
+		{"a", "b", "ai"},
+		{"az", "b", "azi"},
+		{"a", "a01", "a00i"},
+		{"1", "1736", "13"},
+		{"", "1", "0i"},
+	}
+	for _, tt := range tests {
+		got, err := RankBetween(tt.lo, tt.hi)
+		require.NoError(t, err, "%q..%q", tt.lo, tt.hi)
+		assert.Equal(t, tt.want, got, "%q..%q", tt.lo, tt.hi)
+		assert.Greater(t, got, tt.lo)
+		if tt.hi != "" {
+			assert.Less(t, got, tt.hi)
+		}
+	}
+
+	for _, bounds := range [][2]string{{"b", "a"}, {"a", "a"}, {"a", "a0"}, {"A", ""}, {"", "a-b"}} {
+		_, err := RankBetween(bounds[0], bounds[1])
+		assert.Error(t, err, "%q..%q", bounds[0], bounds[1])
+	}
+}
+
+func TestRankBetween_RepeatedInsertsStayShort(t *testing.T) {
+	lo, hi := TimeRank(time.Unix(1, 0)), TimeRank(time.Unix(2, 0))
+	// Always inserting right below hi halves the gap every time.
+	for range 100 {
+		mid, err := RankBetween(lo, hi)
+		require.NoError(t, err)
+		require.Less(t, lo, mid)
+		require.Less(t, mid, hi)
+		lo = mid
+	}
+	assert.Less(t, len(lo), 60)
+}
+
+func TestTimeRank(t *testing.T) {
+	early, late := time.Unix(0, 5), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "0000000000000000005", TimeRank(early))
+	assert.Less(t, TimeRank(early), TimeRank(late))
+	assert.Equal(t, "0000000000000000000", TimeRank(time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	task := &Task{CreatedAt: late}
+	assert.Equal(t, TimeRank(late), task.RankKey(), "unranked tasks rank by creation time")
+	task.Rank = "0i"
+	assert.Equal(t, "0i", task.RankKey())
+}
+
+func TestBoardMove_Validate(t *testing.T) {
+	assert.NoError(t, (&BoardMove{Status: StatusTodo}).Validate())
+	assert.NoError(t, (&BoardMove{Before: "a"}).Validate())
+	assert.Error(t, (&BoardMove{Before: "a", After: "b"}).Validate())
+}
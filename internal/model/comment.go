@@ -0,0 +1,127 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+	"unicode/utf8"
+)
+
+// Comment is a message in the discussion of a task.
+//
+// Fields:
+//   - ID: unique identifier, set by the server
+//   - TaskID: the task the comment belongs to
+//   - Author: the actor who posted the comment, empty if unknown
+//   - Body: required, at most MaxCommentLength characters
+//   - Edited: whether the body was changed after the comment was posted
+//   - CreatedAt: timestamp when the comment was posted
+//   - UpdatedAt: timestamp when the comment was last edited
+type Comment struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Author    string    `json:"author,omitempty"`
+	Body      string    `json:"body"`
+	Edited    bool      `json:"edited"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MaxCommentLength is the largest number of characters in a comment body.
+const MaxCommentLength = 10000
+
+// Clone returns a copy of the comment.
+func (c *Comment) Clone() *Comment {
+	cc := *c
+	return &cc
+}
+
+// Validate checks the comment fields for correctness.
+func (c *Comment) Validate() error {
+	if strings.TrimSpace(c.Body) == "" {
+		return apperror.InvalidField("body", "body is required")
+	}
+	if utf8.RuneCountInString(c.Body) > MaxCommentLength {
+		return apperror.InvalidField("body", "body must be at most 10000 characters")
+	}
+	return nil
+}
+
+// CommentQuery selects a page of the comments on a task, oldest first with
+// ties broken by ID. A zero Limit means no limit; After resumes from the
+// cursor of a previous page.
+type CommentQuery struct {
+	TaskID string
+	Limit  int
+	After  *CommentCursor
+}
+
+// IsAfterCursor reports whether c comes strictly after q.After. It is true for
+// every comment when there is no cursor.
+func (q *CommentQuery) IsAfterCursor(c *Comment) bool {
+	if q.After == nil {
+		return true
+	}
+	return compareKeys(c.CreatedAt.UnixNano(), "", c.ID, q.After.CreatedAt, "", q.After.ID) > 0
+}
+
+// CursorAt returns the cursor that resumes the query after c.
+func (q *CommentQuery) CursorAt(c *Comment) *CommentCursor {
+	return &CommentCursor{CreatedAt: c.CreatedAt.UnixNano(), ID: c.ID}
+}
+
+// Apply runs the query over the unordered comments of q.TaskID, for backends
+// that cannot push it down any further. The backing array of comments is reused.
+func (q *CommentQuery) Apply(comments []*Comment) *CommentPage {
+	matched := comments[:0]
+	for _, c := range comments {
+		if c.TaskID == q.TaskID && q.IsAfterCursor(c) {
+			matched = append(matched, c)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		return compareKeys(a.CreatedAt.UnixNano(), "", a.ID, b.CreatedAt.UnixNano(), "", b.ID) < 0
+	})
+	page := &CommentPage{Comments: matched}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		page.Comments = matched[:q.Limit]
+		page.Next = q.CursorAt(page.Comments[q.Limit-1])
+	}
+	return page
+}
+
+// CommentPage is one page of a comment query. Next is nil on the last page.
+type CommentPage struct {
+	Comments []*Comment
+	Next     *CommentCursor
+}
+
+// CommentCursor marks a position in the comments on a task: the creation time
+// in Unix nanoseconds and ID of the last comment of a page.
+type CommentCursor struct {
+	CreatedAt int64  `json:"t"`
+	ID        string `json:"i"`
+}
+
+// Encode returns the opaque token form of the cursor.
+func (c *CommentCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCommentCursor decodes a token produced by CommentCursor.Encode.
+func ParseCommentCursor(token string) (*CommentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c CommentCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComment_Validate(t *testing.T) {
+	assert.NoError(t, (&Comment{Body: "Hello"}).Validate())
+	assert.NoError(t, (&Comment{Body: strings.Repeat("ü", MaxCommentLength)}).Validate(), "length counts characters")
+	for _, body := range []string{"", " \n\t", strings.Repeat("x", MaxCommentLength+1)} {
+		err := (&Comment{Body: body}).Validate()
+		require.Error(t, err)
+		assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+		assert.Equal(t, "body", apperror.FieldsOf(err)[0].Field)
+	}
+}
+
+func TestCommentQuery_Apply(t *testing.T) {
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	comments := func() []*Comment {
+		return []*Comment{
+			{ID: "c", TaskID: "t", CreatedAt: at.Add(time.Minute)},
+			{ID: "b", TaskID: "t", CreatedAt: at},
+			{ID: "a", TaskID: "t", CreatedAt: at},
+			{ID: "x", TaskID: "other", CreatedAt: at},
+		}
+	}
+	ids := func(page *CommentPage) []string {
+		var out []string
+		for _, c := range page.Comments {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	q := CommentQuery{TaskID: "t", Limit: 2}
+	page := q.Apply(comments())
+	assert.Equal(t, []string{"a", "b"}, ids(page))
+	require.NotNil(t, page.Next)
+
+	cursor, err := ParseCommentCursor(page.Next.Encode())
+	require.NoError(t, err)
+	q.After = cursor
+	page = q.Apply(comments())
+	assert.Equal(t, []string{"c"}, ids(page))
+	assert.Nil(t, page.Next)
+
+	_, err = ParseCommentCursor("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
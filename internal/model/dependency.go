@@ -0,0 +1,228 @@
+package model
+
+import (
+	"container/heap"
+	"taskmanager/internal/apperror"
+	"time"
+)
+
+// Dependency records that a task is blocked by another one: TaskID cannot be
+// completed while BlockerID is open. Dependencies form a directed acyclic graph.
+//
+// Fields:
+//   - TaskID: the blocked task
+//   - BlockerID: the task that has to be completed first
+//   - CreatedAt: timestamp when the dependency was added
+type Dependency struct {
+	TaskID    string    `json:"task_id"`
+	BlockerID string    `json:"blocker_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Clone returns a copy of the dependency.
+func (d *Dependency) Clone() *Dependency {
+	c := *d
+	return &c
+}
+
+// Validate checks that both ends are set and differ.
+func (d *Dependency) Validate() error {
+	var fields []apperror.FieldError
+	if d.TaskID == "" {
+		fields = append(fields, apperror.FieldError{Field: "task_id", Message: "task_id is required"})
+	}
+	if d.BlockerID == "" {
+		fields = append(fields, apperror.FieldError{Field: "blocker_id", Message: "blocker_id is required"})
+	} else if d.BlockerID == d.TaskID {
+		fields = append(fields, apperror.FieldError{Field: "blocker_id", Message: "a task cannot block itself"})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
+
+// ErrDependencyCycle is returned when dependencies would form a cycle.
+var ErrDependencyCycle = apperror.Conflict("dependency cycle")
+
+// CriticalPath is the longest chain of dependent tasks measured by their
+// estimates: the least time in which the work can be finished.
+type CriticalPath struct {
+	// EstimateMinutes is the sum of the estimates along Tasks.
+	EstimateMinutes int `json:"estimate_minutes"`
+	// Unestimated counts the tasks on the path without an estimate, which
+	// contribute nothing to its length.
+	Unestimated int `json:"unestimated"`
+	// Tasks lists the path from the first blocker to the last blocked task.
+	Tasks []*Task `json:"tasks"`
+}
+
+// DependencyGraph is an in-memory view of the dependencies between a set of
+// tasks. Dependencies on tasks outside the set are ignored.
+type DependencyGraph struct {
+	tasks      map[string]*Task
+	blockers   map[string][]string
+	dependents map[string][]string
+}
+
+// NewDependencyGraph builds the graph of deps restricted to tasks.
+func NewDependencyGraph(tasks []*Task, deps []*Dependency) *DependencyGraph {
+	g := &DependencyGraph{
+		tasks:      make(map[string]*Task, len(tasks)),
+		blockers:   make(map[string][]string),
+		dependents: make(map[string][]string),
+	}
+	for _, t := range tasks {
+		g.tasks[t.ID] = t
+	}
+	for _, d := range deps {
+		if g.tasks[d.TaskID] == nil || g.tasks[d.BlockerID] == nil {
+			continue
+		}
+		g.blockers[d.TaskID] = append(g.blockers[d.TaskID], d.BlockerID)
+		g.dependents[d.BlockerID] = append(g.dependents[d.BlockerID], d.TaskID)
+	}
+	return g
+}
+
+// TopologicalOrder returns the tasks so that every blocker comes before the
+// tasks it blocks. Among tasks that are ready at the same time the oldest comes
+// first. It fails with ErrDependencyCycle if the graph has a cycle.
+func (g *DependencyGraph) TopologicalOrder() ([]*Task, error) {
+	pending := make(map[string]int, len(g.tasks))
+	ready := &taskHeap{}
+	for id, t := range g.tasks {
+		pending[id] = len(g.blockers[id])
+		if pending[id] == 0 {
+			heap.Push(ready, t)
+		}
+	}
+	order := make([]*Task, 0, len(g.tasks))
+	for ready.Len() > 0 {
+		t := heap.Pop(ready).(*Task)
+		order = append(order, t)
+		for _, id := range g.dependents[t.ID] {
+			if pending[id]--; pending[id] == 0 {
+				heap.Push(ready, g.tasks[id])
+			}
+		}
+	}
+	if len(order) != len(g.tasks) {
+		return nil, ErrDependencyCycle
+	}
+	return order, nil
+}
+
+// Unblocked returns the incomplete tasks none of whose blockers in the graph is
+// incomplete, oldest first.
+func (g *DependencyGraph) Unblocked() []*Task {
+	ready := &taskHeap{}
+	for id, t := range g.tasks {
+		if t.Completed {
+			continue
+		}
+		blocked := false
+		for _, b := range g.blockers[id] {
+			if !g.tasks[b].Completed {
+				blocked = true
+				break
+			}
+		}
+		if !blocked {
+			heap.Push(ready, t)
+		}
+	}
+	tasks := make([]*Task, 0, ready.Len())
+	for ready.Len() > 0 {
+		tasks = append(tasks, heap.Pop(ready).(*Task))
+	}
+	return tasks
+}
+
+// CriticalPath returns the chain of dependent tasks with the largest total
+// estimate. Ties go to the chain found first in topological order. It fails
+// with ErrDependencyCycle if the graph has a cycle.
+func (g *DependencyGraph) CriticalPath() (*CriticalPath, error) {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	// length[id] is the longest chain ending in id, including its own estimate.
+	length := make(map[string]int, len(order))
+	prev := make(map[string]string, len(order))
+	var end *Task
+	for _, t := range order {
+		best, from := 0, ""
+		for _, b := range g.blockers[t.ID] {
+			if from == "" || length[b] > best {
+				best, from = length[b], b
+			}
+		}
+		prev[t.ID] = from
+		length[t.ID] = best + t.EstimateMinutes
+		if end == nil || length[t.ID] > length[end.ID] {
+			end = t
+		}
+	}
+	path := &CriticalPath{Tasks: []*Task{}}
+	if end == nil {
+		return path, nil
+	}
+	path.EstimateMinutes = length[end.ID]
+	for id := end.ID; id != ""; id = prev[id] {
+		t := g.tasks[id]
+		path.Tasks = append(path.Tasks, t)
+		if t.EstimateMinutes == 0 {
+			path.Unestimated++
+		}
+	}
+	for i, j := 0, len(path.Tasks)-1; i < j; i, j = i+1, j-1 {
+		path.Tasks[i], path.Tasks[j] = path.Tasks[j], path.Tasks[i]
+	}
+	return path, nil
+}
+
+// dependsOn walks blockersOf from from and reports whether it reaches to.
+func dependsOn(from, to string, blockersOf func(string) []string) bool {
+	seen := map[string]bool{from: true}
+	for stack := []string{from}; len(stack) > 0; {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
+		}
+		for _, b := range blockersOf(id) {
+			if !seen[b] {
+				seen[b] = true
+				stack = append(stack, b)
+			}
+		}
+	}
+	return false
+}
+
+// WouldCycle reports whether adding d to a graph whose blockers are listed by
+// blockersOf would create a cycle, i.e. whether d.BlockerID already depends on
+// d.TaskID. Repositories use it before storing a dependency.
+func (d *Dependency) WouldCycle(blockersOf func(id string) []string) bool {
+	return dependsOn(d.BlockerID, d.TaskID, blockersOf)
+}
+
+// taskHeap orders tasks by creation time, then ID.
+type taskHeap []*Task
+
+func (h taskHeap) Len() int { return len(h) }
+func (h taskHeap) Less(i, j int) bool {
+	if !h[i].CreatedAt.Equal(h[j].CreatedAt) {
+		return h[i].CreatedAt.Before(h[j].CreatedAt)
+	}
+	return h[i].ID < h[j].ID
+}
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x any)   { *h = append(*h, x.(*Task)) }
+func (h *taskHeap) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependency_Validate(t *testing.T) {
+	assert.NoError(t, (&Dependency{TaskID: "a", BlockerID: "b"}).Validate())
+	assert.Len(t, apperror.FieldsOf((&Dependency{}).Validate()), 2)
+	assert.ErrorContains(t, (&Dependency{TaskID: "a", BlockerID: "a"}).Validate(), "cannot block itself")
+}
+
+func TestDependency_WouldCycle(t *testing.T) {
+	// c is blocked by b, which is blocked by a.
+	blockers := map[string][]string{"c": {"b"}, "b": {"a"}}
+	lookup := func(id string) []string { return blockers[id] }
+	assert.True(t, (&Dependency{TaskID: "a", BlockerID: "c"}).WouldCycle(lookup))
+	assert.True(t, (&Dependency{TaskID: "b", BlockerID: "c"}).WouldCycle(lookup))
+	assert.False(t, (&Dependency{TaskID: "c", BlockerID: "a"}).WouldCycle(lookup))
+	assert.False(t, (&Dependency{TaskID: "d", BlockerID: "c"}).WouldCycle(lookup))
+}
+
+// graphTasks returns tasks with the given IDs, created in that order.
+func graphTasks(ids ...string) []*Task {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := make([]*Task, len(ids))
+	for i, id := range ids {
+		tasks[i] = &Task{ID: id, CreatedAt: t0.Add(time.Duration(i) * time.Minute)}
+	}
+	return tasks
+}
+
+func ids(tasks []*Task) []string {
+	out := make([]string, len(tasks))
+	for i, t := range tasks {
+		out[i] = t.ID
+	}
+	return out
+}
+
+func TestDependencyGraph_TopologicalOrder(t *testing.T) {
+	tasks := graphTasks("a", "b", "c", "d")
+	deps := []*Dependency{
+		{TaskID: "a", BlockerID: "d"},
+		{TaskID: "b", BlockerID: "a"},
+		{TaskID: "c", BlockerID: "d"},
+		{TaskID: "b", BlockerID: "outside"},
+	}
+	order, err := NewDependencyGraph(tasks, deps).TopologicalOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "a", "b", "c"}, ids(order))
+
+	deps = append(deps, &Dependency{TaskID: "d", BlockerID: "b"})
+	_, err = NewDependencyGraph(tasks, deps).TopologicalOrder()
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+func TestDependencyGraph_Unblocked(t *testing.T) {
+	tasks := graphTasks("a", "b", "c", "d")
+	tasks[0].Completed = true
+	deps := []*Dependency{
+		{TaskID: "b", BlockerID: "a"},
+		{TaskID: "c", BlockerID: "b"},
+	}
+	assert.Equal(t, []string{"b", "d"}, ids(NewDependencyGraph(tasks, deps).Unblocked()))
+}
+
+func TestDependencyGraph_CriticalPath(t *testing.T) {
+	tasks := graphTasks("a", "b", "c", "d", "e")
+	for i, est := range []int{30, 0, 90, 45, 10} {
+		tasks[i].EstimateMinutes = est
+	}
+	// a -> b -> d and c -> d; e stands alone.
+	deps := []*Dependency{
+		{TaskID: "b", BlockerID: "a"},
+		{TaskID: "d", BlockerID: "b"},
+		{TaskID: "d", BlockerID: "c"},
+	}
+	path, err := NewDependencyGraph(tasks, deps).CriticalPath()
+	require.NoError(t, err)
+	assert.Equal(t, 135, path.EstimateMinutes)
+	assert.Equal(t, []string{"c", "d"}, ids(path.Tasks))
+	assert.Zero(t, path.Unestimated)
+
+	tasks[2].EstimateMinutes = 10
+	path, err = NewDependencyGraph(tasks, deps).CriticalPath()
+	require.NoError(t, err)
+	assert.Equal(t, 75, path.EstimateMinutes)
+	assert.Equal(t, []string{"a", "b", "d"}, ids(path.Tasks))
+	assert.Equal(t, 1, path.Unestimated)
+
+	empty, err := NewDependencyGraph(nil, nil).CriticalPath()
+	require.NoError(t, err)
+	assert.Zero(t, empty.EstimateMinutes)
+	assert.NotNil(t, empty.Tasks)
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CascadePolicy decides what happens to the subtasks of a task that is
+// completed or deleted.
+type CascadePolicy string
+
+const (
+	// CascadeBlock refuses the change while the task has subtasks that would be
+	// affected: incomplete ones on completion, any on deletion.
+	CascadeBlock CascadePolicy = "block"
+	// CascadeCascade applies the change to the whole subtree.
+	CascadeCascade CascadePolicy = "cascade"
+	// CascadeOrphan detaches the affected children, which become top-level tasks.
+	CascadeOrphan CascadePolicy = "orphan"
+)
+
+// ParseCascadePolicy converts "block", "cascade" or "orphan" into a CascadePolicy.
+func ParseCascadePolicy(s string) (CascadePolicy, error) {
+	switch p := CascadePolicy(s); p {
+	case CascadeBlock, CascadeCascade, CascadeOrphan:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown cascade policy %q (want block, cascade or orphan)", s)
+}
+
+// Progress is the roll-up completion of a task's subtree.
+//
+// Percent is 100 for a completed task, 0 for an incomplete task without
+// subtasks, and otherwise the mean of its children's percentages, so every
+// child weighs the same regardless of how finely it is broken down.
+type Progress struct {
+	Subtasks  int     `json:"subtasks"`
+	Completed int     `json:"completed"`
+	Percent   float64 `json:"percent"`
+}
+
+// TaskTree is a task with its subtasks and roll-up progress.
+type TaskTree struct {
+	Task     *Task       `json:"task"`
+	Progress Progress    `json:"progress"`
+	Children []*TaskTree `json:"children"`
+}
+
+// BuildTaskTree arranges root and its descendants into a tree with children in
+// creation order and computes the progress of every node. Descendants whose
+// parent is not part of the tree are ignored.
+func BuildTaskTree(root *Task, descendants []*Task) *TaskTree {
+	children := make(map[string][]*Task)
+	for _, t := range descendants {
+		children[t.ParentID] = append(children[t.ParentID], t)
+	}
+	var build func(t *Task) (*TaskTree, float64)
+	build = func(t *Task) (*TaskTree, float64) {
+		node := &TaskTree{Task: t, Children: []*TaskTree{}}
+		kids := children[t.ID]
+		sort.Slice(kids, func(i, j int) bool {
+			if !kids[i].CreatedAt.Equal(kids[j].CreatedAt) {
+				return kids[i].CreatedAt.Before(kids[j].CreatedAt)
+			}
+			return kids[i].ID < kids[j].ID
+		})
+		sum := 0.0
+		for _, kid := range kids {
+			child, percent := build(kid)
+			node.Children = append(node.Children, child)
+			node.Progress.Subtasks += 1 + child.Progress.Subtasks
+			node.Progress.Completed += child.Progress.Completed
+			if kid.Completed {
+				node.Progress.Completed++
+			}
+			sum += percent
+		}
+		percent := 0.0
+		switch {
+		case t.Completed:
+			percent = 100
+		case len(kids) > 0:
+			percent = sum / float64(len(kids))
+		}
+		node.Progress.Percent = math.Round(percent*10) / 10
+		return node, percent
+	}
+	tree, _ := build(root)
+	return tree
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCascadePolicy(t *testing.T) {
+	for _, s := range []string{"block", "cascade", "orphan"} {
+		p, err := ParseCascadePolicy(s)
+		require.NoError(t, err)
+		assert.Equal(t, CascadePolicy(s), p)
+	}
+	_, err := ParseCascadePolicy("delete")
+	assert.ErrorContains(t, err, "unknown cascade policy")
+}
+
+func TestBuildTaskTree(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	root := &Task{ID: "root"}
+	descendants := []*Task{
+		// Created out of order to check that children are sorted.
+		{ID: "b", ParentID: "root", CreatedAt: t0.Add(2 * time.Minute)},
+		{ID: "a", ParentID: "root", CreatedAt: t0.Add(time.Minute)},
+		{ID: "a1", ParentID: "a", Completed: true, CreatedAt: t0},
+		{ID: "a2", ParentID: "a", CreatedAt: t0},
+		{ID: "a3", ParentID: "a", CreatedAt: t0},
+		{ID: "stray", ParentID: "elsewhere"},
+	}
+	tree := BuildTaskTree(root, descendants)
+
+	require.Len(t, tree.Children, 2)
+	a, b := tree.Children[0], tree.Children[1]
+	assert.Equal(t, "a", a.Task.ID)
+	assert.Equal(t, []string{"a1", "a2", "a3"}, []string{a.Children[0].Task.ID, a.Children[1].Task.ID, a.Children[2].Task.ID})
+	assert.Equal(t, Progress{Subtasks: 3, Completed: 1, Percent: 33.3}, a.Progress)
+	assert.Equal(t, Progress{}, b.Progress)
+	assert.NotNil(t, b.Children)
+	// The mean of 33.33 and 0, rounded only for display.
+	assert.Equal(t, Progress{Subtasks: 5, Completed: 1, Percent: 16.7}, tree.Progress)
+
+	root.Completed = true
+	assert.Equal(t, 100.0, BuildTaskTree(root, descendants).Progress.Percent)
+}
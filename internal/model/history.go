@@ -0,0 +1,163 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HistoryAction is the kind of change a HistoryEntry records.
+type HistoryAction string
+
+const (
+	HistoryCreate HistoryAction = "create"
+	HistoryUpdate HistoryAction = "update"
+	// HistoryDelete moves a task to the trash, HistoryUndelete takes it back
+	// out and HistoryPurge removes it for good.
+	HistoryDelete   HistoryAction = "delete"
+	HistoryUndelete HistoryAction = "undelete"
+	HistoryPurge    HistoryAction = "purge"
+	// HistoryRestore returns a task to an earlier revision.
+	HistoryRestore HistoryAction = "restore"
+)
+
+// HistoryEntry is an immutable record of one change to a task.
+//
+// Fields:
+//   - TaskID: the changed task; entries outlive deleted tasks
+//   - Revision: position in the task's history, starting at 1; set by the repository
+//   - Version: the task version the change produced, 0 for purges
+//   - Action: what happened to the task
+//   - Actor: who made the change, empty if unknown
+//   - At: when the change was made
+//   - Changes: the fields that changed, in Task field order
+//   - RestoredRevision: for restores, the revision the task was restored to
+type HistoryEntry struct {
+	TaskID           string        `json:"task_id"`
+	Revision         int64         `json:"revision"`
+	Version          int64         `json:"version,omitempty"`
+	Action           HistoryAction `json:"action"`
+	Actor            string        `json:"actor,omitempty"`
+	At               time.Time     `json:"at"`
+	Changes          []FieldChange `json:"changes,omitempty"`
+	RestoredRevision int64         `json:"restored_revision,omitempty"`
+}
+
+// FieldChange is the change of one task field, with the JSON values before
+// and after. A value is null when the field was unset.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Clone returns a copy of the entry that shares no mutable state with e.
+func (e *HistoryEntry) Clone() *HistoryEntry {
+	c := *e
+	c.Changes = slices.Clone(e.Changes)
+	for i, fc := range c.Changes {
+		c.Changes[i].Before = bytes.Clone(fc.Before)
+		c.Changes[i].After = bytes.Clone(fc.After)
+	}
+	return &c
+}
+
+// trackedFields are the JSON names of the task fields recorded in history. The
+// ID, version and timestamps are bookkeeping and not tracked.
+var trackedFields = func() []string {
+	var names []string
+	typ := reflect.TypeOf(Task{})
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		switch name {
+		case "", "-", "id", "version", "created_at", "updated_at":
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}()
+
+var jsonNull = json.RawMessage("null")
+
+// DiffTasks returns the tracked fields that differ between before and after.
+// A nil task has every field unset, so DiffTasks(nil, t) lists t's fields.
+func DiffTasks(before, after *Task) ([]FieldChange, error) {
+	b, err := taskFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := taskFields(after)
+	if err != nil {
+		return nil, err
+	}
+	var changes []FieldChange
+	for _, name := range trackedFields {
+		if !bytes.Equal(b[name], a[name]) {
+			changes = append(changes, FieldChange{Field: name, Before: orNull(b[name]), After: orNull(a[name])})
+		}
+	}
+	return changes, nil
+}
+
+// RevertChanges returns a copy of t with entries undone, newest first, by
+// setting every changed field back to its value before the change. ID, version
+// and timestamps are kept.
+func (t *Task) RevertChanges(entries []*HistoryEntry) (*Task, error) {
+	fields, err := taskFields(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, fc := range entries[i].Changes {
+			if bytes.Equal(fc.Before, jsonNull) {
+				delete(fields, fc.Field)
+			} else {
+				fields[fc.Field] = fc.Before
+			}
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var reverted Task
+	if err := json.Unmarshal(data, &reverted); err != nil {
+		return nil, fmt.Errorf("revert task %s: %w", t.ID, err)
+	}
+	return &reverted, nil
+}
+
+// taskFields returns the JSON encoding of each set field of t. Dates are
+// compared in UTC, so that storing an instant with another offset is no change.
+func taskFields(t *Task) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if t == nil {
+		return fields, nil
+	}
+	t = t.Clone()
+	for _, at := range []*time.Time{t.StartAt, t.DueAt} {
+		if at != nil {
+			*at = at.UTC()
+		}
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func orNull(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return jsonNull
+	}
+	return v
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTasks(t *testing.T) {
+	due := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := &Task{ID: "t", Title: "Old", Status: StatusTodo, Labels: []string{"a"}, Version: 1}
+	after := &Task{ID: "t", Title: "New", Status: StatusTodo, DueAt: &due, Version: 2, UpdatedAt: time.Now()}
+
+	changes, err := DiffTasks(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Field: "title", Before: json.RawMessage(`"Old"`), After: json.RawMessage(`"New"`)},
+		{Field: "due_at", Before: jsonNull, After: json.RawMessage(`"2025-05-01T12:00:00Z"`)},
+		{Field: "labels", Before: json.RawMessage(`["a"]`), After: jsonNull},
+	}, changes, "version and timestamps are not tracked")
+
+	berlin := time.FixedZone("CEST", 2*60*60)
+	moved := after.Clone()
+	moved.DueAt = new(time.Time)
+	*moved.DueAt = due.In(berlin)
+	changes, err = DiffTasks(after, moved)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "the same instant in another zone is no change")
+
+	changes, err = DiffTasks(nil, before)
+	require.NoError(t, err)
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		fields[i] = c.Field
+		assert.Equal(t, jsonNull, c.Before)
+	}
+	assert.Equal(t, []string{"title", "status", "completed", "labels"}, fields)
+}
+
+func TestTask_RevertChanges(t *testing.T) {
+	due := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	v1 := &Task{ID: "t", Title: "First", Status: StatusTodo}
+	v2 := &Task{ID: "t", Title: "Second", Status: StatusTodo, DueAt: &due}
+	v3 := &Task{ID: "t", Title: "Third", Status: StatusDone, Completed: true, DueAt: &due, Version: 3}
+
+	var entries []*HistoryEntry
+	for _, pair := range [][2]*Task{{v1, v2}, {v2, v3}} {
+		changes, err := DiffTasks(pair[0], pair[1])
+		require.NoError(t, err)
+		entries = append(entries, &HistoryEntry{Action: HistoryUpdate, Changes: changes})
+	}
+
+	reverted, err := v3.RevertChanges(entries)
+	require.NoError(t, err)
+	assert.Equal(t, "First", reverted.Title)
+	assert.Nil(t, reverted.DueAt, "a field unset before is removed")
+	assert.False(t, reverted.Completed)
+	assert.Equal(t, "t", reverted.ID)
+
+	reverted, err = v3.RevertChanges(entries[1:])
+	require.NoError(t, err)
+	assert.Equal(t, "Second", reverted.Title)
+	assert.Equal(t, StatusTodo, reverted.Status)
+	require.NotNil(t, reverted.DueAt)
+	assert.True(t, due.Equal(*reverted.DueAt))
+	assert.Equal(t, "Third", v3.Title, "the receiver is not modified")
+}
+
+func TestHistoryEntry_Clone(t *testing.T) {
+	e := &HistoryEntry{TaskID: "t", Changes: []FieldChange{{Field: "title", Before: json.RawMessage(`"a"`), After: json.RawMessage(`"b"`)}}}
+	c := e.Clone()
+	c.Changes[0].Before[1] = 'z'
+	c.Changes[0].Field = "status"
+	assert.Equal(t, `"a"`, string(e.Changes[0].Before))
+	assert.Equal(t, "title", e.Changes[0].Field)
+}
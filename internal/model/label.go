@@ -0,0 +1,94 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+)
+
+// Label categorises tasks, e.g. by area or customer. Labels are identified by
+// their name, which tasks reference in Task.Labels.
+//
+// Fields:
+//   - Name: required, 1-50 characters: letters, digits, space and - _ . :
+//   - Color: "#rrggbb"
+//   - CreatedAt: timestamp when the label was created
+//   - UpdatedAt: timestamp when the label was last updated
+type Label struct {
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultLabelColor is used for labels created without a color.
+const DefaultLabelColor = "#808080"
+
+// MaxTaskLabels is the largest number of labels a task can carry.
+const MaxTaskLabels = 20
+
+// Clone returns a copy of the label.
+func (l *Label) Clone() *Label {
+	c := *l
+	return &c
+}
+
+// Validate checks the label fields for correctness.
+func (l *Label) Validate() error {
+	var fields []apperror.FieldError
+	if err := ValidateLabelName(l.Name); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: err.Error()})
+	}
+	if !validColor(l.Color) {
+		fields = append(fields, apperror.FieldError{Field: "color", Message: "color must be of the form #rrggbb"})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
+
+// ValidateLabelName checks that name is a well-formed label name.
+func ValidateLabelName(name string) error {
+	if name == "" || strings.TrimSpace(name) != name {
+		return apperror.InvalidField("name", "label name is required and cannot start or end with a space")
+	}
+	if len(name) > 50 {
+		return apperror.InvalidField("name", "label name must be at most 50 characters")
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune(" -_.:", c)) {
+			return apperror.InvalidField("name", "label name may only contain letters, digits, spaces and - _ . :")
+		}
+	}
+	return nil
+}
+
+func validColor(color string) bool {
+	if len(color) != 7 || color[0] != '#' {
+		return false
+	}
+	for _, c := range color[1:] {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeLabels sorts label names and removes duplicates, so tasks compare
+// and store their labels canonically.
+func NormalizeLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := slices.Clone(labels)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// HasLabel reports whether the task carries the named label.
+func (t *Task) HasLabel(name string) bool {
+	return slices.Contains(t.Labels, name)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelValidation(t *testing.T) {
+	assert.NoError(t, (&Label{Name: "team: backend-1.x", Color: "#A0b1C2"}).Validate())
+
+	err := (&Label{Name: "", Color: "red"}).Validate()
+	fields := apperror.FieldsOf(err)
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, "name", fields[0].Field)
+		assert.Equal(t, "color", fields[1].Field)
+	}
+}
+
+func TestValidateLabelName(t *testing.T) {
+	for _, name := range []string{"", " leading", "trailing ", "semi;colon", "émoji", strings.Repeat("x", 51)} {
+		assert.Error(t, ValidateLabelName(name), "name %q", name)
+	}
+	assert.NoError(t, ValidateLabelName(strings.Repeat("x", 50)))
+}
+
+func TestNormalizeLabels(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, NormalizeLabels([]string{"c", "a", "b", "a"}))
+	assert.Nil(t, NormalizeLabels([]string{}))
+	in := []string{"b", "a"}
+	NormalizeLabels(in)
+	assert.Equal(t, []string{"b", "a"}, in, "the input must not be modified")
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+	"unicode/utf8"
+)
+
+// Project groups the tasks of a team. Tasks outside any project have an empty
+// Task.ProjectID.
+//
+// Fields:
+//   - ID: required, 1-64 characters: letters, digits and - _ .
+//   - Name: required, at most 100 characters
+//   - Description: optional, at most 1000 characters
+//   - Settings: how the project's tasks behave, see ProjectSettings
+//   - LastTaskNumber: the number in the last task ID generated with the ID prefix; set by the repository
+//   - CreatedAt: timestamp when the project was created
+//   - UpdatedAt: timestamp when the project was last updated
+type Project struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Settings       ProjectSettings `json:"settings"`
+	LastTaskNumber int64           `json:"last_task_number"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// ProjectSettings configure the tasks of a project.
+//
+// Fields:
+//   - Workflow: the state machine of the project's task statuses; nil for the service's default workflow
+//   - Labels: the labels the project's tasks may carry, sorted; empty allows every label
+//   - IDPrefix: optional, 1-10 letters and digits starting with a letter; new
+//     tasks without an ID are numbered PREFIX-1, PREFIX-2, ... instead of getting a UUID
+type ProjectSettings struct {
+	Workflow *Workflow `json:"workflow,omitempty"`
+	Labels   []string  `json:"labels,omitempty"`
+	IDPrefix string    `json:"id_prefix,omitempty"`
+}
+
+// Clone returns a copy of the project that shares no mutable state with p.
+func (p *Project) Clone() *Project {
+	c := *p
+	c.Settings.Labels = slices.Clone(p.Settings.Labels)
+	if p.Settings.Workflow != nil {
+		c.Settings.Workflow = p.Settings.Workflow.Clone()
+	}
+	return &c
+}
+
+// Validate checks the project fields for correctness.
+func (p *Project) Validate() error {
+	var fields []apperror.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+	if err := ValidateProjectID(p.ID); err != nil {
+		fields = append(fields, apperror.FieldsOf(err)...)
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		invalid("name", "name is required")
+	} else if utf8.RuneCountInString(p.Name) > 100 {
+		invalid("name", "name must be at most 100 characters")
+	}
+	if utf8.RuneCountInString(p.Description) > 1000 {
+		invalid("description", "description must be at most 1000 characters")
+	}
+	if wf := p.Settings.Workflow; wf != nil {
+		if err := wf.Validate(); err != nil {
+			invalid("settings.workflow", err.Error())
+		}
+	}
+	for _, name := range p.Settings.Labels {
+		if err := ValidateLabelName(name); err != nil {
+			invalid("settings.labels", err.Error())
+			break
+		}
+	}
+	if prefix := p.Settings.IDPrefix; prefix != "" {
+		if err := validateIDPrefix(prefix); err != nil {
+			invalid("settings.id_prefix", err.Error())
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
+
+// ValidateProjectID checks that id is a well-formed project ID.
+func ValidateProjectID(id string) error {
+	if id == "" {
+		return apperror.InvalidField("id", "project id is required")
+	}
+	if len(id) > 64 {
+		return apperror.InvalidField("id", "project id must be at most 64 characters")
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.", c)) {
+			return apperror.InvalidField("id", "project id may only contain letters, digits and - _ .")
+		}
+	}
+	return nil
+}
+
+// validateIDPrefix checks a task ID prefix. Numbered IDs of up to 25 digits
+// then stay within the 36 characters of a task ID.
+func validateIDPrefix(prefix string) error {
+	if len(prefix) > 10 {
+		return fmt.Errorf("id_prefix must be at most 10 characters")
+	}
+	for i, c := range prefix {
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return fmt.Errorf("id_prefix must be letters and digits, starting with a letter")
+		}
+	}
+	return nil
+}
+
+// TaskID returns the ID of the project's task numbered n.
+func (s *ProjectSettings) TaskID(n int64) string {
+	return fmt.Sprintf("%s-%d", s.IDPrefix, n)
+}
+
+// AllowsLabel reports whether the project's tasks may carry the label name.
+func (s *ProjectSettings) AllowsLabel(name string) bool {
+	if len(s.Labels) == 0 {
+		return true
+	}
+	return slices.Contains(s.Labels, name)
+}
+
+// ProjectPatch is a partial update of a project; nil fields are left unchanged.
+// Settings are replaced as a whole.
+type ProjectPatch struct {
+	Name        *string          `json:"name"`
+	Description *string          `json:"description"`
+	Settings    *ProjectSettings `json:"settings"`
+}
+
+// Apply sets the patched fields on p.
+func (patch *ProjectPatch) Apply(p *Project) {
+	if patch.Name != nil {
+		p.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		p.Description = *patch.Description
+	}
+	if patch.Settings != nil {
+		p.Settings = *patch.Settings
+	}
+}
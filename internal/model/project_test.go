@@ -0,0 +1,76 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectValidation(t *testing.T) {
+	assert.NoError(t, (&Project{ID: "web.app-1_x", Name: "Website"}).Validate())
+	assert.NoError(t, (&Project{ID: "api", Name: "API", Settings: ProjectSettings{
+		Workflow: DefaultWorkflow(), Labels: []string{"bug"}, IDPrefix: "API2",
+	}}).Validate())
+
+	err := (&Project{ID: "a b", Name: " ", Description: strings.Repeat("x", 1001), Settings: ProjectSettings{
+		Workflow: &Workflow{States: []string{"open"}},
+		Labels:   []string{"Not A Label!"},
+		IDPrefix: "1X",
+	}}).Validate()
+	fields := apperror.FieldsOf(err)
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Field)
+	}
+	assert.Equal(t, []string{"id", "name", "description", "settings.workflow", "settings.labels", "settings.id_prefix"}, names)
+	assert.Error(t, (&Project{ID: "web", Name: strings.Repeat("x", 101)}).Validate())
+}
+
+func TestValidateIDPrefix(t *testing.T) {
+	for _, prefix := range []string{"A", "web", "X2Y", "ABCDEFGHIJ"} {
+		assert.NoError(t, validateIDPrefix(prefix), "prefix %q", prefix)
+	}
+	for _, prefix := range []string{"2X", "A-B", "ÄB", "ABCDEFGHIJK"} {
+		assert.Error(t, validateIDPrefix(prefix), "prefix %q", prefix)
+	}
+}
+
+func TestProjectSettings(t *testing.T) {
+	settings := &ProjectSettings{IDPrefix: "WEB"}
+	assert.Equal(t, "WEB-42", settings.TaskID(42))
+	assert.True(t, settings.AllowsLabel("anything"), "no labels allow every label")
+	settings.Labels = []string{"bug", "feature"}
+	assert.True(t, settings.AllowsLabel("bug"))
+	assert.False(t, settings.AllowsLabel("chore"))
+}
+
+func TestProject_Clone(t *testing.T) {
+	p := &Project{ID: "web", Settings: ProjectSettings{Workflow: DefaultWorkflow(), Labels: []string{"bug"}}}
+	c := p.Clone()
+	c.Settings.Labels[0] = "changed"
+	c.Settings.Workflow.States[0] = "changed"
+	assert.Equal(t, "bug", p.Settings.Labels[0])
+	assert.NotEqual(t, "changed", p.Settings.Workflow.States[0])
+}
+
+func TestProjectPatch_Apply(t *testing.T) {
+	project := &Project{ID: "web", Name: "Web", Description: "Site", Settings: ProjectSettings{IDPrefix: "WEB"}}
+	name := "Website"
+	(&ProjectPatch{Name: &name}).Apply(project)
+	assert.Equal(t, "Website", project.Name)
+	assert.Equal(t, "Site", project.Description)
+	assert.Equal(t, "WEB", project.Settings.IDPrefix)
+	(&ProjectPatch{Settings: &ProjectSettings{Labels: []string{"bug"}}}).Apply(project)
+	assert.Empty(t, project.Settings.IDPrefix, "settings are replaced as a whole")
+	assert.Equal(t, []string{"bug"}, project.Settings.Labels)
+}
+
+func TestTaskValidation_ProjectID(t *testing.T) {
+	task := &Task{ID: "a", Title: "A", ProjectID: "web"}
+	assert.NoError(t, task.Validate())
+	task.ProjectID = "not a project"
+	assert.Equal(t, "project_id", apperror.FieldsOf(task.Validate())[0].Field)
+}
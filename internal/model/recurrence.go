@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Recurrence makes a task repeat. Completing an occurrence schedules the next
+// one as a new task; see Task.NextOccurrence.
+//
+// Fields:
+//   - Rule: RFC 5545 RRULE value, e.g. "FREQ=WEEKLY;BYDAY=MO,TH"; see RRule
+//   - TZ: IANA time zone the rule is evaluated in (default UTC), so that a 9:00
+//     occurrence stays at 9:00 local time across daylight saving changes
+//   - Occurrence: position of this task in the series, starting at 1; set by the service
+//   - NextID: ID of the task created for the next occurrence; set by the service
+type Recurrence struct {
+	Rule       string `json:"rule"`
+	TZ         string `json:"tz,omitempty"`
+	Occurrence int    `json:"occurrence,omitempty"`
+	NextID     string `json:"next_id,omitempty"`
+}
+
+// Occurrence is the schedule of one occurrence of a recurring task.
+type Occurrence struct {
+	StartAt *time.Time `json:"start_at,omitempty"`
+	DueAt   *time.Time `json:"due_at,omitempty"`
+}
+
+// MaxOccurrencesPreview bounds Task.UpcomingOccurrences.
+const MaxOccurrencesPreview = 100
+
+// Validate checks that the rule parses and the time zone exists.
+func (r *Recurrence) Validate() error {
+	if _, err := ParseRRule(r.Rule); err != nil {
+		return fmt.Errorf("invalid recurrence rule: %v", err)
+	}
+	if _, err := r.location(); err != nil {
+		return fmt.Errorf("unknown recurrence time zone %q", r.TZ)
+	}
+	if r.Occurrence < 0 {
+		return fmt.Errorf("recurrence occurrence must not be negative")
+	}
+	return nil
+}
+
+func (r *Recurrence) location() (*time.Location, error) {
+	if r.TZ == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(r.TZ)
+}
+
+// ReplaceRecurrence returns the recurrence of a task whose recurrence old is
+// replaced by r. The series bookkeeping, Occurrence and NextID, belongs to the
+// server and is carried over from old.
+func ReplaceRecurrence(old, r *Recurrence) *Recurrence {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Occurrence, c.NextID = 1, ""
+	if old != nil {
+		c.Occurrence, c.NextID = old.Occurrence, old.NextID
+	}
+	return &c
+}
+
+// NextOccurrence returns a new task for the occurrence after t, or nil if the
+// series ends with t. The copy keeps t's content, labels and parent and has
+// its dates moved to the next occurrence; ID, status and timestamps are left
+// for the service to set.
+func (t *Task) NextOccurrence() (*Task, error) {
+	next, err := t.UpcomingOccurrences(1)
+	if err != nil || len(next) == 0 {
+		return nil, err
+	}
+	c := t.Clone()
+	c.ID, c.Status, c.Completed, c.Version = "", "", false, 0
+	c.CreatedAt, c.UpdatedAt = time.Time{}, time.Time{}
+	c.StartAt, c.DueAt = next[0].StartAt, next[0].DueAt
+	c.Recurrence = &Recurrence{Rule: t.Recurrence.Rule, TZ: t.Recurrence.TZ, Occurrence: t.occurrence() + 1}
+	return c, nil
+}
+
+// UpcomingOccurrences returns up to n occurrences after t's own. The series is
+// anchored on t's due date, or its start date if it has none; the other date
+// keeps its distance to the anchor. COUNT includes the occurrences before t.
+func (t *Task) UpcomingOccurrences(n int) ([]Occurrence, error) {
+	if t.Recurrence == nil || n <= 0 {
+		return nil, nil
+	}
+	rule, err := ParseRRule(t.Recurrence.Rule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := t.Recurrence.location()
+	if err != nil {
+		return nil, err
+	}
+	anchor := t.DueAt
+	if anchor == nil {
+		anchor = t.StartAt
+	}
+	if anchor == nil {
+		return nil, nil
+	}
+	if rule.Count > 0 {
+		// Count from t, which is the first occurrence the iteration yields.
+		rule.Count -= t.occurrence() - 1
+		if rule.Count <= 0 {
+			return nil, nil
+		}
+	}
+	var out []Occurrence
+	for at := range rule.All(anchor.In(loc)) {
+		if at.Equal(*anchor) {
+			continue
+		}
+		at = at.UTC()
+		o := Occurrence{DueAt: &at}
+		switch {
+		case t.DueAt == nil:
+			o = Occurrence{StartAt: &at}
+		case t.StartAt != nil:
+			start := at.Add(t.StartAt.Sub(*t.DueAt))
+			o.StartAt = &start
+		}
+		if out = append(out, o); len(out) == n {
+			break
+		}
+	}
+	return out, nil
+}
+
+// occurrence is t's position in its series; tasks created before the series
+// was numbered count as the first.
+func (t *Task) occurrence() int {
+	return max(t.Recurrence.Occurrence, 1)
+}
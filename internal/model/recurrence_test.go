@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrence_Validate(t *testing.T) {
+	assert.NoError(t, (&Recurrence{Rule: "FREQ=DAILY", TZ: "America/New_York"}).Validate())
+	assert.ErrorContains(t, (&Recurrence{Rule: "FREQ=SOMETIMES"}).Validate(), "invalid recurrence rule")
+	assert.ErrorContains(t, (&Recurrence{Rule: "FREQ=DAILY", TZ: "Mars/Olympus"}).Validate(), "unknown recurrence time zone")
+
+	task := &Task{ID: "t", Title: "T", Recurrence: &Recurrence{Rule: "FREQ=DAILY"}}
+	assert.ErrorContains(t, task.Validate(), "needs a start_at or due_at")
+}
+
+func TestReplaceRecurrence(t *testing.T) {
+	assert.Nil(t, ReplaceRecurrence(&Recurrence{Rule: "FREQ=DAILY"}, nil))
+	assert.Equal(t, &Recurrence{Rule: "FREQ=DAILY", Occurrence: 1}, ReplaceRecurrence(nil, &Recurrence{Rule: "FREQ=DAILY", NextID: "x"}))
+}
+
+func TestTask_NextOccurrence(t *testing.T) {
+	start := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 1, 6, 17, 0, 0, 0, time.UTC)
+	task := &Task{
+		ID: "t", Title: "Standup notes", Status: StatusDone, Completed: true, Version: 3, Labels: []string{"team"},
+		StartAt: &start, DueAt: &due,
+		Recurrence: &Recurrence{Rule: "FREQ=WEEKLY;BYDAY=MO,TH", Occurrence: 2, NextID: "old"},
+	}
+
+	next, err := task.NextOccurrence()
+	require.NoError(t, err)
+	assert.Empty(t, next.ID)
+	assert.Empty(t, next.Status)
+	assert.False(t, next.Completed)
+	assert.Equal(t, []string{"team"}, next.Labels)
+	assert.Equal(t, time.Date(2025, 1, 9, 8, 0, 0, 0, time.UTC), *next.StartAt)
+	assert.Equal(t, time.Date(2025, 1, 9, 17, 0, 0, 0, time.UTC), *next.DueAt)
+	assert.Equal(t, &Recurrence{Rule: "FREQ=WEEKLY;BYDAY=MO,TH", Occurrence: 3}, next.Recurrence)
+}
+
+func TestTask_UpcomingOccurrences(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// 09:00 in Berlin, before the switch to summer time on 2025-03-30.
+	start := time.Date(2025, 3, 28, 8, 0, 0, 0, time.UTC)
+	task := &Task{StartAt: &start, Recurrence: &Recurrence{Rule: "FREQ=DAILY;COUNT=4", TZ: "Europe/Berlin", Occurrence: 2}}
+
+	upcoming, err := task.UpcomingOccurrences(10)
+	require.NoError(t, err)
+	require.Len(t, upcoming, 2, "COUNT includes the earlier occurrences")
+	for _, o := range upcoming {
+		assert.Nil(t, o.DueAt)
+		assert.Equal(t, 9, o.StartAt.In(berlin).Hour())
+	}
+	assert.Equal(t, time.Date(2025, 3, 30, 7, 0, 0, 0, time.UTC), *upcoming[1].StartAt)
+
+	task.Recurrence.Occurrence = 4
+	next, err := task.NextOccurrence()
+	require.NoError(t, err)
+	assert.Nil(t, next, "the series ends after COUNT occurrences")
+}
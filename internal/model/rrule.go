@@ -0,0 +1,376 @@
+package model
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ of a recurrence rule.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry: every Day of the period, or only the Nth one
+// when N is non-zero, counting from the end of the period when N is negative.
+// "2MO" is the second Monday of the month in a monthly rule, "-1FR" the last
+// Friday.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// RRule is a parsed RFC 5545 recurrence rule. The FREQ, INTERVAL, BYDAY,
+// BYMONTHDAY, COUNT, UNTIL and WKST parts are supported, with DAILY, WEEKLY,
+// MONTHLY and YEARLY frequencies.
+type RRule struct {
+	Freq Frequency
+	// Interval is the number of periods between occurrences, at least 1.
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	// Count limits the number of occurrences, DTSTART included; zero means no limit.
+	Count int
+	// Until bounds the occurrences, inclusively; zero means no bound.
+	Until     time.Time
+	untilKind untilKind
+	// WeekStart is the first day of the week for weekly rules; Monday by default.
+	WeekStart time.Weekday
+}
+
+// untilKind records which of RFC 5545's forms UNTIL was given in.
+type untilKind int
+
+const (
+	untilUTC      untilKind = iota // 20250131T090000Z
+	untilFloating                  // 20250131T090000, in the rule's time zone
+	untilDate                      // 20250131, the whole day in the rule's time zone
+)
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// maxPeriods bounds how many periods are searched for occurrences, so rules
+// that rarely or never match, like the 31st of every 12th month from
+// February, still end.
+const maxPeriods = 10000
+
+// ParseRRule parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR".
+// An "RRULE:" prefix is accepted.
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("rule is empty")
+	}
+	r := &RRule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("malformed rule part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is given twice", name)
+		}
+		seen[name] = true
+		var err error
+		switch name {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(value)); f {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = f
+			default:
+				err = fmt.Errorf("unsupported FREQ %q (want DAILY, WEEKLY, MONTHLY or YEARLY)", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = parseRulePositive(name, value)
+		case "COUNT":
+			r.Count, err = parseRulePositive(name, value)
+		case "UNTIL":
+			err = r.parseUntil(value)
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				wd, perr := parseWeekdayNum(v)
+				if perr != nil {
+					return nil, perr
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				d, perr := strconv.Atoi(v)
+				if perr != nil || d == 0 || d < -31 || d > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q (want 1 to 31 or -31 to -1)", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, d)
+			}
+		case "WKST":
+			i := slices.Index(weekdayCodes, strings.ToUpper(value))
+			if i < 0 {
+				err = fmt.Errorf("invalid WKST %q", value)
+			}
+			r.WeekStart = time.Weekday(i)
+		default:
+			err = fmt.Errorf("unsupported rule part %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := r.check(seen); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// check enforces the constraints RFC 5545 places between rule parts.
+func (r *RRule) check(seen map[string]bool) error {
+	switch {
+	case r.Freq == "":
+		return fmt.Errorf("FREQ is required")
+	case seen["COUNT"] && seen["UNTIL"]:
+		return fmt.Errorf("COUNT and UNTIL cannot both be given")
+	case r.Freq == FreqWeekly && len(r.ByMonthDay) > 0:
+		return fmt.Errorf("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	for _, wd := range r.ByDay {
+		switch {
+		case wd.N == 0:
+		case r.Freq != FreqMonthly && r.Freq != FreqYearly:
+			return fmt.Errorf("numbered BYDAY values need FREQ=MONTHLY or FREQ=YEARLY")
+		case r.Freq == FreqMonthly && (wd.N < -5 || wd.N > 5):
+			return fmt.Errorf("BYDAY number %d is out of range for a month", wd.N)
+		}
+	}
+	return nil
+}
+
+func parseRulePositive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+// parseWeekdayNum parses a BYDAY value such as "MO", "+2TU" or "-1FR".
+func parseWeekdayNum(v string) (WeekdayNum, error) {
+	v = strings.ToUpper(v)
+	if len(v) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+	day := slices.Index(weekdayCodes, v[len(v)-2:])
+	if day < 0 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+	}
+	wd := WeekdayNum{Day: time.Weekday(day)}
+	if num := v[:len(v)-2]; num != "" {
+		n, err := strconv.Atoi(num)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", v)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+func (r *RRule) parseUntil(v string) error {
+	var err error
+	switch {
+	case strings.HasSuffix(v, "Z"):
+		r.Until, err = time.Parse("20060102T150405Z", v)
+		r.untilKind = untilUTC
+	case strings.Contains(v, "T"):
+		r.Until, err = time.Parse("20060102T150405", v)
+		r.untilKind = untilFloating
+	default:
+		r.Until, err = time.Parse("20060102", v)
+		r.untilKind = untilDate
+	}
+	if err != nil {
+		return fmt.Errorf("invalid UNTIL %q (want YYYYMMDD, YYYYMMDDTHHMMSS or YYYYMMDDTHHMMSSZ)", v)
+	}
+	return nil
+}
+
+// String formats the rule in canonical part order.
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = weekdayCodes[wd.Day]
+			if wd.N != 0 {
+				days[i] = strconv.Itoa(wd.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		layout := map[untilKind]string{untilUTC: "20060102T150405Z", untilFloating: "20060102T150405", untilDate: "20060102"}[r.untilKind]
+		parts = append(parts, "UNTIL="+r.Until.Format(layout))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// All returns the occurrences of the rule starting at dtstart, in order. As
+// in RFC 5545, dtstart is always the first occurrence. Occurrences keep
+// dtstart's wall-clock time in its location, across daylight saving changes.
+func (r *RRule) All(dtstart time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		until := r.until(dtstart.Location())
+		count := 0
+		emit := func(t time.Time) bool {
+			if !until.IsZero() && t.After(until) {
+				return false
+			}
+			count++
+			return yield(t) && (r.Count == 0 || count < r.Count)
+		}
+		if !emit(dtstart) {
+			return
+		}
+		for p := 0; p < maxPeriods; p++ {
+			for _, t := range r.candidates(dtstart, p) {
+				if t.After(dtstart) && !emit(t) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// until returns the inclusive bound of the rule in loc, or the zero time.
+func (r *RRule) until(loc *time.Location) time.Time {
+	u := r.Until
+	switch {
+	case u.IsZero() || r.untilKind == untilUTC:
+		return u
+	case r.untilKind == untilDate:
+		return time.Date(u.Year(), u.Month(), u.Day(), 23, 59, 59, 999999999, loc)
+	default:
+		return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+	}
+}
+
+// candidates returns the occurrences in the p-th period counted from dtstart's,
+// including any before dtstart, in order.
+func (r *RRule) candidates(dtstart time.Time, p int) []time.Time {
+	y, m, d := dtstart.Date()
+	step := p * r.Interval
+	var first time.Time
+	var days int
+	switch r.Freq {
+	case FreqDaily:
+		first, days = civilDate(y, m, d+step), 1
+	case FreqWeekly:
+		back := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		first, days = civilDate(y, m, d-back+7*step), 7
+	case FreqMonthly:
+		first = civilDate(y, m+time.Month(step), 1)
+		days = daysIn(first.Year(), first.Month())
+	case FreqYearly:
+		first = civilDate(y+step, time.January, 1)
+		days = civilDate(y+step, time.December, 31).YearDay()
+	}
+	hh, mm, ss := dtstart.Clock()
+	var out []time.Time
+	for i := 0; i < days; i++ {
+		day := first.AddDate(0, 0, i)
+		if r.matches(day, dtstart) {
+			out = append(out, time.Date(day.Year(), day.Month(), day.Day(), hh, mm, ss, dtstart.Nanosecond(), dtstart.Location()))
+		}
+	}
+	return out
+}
+
+// matches reports whether the civil date day is an occurrence date. Without
+// BYDAY or BYMONTHDAY the rule repeats dtstart's weekday, day of the month or
+// date, depending on the frequency.
+func (r *RRule) matches(day, dtstart time.Time) bool {
+	if len(r.ByMonthDay) > 0 && !monthDayMatches(day, r.ByMonthDay) {
+		return false
+	}
+	if len(r.ByDay) > 0 {
+		return r.byDayMatches(day)
+	}
+	if len(r.ByMonthDay) > 0 {
+		return true
+	}
+	switch r.Freq {
+	case FreqWeekly:
+		return day.Weekday() == dtstart.Weekday()
+	case FreqMonthly:
+		return day.Day() == dtstart.Day()
+	case FreqYearly:
+		return day.Month() == dtstart.Month() && day.Day() == dtstart.Day()
+	}
+	return true
+}
+
+// byDayMatches reports whether day is one of the BYDAY weekdays. Numbered
+// entries count within the month, or within the year for yearly rules.
+func (r *RRule) byDayMatches(day time.Time) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day != day.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+		idx, total := day.Day()-1, daysIn(day.Year(), day.Month())
+		if r.Freq == FreqYearly {
+			idx, total = day.YearDay()-1, civilDate(day.Year(), time.December, 31).YearDay()
+		}
+		if wd.N > 0 && idx/7+1 == wd.N || wd.N < 0 && (total-1-idx)/7+1 == -wd.N {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDayMatches reports whether day is one of days, where negative days
+// count back from the end of the month.
+func monthDayMatches(day time.Time, days []int) bool {
+	n := daysIn(day.Year(), day.Month())
+	for _, d := range days {
+		if d == day.Day() || d < 0 && n+d+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// civilDate returns midnight UTC of the given date, normalizing out of range
+// months and days. Day arithmetic on it is free of time zone effects.
+func civilDate(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func daysIn(y int, m time.Month) int {
+	return civilDate(y, m+1, 0).Day()
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// firstN returns the first n occurrences of rule from dtstart, formatted in
+// dtstart's location.
+func firstN(t *testing.T, rule string, dtstart time.Time, n int) []string {
+	t.Helper()
+	r, err := ParseRRule(rule)
+	require.NoError(t, err)
+	var out []string
+	for at := range r.All(dtstart) {
+		out = append(out, at.Format("2006-01-02 15:04 Mon"))
+		if len(out) == n {
+			break
+		}
+	}
+	return out
+}
+
+func TestParseRRule(t *testing.T) {
+	r, err := ParseRRule("RRULE:freq=monthly;INTERVAL=2;BYDAY=-1FR,2MO;COUNT=5;WKST=SU")
+	require.NoError(t, err)
+	assert.Equal(t, FreqMonthly, r.Freq)
+	assert.Equal(t, 2, r.Interval)
+	assert.Equal(t, []WeekdayNum{{N: -1, Day: time.Friday}, {N: 2, Day: time.Monday}}, r.ByDay)
+	assert.Equal(t, 5, r.Count)
+	assert.Equal(t, time.Sunday, r.WeekStart)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR,2MO;COUNT=5;WKST=SU", r.String())
+
+	for _, rule := range []string{"FREQ=DAILY;UNTIL=20250131", "FREQ=WEEKLY;UNTIL=20250131T090000", "FREQ=YEARLY;BYMONTHDAY=1,-1;UNTIL=20250131T090000Z"} {
+		r, err := ParseRRule(rule)
+		require.NoError(t, err, rule)
+		assert.Equal(t, rule, r.String())
+	}
+}
+
+func TestParseRRule_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":                                  "empty",
+		"INTERVAL=2":                        "FREQ is required",
+		"FREQ=HOURLY":                       "unsupported FREQ",
+		"FREQ=DAILY;FREQ=WEEKLY":            "given twice",
+		"FREQ=DAILY;INTERVAL=0":             "INTERVAL must be a positive integer",
+		"FREQ=DAILY;COUNT=x":                "COUNT must be a positive integer",
+		"FREQ=DAILY;COUNT=2;UNTIL=2025010":  "invalid UNTIL",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101": "cannot both be given",
+		"FREQ=WEEKLY;BYDAY=XX":              "invalid BYDAY",
+		"FREQ=WEEKLY;BYDAY=1MO":             "numbered BYDAY",
+		"FREQ=MONTHLY;BYDAY=6MO":            "out of range",
+		"FREQ=MONTHLY;BYMONTHDAY=32":        "invalid BYMONTHDAY",
+		"FREQ=WEEKLY;BYMONTHDAY=1":          "cannot be used with FREQ=WEEKLY",
+		"FREQ=DAILY;BYHOUR=9":               "unsupported rule part BYHOUR",
+		"FREQ=DAILY;WKST=XX":                "invalid WKST",
+		"FREQ=DAILY;COUNT":                  "malformed",
+	}
+	for rule, want := range tests {
+		_, err := ParseRRule(rule)
+		assert.ErrorContains(t, err, want, rule)
+	}
+}
+
+func TestRRule_All(t *testing.T) {
+	// Wednesday, 2025-01-15 09:00 UTC.
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		want []string
+		ends bool // no occurrences after want
+	}{
+		{"FREQ=DAILY;INTERVAL=3", []string{"2025-01-15 09:00 Wed", "2025-01-18 09:00 Sat", "2025-01-21 09:00 Tue"}, false},
+		{"FREQ=DAILY;BYDAY=MO,FR", []string{"2025-01-15 09:00 Wed", "2025-01-17 09:00 Fri", "2025-01-20 09:00 Mon"}, false},
+		{"FREQ=WEEKLY", []string{"2025-01-15 09:00 Wed", "2025-01-22 09:00 Wed", "2025-01-29 09:00 Wed"}, false},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", []string{"2025-01-15 09:00 Wed", "2025-01-16 09:00 Thu", "2025-01-20 09:00 Mon", "2025-01-23 09:00 Thu"}, false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", []string{"2025-01-15 09:00 Wed", "2025-01-27 09:00 Mon", "2025-02-10 09:00 Mon"}, false},
+		{"FREQ=MONTHLY", []string{"2025-01-15 09:00 Wed", "2025-02-15 09:00 Sat", "2025-03-15 09:00 Sat"}, false},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", []string{"2025-01-15 09:00 Wed", "2025-01-31 09:00 Fri", "2025-02-28 09:00 Fri", "2025-03-31 09:00 Mon"}, false},
+		{"FREQ=MONTHLY;BYDAY=2MO", []string{"2025-01-15 09:00 Wed", "2025-02-10 09:00 Mon", "2025-03-10 09:00 Mon"}, false},
+		{"FREQ=MONTHLY;BYDAY=-1FR", []string{"2025-01-15 09:00 Wed", "2025-01-31 09:00 Fri", "2025-02-28 09:00 Fri"}, false},
+		{"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", []string{"2025-01-15 09:00 Wed", "2025-06-13 09:00 Fri", "2026-02-13 09:00 Fri"}, false},
+		{"FREQ=YEARLY", []string{"2025-01-15 09:00 Wed", "2026-01-15 09:00 Thu", "2027-01-15 09:00 Fri"}, false},
+		{"FREQ=YEARLY;BYDAY=-1MO", []string{"2025-01-15 09:00 Wed", "2025-12-29 09:00 Mon", "2026-12-28 09:00 Mon"}, false},
+		{"FREQ=YEARLY;BYMONTHDAY=1", []string{"2025-01-15 09:00 Wed", "2025-02-01 09:00 Sat", "2025-03-01 09:00 Sat"}, false},
+		{"FREQ=DAILY;COUNT=2", []string{"2025-01-15 09:00 Wed", "2025-01-16 09:00 Thu"}, true},
+		{"FREQ=DAILY;UNTIL=20250117", []string{"2025-01-15 09:00 Wed", "2025-01-16 09:00 Thu", "2025-01-17 09:00 Fri"}, true},
+		{"FREQ=DAILY;UNTIL=20250117T085959Z", []string{"2025-01-15 09:00 Wed", "2025-01-16 09:00 Thu"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			n := len(tt.want)
+			if tt.ends {
+				n++
+			}
+			assert.Equal(t, tt.want, firstN(t, tt.rule, start, n))
+		})
+	}
+}
+
+func TestRRule_All_SkipsMissingDays(t *testing.T) {
+	start := time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-01-31 08:00 Wed", "2024-03-31 08:00 Sun", "2024-05-31 08:00 Fri"},
+		firstN(t, "FREQ=MONTHLY", start, 3))
+
+	leap := time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-02-29 08:00 Thu", "2028-02-29 08:00 Tue"}, firstN(t, "FREQ=YEARLY", leap, 2))
+
+	// Never matches after DTSTART: the search gives up instead of looping.
+	assert.Equal(t, []string{"2024-01-31 08:00 Wed"}, firstN(t, "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30;BYDAY=2MO", start, 2))
+}
+
+func TestRRule_All_KeepsLocalTimeAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	start := time.Date(2025, 3, 24, 9, 0, 0, 0, berlin)
+	assert.Equal(t, []string{"2025-03-24 09:00 Mon", "2025-03-31 09:00 Mon"}, firstN(t, "FREQ=WEEKLY", start, 2))
+
+	r, err := ParseRRule("FREQ=WEEKLY;UNTIL=20250331")
+	require.NoError(t, err)
+	var utc []string
+	for at := range r.All(start) {
+		utc = append(utc, at.UTC().Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2025-03-24T08:00:00Z", "2025-03-31T07:00:00Z"}, utc, "UNTIL dates are whole local days")
+}
@@ -2,8 +2,10 @@
 package model
 
 import (
-	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"taskmanager/internal/apperror"
 	"time"
 )
 
@@ -13,50 +15,211 @@ import (
 //   - ID: unique identifier (string, max 24 chars, alphanumeric/dash)
 //   - Title: required, 1-200 characters
 //   - Description: optional, max 1000 characters
-//   - Completed: boolean, required (default false)
+//   - Status: workflow state; set by the service, see workflow.go
+//   - Completed: derived from Status, true in the workflow's terminal states
 //   - CreatedAt: timestamp when task was created
 //   - UpdatedAt: timestamp when task was last updated
+//   - Priority: optional, one of low, medium, high, urgent
+//   - StartAt: optional time work on the task may start
+//   - DueAt: optional deadline, not before StartAt
+//   - Labels: names of attached labels, sorted, at most MaxTaskLabels
+//   - ParentID: optional ID of the parent task; see hierarchy.go
+//   - EstimateMinutes: optional estimated effort, at most MaxEstimateMinutes; see dependency.go
+//   - Recurrence: optional repeat schedule, needs StartAt or DueAt; see recurrence.go
+//   - Assignee: optional ID of the user working on the task; see user.go
+//   - CreatedBy: the actor that created the task, empty if unknown; set by the service
+//   - ProjectID: the project the task belongs to, empty for none; set by the service, see project.go
+//   - Rank: the task's position within its board column; set by the service, see board.go
+//   - DeletedAt: when the task was moved to the trash; nil for live tasks, set by the service
+//   - Version: incremented by the repository on every update, used for optimistic concurrency
 type Task struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description,omitempty"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	Completed   bool       `json:"completed"`
+	Priority    Priority   `json:"priority,omitempty"`
+	StartAt     *time.Time `json:"start_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Labels      []string   `json:"labels,omitempty"`
+	ParentID    string     `json:"parent_id,omitempty"`
+	// EstimateMinutes is zero when the task has no estimate.
+	EstimateMinutes int         `json:"estimate_minutes,omitempty"`
+	Recurrence      *Recurrence `json:"recurrence,omitempty"`
+	Assignee        string      `json:"assignee,omitempty"`
+	CreatedBy       string      `json:"created_by,omitempty"`
+	ProjectID       string      `json:"project_id,omitempty"`
+	Rank            string      `json:"rank,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	DeletedAt       *time.Time  `json:"deleted_at,omitempty"`
+	Version         int64       `json:"version"`
+}
+
+// reservedTaskIDs are the names of the task views served at /tasks/<name>,
+// which would shadow tasks of the same ID.
+var reservedTaskIDs = []string{
+	"search", "overdue", "due-today", "upcoming",
+	"order", "unblocked", "critical-path", "board",
+}
+
+// MaxEstimateMinutes bounds Task.EstimateMinutes to one year of effort.
+const MaxEstimateMinutes = 365 * 24 * 60
+
+// Priority is the urgency of a task. The zero value means no priority.
+type Priority string
+
+const (
+	PriorityNone   Priority = ""
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// priorities lists the priorities from least to most urgent.
+var priorities = []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Valid reports whether p is a known priority.
+func (p Priority) Valid() bool {
+	return p.Rank() >= 0
+}
+
+// Rank orders priorities: 0 for none up to 4 for urgent, -1 if p is unknown.
+func (p Priority) Rank() int {
+	for i, q := range priorities {
+		if p == q {
+			return i
+		}
+	}
+	return -1
+}
+
+// PriorityOfRank is the inverse of Priority.Rank; out of range ranks map to
+// PriorityNone.
+func PriorityOfRank(rank int) Priority {
+	if rank < 0 || rank >= len(priorities) {
+		return PriorityNone
+	}
+	return priorities[rank]
+}
+
+// Clone returns a copy of the task that shares no mutable state with t.
+func (t *Task) Clone() *Task {
+	c := *t
+	c.StartAt = cloneTime(t.StartAt)
+	c.DueAt = cloneTime(t.DueAt)
+	c.DeletedAt = cloneTime(t.DeletedAt)
+	c.Labels = slices.Clone(t.Labels)
+	if t.Recurrence != nil {
+		r := *t.Recurrence
+		c.Recurrence = &r
+	}
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// ValidateNewID checks the ID of a task about to be created, which must not be
+// the name of a task view. Validate does not, so that tasks created before a
+// view took their ID can still be changed.
+func (t *Task) ValidateNewID() error {
+	if id := strings.TrimSpace(t.ID); slices.Contains(reservedTaskIDs, id) {
+		return apperror.InvalidField("id", fmt.Sprintf("id %q is reserved for the view at /tasks/%s", id, id))
+	}
+	return nil
 }
 
 // Validate checks the Task fields for correctness according to business rules.
+// It reports every invalid field as an apperror validation error.
 func (t *Task) Validate() error {
+	var fields []apperror.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+
 	// Validate ID: non-empty, alphanumeric/dash, max 36 chars (UUID allowed)
 	id := strings.TrimSpace(t.ID)
-	if id == "" {
-		return errors.New("id is required")
-	}
-	if len(id) > 36 {
-		return errors.New("id must be at most 36 characters")
-	}
-	for _, c := range id {
-		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
-			return errors.New("id must be alphanumeric or dash")
+	switch {
+	case id == "":
+		invalid("id", "id is required")
+	case len(id) > 36:
+		invalid("id", "id must be at most 36 characters")
+	default:
+		for _, c := range id {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				invalid("id", "id must be alphanumeric or dash")
+				break
+			}
 		}
 	}
 
 	// Validate Title: required, 1-200 chars
 	title := strings.TrimSpace(t.Title)
 	if title == "" {
-		return errors.New("title is required")
-	}
-	if len(title) < 1 || len(title) > 200 {
-		return errors.New("title must be between 1 and 200 characters")
+		invalid("title", "title is required")
+	} else if len(title) < 1 || len(title) > 200 {
+		invalid("title", "title must be between 1 and 200 characters")
 	}
 
 	// Validate Description: optional, max 1000 chars
 	if len(t.Description) > 1000 {
-		return errors.New("description must be at most 1000 characters")
+		invalid("description", "description must be at most 1000 characters")
+	}
+
+	if !t.Priority.Valid() {
+		invalid("priority", "priority must be one of low, medium, high, urgent")
+	}
+
+	// Dates: optional, but a task cannot be due before it starts
+	if t.StartAt != nil && t.DueAt != nil && t.DueAt.Before(*t.StartAt) {
+		invalid("due_at", "due_at must not be before start_at")
+	}
+
+	if t.EstimateMinutes < 0 || t.EstimateMinutes > MaxEstimateMinutes {
+		invalid("estimate_minutes", fmt.Sprintf("estimate_minutes must be between 0 and %d", MaxEstimateMinutes))
+	}
+
+	if t.Recurrence != nil {
+		if err := t.Recurrence.Validate(); err != nil {
+			invalid("recurrence", err.Error())
+		} else if t.StartAt == nil && t.DueAt == nil {
+			invalid("recurrence", "a recurring task needs a start_at or due_at")
+		}
+	}
+
+	if t.ParentID != "" && t.ParentID == t.ID {
+		invalid("parent_id", "a task cannot be its own parent")
+	}
+
+	if t.Assignee != "" && ValidateUserID(t.Assignee) != nil {
+		invalid("assignee", "assignee must be a user id")
+	}
+	if t.ProjectID != "" && ValidateProjectID(t.ProjectID) != nil {
+		invalid("project_id", "project_id must be a project id")
+	}
+
+	if len(t.Labels) > MaxTaskLabels {
+		invalid("labels", fmt.Sprintf("a task can have at most %d labels", MaxTaskLabels))
+	}
+	for _, name := range t.Labels {
+		if err := ValidateLabelName(name); err != nil {
+			invalid("labels", err.Error())
+			break
+		}
 	}
 
 	// Completed: required (bool, default false)
 	// No validation needed for bool, but check for presence if needed in JSON unmarshalling elsewhere
 
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
 	return nil
 }
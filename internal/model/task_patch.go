@@ -0,0 +1,387 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+)
+
+// TaskPatch is a partial update of a task. Nil fields are left unchanged, so
+// "not sent" can be told apart from false or the empty string.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	Status      *string
+	Completed   *bool
+	Priority    *Priority
+	StartAt     OptionalTime
+	DueAt       OptionalTime
+	ParentID    *string
+	// EstimateMinutes replaces the estimate; zero removes it.
+	EstimateMinutes *int
+	Recurrence      OptionalRecurrence
+	// Assignee replaces the assignee; the empty string unassigns the task.
+	Assignee *string
+
+	// tests holds JSON Patch "test" operations on fields the patch does not
+	// change; they are checked against the stored task in Apply.
+	tests []patchTest
+}
+
+// OptionalTime is a patch to a nullable time field. When Set, the field is
+// replaced by Time, which may be nil to clear it.
+type OptionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+// OptionalRecurrence is a patch to Task.Recurrence. When Set, the recurrence
+// is replaced by Recurrence, which may be nil to stop the task recurring.
+type OptionalRecurrence struct {
+	Set        bool
+	Recurrence *Recurrence
+}
+
+type patchTest struct {
+	field string
+	value json.RawMessage
+}
+
+// ErrInvalidPatch is wrapped by every error caused by a malformed patch document.
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// ErrPatchTestFailed is returned when a JSON Patch "test" operation does not hold.
+var ErrPatchTestFailed = apperror.Conflict("patch test failed")
+
+// readOnlyFields may appear in a task document but cannot be patched.
+var readOnlyFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+	"created_by": true,
+	// Tasks change projects by being moved.
+	"project_id": true,
+	// Tasks are ranked by placing them on the board.
+	"rank": true,
+	// Labels are attached and detached through their own endpoints.
+	"labels": true,
+}
+
+// Apply checks the patch's test operations against t and then applies the set
+// fields to it. t is left untouched if a test fails.
+func (p *TaskPatch) Apply(t *Task) error {
+	for _, test := range p.tests {
+		current, _ := taskFieldJSON(t, test.field)
+		if !jsonEqual(current, test.value) {
+			return fmt.Errorf("%w: %s is %s", ErrPatchTestFailed, test.field, current)
+		}
+	}
+	if p.Title != nil {
+		t.Title = *p.Title
+	}
+	if p.Description != nil {
+		t.Description = *p.Description
+	}
+	if p.Status != nil {
+		t.Status = *p.Status
+	}
+	if p.Completed != nil {
+		t.Completed = *p.Completed
+	}
+	if p.Priority != nil {
+		t.Priority = *p.Priority
+	}
+	if p.StartAt.Set {
+		t.StartAt = cloneTime(p.StartAt.Time)
+	}
+	if p.DueAt.Set {
+		t.DueAt = cloneTime(p.DueAt.Time)
+	}
+	if p.ParentID != nil {
+		t.ParentID = *p.ParentID
+	}
+	if p.EstimateMinutes != nil {
+		t.EstimateMinutes = *p.EstimateMinutes
+	}
+	if p.Recurrence.Set {
+		t.Recurrence = ReplaceRecurrence(t.Recurrence, p.Recurrence.Recurrence)
+	}
+	if p.Assignee != nil {
+		t.Assignee = *p.Assignee
+	}
+	return nil
+}
+
+// ParseMergePatch decodes an RFC 7396 JSON Merge Patch document. A null value
+// removes a field, which resets it to its zero value; title and status cannot
+// be removed.
+func ParseMergePatch(data []byte) (*TaskPatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+		return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+	}
+	patch := &TaskPatch{}
+	for field, raw := range doc {
+		if isJSONNull(raw) {
+			if err := patch.remove(field); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := patch.set(field, raw); err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
+
+// jsonPatchOp is a single RFC 6902 operation.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+	From  string          `json:"from"`
+}
+
+// ParseJSONPatch decodes an RFC 6902 JSON Patch document. The add, replace,
+// remove and test operations are supported on the top-level task fields.
+func ParseJSONPatch(data []byte) (*TaskPatch, error) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("%w: JSON patch must be an array of operations", ErrInvalidPatch)
+	}
+	patch := &TaskPatch{}
+	for i, op := range ops {
+		field, err := patchPathField(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+		switch op.Op {
+		case "add", "replace":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: operation %d: %s requires a value", ErrInvalidPatch, i, op.Op)
+			}
+			err = patch.set(field, op.Value)
+		case "remove":
+			err = patch.remove(field)
+		case "test":
+			err = patch.test(field, op.Value)
+		default:
+			err = fmt.Errorf("%w: operation %d: unsupported op %q", ErrInvalidPatch, i, op.Op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
+
+// set records a new value for field.
+func (p *TaskPatch) set(field string, raw json.RawMessage) error {
+	switch field {
+	case "title":
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: title must be a string", ErrInvalidPatch)
+		}
+		p.Title = &v
+	case "description":
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: description must be a string", ErrInvalidPatch)
+		}
+		p.Description = &v
+	case "status":
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: status must be a string", ErrInvalidPatch)
+		}
+		p.Status = &v
+	case "completed":
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: completed must be a boolean", ErrInvalidPatch)
+		}
+		p.Completed = &v
+	case "priority":
+		var v Priority
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: priority must be a string", ErrInvalidPatch)
+		}
+		p.Priority = &v
+	case "start_at", "due_at":
+		var v time.Time
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: %s must be an RFC 3339 timestamp", ErrInvalidPatch, field)
+		}
+		*p.optionalTime(field) = OptionalTime{Set: true, Time: &v}
+	case "parent_id":
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: parent_id must be a string", ErrInvalidPatch)
+		}
+		p.ParentID = &v
+	case "estimate_minutes":
+		var v int
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: estimate_minutes must be an integer", ErrInvalidPatch)
+		}
+		p.EstimateMinutes = &v
+	case "recurrence":
+		var v Recurrence
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: recurrence must be an object", ErrInvalidPatch)
+		}
+		p.Recurrence = OptionalRecurrence{Set: true, Recurrence: &v}
+	case "assignee":
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("%w: assignee must be a string", ErrInvalidPatch)
+		}
+		p.Assignee = &v
+	default:
+		return unpatchableField(field)
+	}
+	return nil
+}
+
+func (p *TaskPatch) optionalTime(field string) *OptionalTime {
+	if field == "start_at" {
+		return &p.StartAt
+	}
+	return &p.DueAt
+}
+
+// remove resets field to its zero value.
+func (p *TaskPatch) remove(field string) error {
+	switch field {
+	case "title":
+		return fmt.Errorf("%w: title cannot be removed", ErrInvalidPatch)
+	case "status":
+		return fmt.Errorf("%w: status cannot be removed", ErrInvalidPatch)
+	case "description":
+		p.Description = new(string)
+	case "completed":
+		p.Completed = new(bool)
+	case "priority":
+		p.Priority = new(Priority)
+	case "start_at", "due_at":
+		*p.optionalTime(field) = OptionalTime{Set: true}
+	case "parent_id":
+		p.ParentID = new(string)
+	case "estimate_minutes":
+		p.EstimateMinutes = new(int)
+	case "recurrence":
+		p.Recurrence = OptionalRecurrence{Set: true}
+	case "assignee":
+		p.Assignee = new(string)
+	default:
+		return unpatchableField(field)
+	}
+	return nil
+}
+
+// test records a "test" operation. Fields already changed by an earlier
+// operation are compared against that pending value right away, preserving
+// RFC 6902's sequential semantics.
+func (p *TaskPatch) test(field string, value json.RawMessage) error {
+	if _, ok := taskFieldJSON(&Task{}, field); !ok && !readOnlyFields[field] {
+		return unpatchableField(field)
+	}
+	var pending any
+	switch {
+	case field == "title" && p.Title != nil:
+		pending = *p.Title
+	case field == "description" && p.Description != nil:
+		pending = *p.Description
+	case field == "status" && p.Status != nil:
+		pending = *p.Status
+	case field == "completed" && p.Completed != nil:
+		pending = *p.Completed
+	case field == "priority" && p.Priority != nil:
+		pending = *p.Priority
+	case (field == "start_at" || field == "due_at") && p.optionalTime(field).Set:
+		pending = p.optionalTime(field).Time
+	case field == "parent_id" && p.ParentID != nil:
+		pending = *p.ParentID
+	case field == "estimate_minutes" && p.EstimateMinutes != nil:
+		pending = *p.EstimateMinutes
+	case field == "recurrence" && p.Recurrence.Set:
+		pending = p.Recurrence.Recurrence
+	case field == "assignee" && p.Assignee != nil:
+		pending = *p.Assignee
+	default:
+		p.tests = append(p.tests, patchTest{field: field, value: value})
+		return nil
+	}
+	current, _ := json.Marshal(pending)
+	if !jsonEqual(current, value) {
+		return fmt.Errorf("%w: %s is %s", ErrPatchTestFailed, field, current)
+	}
+	return nil
+}
+
+func unpatchableField(field string) error {
+	if readOnlyFields[field] {
+		return fmt.Errorf("%w: %s is read-only", ErrInvalidPatch, field)
+	}
+	return fmt.Errorf("%w: unknown field %q", ErrInvalidPatch, field)
+}
+
+// patchPathField maps a JSON Pointer such as "/title" to a top-level field name.
+func patchPathField(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") || strings.Count(path, "/") != 1 {
+		return "", fmt.Errorf("unsupported path %q", path)
+	}
+	field := strings.TrimPrefix(path, "/")
+	field = strings.ReplaceAll(field, "~1", "/")
+	field = strings.ReplaceAll(field, "~0", "~")
+	return field, nil
+}
+
+// taskFieldJSON returns the JSON encoding of one field of t as it appears in the
+// task document.
+func taskFieldJSON(t *Task, field string) (json.RawMessage, bool) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, false
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+	raw, ok := doc[field]
+	if !ok {
+		// Optional fields are omitted when empty.
+		switch field {
+		case "description", "priority", "parent_id", "assignee", "created_by", "project_id", "rank":
+			return json.RawMessage(`""`), true
+		case "start_at", "due_at", "recurrence":
+			return json.RawMessage(`null`), true
+		case "labels":
+			return json.RawMessage(`[]`), true
+		case "estimate_minutes":
+			return json.RawMessage(`0`), true
+		}
+	}
+	return raw, ok
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ea, _ := json.Marshal(va)
+	eb, _ := json.Marshal(vb)
+	return bytes.Equal(ea, eb)
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
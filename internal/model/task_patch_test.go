@@ -0,0 +1,222 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatchTarget() *Task {
+	return &Task{ID: "task-1", Title: "Title", Description: "Desc", Completed: true, Version: 4}
+}
+
+func TestParseMergePatch_PresenceAware(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"title":"New"}`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, "New", task.Title)
+	assert.Equal(t, "Desc", task.Description, "absent fields are unchanged")
+	assert.True(t, task.Completed, "absent completed must not un-complete the task")
+}
+
+func TestParseMergePatch_NullClearsField(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"description":null,"completed":false}`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, "", task.Description)
+	assert.False(t, task.Completed)
+}
+
+func TestParseMergePatch_Errors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"not an object":  `[1,2]`,
+		"null document":  `null`,
+		"wrong type":     `{"completed":"yes"}`,
+		"remove title":   `{"title":null}`,
+		"read-only":      `{"id":"other"}`,
+		"labels":         `{"labels":["x"]}`,
+		"unknown field":  `{"priority":1}`,
+		"malformed json": `{`,
+	} {
+		_, err := ParseMergePatch([]byte(doc))
+		assert.ErrorIs(t, err, ErrInvalidPatch, name)
+	}
+}
+
+func TestParseJSONPatch_Operations(t *testing.T) {
+	patch, err := ParseJSONPatch([]byte(`[
+		{"op":"test","path":"/version","value":4},
+		{"op":"replace","path":"/title","value":"New"},
+		{"op":"test","path":"/title","value":"New"},
+		{"op":"remove","path":"/description"},
+		{"op":"add","path":"/completed","value":false}
+	]`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, "New", task.Title)
+	assert.Equal(t, "", task.Description)
+	assert.False(t, task.Completed)
+}
+
+func TestParseJSONPatch_TestFailure(t *testing.T) {
+	patch, err := ParseJSONPatch([]byte(`[{"op":"test","path":"/title","value":"Other"},{"op":"replace","path":"/title","value":"New"}]`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	assert.ErrorIs(t, patch.Apply(task), ErrPatchTestFailed)
+	assert.Equal(t, "Title", task.Title, "a failed test leaves the task untouched")
+
+	_, err = ParseJSONPatch([]byte(`[{"op":"replace","path":"/title","value":"New"},{"op":"test","path":"/title","value":"Title"}]`))
+	assert.ErrorIs(t, err, ErrPatchTestFailed, "tests after a change see the pending value")
+}
+
+func TestParseJSONPatch_Errors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"not an array":   `{"op":"add"}`,
+		"nested path":    `[{"op":"replace","path":"/title/x","value":"a"}]`,
+		"unsupported op": `[{"op":"move","from":"/title","path":"/description"}]`,
+		"missing value":  `[{"op":"replace","path":"/title"}]`,
+		"read-only":      `[{"op":"replace","path":"/created_at","value":"2020-01-01T00:00:00Z"}]`,
+	} {
+		_, err := ParseJSONPatch([]byte(doc))
+		assert.ErrorIs(t, err, ErrInvalidPatch, name)
+	}
+}
+
+func TestParseMergePatch_PriorityAndDates(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"priority":"high","due_at":"2025-03-10T17:00:00+01:00"}`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, PriorityHigh, task.Priority)
+	require.NotNil(t, task.DueAt)
+	assert.True(t, task.DueAt.Equal(time.Date(2025, 3, 10, 16, 0, 0, 0, time.UTC)))
+	assert.Nil(t, task.StartAt, "absent dates are unchanged")
+
+	patch, err = ParseMergePatch([]byte(`{"priority":null,"due_at":null}`))
+	require.NoError(t, err)
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, PriorityNone, task.Priority)
+	assert.Nil(t, task.DueAt)
+}
+
+func TestParseMergePatch_InvalidDate(t *testing.T) {
+	_, err := ParseMergePatch([]byte(`{"start_at":"tomorrow"}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseJSONPatch_TestOmittedOptionalFields(t *testing.T) {
+	patch, err := ParseJSONPatch([]byte(`[
+		{"op":"test","path":"/due_at","value":null},
+		{"op":"test","path":"/priority","value":""},
+		{"op":"add","path":"/start_at","value":"2025-03-10T09:00:00Z"},
+		{"op":"test","path":"/start_at","value":"2025-03-10T09:00:00Z"}
+	]`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	require.NotNil(t, task.StartAt)
+
+	patch, err = ParseJSONPatch([]byte(`[{"op":"test","path":"/start_at","value":null}]`))
+	require.NoError(t, err)
+	assert.ErrorIs(t, patch.Apply(task), ErrPatchTestFailed)
+}
+
+func TestParseMergePatch_ParentID(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"parent_id":"task-9"}`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, "task-9", task.ParentID)
+
+	patch, err = ParseMergePatch([]byte(`{"parent_id":null}`))
+	require.NoError(t, err)
+	require.NoError(t, patch.Apply(task))
+	assert.Empty(t, task.ParentID)
+
+	_, err = ParseMergePatch([]byte(`{"parent_id":7}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseJSONPatch_Estimate(t *testing.T) {
+	patch, err := ParseJSONPatch([]byte(`[{"op":"test","path":"/estimate_minutes","value":0},{"op":"add","path":"/estimate_minutes","value":90}]`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, 90, task.EstimateMinutes)
+
+	patch, err = ParseJSONPatch([]byte(`[{"op":"remove","path":"/estimate_minutes"}]`))
+	require.NoError(t, err)
+	require.NoError(t, patch.Apply(task))
+	assert.Zero(t, task.EstimateMinutes)
+
+	_, err = ParseMergePatch([]byte(`{"estimate_minutes":"1h"}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseMergePatch_Status(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"status":"in_review"}`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, StatusInReview, task.Status)
+
+	_, err = ParseMergePatch([]byte(`{"status":null}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+	_, err = ParseJSONPatch([]byte(`[{"op":"remove","path":"/status"}]`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseMergePatch_Recurrence(t *testing.T) {
+	task := newPatchTarget()
+	task.Recurrence = &Recurrence{Rule: "FREQ=DAILY", Occurrence: 3, NextID: "next"}
+
+	patch, err := ParseMergePatch([]byte(`{"recurrence":{"rule":"FREQ=WEEKLY","tz":"Europe/Berlin","occurrence":9}}`))
+	require.NoError(t, err)
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, &Recurrence{Rule: "FREQ=WEEKLY", TZ: "Europe/Berlin", Occurrence: 3, NextID: "next"}, task.Recurrence,
+		"the series position is kept")
+
+	patch, err = ParseMergePatch([]byte(`{"recurrence":null}`))
+	require.NoError(t, err)
+	require.NoError(t, patch.Apply(task))
+	assert.Nil(t, task.Recurrence)
+
+	_, err = ParseMergePatch([]byte(`{"recurrence":"FREQ=DAILY"}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseMergePatch_Assignee(t *testing.T) {
+	patch, err := ParseMergePatch([]byte(`{"assignee":"alice"}`))
+	require.NoError(t, err)
+	task := newPatchTarget()
+	require.NoError(t, patch.Apply(task))
+	assert.Equal(t, "alice", task.Assignee)
+
+	patch, err = ParseJSONPatch([]byte(`[{"op":"test","path":"/assignee","value":"alice"},{"op":"remove","path":"/assignee"}]`))
+	require.NoError(t, err)
+	require.NoError(t, patch.Apply(task))
+	assert.Empty(t, task.Assignee)
+
+	_, err = ParseJSONPatch([]byte(`[{"op":"replace","path":"/created_by","value":"mallory"}]`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseMergePatch_ProjectIsReadOnly(t *testing.T) {
+	_, err := ParseMergePatch([]byte(`{"project_id":"web"}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+	_, err = ParseJSONPatch([]byte(`[{"op":"add","path":"/project_id","value":"web"}]`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestParseMergePatch_RankIsReadOnly(t *testing.T) {
+	_, err := ParseMergePatch([]byte(`{"rank":"i"}`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+	patch, err := ParseJSONPatch([]byte(`[{"op":"test","path":"/rank","value":"i"}]`))
+	require.NoError(t, err)
+	assert.NoError(t, patch.Apply(&Task{Rank: "i"}))
+}
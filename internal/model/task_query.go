@@ -0,0 +1,365 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+)
+
+// TaskSortField names a task field tasks can be ordered by.
+type TaskSortField string
+
+const (
+	SortByCreatedAt TaskSortField = "created_at"
+	SortByUpdatedAt TaskSortField = "updated_at"
+	SortByTitle     TaskSortField = "title"
+	SortByPriority  TaskSortField = "priority"
+	SortByStartAt   TaskSortField = "start_at"
+	SortByDueAt     TaskSortField = "due_at"
+	SortByRank      TaskSortField = "rank"
+)
+
+// sortFields lists the valid sort fields; textual ones order by a string key,
+// the others by an integer key.
+var sortFields = map[TaskSortField]bool{
+	SortByCreatedAt: false,
+	SortByUpdatedAt: false,
+	SortByTitle:     true,
+	SortByPriority:  false,
+	SortByStartAt:   false,
+	SortByDueAt:     false,
+	SortByRank:      true,
+}
+
+// NoDate is the sort key of a missing start or due date, so tasks without one
+// sort after all dated tasks in ascending order.
+const NoDate int64 = math.MaxInt64
+
+// Valid reports whether f is a known sort field.
+func (f TaskSortField) Valid() bool {
+	_, ok := sortFields[f]
+	return ok
+}
+
+// Textual reports whether f orders by a string key (see TaskCursor.Str) rather
+// than an integer one (see TaskCursor.Int).
+func (f TaskSortField) Textual() bool {
+	return sortFields[f]
+}
+
+// key returns the sort key of t for f. Times are compared as Unix nanoseconds,
+// which is also how the SQL backend stores them; priorities by rank.
+func (f TaskSortField) key(t *Task) (int64, string) {
+	switch f {
+	case SortByUpdatedAt:
+		return t.UpdatedAt.UnixNano(), ""
+	case SortByTitle:
+		return 0, t.Title
+	case SortByPriority:
+		return int64(t.Priority.Rank()), ""
+	case SortByStartAt:
+		return optionalTimeKey(t.StartAt), ""
+	case SortByDueAt:
+		return optionalTimeKey(t.DueAt), ""
+	case SortByRank:
+		return 0, t.RankKey()
+	default:
+		return t.CreatedAt.UnixNano(), ""
+	}
+}
+
+func optionalTimeKey(t *time.Time) int64 {
+	if t == nil {
+		return NoDate
+	}
+	return t.UnixNano()
+}
+
+// TaskFilter restricts which tasks a query returns. Zero fields do not filter.
+// Time ranges include their After bound and exclude their Before bound; a
+// start or due range excludes tasks without that date. Only live tasks match
+// unless Trashed is set.
+type TaskFilter struct {
+	Completed     *bool
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	StartAfter    time.Time
+	StartBefore   time.Time
+	DueAfter      time.Time
+	DueBefore     time.Time
+	// Statuses matches tasks in any of the listed statuses.
+	Statuses []string
+	// Priorities matches tasks with any of the listed priorities.
+	Priorities []Priority
+	// Labels matches tasks carrying all (or, with LabelMatchAny, any) of the
+	// listed labels.
+	Labels     []string
+	LabelMatch LabelMatch
+	// TitleContains matches a substring of the title, ignoring ASCII case.
+	TitleContains string
+	// ParentID matches the children of the given task, or top-level tasks when
+	// it points to the empty string.
+	ParentID *string
+	// Assignee matches the tasks assigned to the given user, or unassigned
+	// tasks when it points to the empty string.
+	Assignee *string
+	// ProjectID matches the tasks of the given project, or those outside any
+	// project when it points to the empty string.
+	ProjectID *string
+	// Trashed selects the tasks in the trash instead of the live ones.
+	Trashed       bool
+	DeletedAfter  time.Time
+	DeletedBefore time.Time
+}
+
+// Matches reports whether t passes the filter.
+func (f *TaskFilter) Matches(t *Task) bool {
+	if (t.DeletedAt != nil) != f.Trashed || !inOptionalRange(t.DeletedAt, f.DeletedAfter, f.DeletedBefore) {
+		return false
+	}
+	if f.Completed != nil && t.Completed != *f.Completed {
+		return false
+	}
+	if !inRange(t.CreatedAt, f.CreatedAfter, f.CreatedBefore) || !inRange(t.UpdatedAt, f.UpdatedAfter, f.UpdatedBefore) {
+		return false
+	}
+	if !inOptionalRange(t.StartAt, f.StartAfter, f.StartBefore) || !inOptionalRange(t.DueAt, f.DueAfter, f.DueBefore) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, t.Status) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, t.Priority) {
+		return false
+	}
+	if f.TitleContains != "" && !strings.Contains(asciiLower(t.Title), asciiLower(f.TitleContains)) {
+		return false
+	}
+	if f.ParentID != nil && t.ParentID != *f.ParentID {
+		return false
+	}
+	if f.Assignee != nil && t.Assignee != *f.Assignee {
+		return false
+	}
+	if f.ProjectID != nil && t.ProjectID != *f.ProjectID {
+		return false
+	}
+	return f.MatchesLabels(t)
+}
+
+// LabelMatch selects how TaskFilter.Labels combines.
+type LabelMatch int
+
+const (
+	// LabelMatchAll requires every listed label (AND).
+	LabelMatchAll LabelMatch = iota
+	// LabelMatchAny requires at least one listed label (OR).
+	LabelMatchAny
+)
+
+// MatchesLabels reports whether t passes the label part of the filter.
+func (f *TaskFilter) MatchesLabels(t *Task) bool {
+	if len(f.Labels) == 0 {
+		return true
+	}
+	for _, name := range f.Labels {
+		has := t.HasLabel(name)
+		if has && f.LabelMatch == LabelMatchAny {
+			return true
+		}
+		if !has && f.LabelMatch == LabelMatchAll {
+			return false
+		}
+	}
+	return f.LabelMatch == LabelMatchAll
+}
+
+func inRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
+
+func inOptionalRange(t *time.Time, after, before time.Time) bool {
+	if t == nil {
+		return after.IsZero() && before.IsZero()
+	}
+	return inRange(*t, after, before)
+}
+
+// asciiLower folds only ASCII letters, matching SQLite's built-in lower().
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, s)
+}
+
+// TaskQuery selects a page of tasks. Tasks are ordered by Sort (CreatedAt when
+// empty), ties broken by ID, both descending when Desc is set. A zero Limit
+// means no limit; After resumes from the cursor of a previous page.
+type TaskQuery struct {
+	Filter TaskFilter
+	Sort   TaskSortField
+	Desc   bool
+	Limit  int
+	After  *TaskCursor
+}
+
+// SortField returns the effective sort field of the query.
+func (q *TaskQuery) SortField() TaskSortField {
+	if q.Sort == "" {
+		return SortByCreatedAt
+	}
+	return q.Sort
+}
+
+// Validate checks the query for consistency, including that a cursor was issued
+// for the same ordering.
+func (q *TaskQuery) Validate() error {
+	var fields []apperror.FieldError
+	if !q.SortField().Valid() {
+		fields = append(fields, apperror.FieldError{Field: "sort", Message: "unknown sort field " + string(q.Sort)})
+	}
+	for _, p := range q.Filter.Priorities {
+		if !p.Valid() {
+			fields = append(fields, apperror.FieldError{Field: "priority", Message: "unknown priority " + string(p)})
+		}
+	}
+	if a := q.Filter.Assignee; a != nil && *a != "" && ValidateUserID(*a) != nil {
+		fields = append(fields, apperror.FieldError{Field: "assignee", Message: "invalid user id " + strconv.Quote(*a)})
+	}
+	if p := q.Filter.ProjectID; p != nil && *p != "" && ValidateProjectID(*p) != nil {
+		fields = append(fields, apperror.FieldError{Field: "project", Message: "invalid project id " + strconv.Quote(*p)})
+	}
+	for _, name := range q.Filter.Labels {
+		if ValidateLabelName(name) != nil {
+			fields = append(fields, apperror.FieldError{Field: "label", Message: "invalid label name " + strconv.Quote(name)})
+		}
+	}
+	if q.Limit < 0 {
+		fields = append(fields, apperror.FieldError{Field: "limit", Message: "limit must not be negative"})
+	}
+	if q.After != nil && (q.After.Sort != q.SortField() || q.After.Desc != q.Desc) {
+		fields = append(fields, apperror.FieldError{Field: "cursor", Message: "cursor does not match the requested sort order"})
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
+
+// Compare orders a and b as the query does, returning a negative number when a
+// comes first, zero when they are the same task, and a positive number otherwise.
+func (q *TaskQuery) Compare(a, b *Task) int {
+	an, as := q.SortField().key(a)
+	bn, bs := q.SortField().key(b)
+	return q.direction(compareKeys(an, as, a.ID, bn, bs, b.ID))
+}
+
+// IsAfterCursor reports whether t comes strictly after q.After in the query's
+// order. It is true for every task when there is no cursor.
+func (q *TaskQuery) IsAfterCursor(t *Task) bool {
+	if q.After == nil {
+		return true
+	}
+	n, s := q.SortField().key(t)
+	return q.direction(compareKeys(n, s, t.ID, q.After.Int, q.After.Str, q.After.ID)) > 0
+}
+
+// CursorAt returns the cursor that resumes the query after t.
+func (q *TaskQuery) CursorAt(t *Task) *TaskCursor {
+	n, s := q.SortField().key(t)
+	return &TaskCursor{Sort: q.SortField(), Desc: q.Desc, Int: n, Str: s, ID: t.ID}
+}
+
+func (q *TaskQuery) direction(c int) int {
+	if q.Desc {
+		return -c
+	}
+	return c
+}
+
+func compareKeys(an int64, as, aid string, bn int64, bs, bid string) int {
+	switch {
+	case an != bn:
+		if an < bn {
+			return -1
+		}
+		return 1
+	case as != bs:
+		return strings.Compare(as, bs)
+	default:
+		return strings.Compare(aid, bid)
+	}
+}
+
+// TaskCursor marks a position in an ordered task listing: the sort key and ID
+// of the last task of a page. Clients see it only as an opaque token.
+type TaskCursor struct {
+	Sort TaskSortField `json:"s"`
+	Desc bool          `json:"d,omitempty"`
+	Int  int64         `json:"n,omitempty"`
+	Str  string        `json:"v,omitempty"`
+	ID   string        `json:"i"`
+}
+
+// ErrInvalidCursor is returned for a pagination token that cannot be decoded.
+var ErrInvalidCursor = apperror.InvalidField("cursor", "invalid cursor")
+
+// Encode returns the opaque token form of the cursor.
+func (c *TaskCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseTaskCursor decodes a token produced by TaskCursor.Encode.
+func ParseTaskCursor(token string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c TaskCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || !c.Sort.Valid() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Apply runs the query over an unordered set of tasks, for backends that
+// cannot push it down any further. The backing array of tasks is reused.
+func (q *TaskQuery) Apply(tasks []*Task) *TaskPage {
+	matched := tasks[:0]
+	for _, t := range tasks {
+		if q.Filter.Matches(t) && q.IsAfterCursor(t) {
+			matched = append(matched, t)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return q.Compare(matched[i], matched[j]) < 0 })
+	page := &TaskPage{Tasks: matched}
+	if q.Limit > 0 && len(matched) > q.Limit {
+		page.Tasks = matched[:q.Limit]
+		page.Next = q.CursorAt(page.Tasks[q.Limit-1])
+	}
+	return page
+}
+
+// TaskPage is one page of a task query. Next is nil on the last page.
+type TaskPage struct {
+	Tasks []*Task
+	Next  *TaskCursor
+}
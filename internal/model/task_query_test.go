@@ -0,0 +1,145 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskCursor_RoundTrip(t *testing.T) {
+	q := TaskQuery{Sort: SortByTitle, Desc: true}
+	cursor := q.CursorAt(&Task{ID: "task-1", Title: "Ünïcode title"})
+
+	parsed, err := ParseTaskCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+}
+
+func TestParseTaskCursor_Invalid(t *testing.T) {
+	for _, token := range []string{"", "!!", "bm90IGpzb24", "eyJzIjoiY29sb3IiLCJpIjoieCJ9"} {
+		_, err := ParseTaskCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "token %q", token)
+	}
+}
+
+func TestTaskQuery_Validate(t *testing.T) {
+	cursor := (&TaskQuery{}).CursorAt(&Task{ID: "a"})
+
+	assert.NoError(t, (&TaskQuery{After: cursor}).Validate())
+
+	err := (&TaskQuery{Sort: "color", Limit: -1, After: cursor, Desc: true}).Validate()
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	fields := apperror.FieldsOf(err)
+	require.Len(t, fields, 3)
+	assert.Equal(t, "sort", fields[0].Field)
+	assert.Equal(t, "limit", fields[1].Field)
+	assert.Equal(t, "cursor", fields[2].Field)
+}
+
+func TestTaskQuery_Apply(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := func() []*Task {
+		return []*Task{
+			{ID: "c", Title: "gamma", CreatedAt: base.Add(2 * time.Hour)},
+			{ID: "a", Title: "Alpha", CreatedAt: base, Completed: true},
+			{ID: "b", Title: "beta", CreatedAt: base.Add(time.Hour)},
+			{ID: "d", Title: "ALPHABET", CreatedAt: base.Add(time.Hour)},
+		}
+	}
+	ids := func(page *TaskPage) []string {
+		var out []string
+		for _, task := range page.Tasks {
+			out = append(out, task.ID)
+		}
+		return out
+	}
+
+	q := TaskQuery{Limit: 3}
+	page := q.Apply(tasks())
+	assert.Equal(t, []string{"a", "b", "d"}, ids(page))
+	require.NotNil(t, page.Next)
+	q.After = page.Next
+	page = q.Apply(tasks())
+	assert.Equal(t, []string{"c"}, ids(page))
+	assert.Nil(t, page.Next)
+
+	q = TaskQuery{Filter: TaskFilter{TitleContains: "alpha"}, Sort: SortByTitle, Desc: true}
+	assert.Equal(t, []string{"a", "d"}, ids(q.Apply(tasks())))
+
+	notCompleted := false
+	q = TaskQuery{Filter: TaskFilter{Completed: &notCompleted, CreatedBefore: base.Add(2 * time.Hour)}}
+	assert.Equal(t, []string{"b", "d"}, ids(q.Apply(tasks())))
+}
+
+func TestTaskFilter_Labels(t *testing.T) {
+	task := &Task{ID: "a", Labels: []string{"backend", "urgent"}}
+	for _, tc := range []struct {
+		filter TaskFilter
+		want   bool
+	}{
+		{TaskFilter{}, true},
+		{TaskFilter{Labels: []string{"backend"}}, true},
+		{TaskFilter{Labels: []string{"backend", "urgent"}}, true},
+		{TaskFilter{Labels: []string{"backend", "docs"}}, false},
+		{TaskFilter{Labels: []string{"backend", "docs"}, LabelMatch: LabelMatchAny}, true},
+		{TaskFilter{Labels: []string{"docs"}, LabelMatch: LabelMatchAny}, false},
+	} {
+		assert.Equal(t, tc.want, tc.filter.Matches(task), "%+v", tc.filter)
+	}
+}
+
+func TestTaskQuery_ValidateLabels(t *testing.T) {
+	q := TaskQuery{Filter: TaskFilter{Labels: []string{"ok", "not;ok"}}}
+	err := q.Validate()
+	assert.ErrorContains(t, err, `invalid label name "not;ok"`)
+	assert.Equal(t, "label", apperror.FieldsOf(err)[0].Field)
+}
+
+func TestTaskFilter_ParentID(t *testing.T) {
+	child := &Task{ID: "b", ParentID: "a"}
+	top := &Task{ID: "a"}
+	parent, none := "a", ""
+	assert.True(t, (&TaskFilter{ParentID: &parent}).Matches(child))
+	assert.False(t, (&TaskFilter{ParentID: &parent}).Matches(top))
+	assert.True(t, (&TaskFilter{ParentID: &none}).Matches(top))
+	assert.False(t, (&TaskFilter{ParentID: &none}).Matches(child))
+}
+
+func TestTaskFilter_Statuses(t *testing.T) {
+	task := &Task{ID: "a", Status: StatusInReview}
+	assert.True(t, (&TaskFilter{Statuses: []string{StatusInProgress, StatusInReview}}).Matches(task))
+	assert.False(t, (&TaskFilter{Statuses: []string{StatusTodo}}).Matches(task))
+	assert.True(t, (&TaskFilter{}).Matches(task))
+}
+
+func TestTaskFilter_Assignee(t *testing.T) {
+	assigned := &Task{ID: "a", Assignee: "alice"}
+	unassigned := &Task{ID: "b"}
+	alice, none := "alice", ""
+	assert.True(t, (&TaskFilter{Assignee: &alice}).Matches(assigned))
+	assert.False(t, (&TaskFilter{Assignee: &alice}).Matches(unassigned))
+	assert.True(t, (&TaskFilter{Assignee: &none}).Matches(unassigned))
+	assert.False(t, (&TaskFilter{Assignee: &none}).Matches(assigned))
+
+	bad := "not a user"
+	q := TaskQuery{Filter: TaskFilter{Assignee: &bad}}
+	assert.Equal(t, "assignee", apperror.FieldsOf(q.Validate())[0].Field)
+}
+
+func TestTaskFilter_Project(t *testing.T) {
+	inProject := &Task{ID: "a", ProjectID: "web"}
+	outside := &Task{ID: "b"}
+	web, none := "web", ""
+	assert.True(t, (&TaskFilter{ProjectID: &web}).Matches(inProject))
+	assert.False(t, (&TaskFilter{ProjectID: &web}).Matches(outside))
+	assert.True(t, (&TaskFilter{ProjectID: &none}).Matches(outside))
+	assert.False(t, (&TaskFilter{ProjectID: &none}).Matches(inProject))
+
+	bad := "not a project"
+	q := TaskQuery{Filter: TaskFilter{ProjectID: &bad}}
+	assert.Equal(t, "project", apperror.FieldsOf(q.Validate())[0].Field)
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"fmt"
+	"taskmanager/internal/apperror"
 	"testing"
 	"time"
 
@@ -94,3 +96,104 @@ func TestTaskValidation_IDInvalidChars(t *testing.T) {
 	}
 	assert.ErrorContains(t, task.Validate(), "id must be alphanumeric or dash")
 }
+
+func TestTaskValidation_IDReserved(t *testing.T) {
+	for _, id := range []string{"search", "overdue", "due-today", "upcoming", "order", "unblocked", "critical-path", "board"} {
+		task := &Task{ID: id, Title: "Shadowed by a view"}
+		assert.ErrorContains(t, task.ValidateNewID(), "is reserved", id)
+		assert.NoError(t, task.Validate(), "tasks stored before the view stay valid")
+	}
+	assert.NoError(t, (&Task{ID: "search-ui", Title: "Not a view"}).ValidateNewID())
+}
+
+func TestTaskClone(t *testing.T) {
+	task := &Task{ID: "task-1", Title: "Original"}
+	clone := task.Clone()
+	clone.Title = "Changed"
+	assert.Equal(t, "Original", task.Title)
+	assert.Equal(t, "task-1", clone.ID)
+}
+
+func TestTaskValidation_ReportsAllFields(t *testing.T) {
+	task := &Task{ID: "bad id!", Title: "", Description: string(make([]byte, 1001))}
+	err := task.Validate()
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	fields := apperror.FieldsOf(err)
+	assert.Len(t, fields, 3)
+	assert.Equal(t, "id", fields[0].Field)
+	assert.Equal(t, "title", fields[1].Field)
+	assert.Equal(t, "description", fields[2].Field)
+}
+
+func TestTaskValidation_Priority(t *testing.T) {
+	for _, p := range []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent} {
+		task := &Task{ID: "task-123", Title: "Valid Title", Priority: p}
+		assert.NoError(t, task.Validate(), "priority %q", p)
+	}
+	task := &Task{ID: "task-123", Title: "Valid Title", Priority: "critical"}
+	assert.ErrorContains(t, task.Validate(), "priority must be one of")
+}
+
+func TestPriority_Rank(t *testing.T) {
+	assert.Equal(t, 0, PriorityNone.Rank())
+	assert.Equal(t, 4, PriorityUrgent.Rank())
+	assert.Equal(t, -1, Priority("critical").Rank())
+	assert.Equal(t, PriorityHigh, PriorityOfRank(PriorityHigh.Rank()))
+	assert.Equal(t, PriorityNone, PriorityOfRank(9))
+}
+
+func TestTaskValidation_DueBeforeStart(t *testing.T) {
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	due := start.Add(-time.Hour)
+	task := &Task{ID: "task-123", Title: "Valid Title", StartAt: &start, DueAt: &due}
+	err := task.Validate()
+	assert.ErrorContains(t, err, "due_at must not be before start_at")
+	assert.Equal(t, "due_at", apperror.FieldsOf(err)[0].Field)
+
+	due = start
+	assert.NoError(t, task.Validate(), "due at the start time is allowed")
+}
+
+func TestTask_CloneDates(t *testing.T) {
+	due := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	task := &Task{ID: "task-123", DueAt: &due}
+	c := task.Clone()
+	*c.DueAt = c.DueAt.Add(time.Hour)
+	assert.True(t, task.DueAt.Equal(due), "clone must not share the due date")
+}
+
+func TestTaskValidation_Labels(t *testing.T) {
+	task := &Task{ID: "task-123", Title: "Valid Title", Labels: []string{"backend", "bad;label"}}
+	assert.ErrorContains(t, task.Validate(), "label name may only contain")
+
+	task.Labels = make([]string, MaxTaskLabels+1)
+	for i := range task.Labels {
+		task.Labels[i] = fmt.Sprintf("label-%d", i)
+	}
+	assert.ErrorContains(t, task.Validate(), "at most 20 labels")
+	task.Labels = task.Labels[:MaxTaskLabels]
+	assert.NoError(t, task.Validate())
+}
+
+func TestTask_CloneLabels(t *testing.T) {
+	task := &Task{ID: "task-123", Labels: []string{"a", "b"}}
+	c := task.Clone()
+	c.Labels[0] = "z"
+	assert.Equal(t, []string{"a", "b"}, task.Labels, "clone must not share labels")
+}
+
+func TestTaskValidation_SelfParent(t *testing.T) {
+	task := &Task{ID: "task-123", Title: "Valid Title", ParentID: "task-123"}
+	assert.ErrorContains(t, task.Validate(), "own parent")
+	task.ParentID = "task-456"
+	assert.NoError(t, task.Validate())
+}
+
+func TestTaskValidation_Estimate(t *testing.T) {
+	task := &Task{ID: "task-123", Title: "Valid Title", EstimateMinutes: -1}
+	assert.ErrorContains(t, task.Validate(), "estimate_minutes")
+	task.EstimateMinutes = MaxEstimateMinutes + 1
+	assert.ErrorContains(t, task.Validate(), "estimate_minutes")
+	task.EstimateMinutes = 90
+	assert.NoError(t, task.Validate())
+}
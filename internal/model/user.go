@@ -0,0 +1,88 @@
+package model
+
+import (
+	"net/mail"
+	"strings"
+	"taskmanager/internal/apperror"
+	"time"
+	"unicode/utf8"
+)
+
+// User is a person tasks are created by and assigned to. The ID is the name
+// a user's requests carry as their actor, so Task.CreatedBy and the authors
+// of comments and history entries refer to users by it.
+//
+// Fields:
+//   - ID: required, 1-64 characters: letters, digits and - _ .
+//   - Name: display name, required, at most 100 characters
+//   - Email: optional address
+//   - CreatedAt: timestamp when the user was created
+//   - UpdatedAt: timestamp when the user was last updated
+type User struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Clone returns a copy of the user.
+func (u *User) Clone() *User {
+	c := *u
+	return &c
+}
+
+// Validate checks the user fields for correctness.
+func (u *User) Validate() error {
+	var fields []apperror.FieldError
+	if err := ValidateUserID(u.ID); err != nil {
+		fields = append(fields, apperror.FieldsOf(err)...)
+	}
+	if strings.TrimSpace(u.Name) == "" {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: "name is required"})
+	} else if utf8.RuneCountInString(u.Name) > 100 {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: "name must be at most 100 characters"})
+	}
+	if u.Email != "" {
+		if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+			fields = append(fields, apperror.FieldError{Field: "email", Message: "email must be a plain address such as alice@example.com"})
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation(fields...)
+	}
+	return nil
+}
+
+// ValidateUserID checks that id is a well-formed user ID.
+func ValidateUserID(id string) error {
+	if id == "" {
+		return apperror.InvalidField("id", "user id is required")
+	}
+	if len(id) > 64 {
+		return apperror.InvalidField("id", "user id must be at most 64 characters")
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.", c)) {
+			return apperror.InvalidField("id", "user id may only contain letters, digits and - _ .")
+		}
+	}
+	return nil
+}
+
+// UserPatch is a partial update of a user; nil fields are left unchanged and
+// an empty Email removes the address.
+type UserPatch struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
+}
+
+// Apply sets the patched fields on u.
+func (p *UserPatch) Apply(u *User) {
+	if p.Name != nil {
+		u.Name = *p.Name
+	}
+	if p.Email != nil {
+		u.Email = *p.Email
+	}
+}
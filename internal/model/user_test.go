@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserValidation(t *testing.T) {
+	assert.NoError(t, (&User{ID: "alice.b-1_x", Name: "Alice", Email: "alice@example.com"}).Validate())
+	assert.NoError(t, (&User{ID: "bob", Name: "Bob"}).Validate())
+
+	err := (&User{ID: "a b", Name: " ", Email: "Alice <alice@example.com>"}).Validate()
+	fields := apperror.FieldsOf(err)
+	if assert.Len(t, fields, 3) {
+		assert.Equal(t, "id", fields[0].Field)
+		assert.Equal(t, "name", fields[1].Field)
+		assert.Equal(t, "email", fields[2].Field)
+	}
+	assert.Error(t, (&User{ID: "alice", Name: strings.Repeat("x", 101)}).Validate())
+}
+
+func TestValidateUserID(t *testing.T) {
+	for _, id := range []string{"", "a/b", "émile", strings.Repeat("x", 65)} {
+		assert.Error(t, ValidateUserID(id), "id %q", id)
+	}
+	assert.NoError(t, ValidateUserID(strings.Repeat("x", 64)))
+}
+
+func TestUserPatch_Apply(t *testing.T) {
+	user := &User{ID: "alice", Name: "Alice", Email: "alice@example.com"}
+	name, email := "Alice B.", ""
+	(&UserPatch{Name: &name}).Apply(user)
+	assert.Equal(t, "Alice B.", user.Name)
+	assert.Equal(t, "alice@example.com", user.Email)
+	(&UserPatch{Email: &email}).Apply(user)
+	assert.Empty(t, user.Email)
+}
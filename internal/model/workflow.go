@@ -0,0 +1,214 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+)
+
+// Statuses of the default workflow.
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusInReview   = "in_review"
+	StatusDone       = "done"
+	StatusCancelled  = "cancelled"
+)
+
+// Workflow is the state machine that task statuses follow. A task is
+// completed exactly when its status is terminal; Task.Completed mirrors that
+// for clients that predate statuses.
+//
+// Terminal states close a task but need not be final: the default workflow
+// lets done and cancelled tasks be reopened.
+type Workflow struct {
+	// States lists every status in display order.
+	States []string `json:"states"`
+	// Initial is the status of new tasks and of tasks reopened by setting
+	// completed to false.
+	Initial string `json:"initial"`
+	// Done is the status set by setting completed to true. It is terminal.
+	Done string `json:"done"`
+	// Terminal lists the statuses of completed tasks.
+	Terminal []string `json:"terminal"`
+	// Transitions maps a status to the statuses a task may move to from it.
+	Transitions map[string][]string `json:"transitions"`
+	// WIPLimits maps a status to the most tasks its board column may hold.
+	// Statuses without a limit take any number of tasks.
+	WIPLimits map[string]int `json:"wip_limits,omitempty"`
+}
+
+// DefaultWorkflow returns the workflow used unless another one is configured:
+// todo, in_progress, in_review, done and cancelled. Work moves forward through
+// review or straight to done, may go back a step, and can be cancelled until
+// it is done; done and cancelled tasks can be reopened.
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		States:   []string{StatusTodo, StatusInProgress, StatusInReview, StatusDone, StatusCancelled},
+		Initial:  StatusTodo,
+		Done:     StatusDone,
+		Terminal: []string{StatusDone, StatusCancelled},
+		Transitions: map[string][]string{
+			StatusTodo:       {StatusInProgress, StatusDone, StatusCancelled},
+			StatusInProgress: {StatusTodo, StatusInReview, StatusDone, StatusCancelled},
+			StatusInReview:   {StatusInProgress, StatusDone, StatusCancelled},
+			StatusDone:       {StatusTodo},
+			StatusCancelled:  {StatusTodo},
+		},
+	}
+}
+
+// ParseWorkflow decodes and validates a JSON workflow definition.
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var wf Workflow
+	if err := dec.Decode(&wf); err != nil {
+		return nil, fmt.Errorf("decode workflow: %w", err)
+	}
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+// Validate checks that the workflow is well-formed: state names are unique
+// and valid, and the initial, done, terminal, transition and WIP limit states
+// all exist. The initial state cannot be terminal and done must be; WIP limits
+// are positive.
+func (w *Workflow) Validate() error {
+	if len(w.States) == 0 {
+		return fmt.Errorf("workflow has no states")
+	}
+	for i, s := range w.States {
+		if err := validateStatusName(s); err != nil {
+			return err
+		}
+		if slices.Contains(w.States[:i], s) {
+			return fmt.Errorf("workflow state %q is listed twice", s)
+		}
+	}
+	for _, t := range w.Terminal {
+		if !w.Has(t) {
+			return fmt.Errorf("terminal state %q is not a workflow state", t)
+		}
+	}
+	switch {
+	case !w.Has(w.Initial):
+		return fmt.Errorf("initial state %q is not a workflow state", w.Initial)
+	case w.IsTerminal(w.Initial):
+		return fmt.Errorf("initial state %q cannot be terminal", w.Initial)
+	case !w.IsTerminal(w.Done):
+		return fmt.Errorf("done state %q is not a terminal state", w.Done)
+	}
+	for from, targets := range w.Transitions {
+		if !w.Has(from) {
+			return fmt.Errorf("transitions from unknown state %q", from)
+		}
+		for _, to := range targets {
+			if !w.Has(to) || to == from {
+				return fmt.Errorf("invalid transition from %q to %q", from, to)
+			}
+		}
+	}
+	for status, limit := range w.WIPLimits {
+		if !w.Has(status) {
+			return fmt.Errorf("WIP limit for unknown state %q", status)
+		}
+		if limit < 1 {
+			return fmt.Errorf("WIP limit of state %q must be positive", status)
+		}
+	}
+	return nil
+}
+
+// validateStatusName checks that name is 1-50 lowercase letters, digits or
+// underscores.
+func validateStatusName(name string) error {
+	if name == "" || len(name) > 50 {
+		return fmt.Errorf("workflow state %q must be 1-50 characters", name)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			return fmt.Errorf("workflow state %q may only contain lowercase letters, digits and _", name)
+		}
+	}
+	return nil
+}
+
+// Clone returns a copy of the workflow that shares no mutable state with w.
+func (w *Workflow) Clone() *Workflow {
+	c := *w
+	c.States = slices.Clone(w.States)
+	c.Terminal = slices.Clone(w.Terminal)
+	c.Transitions = make(map[string][]string, len(w.Transitions))
+	for from, targets := range w.Transitions {
+		c.Transitions[from] = slices.Clone(targets)
+	}
+	c.WIPLimits = maps.Clone(w.WIPLimits)
+	return &c
+}
+
+// Has reports whether status is a state of the workflow.
+func (w *Workflow) Has(status string) bool {
+	return slices.Contains(w.States, status)
+}
+
+// WIPLimit returns the most tasks the board column of status may hold, 0 for
+// no limit.
+func (w *Workflow) WIPLimit(status string) int {
+	return w.WIPLimits[status]
+}
+
+// IsTerminal reports whether a task in status counts as completed.
+func (w *Workflow) IsTerminal(status string) bool {
+	return slices.Contains(w.Terminal, status)
+}
+
+// Next lists the statuses a task in status may move to, in the order of
+// States. A task in a status the workflow does not know, left over from a
+// changed definition, may move to any state.
+func (w *Workflow) Next(status string) []string {
+	if !w.Has(status) {
+		return slices.Clone(w.States)
+	}
+	allowed := w.Transitions[status]
+	var next []string
+	for _, s := range w.States {
+		if slices.Contains(allowed, s) {
+			next = append(next, s)
+		}
+	}
+	return next
+}
+
+// CheckTransition returns a validation error on the status field, naming the
+// allowed next states, unless a task may move from one status to another.
+// Staying in the same status is always allowed.
+func (w *Workflow) CheckTransition(from, to string) error {
+	if !w.Has(to) {
+		return apperror.InvalidField("status", fmt.Sprintf("unknown status %q; the workflow states are %s", to, strings.Join(w.States, ", ")))
+	}
+	next := w.Next(from)
+	if from == to || slices.Contains(next, to) {
+		return nil
+	}
+	allowed := "none"
+	if len(next) > 0 {
+		allowed = strings.Join(next, ", ")
+	}
+	return apperror.InvalidField("status", fmt.Sprintf("cannot move from %q to %q; allowed next states: %s", from, to, allowed))
+}
+
+// LegacyStatus is the default workflow's status for a task stored before
+// statuses existed, which only recorded whether it was completed.
+func LegacyStatus(completed bool) string {
+	if completed {
+		return StatusDone
+	}
+	return StatusTodo
+}
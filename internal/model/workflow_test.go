@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+
+	"taskmanager/internal/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultWorkflow(t *testing.T) {
+	wf := DefaultWorkflow()
+	require.NoError(t, wf.Validate())
+	assert.True(t, wf.IsTerminal(StatusDone))
+	assert.True(t, wf.IsTerminal(StatusCancelled))
+	assert.False(t, wf.IsTerminal(StatusInReview))
+	assert.Equal(t, []string{StatusTodo, StatusInReview, StatusDone, StatusCancelled}, wf.Next(StatusInProgress))
+}
+
+func TestWorkflow_CheckTransition(t *testing.T) {
+	wf := DefaultWorkflow()
+	assert.NoError(t, wf.CheckTransition(StatusTodo, StatusInProgress))
+	assert.NoError(t, wf.CheckTransition(StatusDone, StatusDone))
+
+	err := wf.CheckTransition(StatusTodo, StatusInReview)
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	assert.Equal(t, "status", apperror.FieldsOf(err)[0].Field)
+	assert.EqualError(t, err, `cannot move from "todo" to "in_review"; allowed next states: in_progress, done, cancelled`)
+
+	assert.ErrorContains(t, wf.CheckTransition(StatusTodo, "blocked"), `unknown status "blocked"`)
+	// A status the workflow no longer knows may move anywhere.
+	assert.NoError(t, wf.CheckTransition("legacy", StatusInReview))
+}
+
+func TestWorkflow_CheckTransition_NoWayOut(t *testing.T) {
+	wf := &Workflow{
+		States:      []string{"open", "closed"},
+		Initial:     "open",
+		Done:        "closed",
+		Terminal:    []string{"closed"},
+		Transitions: map[string][]string{"open": {"closed"}},
+	}
+	require.NoError(t, wf.Validate())
+	assert.EqualError(t, wf.CheckTransition("closed", "open"), `cannot move from "closed" to "open"; allowed next states: none`)
+}
+
+func TestParseWorkflow(t *testing.T) {
+	wf, err := ParseWorkflow([]byte(`{
+		"states": ["backlog", "doing", "shipped"],
+		"initial": "backlog",
+		"done": "shipped",
+		"terminal": ["shipped"],
+		"transitions": {"backlog": ["doing"], "doing": ["backlog", "shipped"]},
+		"wip_limits": {"doing": 3}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backlog", "shipped"}, wf.Next("doing"))
+	assert.Equal(t, 3, wf.WIPLimit("doing"))
+	assert.Zero(t, wf.WIPLimit("backlog"))
+	clone := wf.Clone()
+	clone.WIPLimits["doing"] = 5
+	assert.Equal(t, 3, wf.WIPLimit("doing"))
+
+	tests := []struct {
+		name, doc, want string
+	}{
+		{"unknown field", `{"states":["a"],"initial":"a","final":"a"}`, "unknown field"},
+		{"no states", `{"states":[]}`, "no states"},
+		{"bad name", `{"states":["In Progress"]}`, "lowercase"},
+		{"duplicate", `{"states":["a","a"]}`, "listed twice"},
+		{"unknown initial", `{"states":["a","b"],"initial":"c","done":"b","terminal":["b"]}`, "initial state"},
+		{"terminal initial", `{"states":["a"],"initial":"a","done":"a","terminal":["a"]}`, "cannot be terminal"},
+		{"done not terminal", `{"states":["a","b"],"initial":"a","done":"b"}`, "not a terminal state"},
+		{"unknown terminal", `{"states":["a","b"],"initial":"a","done":"b","terminal":["b","c"]}`, "terminal state \"c\""},
+		{"unknown target", `{"states":["a","b"],"initial":"a","done":"b","terminal":["b"],"transitions":{"a":["c"]}}`, "invalid transition"},
+		{"self transition", `{"states":["a","b"],"initial":"a","done":"b","terminal":["b"],"transitions":{"a":["a"]}}`, "invalid transition"},
+		{"unknown source", `{"states":["a","b"],"initial":"a","done":"b","terminal":["b"],"transitions":{"c":["a"]}}`, "unknown state"},
+		{"unknown WIP state", `{"states":["a","b"],"initial":"a","done":"b","terminal":["b"],"wip_limits":{"c":1}}`, "unknown state"},
+		{"zero WIP limit", `{"states":["a","b"],"initial":"a","done":"b","terminal":["b"],"wip_limits":{"a":0}}`, "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWorkflow([]byte(tt.doc))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestLegacyStatus(t *testing.T) {
+	assert.Equal(t, StatusDone, LegacyStatus(true))
+	assert.Equal(t, StatusTodo, LegacyStatus(false))
+}
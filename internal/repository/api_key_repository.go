@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrAPIKeyNotFound is returned when an API key does not exist.
+var ErrAPIKeyNotFound = apperror.NotFound("API key not found")
+
+// ErrAPIKeyAlreadyExists is returned when creating an API key whose ID is taken.
+var ErrAPIKeyAlreadyExists = apperror.AlreadyExists("API key already exists")
+
+// APIKeyRepository stores the API keys clients authenticate with. Keys are
+// global rather than per tenant: a key names the tenant it is confined to.
+type APIKeyRepository interface {
+	// CreateAPIKey adds a new key, including its hash.
+	CreateAPIKey(ctx context.Context, key *model.APIKey) error
+	// GetAPIKey retrieves a key, including its hash, by ID.
+	GetAPIKey(ctx context.Context, id string) (*model.APIKey, error)
+	// ListAPIKeys returns all keys ordered by creation time, then ID.
+	ListAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+	// DeleteAPIKey removes a key.
+	DeleteAPIKey(ctx context.Context, id string) error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrAttachmentNotFound is returned when an attachment does not exist on its task.
+var ErrAttachmentNotFound = apperror.NotFound("attachment not found")
+
+// ErrAttachmentAlreadyExists is returned when creating an attachment whose ID is taken.
+var ErrAttachmentAlreadyExists = apperror.AlreadyExists("attachment already exists")
+
+// AttachmentRepository stores the metadata of files attached to tasks; their
+// content lives in a BlobStore. Attachments stay while their task is in the
+// trash, and deleting the task for good deletes them.
+type AttachmentRepository interface {
+	// CreateAttachment adds a new attachment. It returns ErrTaskNotFound if its
+	// task does not exist, live or trashed.
+	CreateAttachment(ctx context.Context, attachment *model.Attachment) error
+	// GetAttachment retrieves attachment id on taskID.
+	GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error)
+	// ListAttachments returns the attachments of taskID ordered by CreatedAt,
+	// then ID, or an empty slice if there are none.
+	ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error)
+	// DeleteAttachment removes attachment id from taskID.
+	DeleteAttachment(ctx context.Context, taskID, id string) error
+	// ListAttachmentBlobs returns the distinct SHA256 digests referenced by any
+	// attachment, in ascending order.
+	ListAttachmentBlobs(ctx context.Context) ([]string, error)
+}
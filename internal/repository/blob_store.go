@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"taskmanager/internal/apperror"
+	"time"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist in the store.
+var ErrBlobNotFound = apperror.NotFound("blob not found")
+
+// ErrBlobTooLarge is returned by BlobStore.Put for content over the size limit.
+var ErrBlobTooLarge = apperror.InvalidField("file", "file is too large")
+
+// BlobInfo describes a stored blob.
+type BlobInfo struct {
+	// Key is the hex-encoded SHA-256 digest of the content.
+	Key  string
+	Size int64
+	// ModTime is when the content was last stored.
+	ModTime time.Time
+}
+
+// BlobStore keeps file contents addressed by their SHA-256 digest, so storing
+// the same content twice keeps a single copy. Implementations must be safe for
+// concurrent use.
+type BlobStore interface {
+	// Put stores the content read from r, failing with ErrBlobTooLarge if it is
+	// longer than maxSize bytes. Storing content that is already present only
+	// refreshes its ModTime.
+	Put(ctx context.Context, r io.Reader, maxSize int64) (BlobInfo, error)
+	// Open returns a reader for the blob with the given key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob with the given key.
+	Delete(ctx context.Context, key string) error
+	// List returns every stored blob ordered by key.
+	List(ctx context.Context) ([]BlobInfo, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrCommentNotFound is returned when a comment does not exist on its task.
+var ErrCommentNotFound = apperror.NotFound("comment not found")
+
+// ErrCommentAlreadyExists is returned when creating a comment whose ID is taken.
+var ErrCommentAlreadyExists = apperror.AlreadyExists("comment already exists")
+
+// CommentRepository stores the comments on tasks. Comments stay while their
+// task is in the trash, and deleting the task for good deletes them.
+type CommentRepository interface {
+	// CreateComment adds a new comment. It returns ErrTaskNotFound if its task
+	// does not exist, live or trashed.
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	// GetComment retrieves comment id on taskID.
+	GetComment(ctx context.Context, taskID, id string) (*model.Comment, error)
+	// UpdateComment replaces an existing comment.
+	UpdateComment(ctx context.Context, comment *model.Comment) error
+	// DeleteComment removes comment id from taskID.
+	DeleteComment(ctx context.Context, taskID, id string) error
+	// ListComments returns the page of comments selected by q.
+	ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error)
+}
@@ -0,0 +1,39 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/repository"
+	"taskmanager/internal/repository/repotest"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInMemoryTaskRepository_Conformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.TaskRepository {
+		return repository.NewInMemoryTaskRepository(zap.NewNop())
+	})
+}
+
+func TestJournaledTaskRepository_Conformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.TaskRepository {
+		repo, err := repository.OpenJournaledTaskRepository(repository.JournalOptions{
+			Dir:  t.TempDir(),
+			Sync: repository.SyncNever,
+		}, zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func TestSQLiteTaskRepository_Conformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.TaskRepository {
+		repo, err := repository.OpenSQLiteTaskRepository(context.Background(), ":memory:", zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrDependencyNotFound is returned when removing a dependency that does not exist.
+var ErrDependencyNotFound = apperror.NotFound("dependency not found")
+
+// ErrDependencyAlreadyExists is returned when adding a dependency twice.
+var ErrDependencyAlreadyExists = apperror.AlreadyExists("dependency already exists")
+
+// ErrDependencyCycle is returned when a new dependency would close a cycle.
+var ErrDependencyCycle = model.ErrDependencyCycle
+
+// DependencyRepository stores the "blocked by" edges between tasks. The edges
+// always form a directed acyclic graph, and deleting a task removes its edges.
+type DependencyRepository interface {
+	// AddDependency records that dep.TaskID is blocked by dep.BlockerID. It
+	// returns ErrTaskNotFound if either task is missing and ErrDependencyCycle if
+	// dep.BlockerID already depends on dep.TaskID, directly or transitively.
+	AddDependency(ctx context.Context, dep *model.Dependency) error
+	// RemoveDependency deletes the edge between taskID and blockerID.
+	RemoveDependency(ctx context.Context, taskID, blockerID string) error
+	// ListDependencies returns every edge ordered by task ID, then blocker ID.
+	ListDependencies(ctx context.Context) ([]*model.Dependency, error)
+	// ListBlockers returns the edges to the tasks blocking taskID, ordered by blocker ID.
+	ListBlockers(ctx context.Context, taskID string) ([]*model.Dependency, error)
+	// ListDependents returns the edges from the tasks blocked by taskID, ordered by task ID.
+	ListDependents(ctx context.Context, taskID string) ([]*model.Dependency, error)
+}
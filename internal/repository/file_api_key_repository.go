@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FileAPIKeyRepository is an APIKeyRepository that keeps its keys in memory
+// and, given a path, in a JSON file. Every change rewrites the file to a
+// temporary one that is synced and renamed into place, so the file always
+// holds a complete set of keys.
+type FileAPIKeyRepository struct {
+	mu     sync.RWMutex
+	path   string
+	keys   map[string]*model.APIKey
+	logger *zap.Logger
+}
+
+// apiKeyRecord is the stored form of an API key, which unlike model.APIKey
+// includes the hash.
+type apiKeyRecord struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Hash      string    `json:"hash"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OpenFileAPIKeyRepository loads the keys stored at path, which need not
+// exist yet. With an empty path keys live in memory only.
+func OpenFileAPIKeyRepository(path string, logger *zap.Logger) (*FileAPIKeyRepository, error) {
+	r := &FileAPIKeyRepository{path: path, keys: make(map[string]*model.APIKey), logger: logger}
+	if path == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read API keys: %w", err)
+	}
+	var records []apiKeyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("decode API keys %s: %w", path, err)
+	}
+	for _, rec := range records {
+		r.keys[rec.ID] = &model.APIKey{
+			ID: rec.ID, Name: rec.Name, Scopes: rec.Scopes, Tenant: rec.Tenant,
+			Hash: rec.Hash, CreatedBy: rec.CreatedBy, CreatedAt: rec.CreatedAt,
+		}
+	}
+	logger.Info("API keys loaded", zap.String("path", path), zap.Int("keys", len(records)))
+	return r, nil
+}
+
+// CreateAPIKey adds a new key to the repository.
+func (r *FileAPIKeyRepository) CreateAPIKey(ctx context.Context, key *model.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.keys[key.ID]; exists {
+		r.logger.Warn("API key already exists", zap.String("id", key.ID))
+		return ErrAPIKeyAlreadyExists
+	}
+	r.keys[key.ID] = key.Clone()
+	if err := r.save(); err != nil {
+		delete(r.keys, key.ID)
+		return err
+	}
+	r.logger.Info("API key created", zap.String("id", key.ID))
+	return nil
+}
+
+// GetAPIKey retrieves a key by ID.
+func (r *FileAPIKeyRepository) GetAPIKey(ctx context.Context, id string) (*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key.Clone(), nil
+}
+
+// ListAPIKeys returns all keys ordered by creation time, then ID.
+func (r *FileAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sorted(), nil
+}
+
+// DeleteAPIKey removes a key.
+func (r *FileAPIKeyRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, exists := r.keys[id]
+	if !exists {
+		r.logger.Warn("API key not found for delete", zap.String("id", id))
+		return ErrAPIKeyNotFound
+	}
+	delete(r.keys, id)
+	if err := r.save(); err != nil {
+		r.keys[id] = key
+		return err
+	}
+	r.logger.Info("API key deleted", zap.String("id", id))
+	return nil
+}
+
+func (r *FileAPIKeyRepository) sorted() []*model.APIKey {
+	keys := make([]*model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key.Clone())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// save writes all keys to the file. The caller holds the write lock.
+func (r *FileAPIKeyRepository) save() error {
+	if r.path == "" {
+		return nil
+	}
+	records := []apiKeyRecord{}
+	for _, key := range r.sorted() {
+		records = append(records, apiKeyRecord{
+			ID: key.ID, Name: key.Name, Scopes: key.Scopes, Tenant: key.Tenant,
+			Hash: key.Hash, CreatedBy: key.CreatedBy, CreatedAt: key.CreatedAt,
+		})
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode API keys: %w", err)
+	}
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create API key dir: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return fmt.Errorf("write API keys: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("replace API keys: %w", err)
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFileAPIKeyRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys", "api_keys.json")
+	repo, err := OpenFileAPIKeyRepository(path, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ci := &model.APIKey{ID: "b1", Name: "CI", Scopes: []string{model.ScopeTasksRead}, Hash: "h1", CreatedAt: now}
+	ops := &model.APIKey{ID: "a2", Name: "Ops", Scopes: []string{model.ScopeAdmin}, Tenant: "acme", Hash: "h2", CreatedAt: now.Add(time.Minute)}
+	require.NoError(t, repo.CreateAPIKey(ctx, ci))
+	require.NoError(t, repo.CreateAPIKey(ctx, ops))
+	assert.ErrorIs(t, repo.CreateAPIKey(ctx, ci), ErrAPIKeyAlreadyExists)
+
+	// Hashes survive a reopen.
+	reopened, err := OpenFileAPIKeyRepository(path, zap.NewNop())
+	require.NoError(t, err)
+	keys, err := reopened.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "b1", keys[0].ID, "ordered by creation time")
+	assert.Equal(t, ops, keys[1])
+
+	require.NoError(t, reopened.DeleteAPIKey(ctx, "b1"))
+	assert.ErrorIs(t, reopened.DeleteAPIKey(ctx, "b1"), ErrAPIKeyNotFound)
+	_, err = reopened.GetAPIKey(ctx, "b1")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	reopened, err = OpenFileAPIKeyRepository(path, zap.NewNop())
+	require.NoError(t, err)
+	keys, err = reopened.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "a2", keys[0].ID)
+	_, err = os.Stat(path + ".tmp")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFileAPIKeyRepository_InMemory(t *testing.T) {
+	ctx := context.Background()
+	repo, err := OpenFileAPIKeyRepository("", zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateAPIKey(ctx, &model.APIKey{ID: "k1", Name: "CI", Scopes: []string{model.ScopeAdmin}}))
+	key, err := repo.GetAPIKey(ctx, "k1")
+	require.NoError(t, err)
+	key.Scopes[0] = model.ScopeTasksWrite
+	stored, err := repo.GetAPIKey(ctx, "k1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.ScopeAdmin}, stored.Scopes, "callers get copies")
+}
+
+func TestOpenFileAPIKeyRepository_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err := OpenFileAPIKeyRepository(path, zap.NewNop())
+	assert.Error(t, err)
+}
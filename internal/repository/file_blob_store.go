@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// FileBlobStore is a BlobStore in a local directory. Each blob is a file named
+// by its key in a subdirectory named by the key's first two characters.
+// Uploads are written to a temporary file and renamed into place, so a blob
+// is either complete or absent.
+type FileBlobStore struct {
+	dir    string
+	logger *zap.Logger
+}
+
+// NewFileBlobStore creates a FileBlobStore in dir, creating the directory if
+// needed.
+func NewFileBlobStore(dir string, logger *zap.Logger) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create blob dir: %w", err)
+	}
+	return &FileBlobStore{dir: dir, logger: logger}, nil
+}
+
+// Put stores the content read from r under its SHA-256 digest.
+func (s *FileBlobStore) Put(ctx context.Context, r io.Reader, maxSize int64) (BlobInfo, error) {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("create upload file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("write upload: %w", err)
+	}
+	if n > maxSize {
+		return BlobInfo{}, ErrBlobTooLarge
+	}
+	if err := tmp.Sync(); err != nil {
+		return BlobInfo{}, fmt.Errorf("sync upload: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return BlobInfo{}, fmt.Errorf("close upload: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return BlobInfo{}, err
+	}
+
+	info := BlobInfo{Key: hex.EncodeToString(h.Sum(nil)), Size: n, ModTime: time.Now().UTC()}
+	path := s.path(info.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return BlobInfo{}, fmt.Errorf("create blob dir: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		// Already stored; the refreshed time keeps it from being swept as an
+		// orphan before the caller records its use.
+		if err := os.Chtimes(path, info.ModTime, info.ModTime); err != nil {
+			return BlobInfo{}, fmt.Errorf("touch blob: %w", err)
+		}
+		s.logger.Debug("blob deduplicated", zap.String("key", info.Key))
+		return info, nil
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return BlobInfo{}, fmt.Errorf("install blob: %w", err)
+	}
+	s.logger.Info("blob stored", zap.String("key", info.Key), zap.Int64("size", n))
+	return info, nil
+}
+
+// Open returns the content of blob key.
+func (s *FileBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validBlobKey(key) {
+		return nil, ErrBlobNotFound
+	}
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open blob: %w", err)
+	}
+	return f, nil
+}
+
+// Delete removes blob key.
+func (s *FileBlobStore) Delete(ctx context.Context, key string) error {
+	if !validBlobKey(key) {
+		return ErrBlobNotFound
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrBlobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	s.logger.Info("blob deleted", zap.String("key", key))
+	return nil
+}
+
+// List returns every stored blob ordered by key. Unfinished uploads and
+// foreign files are skipped.
+func (s *FileBlobStore) List(ctx context.Context) ([]BlobInfo, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+	blobs := []BlobInfo{}
+	for _, d := range dirs {
+		if !d.IsDir() || len(d.Name()) != 2 {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(s.dir, d.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("list blobs: %w", err)
+		}
+		for _, f := range files {
+			if !validBlobKey(f.Name()) || f.Name()[:2] != d.Name() {
+				continue
+			}
+			fi, err := f.Info()
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("stat blob: %w", err)
+			}
+			blobs = append(blobs, BlobInfo{Key: f.Name(), Size: fi.Size(), ModTime: fi.ModTime().UTC()})
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key < blobs[j].Key })
+	return blobs, nil
+}
+
+func (s *FileBlobStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key)
+}
+
+// validBlobKey reports whether key is a lowercase hex SHA-256 digest, which
+// also keeps keys from escaping the store's directory.
+func validBlobKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
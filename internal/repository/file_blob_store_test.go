@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFileBlobStore_PutAndOpen(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileBlobStore(filepath.Join(t.TempDir(), "blobs"), zap.NewNop())
+	require.NoError(t, err)
+
+	info, err := store.Put(ctx, strings.NewReader("hello"), 5)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), info.Key)
+	assert.Equal(t, int64(5), info.Size)
+
+	r, err := store.Open(ctx, info.Key)
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "hello", string(content))
+
+	_, err = store.Open(ctx, strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	_, err = store.Open(ctx, "../../etc/passwd")
+	assert.ErrorIs(t, err, ErrBlobNotFound, "keys cannot escape the store")
+}
+
+func TestFileBlobStore_Deduplicates(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileBlobStore(t.TempDir(), zap.NewNop())
+	require.NoError(t, err)
+
+	first, err := store.Put(ctx, strings.NewReader("same"), 100)
+	require.NoError(t, err)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(store.path(first.Key), old, old))
+	second, err := store.Put(ctx, strings.NewReader("same"), 100)
+	require.NoError(t, err)
+	assert.Equal(t, first.Key, second.Key)
+	_, err = store.Put(ctx, strings.NewReader("other"), 100)
+	require.NoError(t, err)
+
+	blobs, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	for _, b := range blobs {
+		if b.Key == first.Key {
+			assert.True(t, b.ModTime.After(old), "storing content again refreshes its time")
+		}
+	}
+}
+
+func TestFileBlobStore_SizeLimitAndDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir, zap.NewNop())
+	require.NoError(t, err)
+
+	_, err = store.Put(ctx, strings.NewReader("too long"), 4)
+	assert.ErrorIs(t, err, ErrBlobTooLarge)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "rejected uploads leave nothing behind")
+
+	info, err := store.Put(ctx, strings.NewReader("ok"), 4)
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(ctx, info.Key))
+	assert.ErrorIs(t, store.Delete(ctx, info.Key), ErrBlobNotFound)
+	blobs, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, blobs)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/model"
+)
+
+// HistoryRepository stores the change history of tasks. Entries are never
+// changed or removed, and they outlive the tasks they describe.
+type HistoryRepository interface {
+	// AppendHistory stores entry as the next revision of its task, numbered from
+	// 1, and sets entry.Revision accordingly.
+	AppendHistory(ctx context.Context, entry *model.HistoryEntry) error
+	// ListHistory returns the entries of taskID ordered by revision, or an empty
+	// slice if there are none.
+	ListHistory(ctx context.Context, taskID string) ([]*model.HistoryEntry, error)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// CreateAttachment adds a new attachment to an existing task.
+func (r *InMemoryTaskRepository) CreateAttachment(ctx context.Context, attachment *model.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tasks[attachment.TaskID]; !exists {
+		r.logger.Warn("task not found for attachment", zap.String("id", attachment.TaskID))
+		return ErrTaskNotFound
+	}
+	if _, exists := r.attachments[attachment.TaskID][attachment.ID]; exists {
+		return ErrAttachmentAlreadyExists
+	}
+	stored := attachment.Clone()
+	if err := r.logWrite(journalRecord{Op: opAttachmentPut, ID: attachment.TaskID, Attachment: stored}); err != nil {
+		return err
+	}
+	r.state().putAttachment(stored)
+	r.logger.Info("attachment created", zap.String("task", attachment.TaskID), zap.String("id", attachment.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// GetAttachment retrieves attachment id on taskID.
+func (r *InMemoryTaskRepository) GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attachment, exists := r.attachments[taskID][id]
+	if !exists {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment.Clone(), nil
+}
+
+// ListAttachments returns the attachments of taskID ordered by CreatedAt, then ID.
+func (r *InMemoryTaskRepository) ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attachments := make([]*model.Attachment, 0, len(r.attachments[taskID]))
+	for _, a := range r.attachments[taskID] {
+		attachments = append(attachments, a.Clone())
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return attachments[i].ID < attachments[j].ID
+	})
+	return attachments, nil
+}
+
+// DeleteAttachment removes attachment id from taskID.
+func (r *InMemoryTaskRepository) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attachment, exists := r.attachments[taskID][id]
+	if !exists {
+		return ErrAttachmentNotFound
+	}
+	if err := r.logWrite(journalRecord{Op: opAttachmentDelete, ID: taskID, Attachment: attachment}); err != nil {
+		return err
+	}
+	r.state().deleteAttachment(taskID, id)
+	r.logger.Info("attachment deleted", zap.String("task", taskID), zap.String("id", id))
+	r.maybeSnapshot()
+	return nil
+}
+
+// ListAttachmentBlobs returns the distinct digests referenced by attachments.
+func (r *InMemoryTaskRepository) ListAttachmentBlobs(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := []string{}
+	for _, attachments := range r.attachments {
+		for _, a := range attachments {
+			keys = append(keys, a.SHA256)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys), nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// CreateComment adds a new comment to an existing task.
+func (r *InMemoryTaskRepository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tasks[comment.TaskID]; !exists {
+		r.logger.Warn("task not found for comment", zap.String("id", comment.TaskID))
+		return ErrTaskNotFound
+	}
+	if _, exists := r.comments[comment.TaskID][comment.ID]; exists {
+		return ErrCommentAlreadyExists
+	}
+	stored := comment.Clone()
+	if err := r.logWrite(journalRecord{Op: opCommentPut, ID: comment.TaskID, Comment: stored}); err != nil {
+		return err
+	}
+	r.state().putComment(stored)
+	r.logger.Info("comment created", zap.String("task", comment.TaskID), zap.String("id", comment.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// GetComment retrieves comment id on taskID.
+func (r *InMemoryTaskRepository) GetComment(ctx context.Context, taskID, id string) (*model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comment, exists := r.comments[taskID][id]
+	if !exists {
+		return nil, ErrCommentNotFound
+	}
+	return comment.Clone(), nil
+}
+
+// UpdateComment replaces an existing comment.
+func (r *InMemoryTaskRepository) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.comments[comment.TaskID][comment.ID]; !exists {
+		return ErrCommentNotFound
+	}
+	stored := comment.Clone()
+	if err := r.logWrite(journalRecord{Op: opCommentPut, ID: comment.TaskID, Comment: stored}); err != nil {
+		return err
+	}
+	r.state().putComment(stored)
+	r.logger.Info("comment updated", zap.String("task", comment.TaskID), zap.String("id", comment.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// DeleteComment removes comment id from taskID.
+func (r *InMemoryTaskRepository) DeleteComment(ctx context.Context, taskID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comment, exists := r.comments[taskID][id]
+	if !exists {
+		return ErrCommentNotFound
+	}
+	if err := r.logWrite(journalRecord{Op: opCommentDelete, ID: taskID, Comment: comment}); err != nil {
+		return err
+	}
+	r.state().deleteComment(taskID, id)
+	r.logger.Info("comment deleted", zap.String("task", taskID), zap.String("id", id))
+	r.maybeSnapshot()
+	return nil
+}
+
+// ListComments returns the page of comments selected by q.
+func (r *InMemoryTaskRepository) ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comments := make([]*model.Comment, 0, len(r.comments[q.TaskID]))
+	for _, c := range r.comments[q.TaskID] {
+		comments = append(comments, c.Clone())
+	}
+	return q.Apply(comments), nil
+}
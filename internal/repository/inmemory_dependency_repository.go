@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// AddDependency records that dep.TaskID is blocked by dep.BlockerID.
+func (r *InMemoryTaskRepository) AddDependency(ctx context.Context, dep *model.Dependency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range []string{dep.TaskID, dep.BlockerID} {
+		if _, exists := r.tasks[id]; !exists {
+			r.logger.Warn("task not found for dependency", zap.String("id", id))
+			return ErrTaskNotFound
+		}
+	}
+	if _, exists := r.blockers[dep.TaskID][dep.BlockerID]; exists {
+		return ErrDependencyAlreadyExists
+	}
+	if dep.WouldCycle(r.blockerIDs) {
+		r.logger.Warn("dependency would create a cycle",
+			zap.String("task", dep.TaskID), zap.String("blocker", dep.BlockerID))
+		return ErrDependencyCycle
+	}
+	stored := dep.Clone()
+	if err := r.logWrite(journalRecord{Op: opDependencyPut, ID: dep.TaskID, Dependency: stored}); err != nil {
+		return err
+	}
+	r.state().putDependency(stored)
+	r.indexDependent(dep.BlockerID, dep.TaskID)
+	r.logger.Info("dependency added", zap.String("task", dep.TaskID), zap.String("blocker", dep.BlockerID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// RemoveDependency deletes the edge between taskID and blockerID.
+func (r *InMemoryTaskRepository) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dep, exists := r.blockers[taskID][blockerID]
+	if !exists {
+		return ErrDependencyNotFound
+	}
+	if err := r.logWrite(journalRecord{Op: opDependencyDelete, ID: taskID, Dependency: dep}); err != nil {
+		return err
+	}
+	r.state().deleteDependency(taskID, blockerID)
+	r.unindexDependent(blockerID, taskID)
+	r.logger.Info("dependency removed", zap.String("task", taskID), zap.String("blocker", blockerID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// ListDependencies returns every edge ordered by task ID, then blocker ID.
+func (r *InMemoryTaskRepository) ListDependencies(ctx context.Context) ([]*model.Dependency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deps := []*model.Dependency{}
+	for _, edges := range r.blockers {
+		for _, d := range edges {
+			deps = append(deps, d.Clone())
+		}
+	}
+	sortDependencies(deps)
+	return deps, nil
+}
+
+// ListBlockers returns the edges to the tasks blocking taskID.
+func (r *InMemoryTaskRepository) ListBlockers(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deps := []*model.Dependency{}
+	for _, d := range r.blockers[taskID] {
+		deps = append(deps, d.Clone())
+	}
+	sortDependencies(deps)
+	return deps, nil
+}
+
+// ListDependents returns the edges from the tasks blocked by taskID.
+func (r *InMemoryTaskRepository) ListDependents(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deps := []*model.Dependency{}
+	for id := range r.dependents[taskID] {
+		deps = append(deps, r.blockers[id][taskID].Clone())
+	}
+	sortDependencies(deps)
+	return deps, nil
+}
+
+// blockerIDs lists the blockers of task id. Callers hold r.mu.
+func (r *InMemoryTaskRepository) blockerIDs(id string) []string {
+	ids := make([]string, 0, len(r.blockers[id]))
+	for blockerID := range r.blockers[id] {
+		ids = append(ids, blockerID)
+	}
+	return ids
+}
+
+func (r *InMemoryTaskRepository) indexDependent(blockerID, taskID string) {
+	ids := r.dependents[blockerID]
+	if ids == nil {
+		ids = make(map[string]struct{})
+		r.dependents[blockerID] = ids
+	}
+	ids[taskID] = struct{}{}
+}
+
+func (r *InMemoryTaskRepository) unindexDependent(blockerID, taskID string) {
+	delete(r.dependents[blockerID], taskID)
+	if len(r.dependents[blockerID]) == 0 {
+		delete(r.dependents, blockerID)
+	}
+}
+
+// dropDependencies removes every edge of a deleted task. The journal's delete
+// record implies the same, so nothing extra is logged. Callers hold r.mu.
+func (r *InMemoryTaskRepository) dropDependencies(id string) {
+	state := r.state()
+	for blockerID := range r.blockers[id] {
+		r.unindexDependent(blockerID, id)
+	}
+	delete(r.blockers, id)
+	for taskID := range r.dependents[id] {
+		state.deleteDependency(taskID, id)
+	}
+	delete(r.dependents, id)
+}
+
+// sortDependencies orders deps by task ID, then blocker ID.
+func sortDependencies(deps []*model.Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].TaskID != deps[j].TaskID {
+			return deps[i].TaskID < deps[j].TaskID
+		}
+		return deps[i].BlockerID < deps[j].BlockerID
+	})
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// AppendHistory stores entry as the next revision of its task.
+func (r *InMemoryTaskRepository) AppendHistory(ctx context.Context, entry *model.HistoryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := entry.Clone()
+	stored.Revision = int64(len(r.history[entry.TaskID])) + 1
+	if err := r.logWrite(journalRecord{Op: opHistoryAppend, ID: entry.TaskID, History: stored}); err != nil {
+		return err
+	}
+	r.history[entry.TaskID] = append(r.history[entry.TaskID], stored)
+	entry.Revision = stored.Revision
+	r.logger.Debug("history appended", zap.String("id", entry.TaskID), zap.Int64("revision", stored.Revision))
+	r.maybeSnapshot()
+	return nil
+}
+
+// ListHistory returns the entries of taskID ordered by revision.
+func (r *InMemoryTaskRepository) ListHistory(ctx context.Context, taskID string) ([]*model.HistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]*model.HistoryEntry, 0, len(r.history[taskID]))
+	for _, e := range r.history[taskID] {
+		entries = append(entries, e.Clone())
+	}
+	return entries, nil
+}
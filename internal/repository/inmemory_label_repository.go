@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// labelIndex maps each label name to the IDs of the tasks carrying it.
+type labelIndex map[string]map[string]struct{}
+
+func (x labelIndex) add(task *model.Task) {
+	for _, name := range task.Labels {
+		ids := x[name]
+		if ids == nil {
+			ids = make(map[string]struct{})
+			x[name] = ids
+		}
+		ids[task.ID] = struct{}{}
+	}
+}
+
+func (x labelIndex) remove(task *model.Task) {
+	for _, name := range task.Labels {
+		delete(x[name], task.ID)
+		if len(x[name]) == 0 {
+			delete(x, name)
+		}
+	}
+}
+
+// lookup returns the IDs of the tasks carrying all (or any) of names.
+func (x labelIndex) lookup(names []string, match model.LabelMatch) map[string]struct{} {
+	if match == model.LabelMatchAny {
+		ids := make(map[string]struct{})
+		for _, name := range names {
+			for id := range x[name] {
+				ids[id] = struct{}{}
+			}
+		}
+		return ids
+	}
+	// Intersect starting from the rarest label so the work is bounded by it.
+	smallest := x[names[0]]
+	for _, name := range names[1:] {
+		if len(x[name]) < len(smallest) {
+			smallest = x[name]
+		}
+	}
+	ids := make(map[string]struct{}, len(smallest))
+outer:
+	for id := range smallest {
+		for _, name := range names {
+			if _, ok := x[name][id]; !ok {
+				continue outer
+			}
+		}
+		ids[id] = struct{}{}
+	}
+	return ids
+}
+
+// CreateLabel adds a new label to the repository.
+func (r *InMemoryTaskRepository) CreateLabel(ctx context.Context, label *model.Label) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.labels[label.Name]; exists {
+		r.logger.Warn("label already exists", zap.String("name", label.Name))
+		return ErrLabelAlreadyExists
+	}
+	stored := label.Clone()
+	if err := r.logWrite(journalRecord{Op: opLabelPut, ID: label.Name, Label: stored}); err != nil {
+		return err
+	}
+	r.labels[label.Name] = stored
+	r.logger.Info("label created", zap.String("name", label.Name))
+	r.maybeSnapshot()
+	return nil
+}
+
+// GetLabel retrieves a label by its name.
+func (r *InMemoryTaskRepository) GetLabel(ctx context.Context, name string) (*model.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	label, exists := r.labels[name]
+	if !exists {
+		r.logger.Warn("label not found", zap.String("name", name))
+		return nil, ErrLabelNotFound
+	}
+	return label.Clone(), nil
+}
+
+// ListLabels returns all labels ordered by name.
+func (r *InMemoryTaskRepository) ListLabels(ctx context.Context) ([]*model.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	labels := make([]*model.Label, 0, len(r.labels))
+	for _, label := range r.labels {
+		labels = append(labels, label.Clone())
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels, nil
+}
+
+// UpdateLabel replaces the color and UpdatedAt of an existing label.
+func (r *InMemoryTaskRepository) UpdateLabel(ctx context.Context, label *model.Label) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, exists := r.labels[label.Name]
+	if !exists {
+		r.logger.Warn("label not found for update", zap.String("name", label.Name))
+		return ErrLabelNotFound
+	}
+	stored := current.Clone()
+	stored.Color = label.Color
+	stored.UpdatedAt = label.UpdatedAt
+	if err := r.logWrite(journalRecord{Op: opLabelPut, ID: label.Name, Label: stored}); err != nil {
+		return err
+	}
+	r.labels[label.Name] = stored
+	*label = *stored
+	r.logger.Info("label updated", zap.String("name", label.Name))
+	r.maybeSnapshot()
+	return nil
+}
+
+// DeleteLabel removes a label and detaches it from every task carrying it.
+func (r *InMemoryTaskRepository) DeleteLabel(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.labels[name]; !exists {
+		r.logger.Warn("label not found for delete", zap.String("name", name))
+		return ErrLabelNotFound
+	}
+	var detached []*model.Task
+	for id := range r.byLabel[name] {
+		stored := r.tasks[id].Clone()
+		stored.Labels = model.NormalizeLabels(slices.DeleteFunc(stored.Labels, func(l string) bool { return l == name }))
+		stored.Version++
+		detached = append(detached, stored)
+	}
+	if err := r.logWrite(journalRecord{Op: opLabelDelete, ID: name, Tasks: detached}); err != nil {
+		return err
+	}
+	delete(r.labels, name)
+	delete(r.byLabel, name)
+	for _, task := range detached {
+		r.tasks[task.ID] = task
+	}
+	r.logger.Info("label deleted", zap.String("name", name), zap.Int("detached", len(detached)))
+	r.maybeSnapshot()
+	return nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// CreateProject adds a new project to the repository.
+func (r *InMemoryTaskRepository) CreateProject(ctx context.Context, project *model.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.projects[project.ID]; exists {
+		r.logger.Warn("project already exists", zap.String("id", project.ID))
+		return ErrProjectAlreadyExists
+	}
+	stored := project.Clone()
+	if err := r.logWrite(journalRecord{Op: opProjectPut, ID: project.ID, Project: stored}); err != nil {
+		return err
+	}
+	r.projects[project.ID] = stored
+	r.logger.Info("project created", zap.String("id", project.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// GetProject retrieves a project by ID.
+func (r *InMemoryTaskRepository) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	project, exists := r.projects[id]
+	if !exists {
+		r.logger.Warn("project not found", zap.String("id", id))
+		return nil, ErrProjectNotFound
+	}
+	return project.Clone(), nil
+}
+
+// ListProjects returns all projects ordered by ID.
+func (r *InMemoryTaskRepository) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	projects := make([]*model.Project, 0, len(r.projects))
+	for _, project := range r.projects {
+		projects = append(projects, project.Clone())
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+// UpdateProject replaces the name, description, settings and UpdatedAt of an
+// existing project.
+func (r *InMemoryTaskRepository) UpdateProject(ctx context.Context, project *model.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, exists := r.projects[project.ID]
+	if !exists {
+		r.logger.Warn("project not found for update", zap.String("id", project.ID))
+		return ErrProjectNotFound
+	}
+	stored := project.Clone()
+	stored.LastTaskNumber = current.LastTaskNumber
+	stored.CreatedAt = current.CreatedAt
+	if err := r.logWrite(journalRecord{Op: opProjectPut, ID: project.ID, Project: stored}); err != nil {
+		return err
+	}
+	r.projects[project.ID] = stored
+	*project = *stored.Clone()
+	r.logger.Info("project updated", zap.String("id", project.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// DeleteProject removes a project that has no tasks.
+func (r *InMemoryTaskRepository) DeleteProject(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.projects[id]; !exists {
+		r.logger.Warn("project not found for delete", zap.String("id", id))
+		return ErrProjectNotFound
+	}
+	for _, task := range r.tasks {
+		if task.ProjectID == id {
+			r.logger.Warn("project has tasks", zap.String("id", id))
+			return ErrProjectNotEmpty
+		}
+	}
+	if err := r.logWrite(journalRecord{Op: opProjectDelete, ID: id}); err != nil {
+		return err
+	}
+	delete(r.projects, id)
+	r.logger.Info("project deleted", zap.String("id", id))
+	r.maybeSnapshot()
+	return nil
+}
+
+// NextTaskNumber increments and returns the LastTaskNumber of a project.
+func (r *InMemoryTaskRepository) NextTaskNumber(ctx context.Context, id string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, exists := r.projects[id]
+	if !exists {
+		r.logger.Warn("project not found", zap.String("id", id))
+		return 0, ErrProjectNotFound
+	}
+	stored := current.Clone()
+	stored.LastTaskNumber++
+	if err := r.logWrite(journalRecord{Op: opProjectPut, ID: id, Project: stored}); err != nil {
+		return 0, err
+	}
+	r.projects[id] = stored
+	r.maybeSnapshot()
+	return stored.LastTaskNumber, nil
+}
@@ -3,7 +3,7 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"sort"
 	"sync"
 	"taskmanager/internal/model"
 
@@ -11,18 +11,82 @@ import (
 )
 
 // InMemoryTaskRepository is a thread-safe in-memory implementation of TaskRepository.
+// Tasks are copied on the way in and out, so callers never share state with the map.
+// When opened with OpenJournaledTaskRepository every write is first appended to a
+// durable journal, so the map can be rebuilt after a restart or crash.
 type InMemoryTaskRepository struct {
-	mu     sync.RWMutex
-	tasks  map[string]*model.Task
-	logger *zap.Logger
+	mu      sync.RWMutex
+	tasks   map[string]*model.Task
+	labels  map[string]*model.Label
+	byLabel labelIndex
+	// children maps parent IDs to the IDs of their direct subtasks.
+	children map[string]map[string]struct{}
+	// assigned maps user IDs to the IDs of the tasks assigned to them.
+	assigned map[string]map[string]struct{}
+	// blockers holds the dependency edges by blocked task; dependents is its
+	// reverse index by blocker.
+	blockers   map[string]map[string]*model.Dependency
+	dependents map[string]map[string]struct{}
+	// history holds the entries of every task, including deleted ones.
+	history map[string][]*model.HistoryEntry
+	// comments holds the comments of every task by task ID, then comment ID.
+	comments map[string]map[string]*model.Comment
+	// attachments holds the attachments of every task by task ID, then
+	// attachment ID.
+	attachments map[string]map[string]*model.Attachment
+	// users holds the users by ID.
+	users map[string]*model.User
+	// projects holds the projects by ID.
+	projects map[string]*model.Project
+	journal  *journal
+	logger   *zap.Logger
 }
 
 // NewInMemoryTaskRepository creates a new InMemoryTaskRepository.
 func NewInMemoryTaskRepository(logger *zap.Logger) *InMemoryTaskRepository {
-	return &InMemoryTaskRepository{
-		tasks:  make(map[string]*model.Task),
-		logger: logger,
+	return newInMemoryTaskRepository(newMemState(), nil, logger)
+}
+
+func newInMemoryTaskRepository(state *memState, j *journal, logger *zap.Logger) *InMemoryTaskRepository {
+	r := &InMemoryTaskRepository{
+		tasks:       state.tasks,
+		labels:      state.labels,
+		byLabel:     make(labelIndex),
+		children:    make(map[string]map[string]struct{}),
+		assigned:    make(map[string]map[string]struct{}),
+		blockers:    state.blockers,
+		dependents:  make(map[string]map[string]struct{}),
+		history:     state.history,
+		comments:    state.comments,
+		attachments: state.attachments,
+		users:       state.users,
+		projects:    state.projects,
+		journal:     j,
+		logger:      logger,
+	}
+	for _, task := range r.tasks {
+		if task.Status == "" {
+			// Journaled before tasks had a status.
+			task.Status = model.LegacyStatus(task.Completed)
+		}
+		r.index(task)
+	}
+	for taskID, edges := range r.blockers {
+		for blockerID := range edges {
+			r.indexDependent(blockerID, taskID)
+		}
+	}
+	return r
+}
+
+// OpenJournaledTaskRepository creates an InMemoryTaskRepository backed by a
+// write-ahead journal in opts.Dir, replaying any existing snapshot and journal first.
+func OpenJournaledTaskRepository(opts JournalOptions, logger *zap.Logger) (*InMemoryTaskRepository, error) {
+	j, state, err := openJournal(opts, logger)
+	if err != nil {
+		return nil, err
 	}
+	return newInMemoryTaskRepository(state, j, logger), nil
 }
 
 // CreateTask adds a new task to the repository.
@@ -31,60 +95,357 @@ func (r *InMemoryTaskRepository) CreateTask(ctx context.Context, task *model.Tas
 	defer r.mu.Unlock()
 	if _, exists := r.tasks[task.ID]; exists {
 		r.logger.Warn("task already exists", zap.String("id", task.ID))
-		return errors.New("task already exists")
+		return ErrTaskAlreadyExists
+	}
+	if err := r.checkLabels(task); err != nil {
+		return err
 	}
-	r.tasks[task.ID] = task
+	stored := task.Clone()
+	stored.Version = 1
+	if err := r.logWrite(journalRecord{Op: opCreate, ID: task.ID, Task: stored}); err != nil {
+		return err
+	}
+	r.tasks[task.ID] = stored
+	r.index(stored)
+	task.Version = stored.Version
 	r.logger.Info("task created", zap.String("id", task.ID))
+	r.maybeSnapshot()
 	return nil
 }
 
-// GetTask retrieves a task by its ID.
+// GetTask retrieves a live task by its ID.
 func (r *InMemoryTaskRepository) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	return r.getTask(id, false)
+}
+
+// GetTrashedTask retrieves a task in the trash by its ID.
+func (r *InMemoryTaskRepository) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
+	return r.getTask(id, true)
+}
+
+func (r *InMemoryTaskRepository) getTask(id string, trashed bool) (*model.Task, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	task, exists := r.tasks[id]
-	if !exists {
-		r.logger.Warn("task not found", zap.String("id", id))
-		return nil, errors.New("task not found")
+	if !exists || (task.DeletedAt != nil) != trashed {
+		r.logger.Warn("task not found", zap.String("id", id), zap.Bool("trashed", trashed))
+		return nil, ErrTaskNotFound
 	}
 	r.logger.Debug("task retrieved", zap.String("id", id))
-	return task, nil
+	return task.Clone(), nil
 }
 
-// ListTasks returns all tasks in the repository.
+// ListTasks returns all live tasks in the repository ordered by CreatedAt, then ID.
 func (r *InMemoryTaskRepository) ListTasks(ctx context.Context) ([]*model.Task, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	tasks := make([]*model.Task, 0, len(r.tasks))
 	for _, task := range r.tasks {
-		tasks = append(tasks, task)
+		if task.DeletedAt == nil {
+			tasks = append(tasks, task.Clone())
+		}
 	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
 	r.logger.Debug("listed tasks", zap.Int("count", len(tasks)))
 	return tasks, nil
 }
 
+// CountTasks returns the number of live tasks.
+func (r *InMemoryTaskRepository) CountTasks(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, task := range r.tasks {
+		if task.DeletedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// QueryTasks returns a page of tasks matching q. Parent, assignee and label
+// filters narrow the candidates through their indexes before the remaining filters run.
+func (r *InMemoryTaskRepository) QueryTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var tasks []*model.Task
+	if ids := r.candidates(q.Filter); ids != nil {
+		tasks = make([]*model.Task, 0, len(ids))
+		for id := range ids {
+			tasks = append(tasks, r.tasks[id])
+		}
+	} else {
+		tasks = make([]*model.Task, 0, len(r.tasks))
+		for _, task := range r.tasks {
+			tasks = append(tasks, task)
+		}
+	}
+	page := q.Apply(tasks)
+	for i, task := range page.Tasks {
+		page.Tasks[i] = task.Clone()
+	}
+	r.logger.Debug("queried tasks", zap.Int("count", len(page.Tasks)))
+	return page, nil
+}
+
 // UpdateTask updates an existing task in the repository.
 func (r *InMemoryTaskRepository) UpdateTask(ctx context.Context, task *model.Task) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.tasks[task.ID]; !exists {
+	current, exists := r.tasks[task.ID]
+	if !exists {
 		r.logger.Warn("task not found for update", zap.String("id", task.ID))
-		return errors.New("task not found")
+		return ErrTaskNotFound
+	}
+	if current.Version != task.Version {
+		r.logger.Warn("stale task version", zap.String("id", task.ID),
+			zap.Int64("expected", task.Version), zap.Int64("actual", current.Version))
+		return &VersionConflictError{ID: task.ID, Expected: task.Version, Actual: current.Version}
 	}
-	r.tasks[task.ID] = task
+	if err := r.checkLabels(task); err != nil {
+		return err
+	}
+	stored := task.Clone()
+	stored.Version = current.Version + 1
+	if err := r.logWrite(journalRecord{Op: opUpdate, ID: task.ID, Task: stored}); err != nil {
+		return err
+	}
+	r.unindex(current)
+	r.tasks[task.ID] = stored
+	r.index(stored)
+	task.Version = stored.Version
 	r.logger.Info("task updated", zap.String("id", task.ID))
+	r.maybeSnapshot()
 	return nil
 }
 
 // DeleteTask removes a task by its ID from the repository.
-func (r *InMemoryTaskRepository) DeleteTask(ctx context.Context, id string) error {
+func (r *InMemoryTaskRepository) DeleteTask(ctx context.Context, id string, version int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.tasks[id]; !exists {
+	current, exists := r.tasks[id]
+	if !exists {
 		r.logger.Warn("task not found for delete", zap.String("id", id))
-		return errors.New("task not found")
+		return ErrTaskNotFound
+	}
+	if version != AnyVersion && current.Version != version {
+		r.logger.Warn("stale task version for delete", zap.String("id", id),
+			zap.Int64("expected", version), zap.Int64("actual", current.Version))
+		return &VersionConflictError{ID: id, Expected: version, Actual: current.Version}
+	}
+	if err := r.logWrite(journalRecord{Op: opDelete, ID: id}); err != nil {
+		return err
 	}
-	delete(r.tasks, id)
+	r.remove(current)
 	r.logger.Info("task deleted", zap.String("id", id))
+	r.maybeSnapshot()
 	return nil
 }
+
+// remove drops the stored task with its dependencies, comments and
+// attachments.
+func (r *InMemoryTaskRepository) remove(task *model.Task) {
+	r.unindex(task)
+	delete(r.tasks, task.ID)
+	r.dropDependencies(task.ID)
+	delete(r.comments, task.ID)
+	delete(r.attachments, task.ID)
+}
+
+// WriteTasks creates, updates and deletes the tasks in batch as one journal
+// record, after checking every one of them.
+func (r *InMemoryTaskRepository) WriteTasks(ctx context.Context, batch TaskBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := make(map[string]bool, len(batch.Create)+len(batch.Update)+len(batch.Delete))
+	stored := make([]*model.Task, 0, len(batch.Create)+len(batch.Update))
+	for _, task := range batch.Create {
+		if _, exists := r.tasks[task.ID]; exists || seen[task.ID] {
+			r.logger.Warn("task already exists", zap.String("id", task.ID))
+			return ErrTaskAlreadyExists
+		}
+		if err := r.checkLabels(task); err != nil {
+			return err
+		}
+		seen[task.ID] = true
+		t := task.Clone()
+		t.Version = 1
+		stored = append(stored, t)
+	}
+	for _, task := range batch.Update {
+		current, exists := r.tasks[task.ID]
+		if !exists || seen[task.ID] {
+			r.logger.Warn("task not found for update", zap.String("id", task.ID))
+			return ErrTaskNotFound
+		}
+		if current.Version != task.Version {
+			r.logger.Warn("stale task version", zap.String("id", task.ID),
+				zap.Int64("expected", task.Version), zap.Int64("actual", current.Version))
+			return &VersionConflictError{ID: task.ID, Expected: task.Version, Actual: current.Version}
+		}
+		if err := r.checkLabels(task); err != nil {
+			return err
+		}
+		seen[task.ID] = true
+		t := task.Clone()
+		t.Version = current.Version + 1
+		stored = append(stored, t)
+	}
+	deleted := make([]string, 0, len(batch.Delete))
+	for _, task := range batch.Delete {
+		current, exists := r.tasks[task.ID]
+		if !exists || seen[task.ID] {
+			r.logger.Warn("task not found for delete", zap.String("id", task.ID))
+			return ErrTaskNotFound
+		}
+		if task.Version != AnyVersion && current.Version != task.Version {
+			r.logger.Warn("stale task version for delete", zap.String("id", task.ID),
+				zap.Int64("expected", task.Version), zap.Int64("actual", current.Version))
+			return &VersionConflictError{ID: task.ID, Expected: task.Version, Actual: current.Version}
+		}
+		seen[task.ID] = true
+		deleted = append(deleted, task.ID)
+	}
+	if err := r.logWrite(journalRecord{Op: opTasksPut, Tasks: stored, Deleted: deleted}); err != nil {
+		return err
+	}
+	for _, id := range deleted {
+		r.remove(r.tasks[id])
+	}
+	for i, t := range stored {
+		if current, exists := r.tasks[t.ID]; exists {
+			r.unindex(current)
+		}
+		r.tasks[t.ID] = t
+		r.index(t)
+		if i < len(batch.Create) {
+			batch.Create[i].Version = t.Version
+		} else {
+			batch.Update[i-len(batch.Create)].Version = t.Version
+		}
+	}
+	r.logger.Info("tasks written", zap.Int("created", len(batch.Create)), zap.Int("updated", len(batch.Update)),
+		zap.Int("deleted", len(batch.Delete)))
+	r.maybeSnapshot()
+	return nil
+}
+
+// Snapshot compacts the journal into a snapshot of the current state.
+// It is a no-op for repositories without a journal.
+func (r *InMemoryTaskRepository) Snapshot() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.snapshot(r.state())
+}
+
+// Close flushes and closes the journal, if any.
+func (r *InMemoryTaskRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.journal == nil {
+		return nil
+	}
+	return r.journal.close()
+}
+
+// candidates returns the IDs of the tasks that can match f according to the
+// parent, assignee and label indexes, or nil when f uses none of them.
+func (r *InMemoryTaskRepository) candidates(f model.TaskFilter) map[string]struct{} {
+	if f.ParentID != nil && *f.ParentID != "" {
+		return r.children[*f.ParentID]
+	}
+	if f.Assignee != nil && *f.Assignee != "" {
+		return r.assigned[*f.Assignee]
+	}
+	if len(f.Labels) > 0 {
+		return r.byLabel.lookup(f.Labels, f.LabelMatch)
+	}
+	return nil
+}
+
+// index adds task to the secondary indexes. Callers hold r.mu.
+func (r *InMemoryTaskRepository) index(task *model.Task) {
+	r.byLabel.add(task)
+	addToIndex(r.children, task.ParentID, task.ID)
+	addToIndex(r.assigned, task.Assignee, task.ID)
+}
+
+// unindex removes task from the secondary indexes. Callers hold r.mu.
+func (r *InMemoryTaskRepository) unindex(task *model.Task) {
+	r.byLabel.remove(task)
+	removeFromIndex(r.children, task.ParentID, task.ID)
+	removeFromIndex(r.assigned, task.Assignee, task.ID)
+}
+
+// addToIndex records id under key in a secondary index; empty keys are not
+// indexed.
+func addToIndex(index map[string]map[string]struct{}, key, id string) {
+	if key == "" {
+		return
+	}
+	ids := index[key]
+	if ids == nil {
+		ids = make(map[string]struct{})
+		index[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// removeFromIndex drops id from under key in a secondary index.
+func removeFromIndex(index map[string]map[string]struct{}, key, id string) {
+	if ids := index[key]; ids != nil {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+// state returns the repository data for a journal snapshot. Callers hold r.mu.
+func (r *InMemoryTaskRepository) state() *memState {
+	return &memState{tasks: r.tasks, labels: r.labels, blockers: r.blockers, history: r.history, comments: r.comments,
+		attachments: r.attachments, users: r.users, projects: r.projects}
+}
+
+// checkLabels returns UnknownLabel for the first label of task that does not
+// exist. Callers hold r.mu.
+func (r *InMemoryTaskRepository) checkLabels(task *model.Task) error {
+	for _, name := range task.Labels {
+		if _, ok := r.labels[name]; !ok {
+			r.logger.Warn("unknown label", zap.String("id", task.ID), zap.String("label", name))
+			return UnknownLabel(name)
+		}
+	}
+	return nil
+}
+
+// logWrite appends rec to the journal before the map is changed. Callers hold r.mu.
+func (r *InMemoryTaskRepository) logWrite(rec journalRecord) error {
+	if r.journal == nil {
+		return nil
+	}
+	if err := r.journal.append(rec); err != nil {
+		r.logger.Error("journal append failed", zap.String("id", rec.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// maybeSnapshot compacts the journal once it grows past the configured size.
+// A failed snapshot is logged but not returned: the write itself is already durable.
+func (r *InMemoryTaskRepository) maybeSnapshot() {
+	if r.journal == nil || !r.journal.shouldSnapshot() {
+		return
+	}
+	if err := r.journal.snapshot(r.state()); err != nil {
+		r.logger.Error("journal snapshot failed", zap.Error(err))
+	}
+}
@@ -45,13 +45,13 @@ func TestInMemoryTaskRepository_CRUD(t *testing.T) {
 
 	// Update
 	task.Title = "Updated Title"
+	task.Completed = true
 	err = repo.UpdateTask(ctx, task)
 	assert.NoError(t, err)
 	got, _ = repo.GetTask(ctx, task.ID)
 	assert.Equal(t, "Updated Title", got.Title)
-	task.Completed = true
 	// Delete
-	err = repo.DeleteTask(ctx, task.ID)
+	err = repo.DeleteTask(ctx, task.ID, AnyVersion)
 	assert.NoError(t, err)
 	_, err = repo.GetTask(ctx, task.ID)
 	assert.Equal(t, true, got.Completed)
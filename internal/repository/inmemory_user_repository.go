@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// CreateUser adds a new user to the repository.
+func (r *InMemoryTaskRepository) CreateUser(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.users[user.ID]; exists {
+		r.logger.Warn("user already exists", zap.String("id", user.ID))
+		return ErrUserAlreadyExists
+	}
+	stored := user.Clone()
+	if err := r.logWrite(journalRecord{Op: opUserPut, ID: user.ID, User: stored}); err != nil {
+		return err
+	}
+	r.users[user.ID] = stored
+	r.logger.Info("user created", zap.String("id", user.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// GetUser retrieves a user by ID.
+func (r *InMemoryTaskRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, exists := r.users[id]
+	if !exists {
+		r.logger.Warn("user not found", zap.String("id", id))
+		return nil, ErrUserNotFound
+	}
+	return user.Clone(), nil
+}
+
+// ListUsers returns all users ordered by ID.
+func (r *InMemoryTaskRepository) ListUsers(ctx context.Context) ([]*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]*model.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user.Clone())
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// UpdateUser replaces the name, email and UpdatedAt of an existing user.
+func (r *InMemoryTaskRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, exists := r.users[user.ID]
+	if !exists {
+		r.logger.Warn("user not found for update", zap.String("id", user.ID))
+		return ErrUserNotFound
+	}
+	stored := current.Clone()
+	stored.Name = user.Name
+	stored.Email = user.Email
+	stored.UpdatedAt = user.UpdatedAt
+	if err := r.logWrite(journalRecord{Op: opUserPut, ID: user.ID, User: stored}); err != nil {
+		return err
+	}
+	r.users[user.ID] = stored
+	*user = *stored
+	r.logger.Info("user updated", zap.String("id", user.ID))
+	r.maybeSnapshot()
+	return nil
+}
+
+// DeleteUser removes a user.
+func (r *InMemoryTaskRepository) DeleteUser(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.users[id]; !exists {
+		r.logger.Warn("user not found for delete", zap.String("id", id))
+		return ErrUserNotFound
+	}
+	if err := r.logWrite(journalRecord{Op: opUserDelete, ID: id}); err != nil {
+		return err
+	}
+	delete(r.users, id)
+	r.logger.Info("user deleted", zap.String("id", id))
+	r.maybeSnapshot()
+	return nil
+}
@@ -0,0 +1,736 @@
+package repository
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SyncPolicy controls when journal writes are flushed to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs after every record. Slowest, but no acknowledged write is lost.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval fsyncs in the background every JournalOptions.SyncInterval.
+	// A power loss can drop writes from the last interval.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system. Writes survive a process
+	// crash but not a power loss.
+	SyncNever
+)
+
+// ParseSyncPolicy converts "always", "interval" or "never" into a SyncPolicy.
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch s {
+	case "always":
+		return SyncAlways, nil
+	case "interval":
+		return SyncInterval, nil
+	case "never":
+		return SyncNever, nil
+	}
+	return 0, fmt.Errorf("unknown sync policy %q", s)
+}
+
+// JournalOptions configures the write-ahead journal of an InMemoryTaskRepository.
+type JournalOptions struct {
+	// Dir holds the journal and snapshot files. It is created if missing.
+	Dir string
+	// Sync is the fsync policy for journal appends.
+	Sync SyncPolicy
+	// SyncInterval is the flush period used with SyncInterval (default 1s).
+	SyncInterval time.Duration
+	// SnapshotEvery compacts the journal into a snapshot after this many records.
+	// Zero disables automatic snapshots.
+	SnapshotEvery int
+}
+
+const (
+	journalFile  = "journal.log"
+	snapshotFile = "snapshot.dat"
+
+	// recordHeaderSize is the length prefix plus the CRC32 checksum.
+	recordHeaderSize = 8
+	// maxRecordSize guards against allocating huge buffers for a corrupt length.
+	maxRecordSize = 64 << 20
+)
+
+// ErrJournalCorrupt is returned when a journal or snapshot fails its checksum
+// somewhere other than a torn write at the end of the journal.
+var ErrJournalCorrupt = errors.New("journal corrupt")
+
+// ErrJournalBroken is returned by every write after a failed append could not
+// be rolled back, leaving the journal with a partial record at its end.
+var ErrJournalBroken = errors.New("journal broken")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type journalOp string
+
+const (
+	opCreate journalOp = "create"
+	opUpdate journalOp = "update"
+	opDelete journalOp = "delete"
+	// opLabelPut creates or updates the label in Label.
+	opLabelPut journalOp = "label_put"
+	// opLabelDelete removes the label named ID; Tasks holds the tasks it was
+	// detached from, so the whole change is a single record.
+	opLabelDelete journalOp = "label_delete"
+	// opDependencyPut adds the edge in Dependency.
+	opDependencyPut journalOp = "dependency_put"
+	// opDependencyDelete removes the edge in Dependency.
+	opDependencyDelete journalOp = "dependency_delete"
+	// opHistoryAppend adds the entry in History to its task's history.
+	opHistoryAppend journalOp = "history_append"
+	// opCommentPut creates or updates the comment in Comment.
+	opCommentPut journalOp = "comment_put"
+	// opCommentDelete removes the comment in Comment.
+	opCommentDelete journalOp = "comment_delete"
+	// opAttachmentPut adds the attachment in Attachment.
+	opAttachmentPut journalOp = "attachment_put"
+	// opAttachmentDelete removes the attachment in Attachment.
+	opAttachmentDelete journalOp = "attachment_delete"
+	// opUserPut creates or updates the user in User.
+	opUserPut journalOp = "user_put"
+	// opUserDelete removes the user with ID.
+	opUserDelete journalOp = "user_delete"
+	// opProjectPut creates or updates the project in Project.
+	opProjectPut journalOp = "project_put"
+	// opProjectDelete removes the project with ID.
+	opProjectDelete journalOp = "project_delete"
+	// opTasksPut stores every task in Tasks and deletes the tasks with the IDs
+	// in Deleted, which WriteTasks wrote together.
+	opTasksPut journalOp = "tasks_put"
+)
+
+// journalRecord is a single logged mutation. Records carry the full task and
+// label state, so replaying them on top of a newer snapshot is idempotent.
+// Deleting a task also deletes its dependencies, comments and attachments,
+// during replay too.
+type journalRecord struct {
+	Op         journalOp           `json:"op"`
+	ID         string              `json:"id"`
+	Task       *model.Task         `json:"task,omitempty"`
+	Label      *model.Label        `json:"label,omitempty"`
+	Tasks      []*model.Task       `json:"tasks,omitempty"`
+	Deleted    []string            `json:"deleted,omitempty"`
+	Dependency *model.Dependency   `json:"dependency,omitempty"`
+	History    *model.HistoryEntry `json:"history,omitempty"`
+	Comment    *model.Comment      `json:"comment,omitempty"`
+	Attachment *model.Attachment   `json:"attachment,omitempty"`
+	User       *model.User         `json:"user,omitempty"`
+	Project    *model.Project      `json:"project,omitempty"`
+}
+
+// memState is the data of an InMemoryTaskRepository that the journal persists.
+type memState struct {
+	tasks  map[string]*model.Task
+	labels map[string]*model.Label
+	// blockers maps a task ID to its blockers' IDs and the dependency edges.
+	blockers map[string]map[string]*model.Dependency
+	// history holds each task's entries ordered by revision.
+	history map[string][]*model.HistoryEntry
+	// comments maps a task ID to its comments by comment ID.
+	comments map[string]map[string]*model.Comment
+	// attachments maps a task ID to its attachments by attachment ID.
+	attachments map[string]map[string]*model.Attachment
+	// users maps IDs to users.
+	users map[string]*model.User
+	// projects maps IDs to projects.
+	projects map[string]*model.Project
+}
+
+func newMemState() *memState {
+	return &memState{
+		tasks:       make(map[string]*model.Task),
+		labels:      make(map[string]*model.Label),
+		blockers:    make(map[string]map[string]*model.Dependency),
+		history:     make(map[string][]*model.HistoryEntry),
+		comments:    make(map[string]map[string]*model.Comment),
+		attachments: make(map[string]map[string]*model.Attachment),
+		users:       make(map[string]*model.User),
+		projects:    make(map[string]*model.Project),
+	}
+}
+
+// putHistory stores e at its revision. Entries already present are replaced,
+// which keeps replaying the journal over a newer snapshot idempotent.
+func (s *memState) putHistory(e *model.HistoryEntry) error {
+	entries := s.history[e.TaskID]
+	switch {
+	case e.Revision <= int64(len(entries)) && e.Revision > 0:
+		entries[e.Revision-1] = e
+	case e.Revision == int64(len(entries))+1:
+		s.history[e.TaskID] = append(entries, e)
+	default:
+		return fmt.Errorf("history revision %d of task %s follows revision %d", e.Revision, e.TaskID, len(entries))
+	}
+	return nil
+}
+
+// putDependency adds d to the state.
+func (s *memState) putDependency(d *model.Dependency) {
+	edges := s.blockers[d.TaskID]
+	if edges == nil {
+		edges = make(map[string]*model.Dependency)
+		s.blockers[d.TaskID] = edges
+	}
+	edges[d.BlockerID] = d
+}
+
+// deleteTask removes task id with its dependencies, comments and attachments.
+func (s *memState) deleteTask(id string) {
+	delete(s.tasks, id)
+	delete(s.blockers, id)
+	for taskID := range s.blockers {
+		s.deleteDependency(taskID, id)
+	}
+	delete(s.comments, id)
+	delete(s.attachments, id)
+}
+
+// deleteDependency removes the edge between taskID and blockerID.
+func (s *memState) deleteDependency(taskID, blockerID string) {
+	delete(s.blockers[taskID], blockerID)
+	if len(s.blockers[taskID]) == 0 {
+		delete(s.blockers, taskID)
+	}
+}
+
+// putComment adds or replaces c in the state.
+func (s *memState) putComment(c *model.Comment) {
+	comments := s.comments[c.TaskID]
+	if comments == nil {
+		comments = make(map[string]*model.Comment)
+		s.comments[c.TaskID] = comments
+	}
+	comments[c.ID] = c
+}
+
+// deleteComment removes comment id from taskID.
+func (s *memState) deleteComment(taskID, id string) {
+	delete(s.comments[taskID], id)
+	if len(s.comments[taskID]) == 0 {
+		delete(s.comments, taskID)
+	}
+}
+
+// putAttachment adds or replaces a in the state.
+func (s *memState) putAttachment(a *model.Attachment) {
+	attachments := s.attachments[a.TaskID]
+	if attachments == nil {
+		attachments = make(map[string]*model.Attachment)
+		s.attachments[a.TaskID] = attachments
+	}
+	attachments[a.ID] = a
+}
+
+// deleteAttachment removes attachment id from taskID.
+func (s *memState) deleteAttachment(taskID, id string) {
+	delete(s.attachments[taskID], id)
+	if len(s.attachments[taskID]) == 0 {
+		delete(s.attachments, taskID)
+	}
+}
+
+// snapshotData is the encoded form of a snapshot. Snapshots written before
+// labels existed are a bare JSON array of tasks.
+type snapshotData struct {
+	Tasks        []*model.Task         `json:"tasks"`
+	Labels       []*model.Label        `json:"labels,omitempty"`
+	Dependencies []*model.Dependency   `json:"dependencies,omitempty"`
+	History      []*model.HistoryEntry `json:"history,omitempty"`
+	Comments     []*model.Comment      `json:"comments,omitempty"`
+	Attachments  []*model.Attachment   `json:"attachments,omitempty"`
+	Users        []*model.User         `json:"users,omitempty"`
+	Projects     []*model.Project      `json:"projects,omitempty"`
+}
+
+// journalStore is the file a journal appends to; *os.File implements it.
+type journalStore interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// journal is an append-only log of task mutations with compacting snapshots.
+// It is not safe for concurrent use; the owning repository serialises access.
+type journal struct {
+	opts    JournalOptions
+	file    journalStore
+	records int
+	logger  *zap.Logger
+	// broken is why a failed append could not be rolled back; once set, every
+	// append fails.
+	broken error
+
+	mu     sync.Mutex // guards file against the background syncer
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// openJournal loads the snapshot and replays the journal in opts.Dir, returning the
+// recovered state and a journal ready for appends.
+func openJournal(opts JournalOptions, logger *zap.Logger) (*journal, *memState, error) {
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Second
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("create journal dir: %w", err)
+	}
+
+	state, err := readSnapshot(filepath.Join(opts.Dir, snapshotFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path := filepath.Join(opts.Dir, journalFile)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open journal: %w", err)
+	}
+	records, err := replayJournal(f, state, logger)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("seek journal: %w", err)
+	}
+
+	// Data written before task versions existed starts at version 1.
+	for _, t := range state.tasks {
+		if t.Version == 0 {
+			t.Version = 1
+		}
+	}
+
+	j := &journal{opts: opts, file: f, records: records, logger: logger}
+	if opts.Sync == SyncInterval {
+		j.stop = make(chan struct{})
+		j.done = make(chan struct{})
+		go j.syncLoop()
+	}
+	logger.Info("journal recovered",
+		zap.String("dir", opts.Dir), zap.Int("tasks", len(state.tasks)), zap.Int("labels", len(state.labels)),
+		zap.Int("replayed", records))
+	return j, state, nil
+}
+
+// append writes rec to the journal, honouring the sync policy.
+func (j *journal) append(rec journalRecord) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.broken != nil {
+		return fmt.Errorf("%w: %v", ErrJournalBroken, j.broken)
+	}
+	offset, err := j.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("seek journal: %w", err)
+	}
+	if _, err := j.file.Write(frame(payload)); err != nil {
+		j.rollback(offset)
+		return fmt.Errorf("append journal record: %w", err)
+	}
+	if j.opts.Sync == SyncAlways {
+		if err := j.file.Sync(); err != nil {
+			j.rollback(offset)
+			return fmt.Errorf("sync journal: %w", err)
+		}
+	}
+	j.records++
+	return nil
+}
+
+// rollback cuts the journal back to offset after a failed append, so that the
+// next record does not follow a partial one, which replay would reject. If
+// that fails too, the journal is marked broken.
+func (j *journal) rollback(offset int64) {
+	err := j.file.Truncate(offset)
+	if err == nil {
+		_, err = j.file.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		j.broken = err
+		j.logger.Error("journal rollback failed, refusing further writes", zap.Error(err))
+	}
+}
+
+// shouldSnapshot reports whether enough records accumulated for a compaction.
+func (j *journal) shouldSnapshot() bool {
+	return j.opts.SnapshotEvery > 0 && j.records >= j.opts.SnapshotEvery
+}
+
+// snapshot atomically writes state as the new snapshot and truncates the journal.
+// A crash between the two steps is harmless because replay is idempotent.
+func (j *journal) snapshot(state *memState) error {
+	data := snapshotData{Tasks: make([]*model.Task, 0, len(state.tasks))}
+	for _, t := range state.tasks {
+		data.Tasks = append(data.Tasks, t)
+	}
+	for _, l := range state.labels {
+		data.Labels = append(data.Labels, l)
+	}
+	for _, edges := range state.blockers {
+		for _, d := range edges {
+			data.Dependencies = append(data.Dependencies, d)
+		}
+	}
+	for _, entries := range state.history {
+		data.History = append(data.History, entries...)
+	}
+	for _, comments := range state.comments {
+		for _, c := range comments {
+			data.Comments = append(data.Comments, c)
+		}
+	}
+	for _, attachments := range state.attachments {
+		for _, a := range attachments {
+			data.Attachments = append(data.Attachments, a)
+		}
+	}
+	for _, u := range state.users {
+		data.Users = append(data.Users, u)
+	}
+	for _, p := range state.projects {
+		data.Projects = append(data.Projects, p)
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	path := filepath.Join(j.opts.Dir, snapshotFile)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, frame(payload)); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("install snapshot: %w", err)
+	}
+	if err := syncDir(j.opts.Dir); err != nil {
+		return fmt.Errorf("sync journal dir: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate journal: %w", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("sync journal: %w", err)
+	}
+	j.logger.Info("journal compacted", zap.Int("tasks", len(data.Tasks)), zap.Int("records", j.records))
+	j.records = 0
+	return nil
+}
+
+// close stops the background syncer, flushes and closes the journal file.
+func (j *journal) close() error {
+	if j.stop != nil {
+		close(j.stop)
+		<-j.done
+		j.stop = nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+	if err := j.file.Sync(); err != nil {
+		j.file.Close()
+		return fmt.Errorf("sync journal: %w", err)
+	}
+	return j.file.Close()
+}
+
+func (j *journal) syncLoop() {
+	defer close(j.done)
+	ticker := time.NewTicker(j.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			j.mu.Lock()
+			if err := j.file.Sync(); err != nil {
+				j.logger.Error("journal sync failed", zap.Error(err))
+			}
+			j.mu.Unlock()
+		}
+	}
+}
+
+// frame prefixes payload with its length and CRC32-C checksum.
+func frame(payload []byte) []byte {
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[recordHeaderSize:], payload)
+	return buf
+}
+
+// errTornRecord marks a record that is incomplete or fails its checksum.
+var errTornRecord = errors.New("torn record")
+
+// readFrame reads one framed payload. It returns io.EOF at a clean end of input and
+// errTornRecord for a short or checksum-failing record.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, errTornRecord
+	}
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return nil, errTornRecord
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errTornRecord
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errTornRecord
+	}
+	return payload, nil
+}
+
+// replayJournal applies every intact record in f to state. A damaged record at the
+// very end of the file is treated as a torn write and truncated away; damage
+// followed by further data is reported as ErrJournalCorrupt.
+func replayJournal(f *os.File, state *memState, logger *zap.Logger) (int, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("stat journal: %w", err)
+	}
+	size := info.Size()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	records := 0
+	for {
+		payload, err := readFrame(r)
+		switch err {
+		case io.EOF:
+			return records, nil
+		case errTornRecord:
+			return records, recoverTail(f, offset, size, logger)
+		}
+		if err := applyRecord(payload, state); err != nil {
+			return records, fmt.Errorf("%w: record at offset %d: %v", ErrJournalCorrupt, offset, err)
+		}
+		offset += int64(recordHeaderSize + len(payload))
+		records++
+	}
+}
+
+// recoverTail truncates a torn final record at offset, or fails if the damaged
+// record is followed by a complete one.
+func recoverTail(f *os.File, offset, size int64, logger *zap.Logger) error {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek journal: %w", err)
+	}
+	var header [recordHeaderSize]byte
+	if n, _ := io.ReadFull(f, header[:]); n == recordHeaderSize {
+		end := offset + recordHeaderSize + int64(binary.BigEndian.Uint32(header[0:4]))
+		if end < size {
+			return fmt.Errorf("%w: bad record at offset %d", ErrJournalCorrupt, offset)
+		}
+	}
+	logger.Warn("truncating torn journal tail", zap.Int64("offset", offset), zap.Int64("dropped_bytes", size-offset))
+	if err := f.Truncate(offset); err != nil {
+		return fmt.Errorf("truncate journal: %w", err)
+	}
+	return f.Sync()
+}
+
+func applyRecord(payload []byte, state *memState) error {
+	var rec journalRecord
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return err
+	}
+	switch rec.Op {
+	case opCreate, opUpdate:
+		if rec.Task == nil {
+			return fmt.Errorf("%s record without task", rec.Op)
+		}
+		state.tasks[rec.Task.ID] = rec.Task
+	case opDelete:
+		state.deleteTask(rec.ID)
+	case opLabelPut:
+		if rec.Label == nil {
+			return fmt.Errorf("%s record without label", rec.Op)
+		}
+		state.labels[rec.Label.Name] = rec.Label
+	case opLabelDelete:
+		delete(state.labels, rec.ID)
+		for _, t := range rec.Tasks {
+			state.tasks[t.ID] = t
+		}
+	case opDependencyPut, opDependencyDelete:
+		if rec.Dependency == nil {
+			return fmt.Errorf("%s record without dependency", rec.Op)
+		}
+		if rec.Op == opDependencyPut {
+			state.putDependency(rec.Dependency)
+		} else {
+			state.deleteDependency(rec.Dependency.TaskID, rec.Dependency.BlockerID)
+		}
+	case opHistoryAppend:
+		if rec.History == nil {
+			return fmt.Errorf("%s record without history entry", rec.Op)
+		}
+		return state.putHistory(rec.History)
+	case opCommentPut, opCommentDelete:
+		if rec.Comment == nil {
+			return fmt.Errorf("%s record without comment", rec.Op)
+		}
+		if rec.Op == opCommentPut {
+			state.putComment(rec.Comment)
+		} else {
+			state.deleteComment(rec.Comment.TaskID, rec.Comment.ID)
+		}
+	case opAttachmentPut, opAttachmentDelete:
+		if rec.Attachment == nil {
+			return fmt.Errorf("%s record without attachment", rec.Op)
+		}
+		if rec.Op == opAttachmentPut {
+			state.putAttachment(rec.Attachment)
+		} else {
+			state.deleteAttachment(rec.Attachment.TaskID, rec.Attachment.ID)
+		}
+	case opUserPut:
+		if rec.User == nil {
+			return fmt.Errorf("%s record without user", rec.Op)
+		}
+		state.users[rec.User.ID] = rec.User
+	case opUserDelete:
+		delete(state.users, rec.ID)
+	case opProjectPut:
+		if rec.Project == nil {
+			return fmt.Errorf("%s record without project", rec.Op)
+		}
+		state.projects[rec.Project.ID] = rec.Project
+	case opProjectDelete:
+		delete(state.projects, rec.ID)
+	case opTasksPut:
+		for _, t := range rec.Tasks {
+			state.tasks[t.ID] = t
+		}
+		for _, id := range rec.Deleted {
+			state.deleteTask(id)
+		}
+	default:
+		return fmt.Errorf("unknown journal op %q", rec.Op)
+	}
+	return nil
+}
+
+// readSnapshot loads the snapshot at path, returning an empty state if none exists.
+func readSnapshot(path string) (*memState, error) {
+	state := newMemState()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	payload, err := readFrame(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: snapshot %s is damaged", ErrJournalCorrupt, path)
+	}
+	var data snapshotData
+	if len(payload) > 0 && payload[0] == '[' {
+		err = json.Unmarshal(payload, &data.Tasks)
+	} else {
+		err = json.Unmarshal(payload, &data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode snapshot: %v", ErrJournalCorrupt, err)
+	}
+	for _, t := range data.Tasks {
+		state.tasks[t.ID] = t
+	}
+	for _, l := range data.Labels {
+		state.labels[l.Name] = l
+	}
+	for _, d := range data.Dependencies {
+		state.putDependency(d)
+	}
+	// Entries of a task are appended in revision order, whatever order the
+	// snapshot lists the tasks in.
+	sort.SliceStable(data.History, func(i, j int) bool { return data.History[i].Revision < data.History[j].Revision })
+	for _, e := range data.History {
+		if err := state.putHistory(e); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrJournalCorrupt, err)
+		}
+	}
+	for _, c := range data.Comments {
+		state.putComment(c)
+	}
+	for _, a := range data.Attachments {
+		state.putAttachment(a)
+	}
+	for _, u := range data.Users {
+		state.users[u.ID] = u
+	}
+	for _, p := range data.Projects {
+		state.projects[p.ID] = p
+	}
+	return state, nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,513 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func openTestJournaled(t *testing.T, dir string, snapshotEvery int) *InMemoryTaskRepository {
+	t.Helper()
+	repo, err := OpenJournaledTaskRepository(JournalOptions{
+		Dir:           dir,
+		Sync:          SyncAlways,
+		SnapshotEvery: snapshotEvery,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestJournaledRepository_ReplayRebuildsState(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 0)
+	a, b, c := newTestTask("A"), newTestTask("B"), newTestTask("C")
+	require.NoError(t, repo.CreateTask(ctx, a))
+	require.NoError(t, repo.CreateTask(ctx, b))
+	require.NoError(t, repo.CreateTask(ctx, c))
+	b.Title = "B2"
+	b.Completed = true
+	require.NoError(t, repo.UpdateTask(ctx, b))
+	require.NoError(t, repo.DeleteTask(ctx, c.ID, AnyVersion))
+	require.NoError(t, repo.Close())
+
+	repo = openTestJournaled(t, dir, 0)
+	defer repo.Close()
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	got, err := repo.GetTask(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "B2", got.Title)
+	assert.True(t, got.Completed)
+	_, err = repo.GetTask(ctx, c.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestJournaledRepository_SnapshotCompactsJournal(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 3)
+	for _, title := range []string{"A", "B", "C", "D"} {
+		require.NoError(t, repo.CreateTask(ctx, newTestTask(title)))
+	}
+	require.NoError(t, repo.Close())
+
+	// Three records were compacted into the snapshot, one remains in the journal.
+	_, err := os.Stat(filepath.Join(dir, snapshotFile))
+	require.NoError(t, err)
+	f, err := os.Open(filepath.Join(dir, journalFile))
+	require.NoError(t, err)
+	defer f.Close()
+	replayed, err := replayJournal(f, newMemState(), zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+
+	repo = openTestJournaled(t, dir, 3)
+	defer repo.Close()
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 4)
+}
+
+func TestJournaledRepository_TruncatedTailIsRecovered(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 0)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("B")))
+	require.NoError(t, repo.Close())
+
+	// Simulate a crash in the middle of writing the last record.
+	path := filepath.Join(dir, journalFile)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-5))
+
+	repo = openTestJournaled(t, dir, 0)
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	// The journal accepts new writes after the torn record was dropped.
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("C")))
+	require.NoError(t, repo.Close())
+	repo = openTestJournaled(t, dir, 0)
+	defer repo.Close()
+	tasks, err = repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+// failingStore is a journal file whose writes stop halfway while failWrites
+// is set, and whose truncation fails while failTruncate is set.
+type failingStore struct {
+	*os.File
+	failWrites, failTruncate bool
+}
+
+func (f *failingStore) Write(p []byte) (int, error) {
+	if !f.failWrites {
+		return f.File.Write(p)
+	}
+	n, _ := f.File.Write(p[:len(p)/2])
+	return n, errors.New("no space left on device")
+}
+
+func (f *failingStore) Truncate(size int64) error {
+	if f.failTruncate {
+		return errors.New("read-only file system")
+	}
+	return f.File.Truncate(size)
+}
+
+func TestJournaledRepository_FailedAppendIsRolledBack(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 0)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+	store := &failingStore{File: repo.journal.file.(*os.File), failWrites: true}
+	repo.journal.file = store
+	assert.Error(t, repo.CreateTask(ctx, newTestTask("B")))
+
+	// The partial record is gone, so later records replay.
+	store.failWrites = false
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("C")))
+	require.NoError(t, repo.Close())
+	repo = openTestJournaled(t, dir, 0)
+	defer repo.Close()
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.ElementsMatch(t, []string{"task-A", "task-C"}, []string{tasks[0].ID, tasks[1].ID})
+}
+
+func TestJournaledRepository_FailedRollbackBreaksJournal(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 0)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+	store := &failingStore{File: repo.journal.file.(*os.File), failWrites: true, failTruncate: true}
+	repo.journal.file = store
+	assert.Error(t, repo.CreateTask(ctx, newTestTask("B")))
+
+	// No record may follow the partial one.
+	store.failWrites, store.failTruncate = false, false
+	assert.ErrorIs(t, repo.CreateTask(ctx, newTestTask("C")), ErrJournalBroken)
+	_, err := repo.GetTask(ctx, "task-C")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	require.NoError(t, repo.Close())
+
+	repo = openTestJournaled(t, dir, 0)
+	defer repo.Close()
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "task-A", tasks[0].ID)
+}
+
+func TestJournaledRepository_CorruptRecordIsDetected(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 0)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("B")))
+	require.NoError(t, repo.Close())
+
+	// Flip a byte inside the first record's payload; the second record is intact.
+	path := filepath.Join(dir, journalFile)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[recordHeaderSize+2] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	_, err = OpenJournaledTaskRepository(JournalOptions{Dir: dir}, zap.NewNop())
+	assert.ErrorIs(t, err, ErrJournalCorrupt)
+}
+
+func TestJournaledRepository_CorruptSnapshotIsDetected(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	repo := openTestJournaled(t, dir, 0)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+	require.NoError(t, repo.Snapshot())
+	require.NoError(t, repo.Close())
+
+	path := filepath.Join(dir, snapshotFile)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	_, err = OpenJournaledTaskRepository(JournalOptions{Dir: dir}, zap.NewNop())
+	assert.ErrorIs(t, err, ErrJournalCorrupt)
+}
+
+func TestJournaledRepository_LabelsSurviveReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, name := range []string{"backend", "urgent"} {
+			require.NoError(t, repo.CreateLabel(ctx, &model.Label{Name: name, Color: model.DefaultLabelColor}))
+		}
+		task := newTestTask("A")
+		task.Labels = []string{"backend", "urgent"}
+		require.NoError(t, repo.CreateTask(ctx, task))
+		require.NoError(t, repo.DeleteLabel(ctx, "backend"))
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		labels, err := repo.ListLabels(ctx)
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		assert.Equal(t, "urgent", labels[0].Name)
+		got, err := repo.GetTask(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"urgent"}, got.Labels)
+		assert.Equal(t, int64(2), got.Version)
+		page, err := repo.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{Labels: []string{"urgent"}}})
+		require.NoError(t, err)
+		assert.Len(t, page.Tasks, 1, "the label index must be rebuilt on open")
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_DependenciesSurviveReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, id := range []string{"A", "B", "C", "D"} {
+			require.NoError(t, repo.CreateTask(ctx, newTestTask(id)))
+		}
+		for _, pair := range [][2]string{{"B", "A"}, {"C", "B"}, {"D", "C"}, {"D", "A"}} {
+			dep := &model.Dependency{TaskID: "task-" + pair[0], BlockerID: "task-" + pair[1]}
+			require.NoError(t, repo.AddDependency(ctx, dep))
+		}
+		require.NoError(t, repo.RemoveDependency(ctx, "task-D", "task-A"))
+		require.NoError(t, repo.DeleteTask(ctx, "task-B", AnyVersion))
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		deps, err := repo.ListDependencies(ctx)
+		require.NoError(t, err)
+		require.Len(t, deps, 1)
+		assert.Equal(t, model.Dependency{TaskID: "task-D", BlockerID: "task-C"}, *deps[0])
+		dependents, err := repo.ListDependents(ctx, "task-C")
+		require.NoError(t, err)
+		assert.Len(t, dependents, 1, "the dependents index must be rebuilt on open")
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_HistorySurvivesReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, id := range []string{"A", "B", "A", "A"} {
+			require.NoError(t, repo.AppendHistory(ctx, &model.HistoryEntry{TaskID: "task-" + id, Action: model.HistoryUpdate}))
+		}
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		require.NoError(t, repo.AppendHistory(ctx, &model.HistoryEntry{TaskID: "task-A", Action: model.HistoryDelete}))
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		entries, err := repo.ListHistory(ctx, "task-A")
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		for i, e := range entries {
+			assert.Equal(t, int64(i+1), e.Revision)
+		}
+		assert.Equal(t, model.HistoryDelete, entries[3].Action)
+		entry := &model.HistoryEntry{TaskID: "task-B", Action: model.HistoryUpdate}
+		require.NoError(t, repo.AppendHistory(ctx, entry))
+		assert.Equal(t, int64(2), entry.Revision)
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_CommentsSurviveReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, id := range []string{"A", "B"} {
+			require.NoError(t, repo.CreateTask(ctx, newTestTask(id)))
+		}
+		for _, c := range []*model.Comment{
+			{TaskID: "task-A", ID: "c1", Body: "first"},
+			{TaskID: "task-A", ID: "c2", Body: "second"},
+			{TaskID: "task-A", ID: "c3", Body: "third"},
+			{TaskID: "task-B", ID: "c1", Body: "other"},
+		} {
+			require.NoError(t, repo.CreateComment(ctx, c))
+		}
+		require.NoError(t, repo.UpdateComment(ctx, &model.Comment{TaskID: "task-A", ID: "c1", Body: "edited", Edited: true}))
+		require.NoError(t, repo.DeleteComment(ctx, "task-A", "c2"))
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		require.NoError(t, repo.DeleteTask(ctx, "task-B", AnyVersion))
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		page, err := repo.ListComments(ctx, model.CommentQuery{TaskID: "task-A"})
+		require.NoError(t, err)
+		require.Len(t, page.Comments, 2)
+		assert.Equal(t, "edited", page.Comments[0].Body)
+		assert.True(t, page.Comments[0].Edited)
+		assert.Equal(t, "c3", page.Comments[1].ID)
+		page, err = repo.ListComments(ctx, model.CommentQuery{TaskID: "task-B"})
+		require.NoError(t, err)
+		assert.Empty(t, page.Comments, "purging a task deletes its comments")
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_AttachmentsSurviveReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, id := range []string{"A", "B"} {
+			require.NoError(t, repo.CreateTask(ctx, newTestTask(id)))
+		}
+		for _, a := range []*model.Attachment{
+			{TaskID: "task-A", ID: "a1", Filename: "one.txt", SHA256: "11"},
+			{TaskID: "task-A", ID: "a2", Filename: "two.txt", SHA256: "22"},
+			{TaskID: "task-B", ID: "b1", Filename: "three.txt", SHA256: "33"},
+		} {
+			require.NoError(t, repo.CreateAttachment(ctx, a))
+		}
+		require.NoError(t, repo.DeleteAttachment(ctx, "task-A", "a2"))
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		require.NoError(t, repo.DeleteTask(ctx, "task-B", AnyVersion))
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		attachments, err := repo.ListAttachments(ctx, "task-A")
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, "one.txt", attachments[0].Filename)
+		keys, err := repo.ListAttachmentBlobs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"11"}, keys)
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_UsersSurviveReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, id := range []string{"alice", "bob", "carol"} {
+			require.NoError(t, repo.CreateUser(ctx, &model.User{ID: id, Name: id}))
+		}
+		task := newTestTask("A")
+		task.Assignee = "alice"
+		task.CreatedBy = "bob"
+		require.NoError(t, repo.CreateTask(ctx, task))
+		require.NoError(t, repo.UpdateUser(ctx, &model.User{ID: "alice", Name: "Alice"}))
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		require.NoError(t, repo.DeleteUser(ctx, "carol"))
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		users, err := repo.ListUsers(ctx)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, "Alice", users[0].Name)
+		alice := "alice"
+		page, err := repo.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{Assignee: &alice}})
+		require.NoError(t, err)
+		require.Len(t, page.Tasks, 1)
+		assert.Equal(t, "bob", page.Tasks[0].CreatedBy)
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_ProjectsAndBatchesSurviveReplayAndSnapshot(t *testing.T) {
+	for _, snapshot := range []bool{false, true} {
+		dir := t.TempDir()
+		ctx := context.Background()
+
+		repo := openTestJournaled(t, dir, 0)
+		for _, id := range []string{"web", "api"} {
+			require.NoError(t, repo.CreateProject(ctx, &model.Project{ID: id, Name: id}))
+		}
+		require.NoError(t, repo.UpdateProject(ctx, &model.Project{ID: "web", Name: "Website",
+			Settings: model.ProjectSettings{IDPrefix: "WEB"}}))
+		_, err := repo.NextTaskNumber(ctx, "web")
+		require.NoError(t, err)
+		require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+		if snapshot {
+			require.NoError(t, repo.Snapshot())
+		}
+		a, err := repo.GetTask(ctx, "task-A")
+		require.NoError(t, err)
+		a.ProjectID = "web"
+		b := newTestTask("B")
+		b.ProjectID = "web"
+		require.NoError(t, repo.WriteTasks(ctx, TaskBatch{Create: []*model.Task{b}, Update: []*model.Task{a}}))
+		require.NoError(t, repo.CreateTask(ctx, newTestTask("C")))
+		require.NoError(t, repo.WriteTasks(ctx, TaskBatch{Delete: []*model.Task{{ID: "task-C", Version: 1}}}))
+		require.NoError(t, repo.DeleteProject(ctx, "api"))
+		require.NoError(t, repo.Close())
+
+		repo = openTestJournaled(t, dir, 0)
+		projects, err := repo.ListProjects(ctx)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, "Website", projects[0].Name)
+		assert.Equal(t, "WEB", projects[0].Settings.IDPrefix)
+		assert.Equal(t, int64(1), projects[0].LastTaskNumber)
+		web := "web"
+		page, err := repo.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{ProjectID: &web}})
+		require.NoError(t, err)
+		require.Len(t, page.Tasks, 2)
+		assert.Equal(t, int64(2), page.Tasks[0].Version)
+		_, err = repo.GetTask(ctx, "task-C")
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+		require.NoError(t, repo.Close())
+	}
+}
+
+func TestJournaledRepository_ReadsLegacySnapshot(t *testing.T) {
+	dir := t.TempDir()
+	payload := []byte(`[{"id":"task-A","title":"A","completed":false,"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z","version":3}]`)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, snapshotFile), frame(payload), 0o644))
+
+	repo := openTestJournaled(t, dir, 0)
+	defer repo.Close()
+	got, err := repo.GetTask(context.Background(), "task-A")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got.Version)
+	assert.Equal(t, model.StatusTodo, got.Status, "tasks without a status get the legacy one")
+}
+
+func TestParseSyncPolicy(t *testing.T) {
+	for in, want := range map[string]SyncPolicy{"always": SyncAlways, "interval": SyncInterval, "never": SyncNever} {
+		got, err := ParseSyncPolicy(in)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseSyncPolicy("sometimes")
+	assert.Error(t, err)
+}
+
+func TestJournaledRepository_IntervalSync(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	repo, err := OpenJournaledTaskRepository(JournalOptions{
+		Dir:          dir,
+		Sync:         SyncInterval,
+		SyncInterval: time.Millisecond,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("A")))
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, repo.Close())
+	require.NoError(t, repo.Close())
+
+	repo = openTestJournaled(t, dir, 0)
+	defer repo.Close()
+	_, err = repo.GetTask(ctx, "task-A")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrLabelNotFound is returned when a label does not exist. Task writes that
+// reference an unknown label return an error wrapping it; see UnknownLabel.
+var ErrLabelNotFound = apperror.NotFound("label not found")
+
+// ErrLabelAlreadyExists is returned when creating a label whose name is taken.
+var ErrLabelAlreadyExists = apperror.AlreadyExists("label already exists")
+
+// UnknownLabel returns the error for a task write that references the missing
+// label name. It matches ErrLabelNotFound via errors.Is.
+func UnknownLabel(name string) error {
+	return fmt.Errorf("%w: %q", ErrLabelNotFound, name)
+}
+
+// LabelRepository stores labels and indexes tasks by the labels they carry,
+// so TaskFilter.Labels is answered without scanning every task.
+type LabelRepository interface {
+	// CreateLabel adds a new label.
+	CreateLabel(ctx context.Context, label *model.Label) error
+	// GetLabel retrieves a label by its name.
+	GetLabel(ctx context.Context, name string) (*model.Label, error)
+	// ListLabels returns all labels ordered by name.
+	ListLabels(ctx context.Context) ([]*model.Label, error)
+	// UpdateLabel replaces the color and UpdatedAt of an existing label.
+	UpdateLabel(ctx context.Context, label *model.Label) error
+	// DeleteLabel removes a label and detaches it from every task carrying it.
+	// Each affected task gets a new version; its UpdatedAt is left alone.
+	DeleteLabel(ctx context.Context, name string) error
+}
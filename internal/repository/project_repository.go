@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrProjectNotFound is returned when a project does not exist.
+var ErrProjectNotFound = apperror.NotFound("project not found")
+
+// ErrProjectAlreadyExists is returned when creating a project whose ID is taken.
+var ErrProjectAlreadyExists = apperror.AlreadyExists("project already exists")
+
+// ErrProjectNotEmpty is returned when deleting a project that still has
+// tasks, live or in the trash.
+var ErrProjectNotEmpty = apperror.Conflict("project has tasks")
+
+// ProjectRepository stores the projects tasks belong to. Whether a task's
+// project exists is checked by the service, not by storage.
+type ProjectRepository interface {
+	// CreateProject adds a new project.
+	CreateProject(ctx context.Context, project *model.Project) error
+	// GetProject retrieves a project by ID.
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	// ListProjects returns all projects ordered by ID.
+	ListProjects(ctx context.Context) ([]*model.Project, error)
+	// UpdateProject replaces the name, description, settings and UpdatedAt of
+	// an existing project and sets the other fields of project to the stored ones.
+	UpdateProject(ctx context.Context, project *model.Project) error
+	// DeleteProject removes a project that has no tasks.
+	DeleteProject(ctx context.Context, id string) error
+	// NextTaskNumber increments and returns the LastTaskNumber of a project.
+	NextTaskNumber(ctx context.Context, id string) (int64, error)
+}
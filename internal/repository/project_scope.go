@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+type projectScopeKey struct{}
+
+// WithProjectScope returns a context that confines a ProjectScopedRepository to
+// the tasks of project id.
+func WithProjectScope(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, projectScopeKey{}, id)
+}
+
+// WithoutProjectScope returns a context that lifts the project scope of ctx.
+func WithoutProjectScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, projectScopeKey{}, nil)
+}
+
+// ProjectScope returns the project ctx is scoped to, if any.
+func ProjectScope(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(projectScopeKey{}).(string)
+	return id, ok
+}
+
+// ProjectScopedRepository is a TaskRepository that confines task operations to
+// the project of the context they run in, see WithProjectScope. Tasks of other
+// projects do not exist for it; without a scope it passes everything through.
+// Operations on other entities, such as comments, are not scoped: their
+// services load the task first. CountTasks counts every task, for quotas.
+type ProjectScopedRepository struct {
+	TaskRepository
+}
+
+// NewProjectScopedRepository wraps repo so that its task operations honour the
+// project scope of their context.
+func NewProjectScopedRepository(repo TaskRepository) *ProjectScopedRepository {
+	return &ProjectScopedRepository{TaskRepository: repo}
+}
+
+// errOutOfScope rejects writes that would put a task into another project.
+var errOutOfScope = apperror.InvalidField("project_id", "task belongs to another project")
+
+// GetTask retrieves a live task of the scoped project.
+func (r *ProjectScopedRepository) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := r.TaskRepository.GetTask(ctx, id)
+	return r.scoped(ctx, task, err)
+}
+
+// GetTrashedTask retrieves a trashed task of the scoped project.
+func (r *ProjectScopedRepository) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := r.TaskRepository.GetTrashedTask(ctx, id)
+	return r.scoped(ctx, task, err)
+}
+
+// scoped hides task behind ErrTaskNotFound if it is outside the scope of ctx.
+func (r *ProjectScopedRepository) scoped(ctx context.Context, task *model.Task, err error) (*model.Task, error) {
+	if err != nil {
+		return nil, err
+	}
+	if project, ok := ProjectScope(ctx); ok && task.ProjectID != project {
+		return nil, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// ListTasks returns the live tasks of the scoped project.
+func (r *ProjectScopedRepository) ListTasks(ctx context.Context) ([]*model.Task, error) {
+	project, ok := ProjectScope(ctx)
+	if !ok {
+		return r.TaskRepository.ListTasks(ctx)
+	}
+	page, err := r.TaskRepository.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{ProjectID: &project}})
+	if err != nil {
+		return nil, err
+	}
+	return page.Tasks, nil
+}
+
+// QueryTasks returns a page of the scoped project's tasks selected by q.
+func (r *ProjectScopedRepository) QueryTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	if project, ok := ProjectScope(ctx); ok {
+		if q.Filter.ProjectID != nil && *q.Filter.ProjectID != project {
+			return &model.TaskPage{Tasks: []*model.Task{}}, nil
+		}
+		q.Filter.ProjectID = &project
+	}
+	return r.TaskRepository.QueryTasks(ctx, q)
+}
+
+// CreateTask adds a task to the scoped project.
+func (r *ProjectScopedRepository) CreateTask(ctx context.Context, task *model.Task) error {
+	if project, ok := ProjectScope(ctx); ok && task.ProjectID != project {
+		return errOutOfScope
+	}
+	return r.TaskRepository.CreateTask(ctx, task)
+}
+
+// UpdateTask replaces a task of the scoped project, which it cannot leave.
+func (r *ProjectScopedRepository) UpdateTask(ctx context.Context, task *model.Task) error {
+	if err := r.checkWrite(ctx, task); err != nil {
+		return err
+	}
+	return r.TaskRepository.UpdateTask(ctx, task)
+}
+
+// DeleteTask removes a task of the scoped project.
+func (r *ProjectScopedRepository) DeleteTask(ctx context.Context, id string, version int64) error {
+	if err := r.checkStored(ctx, id); err != nil {
+		return err
+	}
+	return r.TaskRepository.DeleteTask(ctx, id, version)
+}
+
+// WriteTasks applies a batch confined to the scoped project.
+func (r *ProjectScopedRepository) WriteTasks(ctx context.Context, batch TaskBatch) error {
+	if project, ok := ProjectScope(ctx); ok {
+		for _, task := range batch.Create {
+			if task.ProjectID != project {
+				return errOutOfScope
+			}
+		}
+		for _, task := range batch.Update {
+			if err := r.checkWrite(ctx, task); err != nil {
+				return err
+			}
+		}
+		for _, task := range batch.Delete {
+			if err := r.checkStored(ctx, task.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return r.TaskRepository.WriteTasks(ctx, batch)
+}
+
+// checkWrite verifies that the stored task and its replacement are both in
+// the scope of ctx.
+func (r *ProjectScopedRepository) checkWrite(ctx context.Context, task *model.Task) error {
+	project, ok := ProjectScope(ctx)
+	if !ok {
+		return nil
+	}
+	if err := r.checkStored(ctx, task.ID); err != nil {
+		return err
+	}
+	if task.ProjectID != project {
+		return errOutOfScope
+	}
+	return nil
+}
+
+// checkStored returns ErrTaskNotFound unless the stored task id, live or
+// trashed, is in the scope of ctx.
+func (r *ProjectScopedRepository) checkStored(ctx context.Context, id string) error {
+	project, ok := ProjectScope(ctx)
+	if !ok {
+		return nil
+	}
+	task, err := r.TaskRepository.GetTask(ctx, id)
+	if apperror.KindOf(err) == apperror.KindNotFound {
+		task, err = r.TaskRepository.GetTrashedTask(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	if task.ProjectID != project {
+		return ErrTaskNotFound
+	}
+	return nil
+}
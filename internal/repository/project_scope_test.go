@@ -0,0 +1,92 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/repository/repotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProjectScopedRepository_Conformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.TaskRepository {
+		return repository.NewProjectScopedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()))
+	})
+}
+
+func TestProjectScopedRepository_Scope(t *testing.T) {
+	repo := repository.NewProjectScopedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()))
+	now := time.Now().UTC()
+	task := func(id, project string) *model.Task {
+		return &model.Task{ID: id, Title: id, Status: model.StatusTodo, ProjectID: project, CreatedAt: now, UpdatedAt: now}
+	}
+	all := context.Background()
+	for _, tk := range []*model.Task{task("w1", "web"), task("w2", "web"), task("a1", "api"), task("n1", "")} {
+		require.NoError(t, repo.CreateTask(all, tk))
+	}
+	web := repository.WithProjectScope(all, "web")
+	scope, ok := repository.ProjectScope(web)
+	assert.True(t, ok)
+	assert.Equal(t, "web", scope)
+	_, ok = repository.ProjectScope(repository.WithoutProjectScope(web))
+	assert.False(t, ok)
+
+	ids := func(tasks []*model.Task) []string {
+		out := []string{}
+		for _, tk := range tasks {
+			out = append(out, tk.ID)
+		}
+		return out
+	}
+	listed, err := repo.ListTasks(web)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"w1", "w2"}, ids(listed))
+	listed, err = repo.ListTasks(all)
+	require.NoError(t, err)
+	assert.Len(t, listed, 4)
+	page, err := repo.QueryTasks(web, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"w1", "w2"}, ids(page.Tasks))
+	api := "api"
+	page, err = repo.QueryTasks(web, model.TaskQuery{Filter: model.TaskFilter{ProjectID: &api}})
+	require.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+
+	// Tasks of other projects do not exist in the scope.
+	_, err = repo.GetTask(web, "a1")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	_, err = repo.GetTask(web, "n1")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	got, err := repo.GetTask(web, "w1")
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.UpdateTask(web, task("a1", "web")), repository.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(web, "a1", repository.AnyVersion), repository.ErrTaskNotFound)
+	deletedAt := now
+	trashed, err := repo.GetTask(all, "a1")
+	require.NoError(t, err)
+	trashed.DeletedAt = &deletedAt
+	require.NoError(t, repo.UpdateTask(all, trashed))
+	_, err = repo.GetTrashedTask(web, "a1")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(web, "a1", repository.AnyVersion), repository.ErrTaskNotFound)
+
+	// Writes cannot take tasks out of the scope or into it.
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(repo.CreateTask(web, task("x", "api"))))
+	got.ProjectID = "api"
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(repo.UpdateTask(web, got)))
+	err = repo.WriteTasks(web, repository.TaskBatch{Create: []*model.Task{task("x", "")}})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	err = repo.WriteTasks(web, repository.TaskBatch{Update: []*model.Task{got}})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	got.ProjectID = "web"
+	got.Title = "renamed"
+	require.NoError(t, repo.WriteTasks(web, repository.TaskBatch{Create: []*model.Task{task("w3", "web")}, Update: []*model.Task{got}}))
+	require.NoError(t, repo.DeleteTask(web, "w3", repository.AnyVersion))
+}
@@ -0,0 +1,72 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttachment(taskID, id, sha string, offset time.Duration) *model.Attachment {
+	return &model.Attachment{
+		ID: id, TaskID: taskID, Filename: id + ".png", ContentType: "image/png", Size: 42, SHA256: sha,
+		Uploader: "alice", CreatedAt: baseTime.Add(offset),
+	}
+}
+
+func attachmentIDs(t *testing.T, repo repository.TaskRepository, taskID string) []string {
+	t.Helper()
+	attachments, err := repo.ListAttachments(context.Background(), taskID)
+	require.NoError(t, err)
+	require.NotNil(t, attachments)
+	ids := []string{}
+	for _, a := range attachments {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func testAttachments(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	require.NoError(t, repo.CreateTask(ctx, newTask("b", 0)))
+	assert.ErrorIs(t, repo.CreateAttachment(ctx, newAttachment("missing", "x", "aa", 0)), repository.ErrTaskNotFound)
+
+	created := newAttachment("a", "a2", "aa", time.Minute)
+	require.NoError(t, repo.CreateAttachment(ctx, created))
+	require.NoError(t, repo.CreateAttachment(ctx, newAttachment("a", "a1", "bb", time.Minute)))
+	require.NoError(t, repo.CreateAttachment(ctx, newAttachment("a", "a0", "aa", 2*time.Minute)))
+	require.NoError(t, repo.CreateAttachment(ctx, newAttachment("b", "b1", "cc", 0)))
+	assert.ErrorIs(t, repo.CreateAttachment(ctx, newAttachment("a", "a1", "bb", 0)), repository.ErrAttachmentAlreadyExists)
+	created.Filename = "changed"
+
+	got, err := repo.GetAttachment(ctx, "a", "a2")
+	require.NoError(t, err)
+	assert.Equal(t, "a2.png", got.Filename)
+	assert.Equal(t, "image/png", got.ContentType)
+	assert.Equal(t, int64(42), got.Size)
+	assert.Equal(t, "aa", got.SHA256)
+	assert.Equal(t, "alice", got.Uploader)
+	assert.True(t, baseTime.Add(time.Minute).Equal(got.CreatedAt))
+	_, err = repo.GetAttachment(ctx, "b", "a2")
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+
+	assert.Equal(t, []string{"a1", "a2", "a0"}, attachmentIDs(t, repo, "a"))
+	assert.Empty(t, attachmentIDs(t, repo, "none"))
+	keys, err := repo.ListAttachmentBlobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aa", "bb", "cc"}, keys)
+
+	require.NoError(t, repo.DeleteAttachment(ctx, "a", "a1"))
+	assert.ErrorIs(t, repo.DeleteAttachment(ctx, "a", "a1"), repository.ErrAttachmentNotFound)
+	require.NoError(t, repo.DeleteTask(ctx, "b", repository.AnyVersion))
+	assert.Empty(t, attachmentIDs(t, repo, "b"), "purging a task deletes its attachments")
+	keys, err = repo.ListAttachmentBlobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aa"}, keys)
+}
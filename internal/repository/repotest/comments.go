@@ -0,0 +1,121 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newComment(taskID, id string, offset time.Duration) *model.Comment {
+	at := baseTime.Add(offset)
+	return &model.Comment{ID: id, TaskID: taskID, Author: "alice", Body: "Comment " + id, CreatedAt: at, UpdatedAt: at}
+}
+
+func commentIDs(t *testing.T, repo repository.TaskRepository, q model.CommentQuery) []string {
+	t.Helper()
+	page, err := repo.ListComments(context.Background(), q)
+	require.NoError(t, err)
+	require.NotNil(t, page.Comments)
+	ids := []string{}
+	for _, c := range page.Comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func testCommentCRUD(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	assert.ErrorIs(t, repo.CreateComment(ctx, newComment("missing", "c1", 0)), repository.ErrTaskNotFound)
+
+	created := newComment("a", "c1", 0)
+	require.NoError(t, repo.CreateComment(ctx, created))
+	assert.ErrorIs(t, repo.CreateComment(ctx, newComment("a", "c1", 0)), repository.ErrCommentAlreadyExists)
+	// Changing the caller's comment must not change the stored one.
+	created.Body = "changed"
+
+	got, err := repo.GetComment(ctx, "a", "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "Comment c1", got.Body)
+	assert.Equal(t, "alice", got.Author)
+	assert.False(t, got.Edited)
+	assert.True(t, baseTime.Equal(got.CreatedAt))
+	_, err = repo.GetComment(ctx, "b", "c1")
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound, "comments are looked up on their task")
+
+	got.Body = "Edited"
+	got.Edited = true
+	got.UpdatedAt = baseTime.Add(time.Hour)
+	require.NoError(t, repo.UpdateComment(ctx, got))
+	got, err = repo.GetComment(ctx, "a", "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", got.Body)
+	assert.True(t, got.Edited)
+	assert.True(t, baseTime.Add(time.Hour).Equal(got.UpdatedAt))
+	assert.ErrorIs(t, repo.UpdateComment(ctx, newComment("a", "c2", 0)), repository.ErrCommentNotFound)
+
+	require.NoError(t, repo.DeleteComment(ctx, "a", "c1"))
+	_, err = repo.GetComment(ctx, "a", "c1")
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+	assert.ErrorIs(t, repo.DeleteComment(ctx, "a", "c1"), repository.ErrCommentNotFound)
+}
+
+func testCommentPagination(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	require.NoError(t, repo.CreateTask(ctx, newTask("b", 0)))
+	// c1 and c2 share a timestamp, so the ID breaks the tie.
+	for _, c := range []*model.Comment{
+		newComment("a", "c3", 2*time.Minute),
+		newComment("a", "c2", time.Minute),
+		newComment("a", "c1", time.Minute),
+		newComment("a", "c4", 3*time.Minute),
+		newComment("a", "c5", 4*time.Minute),
+		newComment("b", "other", 0),
+	} {
+		require.NoError(t, repo.CreateComment(ctx, c))
+	}
+
+	assert.Equal(t, []string{"c1", "c2", "c3", "c4", "c5"}, commentIDs(t, repo, model.CommentQuery{TaskID: "a"}))
+	assert.Empty(t, commentIDs(t, repo, model.CommentQuery{TaskID: "none"}))
+
+	var (
+		ids   []string
+		after *model.CommentCursor
+	)
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		page, err := repo.ListComments(ctx, model.CommentQuery{TaskID: "a", Limit: 2, After: after})
+		require.NoError(t, err)
+		for _, c := range page.Comments {
+			ids = append(ids, c.ID)
+		}
+		if page.Next == nil {
+			break
+		}
+		after = page.Next
+	}
+	assert.Equal(t, []string{"c1", "c2", "c3", "c4", "c5"}, ids)
+}
+
+func testPurgeDeletesComments(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	require.NoError(t, repo.CreateComment(ctx, newComment("a", "c1", 0)))
+
+	trash(t, repo, "a", baseTime)
+	assert.Equal(t, []string{"c1"}, commentIDs(t, repo, model.CommentQuery{TaskID: "a"}), "comments stay in the trash")
+
+	require.NoError(t, repo.DeleteTask(ctx, "a", repository.AnyVersion))
+	assert.Empty(t, commentIDs(t, repo, model.CommentQuery{TaskID: "a"}))
+	_, err := repo.GetComment(ctx, "a", "c1")
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	assert.Empty(t, commentIDs(t, repo, model.CommentQuery{TaskID: "a"}), "a new task with the same ID starts without comments")
+}
@@ -0,0 +1,98 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDependency(taskID, blockerID string) *model.Dependency {
+	return &model.Dependency{TaskID: taskID, BlockerID: blockerID, CreatedAt: baseTime}
+}
+
+// dependencyPairs renders deps as "task<-blocker" strings for compact assertions.
+func dependencyPairs(deps []*model.Dependency) []string {
+	pairs := make([]string, len(deps))
+	for i, d := range deps {
+		pairs[i] = d.TaskID + "<-" + d.BlockerID
+	}
+	return pairs
+}
+
+func testDependencies(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	// c is blocked by a and b; d is blocked by c.
+	for _, d := range []*model.Dependency{newDependency("c", "b"), newDependency("c", "a"), newDependency("d", "c")} {
+		require.NoError(t, repo.AddDependency(ctx, d))
+	}
+	assert.ErrorIs(t, repo.AddDependency(ctx, newDependency("c", "a")), repository.ErrDependencyAlreadyExists)
+	assert.ErrorIs(t, repo.AddDependency(ctx, newDependency("c", "missing")), repository.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.AddDependency(ctx, newDependency("missing", "c")), repository.ErrTaskNotFound)
+
+	all, err := repo.ListDependencies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c<-a", "c<-b", "d<-c"}, dependencyPairs(all))
+	assert.True(t, baseTime.Equal(all[0].CreatedAt))
+
+	blockers, err := repo.ListBlockers(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c<-a", "c<-b"}, dependencyPairs(blockers))
+	dependents, err := repo.ListDependents(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d<-c"}, dependencyPairs(dependents))
+	none, err := repo.ListBlockers(ctx, "e")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	require.NoError(t, repo.RemoveDependency(ctx, "c", "b"))
+	assert.ErrorIs(t, repo.RemoveDependency(ctx, "c", "b"), repository.ErrDependencyNotFound)
+	all, err = repo.ListDependencies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c<-a", "d<-c"}, dependencyPairs(all))
+}
+
+func testDependencyCycles(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	// a <- b <- c: c depends on b, which depends on a.
+	require.NoError(t, repo.AddDependency(ctx, newDependency("b", "a")))
+	require.NoError(t, repo.AddDependency(ctx, newDependency("c", "b")))
+
+	assert.ErrorIs(t, repo.AddDependency(ctx, newDependency("a", "b")), repository.ErrDependencyCycle, "direct")
+	assert.ErrorIs(t, repo.AddDependency(ctx, newDependency("a", "c")), repository.ErrDependencyCycle, "transitive")
+	// A diamond is not a cycle.
+	require.NoError(t, repo.AddDependency(ctx, newDependency("c", "a")))
+	require.NoError(t, repo.AddDependency(ctx, newDependency("d", "c")))
+
+	all, err := repo.ListDependencies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b<-a", "c<-a", "c<-b", "d<-c"}, dependencyPairs(all))
+}
+
+func testDeleteTaskDropsDependencies(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	require.NoError(t, repo.AddDependency(ctx, newDependency("b", "a")))
+	require.NoError(t, repo.AddDependency(ctx, newDependency("c", "b")))
+	require.NoError(t, repo.AddDependency(ctx, newDependency("e", "d")))
+
+	require.NoError(t, repo.DeleteTask(ctx, "b", repository.AnyVersion))
+	all, err := repo.ListDependencies(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e<-d"}, dependencyPairs(all))
+	dependents, err := repo.ListDependents(ctx, "a")
+	require.NoError(t, err)
+	assert.Empty(t, dependents)
+
+	// A task recreated with the same ID starts without dependencies.
+	require.NoError(t, repo.CreateTask(ctx, newTask("b", 0)))
+	blockers, err := repo.ListBlockers(ctx, "b")
+	require.NoError(t, err)
+	assert.Empty(t, blockers)
+}
@@ -0,0 +1,64 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHistory(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	none, err := repo.ListHistory(ctx, "a")
+	require.NoError(t, err)
+	assert.NotNil(t, none)
+	assert.Empty(t, none)
+
+	created := &model.HistoryEntry{
+		TaskID: "a", Version: 1, Action: model.HistoryCreate, Actor: "alice", At: baseTime,
+		Changes: []model.FieldChange{{Field: "title", Before: json.RawMessage(`null`), After: json.RawMessage(`"A"`)}},
+	}
+	require.NoError(t, repo.AppendHistory(ctx, created))
+	assert.Equal(t, int64(1), created.Revision)
+	require.NoError(t, repo.AppendHistory(ctx, &model.HistoryEntry{TaskID: "b", Action: model.HistoryCreate, At: baseTime}))
+	restored := &model.HistoryEntry{TaskID: "a", Version: 2, Action: model.HistoryRestore, At: baseTime, RestoredRevision: 1}
+	require.NoError(t, repo.AppendHistory(ctx, restored))
+	assert.Equal(t, int64(2), restored.Revision, "revisions are numbered per task")
+
+	// Changing the caller's entry must not change the stored one.
+	created.Changes[0].Field = "description"
+
+	entries, err := repo.ListHistory(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	first := entries[0]
+	assert.Equal(t, int64(1), first.Revision)
+	assert.Equal(t, model.HistoryCreate, first.Action)
+	assert.Equal(t, "alice", first.Actor)
+	assert.True(t, baseTime.Equal(first.At))
+	require.Len(t, first.Changes, 1)
+	assert.Equal(t, "title", first.Changes[0].Field)
+	assert.JSONEq(t, `null`, string(first.Changes[0].Before))
+	assert.JSONEq(t, `"A"`, string(first.Changes[0].After))
+	assert.Equal(t, int64(2), entries[1].Revision)
+	assert.Equal(t, int64(1), entries[1].RestoredRevision)
+	assert.Empty(t, entries[1].Changes)
+}
+
+func testHistoryOutlivesTask(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	require.NoError(t, repo.AppendHistory(ctx, &model.HistoryEntry{TaskID: "a", Version: 1, Action: model.HistoryCreate, At: baseTime}))
+	require.NoError(t, repo.DeleteTask(ctx, "a", repository.AnyVersion))
+	require.NoError(t, repo.AppendHistory(ctx, &model.HistoryEntry{TaskID: "a", Action: model.HistoryPurge, At: baseTime}))
+
+	entries, err := repo.ListHistory(ctx, "a")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, model.HistoryPurge, entries[1].Action)
+}
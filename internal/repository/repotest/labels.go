@@ -0,0 +1,166 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLabel(name string) *model.Label {
+	return &model.Label{Name: name, Color: model.DefaultLabelColor, CreatedAt: baseTime, UpdatedAt: baseTime}
+}
+
+func createLabels(t *testing.T, repo repository.TaskRepository, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		require.NoError(t, repo.CreateLabel(context.Background(), newLabel(name)))
+	}
+}
+
+func testLabelCRUD(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	createLabels(t, repo, "urgent", "backend", "docs")
+	assert.ErrorIs(t, repo.CreateLabel(ctx, newLabel("docs")), repository.ErrLabelAlreadyExists)
+
+	got, err := repo.GetLabel(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", got.Name)
+	assert.Equal(t, model.DefaultLabelColor, got.Color)
+	assert.True(t, baseTime.Equal(got.CreatedAt))
+
+	labels, err := repo.ListLabels(ctx)
+	require.NoError(t, err)
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	assert.Equal(t, []string{"backend", "docs", "urgent"}, names)
+
+	update := &model.Label{Name: "docs", Color: "#00ff00", UpdatedAt: baseTime.Add(time.Hour)}
+	require.NoError(t, repo.UpdateLabel(ctx, update))
+	assert.True(t, baseTime.Equal(update.CreatedAt), "update must not change created_at")
+	got, err = repo.GetLabel(ctx, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, "#00ff00", got.Color)
+	assert.True(t, baseTime.Add(time.Hour).Equal(got.UpdatedAt))
+
+	assert.ErrorIs(t, repo.UpdateLabel(ctx, newLabel("missing")), repository.ErrLabelNotFound)
+	assert.ErrorIs(t, repo.DeleteLabel(ctx, "missing"), repository.ErrLabelNotFound)
+	require.NoError(t, repo.DeleteLabel(ctx, "docs"))
+	_, err = repo.GetLabel(ctx, "docs")
+	assert.ErrorIs(t, err, repository.ErrLabelNotFound)
+}
+
+func testTaskLabels(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	createLabels(t, repo, "backend", "urgent")
+
+	task := newTask("task-1", 0)
+	task.Labels = []string{"backend", "urgent"}
+	require.NoError(t, repo.CreateTask(ctx, task))
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "urgent"}, got.Labels)
+
+	got.Labels = []string{"urgent"}
+	require.NoError(t, repo.UpdateTask(ctx, got))
+	got, err = repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, got.Labels)
+
+	unknown := newTask("task-2", time.Hour)
+	unknown.Labels = []string{"backend", "nope"}
+	err = repo.CreateTask(ctx, unknown)
+	assert.ErrorIs(t, err, repository.ErrLabelNotFound)
+	assert.ErrorContains(t, err, "nope")
+	_, err = repo.GetTask(ctx, unknown.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound, "a rejected create must not store the task")
+
+	got.Labels = []string{"nope"}
+	assert.ErrorIs(t, repo.UpdateTask(ctx, got), repository.ErrLabelNotFound)
+	got, err = repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, got.Labels, "a rejected update must not change labels")
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func testQueryLabels(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	createLabels(t, repo, "backend", "frontend", "urgent")
+	seedQueryTasks(t, repo)
+	for id, labels := range map[string][]string{
+		"a": {"backend", "urgent"},
+		"b": {"frontend"},
+		"c": {"backend"},
+		"e": {"frontend", "urgent"},
+	} {
+		task, err := repo.GetTask(ctx, id)
+		require.NoError(t, err)
+		task.Labels = labels
+		require.NoError(t, repo.UpdateTask(ctx, task))
+	}
+
+	tests := []struct {
+		name   string
+		filter model.TaskFilter
+		want   []string
+	}{
+		{"single", model.TaskFilter{Labels: []string{"backend"}}, []string{"a", "c"}},
+		{"all", model.TaskFilter{Labels: []string{"backend", "urgent"}}, []string{"a"}},
+		{"any", model.TaskFilter{Labels: []string{"backend", "urgent"}, LabelMatch: model.LabelMatchAny}, []string{"a", "c", "e"}},
+		{"all none match", model.TaskFilter{Labels: []string{"backend", "frontend"}}, []string{}},
+		{"unused label", model.TaskFilter{Labels: []string{"missing"}}, []string{}},
+		{"duplicates", model.TaskFilter{Labels: []string{"urgent", "urgent"}}, []string{"a", "e"}},
+		{"with other filters", model.TaskFilter{Labels: []string{"frontend"}, Completed: new(bool)}, []string{"e"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, queryIDs(t, repo, model.TaskQuery{Filter: tc.filter}))
+		})
+	}
+
+	t.Run("paginated", func(t *testing.T) {
+		q := model.TaskQuery{Filter: model.TaskFilter{Labels: []string{"backend", "frontend"}, LabelMatch: model.LabelMatchAny}, Limit: 2}
+		page, err := repo.QueryTasks(ctx, q)
+		require.NoError(t, err)
+		require.Len(t, page.Tasks, 2)
+		require.NotNil(t, page.Next)
+		q.After = page.Next
+		assert.Equal(t, []string{"c", "e"}, queryIDs(t, repo, q))
+	})
+}
+
+func testDeleteLabelDetaches(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	createLabels(t, repo, "backend", "urgent")
+	tagged := newTask("tagged", 0)
+	tagged.Labels = []string{"backend", "urgent"}
+	require.NoError(t, repo.CreateTask(ctx, tagged))
+	other := newTask("other", time.Hour)
+	other.Labels = []string{"urgent"}
+	require.NoError(t, repo.CreateTask(ctx, other))
+
+	require.NoError(t, repo.DeleteLabel(ctx, "backend"))
+
+	got, err := repo.GetTask(ctx, "tagged")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, got.Labels)
+	assert.Equal(t, int64(2), got.Version, "detaching a label must bump the version")
+	assert.True(t, tagged.UpdatedAt.Equal(got.UpdatedAt))
+	got, err = repo.GetTask(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.Version, "untouched tasks keep their version")
+
+	assert.Empty(t, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{Labels: []string{"backend"}}}))
+	assert.Equal(t, []string{"tagged", "other"}, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{Labels: []string{"urgent"}}}))
+
+	// The name is free again, and a new label of that name starts unattached.
+	createLabels(t, repo, "backend")
+	assert.Empty(t, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{Labels: []string{"backend"}}}))
+}
@@ -0,0 +1,212 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProject(id string) *model.Project {
+	return &model.Project{ID: id, Name: "Project " + id, CreatedAt: baseTime, UpdatedAt: baseTime}
+}
+
+func testProjectCRUD(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	projects, err := repo.ListProjects(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, projects)
+	assert.Empty(t, projects)
+
+	created := newProject("web")
+	created.Settings = model.ProjectSettings{
+		Workflow: &model.Workflow{
+			States:      []string{"open", "closed"},
+			Initial:     "open",
+			Done:        "closed",
+			Terminal:    []string{"closed"},
+			Transitions: map[string][]string{"open": {"closed"}, "closed": {"open"}},
+		},
+		Labels:   []string{"bug"},
+		IDPrefix: "WEB",
+	}
+	require.NoError(t, repo.CreateProject(ctx, created))
+	require.NoError(t, repo.CreateProject(ctx, newProject("api")))
+	assert.ErrorIs(t, repo.CreateProject(ctx, newProject("web")), repository.ErrProjectAlreadyExists)
+	created.Settings.Labels[0] = "changed"
+
+	got, err := repo.GetProject(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "Project web", got.Name)
+	assert.Equal(t, []string{"bug"}, got.Settings.Labels)
+	assert.Equal(t, "WEB", got.Settings.IDPrefix)
+	require.NotNil(t, got.Settings.Workflow)
+	assert.Equal(t, "closed", got.Settings.Workflow.Done)
+	assert.True(t, baseTime.Equal(got.CreatedAt))
+	_, err = repo.GetProject(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+
+	projects, err = repo.ListProjects(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, "api", projects[0].ID)
+	assert.Equal(t, "web", projects[1].ID)
+
+	// Task numbers count up per project and survive updates.
+	for want := int64(1); want <= 2; want++ {
+		n, err := repo.NextTaskNumber(ctx, "web")
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	_, err = repo.NextTaskNumber(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+
+	update := &model.Project{ID: "web", Name: "Website", UpdatedAt: baseTime.Add(time.Hour)}
+	require.NoError(t, repo.UpdateProject(ctx, update))
+	assert.True(t, baseTime.Equal(update.CreatedAt), "the stored project is returned")
+	assert.Equal(t, int64(2), update.LastTaskNumber)
+	got, err = repo.GetProject(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "Website", got.Name)
+	assert.Equal(t, model.ProjectSettings{}, got.Settings)
+	assert.Equal(t, int64(2), got.LastTaskNumber)
+	assert.True(t, baseTime.Add(time.Hour).Equal(got.UpdatedAt))
+	assert.ErrorIs(t, repo.UpdateProject(ctx, newProject("missing")), repository.ErrProjectNotFound)
+
+	// A project with tasks, even trashed ones, cannot be deleted.
+	task := newTask("t1", 0)
+	task.ProjectID = "api"
+	require.NoError(t, repo.CreateTask(ctx, task))
+	trash(t, repo, "t1", baseTime)
+	assert.ErrorIs(t, repo.DeleteProject(ctx, "api"), repository.ErrProjectNotEmpty)
+	require.NoError(t, repo.DeleteTask(ctx, "t1", repository.AnyVersion))
+	require.NoError(t, repo.DeleteProject(ctx, "api"))
+	_, err = repo.GetProject(ctx, "api")
+	assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+	assert.ErrorIs(t, repo.DeleteProject(ctx, "api"), repository.ErrProjectNotFound)
+}
+
+func testWriteTasks(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	createLabels(t, repo, "bug")
+	require.NoError(t, repo.CreateTask(ctx, newTask("a", 0)))
+	require.NoError(t, repo.CreateTask(ctx, newTask("b", time.Minute)))
+
+	a, err := repo.GetTask(ctx, "a")
+	require.NoError(t, err)
+	a.ProjectID = "web"
+	c := newTask("c", 2*time.Minute)
+	c.Labels = []string{"bug"}
+	c.ParentID = "a"
+	require.NoError(t, repo.WriteTasks(ctx, repository.TaskBatch{Create: []*model.Task{c}, Update: []*model.Task{a}}))
+	assert.Equal(t, int64(1), c.Version)
+	assert.Equal(t, int64(2), a.Version)
+	got, err := repo.GetTask(ctx, "a")
+	require.NoError(t, err)
+	assertSameTask(t, a, got)
+	got, err = repo.GetTask(ctx, "c")
+	require.NoError(t, err)
+	assertSameTask(t, c, got)
+
+	// A batch with one bad write leaves every task as it was.
+	failing := []repository.TaskBatch{
+		{Create: []*model.Task{newTask("d", 0), newTask("a", 0)}},
+		{Create: []*model.Task{newTask("d", 0), newTask("d", 0)}},
+		{Create: []*model.Task{newTask("d", 0)}, Update: []*model.Task{newTask("missing", 0)}},
+		{Create: []*model.Task{newTask("d", 0)}, Update: []*model.Task{{ID: "b", Title: "Stale", Version: 7}}},
+		{Update: []*model.Task{{ID: "b", Title: "Labelled", Version: 1, Labels: []string{"nope"}}}},
+	}
+	for _, batch := range failing {
+		assert.Error(t, repo.WriteTasks(ctx, batch))
+	}
+	_, err = repo.GetTask(ctx, "d")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	b, err := repo.GetTask(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "Task b", b.Title)
+	assert.Equal(t, int64(1), b.Version)
+
+	stale := &model.Task{ID: "b", Title: "Stale", Version: 7}
+	var conflict *repository.VersionConflictError
+	require.ErrorAs(t, repo.WriteTasks(ctx, repository.TaskBatch{Update: []*model.Task{stale}}), &conflict)
+	assert.Equal(t, int64(1), conflict.Actual)
+
+	// Deletes check the version like DeleteTask and fail the whole batch too.
+	c, err = repo.GetTask(ctx, "c")
+	require.NoError(t, err)
+	c.ParentID = ""
+	for _, batch := range []repository.TaskBatch{
+		{Update: []*model.Task{c}, Delete: []*model.Task{{ID: "a", Version: 1}}},
+		{Update: []*model.Task{c}, Delete: []*model.Task{{ID: "missing"}}},
+		{Update: []*model.Task{c}, Delete: []*model.Task{{ID: "c", Version: c.Version}}},
+	} {
+		assert.Error(t, repo.WriteTasks(ctx, batch))
+	}
+	got, err = repo.GetTask(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.ParentID)
+
+	require.NoError(t, repo.WriteTasks(ctx, repository.TaskBatch{
+		Update: []*model.Task{c},
+		Delete: []*model.Task{{ID: "a", Version: a.Version}, {ID: "b", Version: repository.AnyVersion}},
+	}))
+	for _, id := range []string{"a", "b"} {
+		_, err = repo.GetTask(ctx, id)
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound, id)
+	}
+	got, err = repo.GetTask(ctx, "c")
+	require.NoError(t, err)
+	assert.Empty(t, got.ParentID)
+}
+
+func testQueryProject(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	for id, project := range map[string]string{"a": "web", "c": "web", "d": "api"} {
+		task, err := repo.GetTask(ctx, id)
+		require.NoError(t, err)
+		task.ProjectID = project
+		require.NoError(t, repo.UpdateTask(ctx, task))
+	}
+	stored, err := repo.GetTask(ctx, "d")
+	require.NoError(t, err)
+	assert.Equal(t, "api", stored.ProjectID)
+
+	project := func(id string) model.TaskFilter { return model.TaskFilter{ProjectID: &id} }
+	assert.Equal(t, []string{"a", "c"}, queryIDs(t, repo, model.TaskQuery{Filter: project("web")}))
+	assert.Equal(t, []string{"b", "e"}, queryIDs(t, repo, model.TaskQuery{Filter: project("")}))
+	assert.Equal(t, []string{}, queryIDs(t, repo, model.TaskQuery{Filter: project("other")}))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, queryIDs(t, repo, model.TaskQuery{}))
+}
+
+func testQueryRank(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	// Unranked tasks rank by creation time: a, b, c, then d and e at the same time.
+	for id, rank := range map[string]string{"a": "z", "c": "0i"} {
+		task, err := repo.GetTask(ctx, id)
+		require.NoError(t, err)
+		task.Rank = rank
+		require.NoError(t, repo.UpdateTask(ctx, task))
+	}
+	stored, err := repo.GetTask(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, "0i", stored.Rank)
+
+	byRank := model.TaskQuery{Sort: model.SortByRank}
+	assert.Equal(t, []string{"c", "b", "d", "e", "a"}, queryIDs(t, repo, byRank))
+	byRank.Desc = true
+	assert.Equal(t, []string{"a", "e", "d", "b", "c"}, queryIDs(t, repo, byRank))
+
+	q := model.TaskQuery{Sort: model.SortByRank, Limit: 2}
+	page, err := repo.QueryTasks(ctx, q)
+	require.NoError(t, err)
+	require.NotNil(t, page.Next)
+	q.After = page.Next
+	assert.Equal(t, []string{"d", "e"}, queryIDs(t, repo, q))
+}
@@ -0,0 +1,253 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedQueryTasks stores five tasks with distinct creation and update times,
+// titles that sort differently from their IDs, and two of them completed.
+func seedQueryTasks(t *testing.T, repo repository.TaskRepository) {
+	t.Helper()
+	ctx := context.Background()
+	seed := []struct {
+		id, title string
+		created   time.Duration
+		updated   time.Duration
+		completed bool
+	}{
+		{"a", "Write report", 0, 4 * time.Hour, false},
+		{"b", "buy milk", time.Hour, time.Hour, true},
+		{"c", "Review REPORT draft", 2 * time.Hour, 2 * time.Hour, false},
+		{"d", "Alpha release", 3 * time.Hour, 5 * time.Hour, true},
+		{"e", "Zebra crossing", 3 * time.Hour, 3 * time.Hour, false},
+	}
+	for _, s := range seed {
+		task := newTask(s.id, s.created)
+		task.Title = s.title
+		task.UpdatedAt = baseTime.Add(s.updated)
+		task.Completed = s.completed
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+}
+
+func queryIDs(t *testing.T, repo repository.TaskRepository, q model.TaskQuery) []string {
+	t.Helper()
+	page, err := repo.QueryTasks(context.Background(), q)
+	require.NoError(t, err)
+	ids := []string{}
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func testQueryFilters(t *testing.T, repo repository.TaskRepository) {
+	seedQueryTasks(t, repo)
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter model.TaskFilter
+		want   []string
+	}{
+		{"none", model.TaskFilter{}, []string{"a", "b", "c", "d", "e"}},
+		{"completed", model.TaskFilter{Completed: &yes}, []string{"b", "d"}},
+		{"not completed", model.TaskFilter{Completed: &no}, []string{"a", "c", "e"}},
+		{"created range", model.TaskFilter{CreatedAfter: baseTime.Add(time.Hour), CreatedBefore: baseTime.Add(3 * time.Hour)}, []string{"b", "c"}},
+		{"updated after", model.TaskFilter{UpdatedAfter: baseTime.Add(4 * time.Hour)}, []string{"a", "d"}},
+		{"updated before", model.TaskFilter{UpdatedBefore: baseTime.Add(2 * time.Hour)}, []string{"b"}},
+		{"title ignores case", model.TaskFilter{TitleContains: "report"}, []string{"a", "c"}},
+		{"combined", model.TaskFilter{TitleContains: "R", Completed: &yes}, []string{"d"}},
+		{"no match", model.TaskFilter{TitleContains: "nothing"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, queryIDs(t, repo, model.TaskQuery{Filter: tt.filter}))
+		})
+	}
+}
+
+func testQuerySort(t *testing.T, repo repository.TaskRepository) {
+	seedQueryTasks(t, repo)
+
+	tests := []struct {
+		sort model.TaskSortField
+		desc bool
+		want []string
+	}{
+		{model.SortByCreatedAt, false, []string{"a", "b", "c", "d", "e"}},
+		{model.SortByCreatedAt, true, []string{"e", "d", "c", "b", "a"}},
+		{model.SortByUpdatedAt, false, []string{"b", "c", "e", "a", "d"}},
+		// Titles compare byte-wise, so upper case sorts before lower case.
+		{model.SortByTitle, false, []string{"d", "c", "a", "e", "b"}},
+		{model.SortByTitle, true, []string{"b", "e", "a", "c", "d"}},
+	}
+	for _, tt := range tests {
+		name := string(tt.sort)
+		if tt.desc {
+			name += " desc"
+		}
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, queryIDs(t, repo, model.TaskQuery{Sort: tt.sort, Desc: tt.desc}))
+		})
+	}
+}
+
+// testQueryPagination walks every ordering two tasks at a time and checks the
+// pages concatenate to the unpaginated result.
+func testQueryPagination(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+
+	for _, sort := range []model.TaskSortField{model.SortByCreatedAt, model.SortByUpdatedAt, model.SortByTitle} {
+		for _, desc := range []bool{false, true} {
+			want := queryIDs(t, repo, model.TaskQuery{Sort: sort, Desc: desc})
+
+			q := model.TaskQuery{Sort: sort, Desc: desc, Limit: 2}
+			var got []string
+			pages := 0
+			for {
+				page, err := repo.QueryTasks(ctx, q)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(page.Tasks), 2)
+				pages++
+				for _, task := range page.Tasks {
+					got = append(got, task.ID)
+				}
+				if page.Next == nil {
+					break
+				}
+				require.Less(t, pages, 10, "pagination does not terminate")
+				q.After = page.Next
+			}
+			assert.Equal(t, want, got, "sort %s desc=%v", sort, desc)
+			assert.Equal(t, 3, pages, "sort %s desc=%v", sort, desc)
+		}
+	}
+}
+
+// testQueryPaginationAfterDelete checks that a cursor stays valid when the task
+// it points at is deleted before the next page is fetched.
+func testQueryPaginationAfterDelete(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+
+	page, err := repo.QueryTasks(ctx, model.TaskQuery{Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, page.Next)
+	require.NoError(t, repo.DeleteTask(ctx, "b", repository.AnyVersion))
+
+	assert.Equal(t, []string{"c", "d", "e"}, queryIDs(t, repo, model.TaskQuery{After: page.Next}))
+}
+
+// testQueryPlanningFields covers sorting and filtering by priority, start and
+// due dates, including tasks that have no dates.
+func testQueryPlanningFields(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	at := func(h int) *time.Time {
+		v := baseTime.Add(time.Duration(h) * time.Hour)
+		return &v
+	}
+	seed := []struct {
+		id       string
+		priority model.Priority
+		start    *time.Time
+		due      *time.Time
+	}{
+		{"a", model.PriorityLow, nil, at(30)},
+		{"b", model.PriorityUrgent, at(1), at(10)},
+		{"c", model.PriorityNone, nil, nil},
+		{"d", model.PriorityHigh, at(2), at(20)},
+		{"e", model.PriorityHigh, nil, nil},
+	}
+	for i, s := range seed {
+		task := newTask(s.id, time.Duration(i)*time.Second)
+		task.Priority, task.StartAt, task.DueAt = s.priority, s.start, s.due
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+
+	// Descending order also reverses the ID tie-break.
+	assert.Equal(t, []string{"b", "e", "d", "a", "c"}, queryIDs(t, repo, model.TaskQuery{Sort: model.SortByPriority, Desc: true}))
+	assert.Equal(t, []string{"b", "d", "a", "c", "e"}, queryIDs(t, repo, model.TaskQuery{Sort: model.SortByDueAt}), "undated tasks sort last")
+	assert.Equal(t, []string{"e", "c", "a", "d", "b"}, queryIDs(t, repo, model.TaskQuery{Sort: model.SortByDueAt, Desc: true}))
+	assert.Equal(t, []string{"b", "d", "a", "c", "e"}, queryIDs(t, repo, model.TaskQuery{Sort: model.SortByStartAt}))
+
+	assert.Equal(t, []string{"b", "d"}, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{DueBefore: *at(30)}}))
+	assert.Equal(t, []string{"a", "d"}, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{DueAfter: *at(20)}}))
+	assert.Equal(t, []string{"d"}, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{StartAfter: *at(2)}}))
+	assert.Equal(t, []string{"b", "d", "e"}, queryIDs(t, repo, model.TaskQuery{
+		Filter: model.TaskFilter{Priorities: []model.Priority{model.PriorityHigh, model.PriorityUrgent}},
+	}))
+	assert.Equal(t, []string{"c"}, queryIDs(t, repo, model.TaskQuery{
+		Filter: model.TaskFilter{Priorities: []model.Priority{model.PriorityNone}},
+	}))
+
+	// Paging across the undated tail keeps every task exactly once.
+	q := model.TaskQuery{Sort: model.SortByDueAt, Limit: 2}
+	var got []string
+	for {
+		page, err := repo.QueryTasks(ctx, q)
+		require.NoError(t, err)
+		for _, task := range page.Tasks {
+			got = append(got, task.ID)
+		}
+		if page.Next == nil {
+			break
+		}
+		q.After = page.Next
+	}
+	assert.Equal(t, []string{"b", "d", "a", "c", "e"}, got)
+}
+
+func testQueryParent(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	for id, parent := range map[string]string{"b": "a", "c": "a", "d": "c"} {
+		task, err := repo.GetTask(ctx, id)
+		require.NoError(t, err)
+		task.ParentID = parent
+		require.NoError(t, repo.UpdateTask(ctx, task))
+	}
+	stored, err := repo.GetTask(ctx, "d")
+	require.NoError(t, err)
+	assert.Equal(t, "c", stored.ParentID)
+
+	parent := func(id string) model.TaskFilter { return model.TaskFilter{ParentID: &id} }
+	assert.Equal(t, []string{"b", "c"}, queryIDs(t, repo, model.TaskQuery{Filter: parent("a")}))
+	assert.Equal(t, []string{"a", "e"}, queryIDs(t, repo, model.TaskQuery{Filter: parent("")}))
+	assert.Equal(t, []string{}, queryIDs(t, repo, model.TaskQuery{Filter: parent("missing")}))
+	completed := true
+	assert.Equal(t, []string{"b"}, queryIDs(t, repo, model.TaskQuery{Filter: model.TaskFilter{ParentID: parent("a").ParentID, Completed: &completed}}))
+
+	// Moving and deleting a child keeps the listing in sync.
+	stored.ParentID = "a"
+	require.NoError(t, repo.UpdateTask(ctx, stored))
+	require.NoError(t, repo.DeleteTask(ctx, "b", 0))
+	assert.Equal(t, []string{"c", "d"}, queryIDs(t, repo, model.TaskQuery{Filter: parent("a")}))
+	assert.Equal(t, []string{}, queryIDs(t, repo, model.TaskQuery{Filter: parent("c")}))
+}
+
+func testQueryStatus(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	for id, status := range map[string]string{"a": model.StatusInProgress, "c": model.StatusInReview, "d": model.StatusCancelled} {
+		task, err := repo.GetTask(ctx, id)
+		require.NoError(t, err)
+		task.Status = status
+		require.NoError(t, repo.UpdateTask(ctx, task))
+	}
+
+	statuses := func(s ...string) model.TaskQuery { return model.TaskQuery{Filter: model.TaskFilter{Statuses: s}} }
+	assert.Equal(t, []string{"a"}, queryIDs(t, repo, statuses(model.StatusInProgress)))
+	assert.Equal(t, []string{"a", "c"}, queryIDs(t, repo, statuses(model.StatusInReview, model.StatusInProgress)))
+	assert.Equal(t, []string{"b", "e"}, queryIDs(t, repo, statuses(model.StatusTodo)))
+	assert.Equal(t, []string{}, queryIDs(t, repo, statuses("unknown")))
+}
@@ -0,0 +1,443 @@
+// Package repotest provides a conformance test suite for repository.TaskRepository
+// implementations. Every storage backend must pass Run before it is adopted:
+//
+//	func TestMyRepository_Conformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repository.TaskRepository {
+//			return NewMyRepository(...)
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns a new, empty repository for a single subtest. Implementations
+// should register any cleanup with t.Cleanup.
+type Factory func(t *testing.T) repository.TaskRepository
+
+// Run exercises the TaskRepository contract against repositories built by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.TaskRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"CreateDuplicate", testCreateDuplicate},
+		{"GetNotFound", testGetNotFound},
+		{"Update", testUpdate},
+		{"UpdateNotFound", testUpdateNotFound},
+		{"Delete", testDelete},
+		{"DeleteNotFound", testDeleteNotFound},
+		{"ListEmpty", testListEmpty},
+		{"ListOrdering", testListOrdering},
+		{"Isolation", testIsolation},
+		{"ConcurrentCreate", testConcurrentCreate},
+		{"ConcurrentCreateSameID", testConcurrentCreateSameID},
+		{"Versioning", testVersioning},
+		{"DeleteVersioning", testDeleteVersioning},
+		{"ConcurrentReadWrite", testConcurrentReadWrite},
+		{"ConcurrentUpdateSameVersion", testConcurrentUpdateSameVersion},
+		{"QueryFilters", testQueryFilters},
+		{"QuerySort", testQuerySort},
+		{"QueryPagination", testQueryPagination},
+		{"QueryPaginationAfterDelete", testQueryPaginationAfterDelete},
+		{"QueryPlanningFields", testQueryPlanningFields},
+		{"LabelCRUD", testLabelCRUD},
+		{"TaskLabels", testTaskLabels},
+		{"QueryLabels", testQueryLabels},
+		{"DeleteLabelDetaches", testDeleteLabelDetaches},
+		{"QueryParent", testQueryParent},
+		{"QueryStatus", testQueryStatus},
+		{"Dependencies", testDependencies},
+		{"DependencyCycles", testDependencyCycles},
+		{"DeleteTaskDropsDependencies", testDeleteTaskDropsDependencies},
+		{"History", testHistory},
+		{"HistoryOutlivesTask", testHistoryOutlivesTask},
+		{"Trash", testTrash},
+		{"CommentCRUD", testCommentCRUD},
+		{"CommentPagination", testCommentPagination},
+		{"PurgeDeletesComments", testPurgeDeletesComments},
+		{"Attachments", testAttachments},
+		{"UserCRUD", testUserCRUD},
+		{"QueryAssignee", testQueryAssignee},
+		{"ProjectCRUD", testProjectCRUD},
+		{"WriteTasks", testWriteTasks},
+		{"QueryProject", testQueryProject},
+		{"QueryRank", testQueryRank},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newRepo(t))
+		})
+	}
+}
+
+// baseTime is truncated to microseconds so backends with coarser clocks still
+// round-trip timestamps exactly.
+var baseTime = time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+func newTask(id string, offset time.Duration) *model.Task {
+	return &model.Task{
+		ID:          id,
+		Title:       "Task " + id,
+		Description: "Description of " + id,
+		Status:      model.StatusTodo,
+		CreatedAt:   baseTime.Add(offset),
+		UpdatedAt:   baseTime.Add(offset),
+	}
+}
+
+func assertSameTask(t *testing.T, want, got *model.Task) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.Title, got.Title)
+	assert.Equal(t, want.Description, got.Description)
+	assert.Equal(t, want.Status, got.Status)
+	assert.Equal(t, want.Completed, got.Completed)
+	assert.Equal(t, want.Version, got.Version)
+	assert.Equal(t, want.Priority, got.Priority)
+	assert.Equal(t, want.Labels, got.Labels)
+	assert.Equal(t, want.ParentID, got.ParentID)
+	assert.Equal(t, want.EstimateMinutes, got.EstimateMinutes)
+	assert.Equal(t, want.Recurrence, got.Recurrence)
+	assert.Equal(t, want.Assignee, got.Assignee)
+	assert.Equal(t, want.CreatedBy, got.CreatedBy)
+	assert.Equal(t, want.ProjectID, got.ProjectID)
+	assert.Equal(t, want.RankKey(), got.RankKey())
+	assertSameTime(t, "start_at", want.StartAt, got.StartAt)
+	assertSameTime(t, "due_at", want.DueAt, got.DueAt)
+	assert.True(t, want.CreatedAt.Equal(got.CreatedAt), "created_at: want %v, got %v", want.CreatedAt, got.CreatedAt)
+	assert.True(t, want.UpdatedAt.Equal(got.UpdatedAt), "updated_at: want %v, got %v", want.UpdatedAt, got.UpdatedAt)
+}
+
+func assertSameTime(t *testing.T, name string, want, got *time.Time) {
+	t.Helper()
+	if want == nil || got == nil {
+		assert.Equal(t, want == nil, got == nil, "%s: want %v, got %v", name, want, got)
+		return
+	}
+	assert.True(t, want.Equal(*got), "%s: want %v, got %v", name, *want, *got)
+}
+
+func testCreateAndGet(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	task.Status = model.StatusDone
+	task.Completed = true
+	task.Priority = model.PriorityHigh
+	due := baseTime.Add(48 * time.Hour)
+	task.DueAt = &due
+	require.NoError(t, repo.CreateTask(ctx, task))
+	assert.Equal(t, int64(1), task.Version, "create must start at version 1")
+
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assertSameTask(t, task, got)
+}
+
+func testCreateDuplicate(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	original := newTask("task-1", 0)
+	require.NoError(t, repo.CreateTask(ctx, original))
+
+	dup := newTask("task-1", time.Hour)
+	dup.Title = "Duplicate"
+	assert.ErrorIs(t, repo.CreateTask(ctx, dup), repository.ErrTaskAlreadyExists)
+
+	got, err := repo.GetTask(ctx, original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, original.Title, got.Title, "duplicate create must not overwrite")
+}
+
+func testGetNotFound(t *testing.T, repo repository.TaskRepository) {
+	_, err := repo.GetTask(context.Background(), "missing")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+}
+
+func testUpdate(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	updated := task.Clone()
+	updated.Title = "Updated"
+	updated.Description = ""
+	updated.Status = model.StatusCancelled
+	updated.Completed = true
+	updated.Priority = model.PriorityUrgent
+	start := baseTime.Add(time.Hour)
+	updated.StartAt = &start
+	updated.EstimateMinutes = 90
+	updated.Recurrence = &model.Recurrence{Rule: "FREQ=WEEKLY;BYDAY=MO", TZ: "Europe/Berlin", Occurrence: 2}
+	updated.UpdatedAt = baseTime.Add(time.Minute)
+	require.NoError(t, repo.UpdateTask(ctx, updated))
+	assert.Equal(t, int64(2), updated.Version, "update must increment the version")
+
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assertSameTask(t, updated, got)
+}
+
+func testUpdateNotFound(t *testing.T, repo repository.TaskRepository) {
+	err := repo.UpdateTask(context.Background(), newTask("missing", 0))
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+	_, err = repo.GetTask(context.Background(), "missing")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound, "update must not create")
+}
+
+func testDelete(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	keep, gone := newTask("keep", 0), newTask("gone", time.Second)
+	require.NoError(t, repo.CreateTask(ctx, keep))
+	require.NoError(t, repo.CreateTask(ctx, gone))
+
+	require.NoError(t, repo.DeleteTask(ctx, gone.ID, repository.AnyVersion))
+	_, err := repo.GetTask(ctx, gone.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, keep.ID, tasks[0].ID)
+
+	// The ID can be reused once deleted.
+	assert.NoError(t, repo.CreateTask(ctx, newTask("gone", 2*time.Second)))
+}
+
+func testDeleteNotFound(t *testing.T, repo repository.TaskRepository) {
+	assert.ErrorIs(t, repo.DeleteTask(context.Background(), "missing", repository.AnyVersion), repository.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(context.Background(), "missing", 3), repository.ErrTaskNotFound)
+}
+
+func testListEmpty(t *testing.T, repo repository.TaskRepository) {
+	tasks, err := repo.ListTasks(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	n, err := repo.CountTasks(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// testListOrdering checks that ListTasks returns tasks by CreatedAt ascending,
+// breaking ties by ID, regardless of insertion order.
+func testListOrdering(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	inserts := []*model.Task{
+		newTask("c", 2*time.Second),
+		newTask("a", 0),
+		newTask("e", time.Second),
+		newTask("b", time.Second),
+		newTask("d", 3*time.Second),
+	}
+	for _, task := range inserts {
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	assert.Equal(t, []string{"a", "b", "e", "c", "d"}, ids)
+}
+
+// testIsolation checks that callers cannot modify stored state through the
+// pointers they pass in or get back.
+func testIsolation(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	require.NoError(t, repo.CreateTask(ctx, task))
+	task.Title = "mutated after create"
+
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Task task-1", got.Title)
+	got.Title = "mutated after get"
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Task task-1", tasks[0].Title)
+	tasks[0].Title = "mutated after list"
+
+	got, err = repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Task task-1", got.Title)
+}
+
+func testConcurrentCreate(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	const n = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- repo.CreateTask(ctx, newTask(fmt.Sprintf("task-%d", i), time.Duration(i)*time.Millisecond))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, n)
+}
+
+func testConcurrentCreateSameID(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	const n = 20
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.CreateTask(ctx, newTask("same", 0))
+			if err == nil {
+				succeeded.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, repository.ErrTaskAlreadyExists)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), succeeded.Load(), "exactly one create must win")
+}
+
+func testVersioning(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	first := task.Clone()
+	first.Title = "first writer"
+	require.NoError(t, repo.UpdateTask(ctx, first))
+
+	stale := task.Clone()
+	stale.Title = "second writer"
+	err := repo.UpdateTask(ctx, stale)
+	require.ErrorIs(t, err, repository.ErrVersionConflict)
+	var conflict *repository.VersionConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, task.ID, conflict.ID)
+	assert.Equal(t, int64(1), conflict.Expected)
+	assert.Equal(t, int64(2), conflict.Actual)
+	assert.Equal(t, int64(1), stale.Version, "a rejected update must not touch the version")
+
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "first writer", got.Title)
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func testDeleteVersioning(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	require.NoError(t, repo.CreateTask(ctx, task))
+	require.NoError(t, repo.UpdateTask(ctx, task.Clone()))
+
+	err := repo.DeleteTask(ctx, task.ID, 1)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	_, err = repo.GetTask(ctx, task.ID)
+	require.NoError(t, err, "a rejected delete must keep the task")
+
+	require.NoError(t, repo.DeleteTask(ctx, task.ID, 2))
+	_, err = repo.GetTask(ctx, task.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+}
+
+// testConcurrentReadWrite runs read-modify-write loops that retry on conflicts
+// and checks that no increment is lost.
+func testConcurrentReadWrite(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	task.Description = "0"
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for {
+				current, err := repo.GetTask(ctx, task.ID)
+				if !assert.NoError(t, err) {
+					return
+				}
+				count, _ := strconv.Atoi(current.Description)
+				current.Description = strconv.Itoa(count + 1)
+				err = repo.UpdateTask(ctx, current)
+				if errors.Is(err, repository.ErrVersionConflict) {
+					continue
+				}
+				assert.NoError(t, err)
+				return
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetTask(ctx, task.ID)
+			assert.NoError(t, err)
+			_, err = repo.ListTasks(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(n), got.Description)
+	assert.Equal(t, int64(n+1), got.Version)
+}
+
+func testConcurrentUpdateSameVersion(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	task := newTask("task-1", 0)
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	const n = 20
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			update := task.Clone()
+			update.Title = fmt.Sprintf("title-%d", i)
+			err := repo.UpdateTask(ctx, update)
+			if err == nil {
+				succeeded.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), succeeded.Load(), "exactly one update from the same version must win")
+}
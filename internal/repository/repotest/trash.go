@@ -0,0 +1,75 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trash moves task id to the trash at deletedAt.
+func trash(t *testing.T, repo repository.TaskRepository, id string, deletedAt time.Time) *model.Task {
+	t.Helper()
+	task, err := repo.GetTask(context.Background(), id)
+	require.NoError(t, err)
+	task.DeletedAt = &deletedAt
+	require.NoError(t, repo.UpdateTask(context.Background(), task))
+	return task
+}
+
+func testTrash(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	for i, id := range []string{"a", "b", "c"} {
+		require.NoError(t, repo.CreateTask(ctx, newTask(id, time.Duration(i)*time.Minute)))
+	}
+	trashed := trash(t, repo, "b", baseTime.Add(time.Hour))
+	assert.Equal(t, int64(2), trashed.Version)
+	trash(t, repo, "c", baseTime.Add(2*time.Hour))
+
+	_, err := repo.GetTask(ctx, "b")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	got, err := repo.GetTrashedTask(ctx, "b")
+	require.NoError(t, err)
+	require.NotNil(t, got.DeletedAt)
+	assert.True(t, baseTime.Add(time.Hour).Equal(*got.DeletedAt))
+	_, err = repo.GetTrashedTask(ctx, "a")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound, "live tasks are not in the trash")
+	assert.ErrorIs(t, repo.CreateTask(ctx, newTask("b", 0)), repository.ErrTaskAlreadyExists)
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "a", tasks[0].ID)
+	n, err := repo.CountTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "trashed tasks are not counted")
+
+	for _, tc := range []struct {
+		name   string
+		filter model.TaskFilter
+		want   []string
+	}{
+		{"live", model.TaskFilter{}, []string{"a"}},
+		{"trash", model.TaskFilter{Trashed: true}, []string{"b", "c"}},
+		{"deleted before", model.TaskFilter{Trashed: true, DeletedBefore: baseTime.Add(2 * time.Hour)}, []string{"b"}},
+		{"deleted after", model.TaskFilter{Trashed: true, DeletedAfter: baseTime.Add(2 * time.Hour)}, []string{"c"}},
+	} {
+		assert.Equal(t, tc.want, queryIDs(t, repo, model.TaskQuery{Filter: tc.filter}), tc.name)
+	}
+
+	got.DeletedAt = nil
+	require.NoError(t, repo.UpdateTask(ctx, got))
+	restored, err := repo.GetTask(ctx, "b")
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(3), restored.Version)
+
+	require.NoError(t, repo.DeleteTask(ctx, "c", repository.AnyVersion), "trashed tasks can be purged")
+	_, err = repo.GetTrashedTask(ctx, "c")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+}
@@ -0,0 +1,92 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUser(id string) *model.User {
+	return &model.User{ID: id, Name: "User " + id, Email: id + "@example.com", CreatedAt: baseTime, UpdatedAt: baseTime}
+}
+
+func testUserCRUD(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	users, err := repo.ListUsers(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, users)
+	assert.Empty(t, users)
+
+	created := newUser("bob")
+	require.NoError(t, repo.CreateUser(ctx, created))
+	require.NoError(t, repo.CreateUser(ctx, newUser("alice")))
+	assert.ErrorIs(t, repo.CreateUser(ctx, newUser("bob")), repository.ErrUserAlreadyExists)
+	created.Name = "changed"
+
+	got, err := repo.GetUser(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "User bob", got.Name)
+	assert.Equal(t, "bob@example.com", got.Email)
+	assert.True(t, baseTime.Equal(got.CreatedAt))
+	_, err = repo.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+	users, err = repo.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].ID)
+	assert.Equal(t, "bob", users[1].ID)
+
+	update := &model.User{ID: "bob", Name: "Robert", UpdatedAt: baseTime.Add(time.Hour)}
+	require.NoError(t, repo.UpdateUser(ctx, update))
+	assert.True(t, baseTime.Equal(update.CreatedAt), "the stored user is returned")
+	got, err = repo.GetUser(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "Robert", got.Name)
+	assert.Empty(t, got.Email)
+	assert.True(t, baseTime.Add(time.Hour).Equal(got.UpdatedAt))
+	assert.ErrorIs(t, repo.UpdateUser(ctx, newUser("missing")), repository.ErrUserNotFound)
+
+	require.NoError(t, repo.DeleteUser(ctx, "bob"))
+	_, err = repo.GetUser(ctx, "bob")
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+	assert.ErrorIs(t, repo.DeleteUser(ctx, "bob"), repository.ErrUserNotFound)
+}
+
+func testQueryAssignee(t *testing.T, repo repository.TaskRepository) {
+	ctx := context.Background()
+	seedQueryTasks(t, repo)
+	for id, assignee := range map[string]string{"a": "alice", "c": "alice", "d": "bob"} {
+		task, err := repo.GetTask(ctx, id)
+		require.NoError(t, err)
+		task.Assignee = assignee
+		task.CreatedBy = "carol"
+		require.NoError(t, repo.UpdateTask(ctx, task))
+	}
+	stored, err := repo.GetTask(ctx, "d")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", stored.Assignee)
+	assert.Equal(t, "carol", stored.CreatedBy)
+
+	assignee := func(id string) model.TaskFilter { return model.TaskFilter{Assignee: &id} }
+	assert.Equal(t, []string{"a", "c"}, queryIDs(t, repo, model.TaskQuery{Filter: assignee("alice")}))
+	assert.Equal(t, []string{"b", "e"}, queryIDs(t, repo, model.TaskQuery{Filter: assignee("")}))
+	assert.Equal(t, []string{}, queryIDs(t, repo, model.TaskQuery{Filter: assignee("nobody")}))
+
+	// Reassigning and unassigning keeps the listing in sync.
+	stored.Assignee = "alice"
+	require.NoError(t, repo.UpdateTask(ctx, stored))
+	a, err := repo.GetTask(ctx, "a")
+	require.NoError(t, err)
+	a.Assignee = ""
+	require.NoError(t, repo.UpdateTask(ctx, a))
+	assert.Equal(t, []string{"c", "d"}, queryIDs(t, repo, model.TaskQuery{Filter: assignee("alice")}))
+	assert.Equal(t, []string{}, queryIDs(t, repo, model.TaskQuery{Filter: assignee("bob")}))
+	assert.Equal(t, []string{"a", "b", "e"}, queryIDs(t, repo, model.TaskQuery{Filter: assignee("")}))
+}
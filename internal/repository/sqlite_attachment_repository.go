@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const attachmentColumns = `task_id, id, filename, content_type, size, sha256, uploader, created_at`
+
+// CreateAttachment adds a new attachment. The foreign key rejects attachments
+// on missing tasks.
+func (r *SQLiteTaskRepository) CreateAttachment(ctx context.Context, a *model.Attachment) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO task_attachments (`+attachmentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.TaskID, a.ID, a.Filename, a.ContentType, a.Size, a.SHA256, a.Uploader, a.CreatedAt.UnixNano())
+	switch {
+	case isUniqueViolation(err):
+		return ErrAttachmentAlreadyExists
+	case isForeignKeyViolation(err):
+		r.logger.Warn("task not found for attachment", zap.String("id", a.TaskID))
+		return ErrTaskNotFound
+	case err != nil:
+		return fmt.Errorf("insert attachment: %w", err)
+	}
+	r.logger.Info("attachment created", zap.String("task", a.TaskID), zap.String("id", a.ID))
+	return nil
+}
+
+// GetAttachment retrieves attachment id on taskID.
+func (r *SQLiteTaskRepository) GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+attachmentColumns+` FROM task_attachments WHERE task_id = ? AND id = ?`, taskID, id)
+	a, err := scanAttachment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+	return a, nil
+}
+
+// ListAttachments returns the attachments of taskID ordered by CreatedAt, then ID.
+func (r *SQLiteTaskRepository) ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+attachmentColumns+` FROM task_attachments WHERE task_id = ? ORDER BY created_at, id`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	defer rows.Close()
+	attachments := []*model.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// DeleteAttachment removes attachment id from taskID.
+func (r *SQLiteTaskRepository) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM task_attachments WHERE task_id = ? AND id = ?`, taskID, id)
+	if err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAttachmentNotFound
+	}
+	r.logger.Info("attachment deleted", zap.String("task", taskID), zap.String("id", id))
+	return nil
+}
+
+// ListAttachmentBlobs returns the distinct digests referenced by attachments.
+func (r *SQLiteTaskRepository) ListAttachmentBlobs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT sha256 FROM task_attachments ORDER BY sha256`)
+	if err != nil {
+		return nil, fmt.Errorf("list attachment blobs: %w", err)
+	}
+	defer rows.Close()
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scan attachment blob: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list attachment blobs: %w", err)
+	}
+	return keys, nil
+}
+
+func scanAttachment(s rowScanner) (*model.Attachment, error) {
+	var (
+		a         model.Attachment
+		createdAt int64
+	)
+	if err := s.Scan(&a.TaskID, &a.ID, &a.Filename, &a.ContentType, &a.Size, &a.SHA256, &a.Uploader,
+		&createdAt); err != nil {
+		return nil, err
+	}
+	a.CreatedAt = time.Unix(0, createdAt).UTC()
+	return &a, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const commentColumns = `task_id, id, author, body, edited, created_at, updated_at`
+
+// CreateComment adds a new comment. The foreign key rejects comments on
+// missing tasks.
+func (r *SQLiteTaskRepository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO task_comments (`+commentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		comment.TaskID, comment.ID, comment.Author, comment.Body, comment.Edited,
+		comment.CreatedAt.UnixNano(), comment.UpdatedAt.UnixNano())
+	switch {
+	case isUniqueViolation(err):
+		return ErrCommentAlreadyExists
+	case isForeignKeyViolation(err):
+		r.logger.Warn("task not found for comment", zap.String("id", comment.TaskID))
+		return ErrTaskNotFound
+	case err != nil:
+		return fmt.Errorf("insert comment: %w", err)
+	}
+	r.logger.Info("comment created", zap.String("task", comment.TaskID), zap.String("id", comment.ID))
+	return nil
+}
+
+// GetComment retrieves comment id on taskID.
+func (r *SQLiteTaskRepository) GetComment(ctx context.Context, taskID, id string) (*model.Comment, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+commentColumns+` FROM task_comments WHERE task_id = ? AND id = ?`, taskID, id)
+	comment, err := scanComment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// UpdateComment replaces an existing comment.
+func (r *SQLiteTaskRepository) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE task_comments SET author = ?, body = ?, edited = ?, created_at = ?, updated_at = ?
+		WHERE task_id = ? AND id = ?`,
+		comment.Author, comment.Body, comment.Edited, comment.CreatedAt.UnixNano(), comment.UpdatedAt.UnixNano(),
+		comment.TaskID, comment.ID)
+	if err != nil {
+		return fmt.Errorf("update comment: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCommentNotFound
+	}
+	r.logger.Info("comment updated", zap.String("task", comment.TaskID), zap.String("id", comment.ID))
+	return nil
+}
+
+// DeleteComment removes comment id from taskID.
+func (r *SQLiteTaskRepository) DeleteComment(ctx context.Context, taskID, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM task_comments WHERE task_id = ? AND id = ?`, taskID, id)
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCommentNotFound
+	}
+	r.logger.Info("comment deleted", zap.String("task", taskID), zap.String("id", id))
+	return nil
+}
+
+// ListComments returns a page of the comments selected by q, using keyset
+// pagination on the creation time and ID.
+func (r *SQLiteTaskRepository) ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error) {
+	query := `SELECT ` + commentColumns + ` FROM task_comments WHERE task_id = ?`
+	args := []any{q.TaskID}
+	if c := q.After; c != nil {
+		query += ` AND (created_at > ? OR (created_at = ? AND id > ?))`
+		args = append(args, c.CreatedAt, c.CreatedAt, c.ID)
+	}
+	query += ` ORDER BY created_at, id`
+	if q.Limit > 0 {
+		// Fetch one extra row to learn whether there is a next page.
+		query += ` LIMIT ?`
+		args = append(args, q.Limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	defer rows.Close()
+	page := &model.CommentPage{Comments: []*model.Comment{}}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		page.Comments = append(page.Comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+	if q.Limit > 0 && len(page.Comments) > q.Limit {
+		page.Comments = page.Comments[:q.Limit]
+		page.Next = q.CursorAt(page.Comments[q.Limit-1])
+	}
+	return page, nil
+}
+
+func scanComment(s rowScanner) (*model.Comment, error) {
+	var (
+		c                    model.Comment
+		createdAt, updatedAt int64
+	)
+	if err := s.Scan(&c.TaskID, &c.ID, &c.Author, &c.Body, &c.Edited, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c.CreatedAt = time.Unix(0, createdAt).UTC()
+	c.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	return &c, nil
+}
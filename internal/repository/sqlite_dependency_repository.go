@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const dependencyColumns = `task_id, blocker_id, created_at`
+
+// dependsOnQuery reports whether its first argument transitively depends on
+// its second by walking the blocker edges upwards. UNION discards revisited
+// tasks, so the walk terminates.
+const dependsOnQuery = `
+WITH RECURSIVE upstream(id) AS (
+	SELECT ?
+	UNION
+	SELECT d.blocker_id FROM task_dependencies d JOIN upstream u ON d.task_id = u.id
+)
+SELECT EXISTS (SELECT 1 FROM upstream WHERE id = ?)`
+
+// AddDependency records that dep.TaskID is blocked by dep.BlockerID. The cycle
+// check and the insert share a transaction.
+func (r *SQLiteTaskRepository) AddDependency(ctx context.Context, dep *model.Dependency) error {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var cycle bool
+		if err := tx.QueryRowContext(ctx, dependsOnQuery, dep.BlockerID, dep.TaskID).Scan(&cycle); err != nil {
+			return fmt.Errorf("check dependency cycle: %w", err)
+		}
+		if cycle {
+			r.logger.Warn("dependency would create a cycle",
+				zap.String("task", dep.TaskID), zap.String("blocker", dep.BlockerID))
+			return ErrDependencyCycle
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO task_dependencies (`+dependencyColumns+`) VALUES (?, ?, ?)`,
+			dep.TaskID, dep.BlockerID, dep.CreatedAt.UnixNano())
+		switch {
+		case isUniqueViolation(err):
+			return ErrDependencyAlreadyExists
+		case isForeignKeyViolation(err):
+			r.logger.Warn("task not found for dependency",
+				zap.String("task", dep.TaskID), zap.String("blocker", dep.BlockerID))
+			return ErrTaskNotFound
+		case err != nil:
+			return fmt.Errorf("insert dependency: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info("dependency added", zap.String("task", dep.TaskID), zap.String("blocker", dep.BlockerID))
+	return nil
+}
+
+// RemoveDependency deletes the edge between taskID and blockerID.
+func (r *SQLiteTaskRepository) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ?`, taskID, blockerID)
+	if err != nil {
+		return fmt.Errorf("delete dependency: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDependencyNotFound
+	}
+	r.logger.Info("dependency removed", zap.String("task", taskID), zap.String("blocker", blockerID))
+	return nil
+}
+
+// ListDependencies returns every edge ordered by task ID, then blocker ID.
+func (r *SQLiteTaskRepository) ListDependencies(ctx context.Context) ([]*model.Dependency, error) {
+	return r.queryDependencies(ctx, `SELECT `+dependencyColumns+` FROM task_dependencies ORDER BY task_id, blocker_id`)
+}
+
+// ListBlockers returns the edges to the tasks blocking taskID.
+func (r *SQLiteTaskRepository) ListBlockers(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	return r.queryDependencies(ctx,
+		`SELECT `+dependencyColumns+` FROM task_dependencies WHERE task_id = ? ORDER BY blocker_id`, taskID)
+}
+
+// ListDependents returns the edges from the tasks blocked by taskID.
+func (r *SQLiteTaskRepository) ListDependents(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	return r.queryDependencies(ctx,
+		`SELECT `+dependencyColumns+` FROM task_dependencies WHERE blocker_id = ? ORDER BY task_id`, taskID)
+}
+
+func (r *SQLiteTaskRepository) queryDependencies(ctx context.Context, query string, args ...any) ([]*model.Dependency, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	deps := []*model.Dependency{}
+	for rows.Next() {
+		var (
+			dep       model.Dependency
+			createdAt int64
+		)
+		if err := rows.Scan(&dep.TaskID, &dep.BlockerID, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan dependency: %w", err)
+		}
+		dep.CreatedAt = time.Unix(0, createdAt).UTC()
+		deps = append(deps, &dep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list dependencies: %w", err)
+	}
+	return deps, nil
+}
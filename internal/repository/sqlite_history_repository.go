@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const historyColumns = `task_id, revision, version, action, actor, at, changes, restored_revision`
+
+// AppendHistory stores entry as the next revision of its task. Numbering and
+// insert share a transaction, and the primary key rejects a concurrent
+// duplicate.
+func (r *SQLiteTaskRepository) AppendHistory(ctx context.Context, entry *model.HistoryEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("encode history changes: %w", err)
+	}
+	var revision int64
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(revision), 0) + 1 FROM task_history WHERE task_id = ?`, entry.TaskID,
+		).Scan(&revision); err != nil {
+			return fmt.Errorf("number history entry: %w", err)
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO task_history (`+historyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			entry.TaskID, revision, entry.Version, string(entry.Action), entry.Actor, entry.At.UnixNano(),
+			string(changes), entry.RestoredRevision)
+		if err != nil {
+			return fmt.Errorf("insert history entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	entry.Revision = revision
+	r.logger.Debug("history appended", zap.String("id", entry.TaskID), zap.Int64("revision", revision))
+	return nil
+}
+
+// ListHistory returns the entries of taskID ordered by revision.
+func (r *SQLiteTaskRepository) ListHistory(ctx context.Context, taskID string) ([]*model.HistoryEntry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+historyColumns+` FROM task_history WHERE task_id = ? ORDER BY revision`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*model.HistoryEntry{}
+	for rows.Next() {
+		var (
+			e       model.HistoryEntry
+			at      int64
+			changes string
+		)
+		if err := rows.Scan(&e.TaskID, &e.Revision, &e.Version, &e.Action, &e.Actor, &at, &changes,
+			&e.RestoredRevision); err != nil {
+			return nil, fmt.Errorf("scan history entry: %w", err)
+		}
+		e.At = time.Unix(0, at).UTC()
+		if err := json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+			return nil, fmt.Errorf("decode history changes of task %s: %w", taskID, err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list history: %w", err)
+	}
+	return entries, nil
+}
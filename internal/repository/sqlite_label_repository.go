@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const labelColumns = `name, color, created_at, updated_at`
+
+// CreateLabel inserts a new label.
+func (r *SQLiteTaskRepository) CreateLabel(ctx context.Context, label *model.Label) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO labels (`+labelColumns+`) VALUES (?, ?, ?, ?)`,
+		label.Name, label.Color, label.CreatedAt.UnixNano(), label.UpdatedAt.UnixNano(),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.logger.Warn("label already exists", zap.String("name", label.Name))
+			return ErrLabelAlreadyExists
+		}
+		return fmt.Errorf("insert label: %w", err)
+	}
+	r.logger.Info("label created", zap.String("name", label.Name))
+	return nil
+}
+
+// GetLabel retrieves a label by its name.
+func (r *SQLiteTaskRepository) GetLabel(ctx context.Context, name string) (*model.Label, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+labelColumns+` FROM labels WHERE name = ?`, name)
+	label, err := scanLabel(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warn("label not found", zap.String("name", name))
+		return nil, ErrLabelNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select label: %w", err)
+	}
+	return label, nil
+}
+
+// ListLabels returns all labels ordered by name.
+func (r *SQLiteTaskRepository) ListLabels(ctx context.Context) ([]*model.Label, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+labelColumns+` FROM labels ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []*model.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan label: %w", err)
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	return labels, nil
+}
+
+// UpdateLabel replaces the color and UpdatedAt of an existing label.
+func (r *SQLiteTaskRepository) UpdateLabel(ctx context.Context, label *model.Label) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE labels SET color = ?, updated_at = ? WHERE name = ?`,
+		label.Color, label.UpdatedAt.UnixNano(), label.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("update label: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		r.logger.Warn("label not found for update", zap.String("name", label.Name))
+		return ErrLabelNotFound
+	}
+	stored, err := r.GetLabel(ctx, label.Name)
+	if err != nil {
+		return err
+	}
+	*label = *stored
+	r.logger.Info("label updated", zap.String("name", label.Name))
+	return nil
+}
+
+// DeleteLabel removes a label. Its task links go with it through the foreign
+// key cascade; the tasks that carried it get a new version.
+func (r *SQLiteTaskRepository) DeleteLabel(ctx context.Context, name string) error {
+	var detached int64
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE tasks SET version = version + 1 WHERE id IN (SELECT task_id FROM task_labels WHERE label = ?)`, name)
+		if err != nil {
+			return fmt.Errorf("delete label: %w", err)
+		}
+		detached, _ = res.RowsAffected()
+		res, err = tx.ExecContext(ctx, `DELETE FROM labels WHERE name = ?`, name)
+		if err != nil {
+			return fmt.Errorf("delete label: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			r.logger.Warn("label not found for delete", zap.String("name", name))
+			return ErrLabelNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info("label deleted", zap.String("name", name), zap.Int64("detached", detached))
+	return nil
+}
+
+func scanLabel(s rowScanner) (*model.Label, error) {
+	var (
+		label                model.Label
+		createdAt, updatedAt int64
+	)
+	if err := s.Scan(&label.Name, &label.Color, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	label.CreatedAt = time.Unix(0, createdAt).UTC()
+	label.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	return &label, nil
+}
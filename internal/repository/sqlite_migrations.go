@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Migration is a single, versioned schema change for the SQLite backend.
+// Versions must be strictly increasing; a migration is never edited once shipped,
+// later changes are appended as new versions instead.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+}
+
+// sqliteMigrations is the ordered list of schema changes applied by MigrateSQLite.
+var sqliteMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create tasks table",
+		Up: `
+CREATE TABLE tasks (
+	id          TEXT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	completed   INTEGER NOT NULL DEFAULT 0,
+	created_at  INTEGER NOT NULL,
+	updated_at  INTEGER NOT NULL
+);
+CREATE INDEX idx_tasks_created_at ON tasks (created_at, id);
+`,
+	},
+	{
+		Version: 2,
+		Name:    "add task version",
+		Up:      `ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;`,
+	},
+	{
+		Version: 3,
+		Name:    "index task sort keys",
+		Up: `
+CREATE INDEX idx_tasks_updated_at ON tasks (updated_at, id);
+CREATE INDEX idx_tasks_title ON tasks (title, id);
+`,
+	},
+	{
+		Version: 4,
+		Name:    "add task priority and dates",
+		Up: `
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN start_at INTEGER;
+ALTER TABLE tasks ADD COLUMN due_at INTEGER;
+CREATE INDEX idx_tasks_priority ON tasks (priority, id);
+CREATE INDEX idx_tasks_start_at ON tasks (COALESCE(start_at, 9223372036854775807), id);
+CREATE INDEX idx_tasks_due_at ON tasks (COALESCE(due_at, 9223372036854775807), id);
+`,
+	},
+	{
+		Version: 5,
+		Name:    "create labels",
+		Up: `
+CREATE TABLE labels (
+	name       TEXT PRIMARY KEY,
+	color      TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE TABLE task_labels (
+	task_id TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	label   TEXT NOT NULL REFERENCES labels (name) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, label)
+);
+CREATE INDEX idx_task_labels_label ON task_labels (label, task_id);
+`,
+	},
+	{
+		Version: 6,
+		Name:    "add task parent",
+		Up: `
+ALTER TABLE tasks ADD COLUMN parent_id TEXT;
+CREATE INDEX idx_tasks_parent_id ON tasks (parent_id, created_at, id);
+`,
+	},
+	{
+		Version: 7,
+		Name:    "create task dependencies",
+		Up: `
+ALTER TABLE tasks ADD COLUMN estimate_minutes INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE task_dependencies (
+	task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	blocker_id TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	created_at INTEGER NOT NULL,
+	PRIMARY KEY (task_id, blocker_id)
+);
+CREATE INDEX idx_task_dependencies_blocker ON task_dependencies (blocker_id, task_id);
+`,
+	},
+	{
+		Version: 8,
+		Name:    "add task status",
+		Up: `
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT '';
+UPDATE tasks SET status = CASE completed WHEN 0 THEN 'todo' ELSE 'done' END;
+CREATE INDEX idx_tasks_status ON tasks (status, created_at, id);
+`,
+	},
+	{
+		Version: 9,
+		Name:    "add task recurrence",
+		Up:      `ALTER TABLE tasks ADD COLUMN recurrence TEXT;`,
+	},
+	{
+		Version: 10,
+		Name:    "create task history",
+		// No foreign key: the history of a task is kept after it is deleted.
+		Up: `
+CREATE TABLE task_history (
+	task_id           TEXT    NOT NULL,
+	revision          INTEGER NOT NULL,
+	version           INTEGER NOT NULL DEFAULT 0,
+	action            TEXT    NOT NULL,
+	actor             TEXT    NOT NULL DEFAULT '',
+	at                INTEGER NOT NULL,
+	changes           TEXT    NOT NULL DEFAULT '[]',
+	restored_revision INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (task_id, revision)
+);
+`,
+	},
+	{
+		Version: 11,
+		Name:    "add task trash",
+		Up: `
+ALTER TABLE tasks ADD COLUMN deleted_at INTEGER;
+CREATE INDEX idx_tasks_deleted_at ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
+`,
+	},
+	{
+		Version: 12,
+		Name:    "create task comments",
+		Up: `
+CREATE TABLE task_comments (
+	task_id    TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	id         TEXT    NOT NULL,
+	author     TEXT    NOT NULL DEFAULT '',
+	body       TEXT    NOT NULL,
+	edited     BOOLEAN NOT NULL DEFAULT 0,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (task_id, id)
+);
+CREATE INDEX idx_task_comments_created_at ON task_comments (task_id, created_at, id);
+`,
+	},
+	{
+		Version: 13,
+		Name:    "create task attachments",
+		Up: `
+CREATE TABLE task_attachments (
+	task_id      TEXT    NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	id           TEXT    NOT NULL,
+	filename     TEXT    NOT NULL,
+	content_type TEXT    NOT NULL,
+	size         INTEGER NOT NULL,
+	sha256       TEXT    NOT NULL,
+	uploader     TEXT    NOT NULL DEFAULT '',
+	created_at   INTEGER NOT NULL,
+	PRIMARY KEY (task_id, id)
+);
+CREATE INDEX idx_task_attachments_sha256 ON task_attachments (sha256);
+`,
+	},
+	{
+		Version: 14,
+		Name:    "create users and task assignees",
+		// Assignees are checked by the service, not by a foreign key, so
+		// tasks keep naming users who have been deleted.
+		Up: `
+CREATE TABLE users (
+	id         TEXT    PRIMARY KEY,
+	name       TEXT    NOT NULL,
+	email      TEXT    NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+ALTER TABLE tasks ADD COLUMN assignee TEXT;
+ALTER TABLE tasks ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_tasks_assignee ON tasks (assignee, created_at, id);
+`,
+	},
+	{
+		Version: 15,
+		Name:    "create projects and task projects",
+		// Settings are stored as JSON; they are only ever read whole.
+		Up: `
+CREATE TABLE projects (
+	id               TEXT    PRIMARY KEY,
+	name             TEXT    NOT NULL,
+	description      TEXT    NOT NULL DEFAULT '',
+	settings         TEXT    NOT NULL DEFAULT '{}',
+	last_task_number INTEGER NOT NULL DEFAULT 0,
+	created_at       INTEGER NOT NULL,
+	updated_at       INTEGER NOT NULL
+);
+ALTER TABLE tasks ADD COLUMN project_id TEXT;
+CREATE INDEX idx_tasks_project ON tasks (project_id, created_at, id);
+`,
+	},
+	{
+		Version: 16,
+		Name:    "add task ranks",
+		// Existing tasks rank by creation time, like model.TimeRank.
+		Up: `
+ALTER TABLE tasks ADD COLUMN rank TEXT NOT NULL DEFAULT '';
+UPDATE tasks SET rank = printf('%019d', max(created_at, 0));
+CREATE INDEX idx_tasks_rank ON tasks (status, rank, id);
+`,
+	},
+}
+
+// MigrateSQLite brings the database schema up to date by applying every migration
+// newer than the recorded schema version. Each migration runs in its own transaction.
+func MigrateSQLite(ctx context.Context, db *sql.DB, logger *zap.Logger) error {
+	return runMigrations(ctx, db, sqliteMigrations, logger)
+}
+
+func runMigrations(ctx context.Context, db *sql.DB, migrations []Migration, logger *zap.Logger) error {
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at INTEGER NOT NULL
+)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	current, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	last := 0
+	for _, m := range migrations {
+		if m.Version <= last {
+			return fmt.Errorf("migration %d (%s) is out of order", m.Version, m.Name)
+		}
+		last = m.Version
+		if m.Version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+		logger.Info("applied migration", zap.Int("version", m.Version), zap.String("name", m.Name))
+	}
+	return nil
+}
+
+// schemaVersion returns the highest applied migration version, or 0 for a fresh database.
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %d: begin: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, time.Now().UTC().UnixNano(),
+	); err != nil {
+		return fmt.Errorf("migration %d: record version: %w", m.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %d: commit: %w", m.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func openRawSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", sqliteDSN(":memory:"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateSQLite_IsIdempotent(t *testing.T) {
+	db := openRawSQLite(t)
+	ctx := context.Background()
+
+	require.NoError(t, MigrateSQLite(ctx, db, zap.NewNop()))
+	require.NoError(t, MigrateSQLite(ctx, db, zap.NewNop()))
+
+	version, err := schemaVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, sqliteMigrations[len(sqliteMigrations)-1].Version, version)
+}
+
+func TestRunMigrations_AppliesOnlyNewVersions(t *testing.T) {
+	db := openRawSQLite(t)
+	ctx := context.Background()
+	first := []Migration{{Version: 1, Name: "one", Up: `CREATE TABLE a (x INTEGER)`}}
+	require.NoError(t, runMigrations(ctx, db, first, zap.NewNop()))
+
+	// Re-running version 1 would fail because table a already exists.
+	second := append(first, Migration{Version: 2, Name: "two", Up: `ALTER TABLE a ADD COLUMN y INTEGER`})
+	require.NoError(t, runMigrations(ctx, db, second, zap.NewNop()))
+
+	_, err := db.ExecContext(ctx, `INSERT INTO a (x, y) VALUES (1, 2)`)
+	assert.NoError(t, err)
+}
+
+func TestRunMigrations_FailedMigrationIsRolledBack(t *testing.T) {
+	db := openRawSQLite(t)
+	ctx := context.Background()
+	migrations := []Migration{{Version: 1, Name: "broken", Up: `CREATE TABLE b (x INTEGER); NOT VALID SQL`}}
+
+	assert.Error(t, runMigrations(ctx, db, migrations, zap.NewNop()))
+	version, err := schemaVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+}
+
+func TestRunMigrations_RejectsOutOfOrder(t *testing.T) {
+	db := openRawSQLite(t)
+	migrations := []Migration{
+		{Version: 2, Name: "two", Up: `SELECT 1`},
+		{Version: 1, Name: "one", Up: `SELECT 1`},
+	}
+	assert.ErrorContains(t, runMigrations(context.Background(), db, migrations, zap.NewNop()), "out of order")
+}
+
+func TestMigrateSQLite_BackfillsTaskStatus(t *testing.T) {
+	db := openRawSQLite(t)
+	ctx := context.Background()
+	require.NoError(t, runMigrations(ctx, db, sqliteMigrations[:7], zap.NewNop()))
+	_, err := db.ExecContext(ctx, `INSERT INTO tasks (id, title, completed, created_at, updated_at) VALUES ('open', 'Open', 0, 1, 1), ('closed', 'Closed', 1, 1, 1)`)
+	require.NoError(t, err)
+
+	require.NoError(t, MigrateSQLite(ctx, db, zap.NewNop()))
+	for id, want := range map[string]string{"open": "todo", "closed": "done"} {
+		var status string
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT status FROM tasks WHERE id = ?`, id).Scan(&status))
+		assert.Equal(t, want, status, id)
+	}
+}
+
+func TestMigrateSQLite_BackfillsTaskRank(t *testing.T) {
+	db := openRawSQLite(t)
+	ctx := context.Background()
+	require.NoError(t, runMigrations(ctx, db, sqliteMigrations[:15], zap.NewNop()))
+	created := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	_, err := db.ExecContext(ctx, `INSERT INTO tasks (id, title, completed, created_at, updated_at) VALUES ('old', 'Old', 0, ?, ?)`,
+		created.UnixNano(), created.UnixNano())
+	require.NoError(t, err)
+
+	require.NoError(t, MigrateSQLite(ctx, db, zap.NewNop()))
+	var rank string
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT rank FROM tasks WHERE id = 'old'`).Scan(&rank))
+	assert.Equal(t, model.TimeRank(created), rank)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const projectColumns = `id, name, description, settings, last_task_number, created_at, updated_at`
+
+// CreateProject inserts a new project.
+func (r *SQLiteTaskRepository) CreateProject(ctx context.Context, project *model.Project) error {
+	settings, err := json.Marshal(project.Settings)
+	if err != nil {
+		return fmt.Errorf("encode project settings: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		project.ID, project.Name, project.Description, string(settings), project.LastTaskNumber,
+		project.CreatedAt.UnixNano(), project.UpdatedAt.UnixNano(),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.logger.Warn("project already exists", zap.String("id", project.ID))
+			return ErrProjectAlreadyExists
+		}
+		return fmt.Errorf("insert project: %w", err)
+	}
+	r.logger.Info("project created", zap.String("id", project.ID))
+	return nil
+}
+
+// GetProject retrieves a project by ID.
+func (r *SQLiteTaskRepository) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = ?`, id)
+	project, err := scanProject(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warn("project not found", zap.String("id", id))
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select project: %w", err)
+	}
+	return project, nil
+}
+
+// ListProjects returns all projects ordered by ID.
+func (r *SQLiteTaskRepository) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*model.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	return projects, nil
+}
+
+// UpdateProject replaces the name, description, settings and UpdatedAt of an
+// existing project.
+func (r *SQLiteTaskRepository) UpdateProject(ctx context.Context, project *model.Project) error {
+	settings, err := json.Marshal(project.Settings)
+	if err != nil {
+		return fmt.Errorf("encode project settings: %w", err)
+	}
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE projects SET name = ?, description = ?, settings = ?, updated_at = ? WHERE id = ?`,
+		project.Name, project.Description, string(settings), project.UpdatedAt.UnixNano(), project.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		r.logger.Warn("project not found for update", zap.String("id", project.ID))
+		return ErrProjectNotFound
+	}
+	stored, err := r.GetProject(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	*project = *stored
+	r.logger.Info("project updated", zap.String("id", project.ID))
+	return nil
+}
+
+// DeleteProject removes a project that has no tasks.
+func (r *SQLiteTaskRepository) DeleteProject(ctx context.Context, id string) error {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var used bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE project_id = ?)`, id).Scan(&used); err != nil {
+			return fmt.Errorf("check project tasks: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("delete project: %w", err)
+		}
+		switch n, _ := res.RowsAffected(); {
+		case n == 0:
+			r.logger.Warn("project not found for delete", zap.String("id", id))
+			return ErrProjectNotFound
+		case used:
+			r.logger.Warn("project has tasks", zap.String("id", id))
+			return ErrProjectNotEmpty
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.logger.Info("project deleted", zap.String("id", id))
+	return nil
+}
+
+// NextTaskNumber increments and returns the LastTaskNumber of a project.
+func (r *SQLiteTaskRepository) NextTaskNumber(ctx context.Context, id string) (int64, error) {
+	var n int64
+	err := r.db.QueryRowContext(ctx,
+		`UPDATE projects SET last_task_number = last_task_number + 1 WHERE id = ? RETURNING last_task_number`, id,
+	).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warn("project not found", zap.String("id", id))
+		return 0, ErrProjectNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("number project task: %w", err)
+	}
+	return n, nil
+}
+
+func scanProject(s rowScanner) (*model.Project, error) {
+	var (
+		project              model.Project
+		settings             string
+		createdAt, updatedAt int64
+	)
+	if err := s.Scan(&project.ID, &project.Name, &project.Description, &settings, &project.LastTaskNumber,
+		&createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(settings), &project.Settings); err != nil {
+		return nil, fmt.Errorf("decode settings of project %s: %w", project.ID, err)
+	}
+	project.CreatedAt = time.Unix(0, createdAt).UTC()
+	project.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	return &project, nil
+}
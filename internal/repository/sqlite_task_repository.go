@@ -0,0 +1,544 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteTaskRepository is a TaskRepository backed by a SQLite database.
+// It uses the pure-Go modernc.org/sqlite driver, so the binary builds without cgo.
+type SQLiteTaskRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// OpenSQLiteTaskRepository opens (or creates) the SQLite database at path, applies
+// pending schema migrations and returns a repository using it. Use ":memory:" for a
+// throwaway database.
+func OpenSQLiteTaskRepository(ctx context.Context, path string, logger *zap.Logger) (*SQLiteTaskRepository, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	// SQLite serialises writers anyway; a single connection also keeps ":memory:"
+	// databases from being split across connections.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect sqlite database: %w", err)
+	}
+	if err := MigrateSQLite(ctx, db, logger); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return NewSQLiteTaskRepository(db, logger), nil
+}
+
+// NewSQLiteTaskRepository creates a repository on an already migrated database.
+func NewSQLiteTaskRepository(db *sql.DB, logger *zap.Logger) *SQLiteTaskRepository {
+	return &SQLiteTaskRepository{db: db, logger: logger}
+}
+
+// Close releases the underlying database.
+func (r *SQLiteTaskRepository) Close() error {
+	return r.db.Close()
+}
+
+// sqliteDSN builds a connection string that applies per-connection pragmas.
+func sqliteDSN(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "foreign_keys(1)")
+	if path != ":memory:" {
+		q.Add("_pragma", "journal_mode(WAL)")
+	}
+	return "file:" + path + "?" + q.Encode()
+}
+
+const taskColumns = `id, title, description, completed, created_at, updated_at, version, priority, start_at, due_at, parent_id, estimate_minutes, status, recurrence, deleted_at, assignee, created_by, project_id, rank`
+
+// taskSelectColumns adds the task's labels, joined by labelSeparator, to taskColumns.
+const taskSelectColumns = taskColumns +
+	`, (SELECT group_concat(label, char(31)) FROM task_labels WHERE task_id = tasks.id)`
+
+// labelSeparator is char(31), the ASCII unit separator, which label names cannot contain.
+const labelSeparator = "\x1f"
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// CreateTask inserts a new task and its labels.
+func (r *SQLiteTaskRepository) CreateTask(ctx context.Context, task *model.Task) error {
+	if err := r.inTx(ctx, func(tx *sql.Tx) error { return r.insertTask(ctx, tx, task) }); err != nil {
+		return err
+	}
+	task.Version = 1
+	r.logger.Info("task created", zap.String("id", task.ID))
+	return nil
+}
+
+// insertTask inserts task and its labels at version 1.
+func (r *SQLiteTaskRepository) insertTask(ctx context.Context, tx *sql.Tx, task *model.Task) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO tasks (`+taskColumns+`) VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		task.ID, task.Title, task.Description, task.Completed,
+		task.CreatedAt.UnixNano(), task.UpdatedAt.UnixNano(),
+		task.Priority.Rank(), nullableTime(task.StartAt), nullableTime(task.DueAt), nullableString(task.ParentID),
+		task.EstimateMinutes, task.Status, recurrenceColumn(task.Recurrence), nullableTime(task.DeletedAt),
+		nullableString(task.Assignee), task.CreatedBy, nullableString(task.ProjectID), task.RankKey(),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.logger.Warn("task already exists", zap.String("id", task.ID))
+			return ErrTaskAlreadyExists
+		}
+		r.logger.Error("failed to insert task", zap.String("id", task.ID), zap.Error(err))
+		return fmt.Errorf("insert task: %w", err)
+	}
+	return r.insertTaskLabels(ctx, tx, task)
+}
+
+// GetTask retrieves a live task by its ID.
+func (r *SQLiteTaskRepository) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	return r.getTask(ctx, id, false)
+}
+
+// GetTrashedTask retrieves a task in the trash by its ID.
+func (r *SQLiteTaskRepository) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
+	return r.getTask(ctx, id, true)
+}
+
+func (r *SQLiteTaskRepository) getTask(ctx context.Context, id string, trashed bool) (*model.Task, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+taskSelectColumns+` FROM tasks WHERE id = ? AND `+trashCondition(trashed), id)
+	task, err := scanTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warn("task not found", zap.String("id", id), zap.Bool("trashed", trashed))
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select task: %w", err)
+	}
+	r.logger.Debug("task retrieved", zap.String("id", id))
+	return task, nil
+}
+
+// ListTasks returns all live tasks.
+func (r *SQLiteTaskRepository) ListTasks(ctx context.Context) ([]*model.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+taskSelectColumns+` FROM tasks WHERE deleted_at IS NULL ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*model.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	r.logger.Debug("listed tasks", zap.Int("count", len(tasks)))
+	return tasks, nil
+}
+
+// CountTasks returns the number of live tasks.
+func (r *SQLiteTaskRepository) CountTasks(ctx context.Context) (int, error) {
+	var n int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE deleted_at IS NULL`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count tasks: %w", err)
+	}
+	return n, nil
+}
+
+// sortColumns maps sort fields to the expressions they order by. Missing dates
+// sort as model.NoDate, matching the indexes created by migration 4.
+var sortColumns = map[model.TaskSortField]string{
+	model.SortByCreatedAt: "created_at",
+	model.SortByUpdatedAt: "updated_at",
+	model.SortByTitle:     "title",
+	model.SortByPriority:  "priority",
+	model.SortByStartAt:   "COALESCE(start_at, 9223372036854775807)",
+	model.SortByDueAt:     "COALESCE(due_at, 9223372036854775807)",
+	model.SortByRank:      "rank",
+}
+
+// QueryTasks returns a page of tasks matching q, using keyset pagination on the
+// sort column and ID.
+func (r *SQLiteTaskRepository) QueryTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	f := q.Filter
+	var (
+		where = []string{trashCondition(f.Trashed)}
+		args  []any
+	)
+	if f.Completed != nil {
+		where = append(where, "completed = ?")
+		args = append(args, *f.Completed)
+	}
+	for _, bound := range []struct {
+		cond string
+		t    time.Time
+	}{
+		{"created_at >= ?", f.CreatedAfter},
+		{"created_at < ?", f.CreatedBefore},
+		{"updated_at >= ?", f.UpdatedAfter},
+		{"updated_at < ?", f.UpdatedBefore},
+		{"start_at >= ?", f.StartAfter},
+		{"start_at < ?", f.StartBefore},
+		{"due_at >= ?", f.DueAfter},
+		{"due_at < ?", f.DueBefore},
+		{"deleted_at >= ?", f.DeletedAfter},
+		{"deleted_at < ?", f.DeletedBefore},
+	} {
+		if !bound.t.IsZero() {
+			where = append(where, bound.cond)
+			args = append(args, bound.t.UnixNano())
+		}
+	}
+	if len(f.Statuses) > 0 {
+		where = append(where, "status IN (?"+strings.Repeat(", ?", len(f.Statuses)-1)+")")
+		for _, status := range f.Statuses {
+			args = append(args, status)
+		}
+	}
+	if len(f.Priorities) > 0 {
+		where = append(where, "priority IN (?"+strings.Repeat(", ?", len(f.Priorities)-1)+")")
+		for _, p := range f.Priorities {
+			args = append(args, p.Rank())
+		}
+	}
+	if f.TitleContains != "" {
+		where = append(where, "instr(lower(title), lower(?)) > 0")
+		args = append(args, f.TitleContains)
+	}
+	if f.ParentID != nil {
+		if *f.ParentID == "" {
+			where = append(where, "parent_id IS NULL")
+		} else {
+			where = append(where, "parent_id = ?")
+			args = append(args, *f.ParentID)
+		}
+	}
+	if f.Assignee != nil {
+		if *f.Assignee == "" {
+			where = append(where, "assignee IS NULL")
+		} else {
+			where = append(where, "assignee = ?")
+			args = append(args, *f.Assignee)
+		}
+	}
+	if f.ProjectID != nil {
+		if *f.ProjectID == "" {
+			where = append(where, "project_id IS NULL")
+		} else {
+			where = append(where, "project_id = ?")
+			args = append(args, *f.ProjectID)
+		}
+	}
+	if labels := model.NormalizeLabels(f.Labels); len(labels) > 0 {
+		// Served by the task_labels primary key and its (label, task_id) index.
+		cond := "id IN (SELECT task_id FROM task_labels WHERE label IN (?" + strings.Repeat(", ?", len(labels)-1) + ")"
+		for _, name := range labels {
+			args = append(args, name)
+		}
+		if f.LabelMatch == model.LabelMatchAll {
+			cond += " GROUP BY task_id HAVING COUNT(*) = ?"
+			args = append(args, len(labels))
+		}
+		where = append(where, cond+")")
+	}
+
+	column := sortColumns[q.SortField()]
+	cmp, dir := ">", "ASC"
+	if q.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	if c := q.After; c != nil {
+		var key any = c.Int
+		if q.SortField().Textual() {
+			key = c.Str
+		}
+		where = append(where, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, cmp))
+		args = append(args, key, key, c.ID)
+	}
+
+	query := `SELECT ` + taskSelectColumns + ` FROM tasks WHERE ` + strings.Join(where, " AND ")
+	query += fmt.Sprintf(` ORDER BY %[1]s %[2]s, id %[2]s`, column, dir)
+	if q.Limit > 0 {
+		// Fetch one extra row to learn whether there is a next page.
+		query += ` LIMIT ?`
+		args = append(args, q.Limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	defer rows.Close()
+	page := &model.TaskPage{Tasks: []*model.Task{}}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		page.Tasks = append(page.Tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query tasks: %w", err)
+	}
+	if q.Limit > 0 && len(page.Tasks) > q.Limit {
+		page.Tasks = page.Tasks[:q.Limit]
+		page.Next = q.CursorAt(page.Tasks[q.Limit-1])
+	}
+	r.logger.Debug("queried tasks", zap.Int("count", len(page.Tasks)))
+	return page, nil
+}
+
+// UpdateTask overwrites an existing task and its labels if its version is current.
+func (r *SQLiteTaskRepository) UpdateTask(ctx context.Context, task *model.Task) error {
+	if err := r.inTx(ctx, func(tx *sql.Tx) error { return r.updateTask(ctx, tx, task) }); err != nil {
+		return err
+	}
+	task.Version++
+	r.logger.Info("task updated", zap.String("id", task.ID))
+	return nil
+}
+
+// updateTask overwrites task and its labels if its version is current, leaving
+// task.Version to the caller.
+func (r *SQLiteTaskRepository) updateTask(ctx context.Context, tx *sql.Tx, task *model.Task) error {
+	res, err := tx.ExecContext(ctx,
+		`UPDATE tasks SET title = ?, description = ?, completed = ?, created_at = ?, updated_at = ?, version = version + 1,
+		 priority = ?, start_at = ?, due_at = ?, parent_id = ?, estimate_minutes = ?, status = ?, recurrence = ?,
+		 deleted_at = ?, assignee = ?, created_by = ?, project_id = ?, rank = ? WHERE id = ? AND version = ?`,
+		task.Title, task.Description, task.Completed,
+		task.CreatedAt.UnixNano(), task.UpdatedAt.UnixNano(),
+		task.Priority.Rank(), nullableTime(task.StartAt), nullableTime(task.DueAt), nullableString(task.ParentID),
+		task.EstimateMinutes, task.Status, recurrenceColumn(task.Recurrence), nullableTime(task.DeletedAt),
+		nullableString(task.Assignee), task.CreatedBy, nullableString(task.ProjectID), task.RankKey(), task.ID, task.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("update task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return r.writeMissed(ctx, tx, task.ID, task.Version, "update")
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_labels WHERE task_id = ?`, task.ID); err != nil {
+		return fmt.Errorf("update task labels: %w", err)
+	}
+	return r.insertTaskLabels(ctx, tx, task)
+}
+
+// WriteTasks creates, updates and deletes the tasks in batch in one
+// transaction.
+func (r *SQLiteTaskRepository) WriteTasks(ctx context.Context, batch TaskBatch) error {
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, task := range batch.Create {
+			if err := r.insertTask(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+		for _, task := range batch.Update {
+			if err := r.updateTask(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+		for _, task := range batch.Delete {
+			if err := r.deleteTask(ctx, tx, task.ID, task.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, task := range batch.Create {
+		task.Version = 1
+	}
+	for _, task := range batch.Update {
+		task.Version++
+	}
+	r.logger.Info("tasks written", zap.Int("created", len(batch.Create)), zap.Int("updated", len(batch.Update)),
+		zap.Int("deleted", len(batch.Delete)))
+	return nil
+}
+
+// DeleteTask removes a task by its ID if its version matches.
+func (r *SQLiteTaskRepository) DeleteTask(ctx context.Context, id string, version int64) error {
+	if err := r.deleteTask(ctx, r.db, id, version); err != nil {
+		return err
+	}
+	r.logger.Info("task deleted", zap.String("id", id))
+	return nil
+}
+
+// deleteTask removes task id if its version matches.
+func (r *SQLiteTaskRepository) deleteTask(ctx context.Context, db dbtx, id string, version int64) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ? AND (? = 0 OR version = ?)`, id, version, version)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return r.writeMissed(ctx, db, id, version, "delete")
+	}
+	return nil
+}
+
+// writeMissed explains why a versioned write matched no rows: either the task
+// does not exist or its version moved on.
+func (r *SQLiteTaskRepository) writeMissed(ctx context.Context, db dbtx, id string, expected int64, op string) error {
+	var actual int64
+	err := db.QueryRowContext(ctx, `SELECT version FROM tasks WHERE id = ?`, id).Scan(&actual)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warn("task not found for "+op, zap.String("id", id))
+		return ErrTaskNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s task: %w", op, err)
+	}
+	r.logger.Warn("stale task version for "+op, zap.String("id", id),
+		zap.Int64("expected", expected), zap.Int64("actual", actual))
+	return &VersionConflictError{ID: id, Expected: expected, Actual: actual}
+}
+
+// insertTaskLabels links task to its labels, failing with UnknownLabel for a
+// label that does not exist.
+func (r *SQLiteTaskRepository) insertTaskLabels(ctx context.Context, tx *sql.Tx, task *model.Task) error {
+	for _, name := range task.Labels {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO task_labels (task_id, label) VALUES (?, ?)`, task.ID, name); err != nil {
+			if isForeignKeyViolation(err) {
+				r.logger.Warn("unknown label", zap.String("id", task.ID), zap.String("label", name))
+				return UnknownLabel(name)
+			}
+			return fmt.Errorf("insert task label: %w", err)
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, committing if it returns nil.
+func (r *SQLiteTaskRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(s rowScanner) (*model.Task, error) {
+	var (
+		task                 model.Task
+		createdAt, updatedAt int64
+		priority             int
+		startAt, dueAt       sql.NullInt64
+		parentID, labels     sql.NullString
+		assignee, projectID  sql.NullString
+		deletedAt            sql.NullInt64
+		recurrence           sql.NullString
+	)
+	if err := s.Scan(&task.ID, &task.Title, &task.Description, &task.Completed, &createdAt, &updatedAt, &task.Version,
+		&priority, &startAt, &dueAt, &parentID, &task.EstimateMinutes, &task.Status, &recurrence, &deletedAt, &assignee, &task.CreatedBy, &projectID, &task.Rank, &labels); err != nil {
+		return nil, err
+	}
+	task.ParentID = parentID.String
+	task.Assignee = assignee.String
+	task.ProjectID = projectID.String
+	if recurrence.Valid {
+		if err := json.Unmarshal([]byte(recurrence.String), &task.Recurrence); err != nil {
+			return nil, fmt.Errorf("decode recurrence of task %s: %w", task.ID, err)
+		}
+	}
+	if labels.Valid {
+		task.Labels = model.NormalizeLabels(strings.Split(labels.String, labelSeparator))
+	}
+	task.CreatedAt = time.Unix(0, createdAt).UTC()
+	task.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	task.Priority = model.PriorityOfRank(priority)
+	task.StartAt = timeFromNullable(startAt)
+	task.DueAt = timeFromNullable(dueAt)
+	task.DeletedAt = timeFromNullable(deletedAt)
+	return &task, nil
+}
+
+// trashCondition selects the tasks in the trash, or the live ones.
+func trashCondition(trashed bool) string {
+	if trashed {
+		return "deleted_at IS NOT NULL"
+	}
+	return "deleted_at IS NULL"
+}
+
+// nullableTime converts an optional time to its column value.
+func nullableTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+// recurrenceColumn stores a recurrence as JSON, or NULL for none.
+func recurrenceColumn(r *model.Recurrence) sql.NullString {
+	if r == nil {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(r)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// nullableString stores the empty string as NULL.
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func timeFromNullable(n sql.NullInt64) *time.Time {
+	if !n.Valid {
+		return nil
+	}
+	t := time.Unix(0, n.Int64).UTC()
+	return &t
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
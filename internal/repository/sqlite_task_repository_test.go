@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestSQLiteRepo(t *testing.T) *SQLiteTaskRepository {
+	t.Helper()
+	repo, err := OpenSQLiteTaskRepository(context.Background(), ":memory:", zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestSQLiteTaskRepository_CRUD(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+	ctx := context.Background()
+	task := newTestTask("Test Task")
+
+	// Create
+	require.NoError(t, repo.CreateTask(ctx, task))
+	assert.ErrorIs(t, repo.CreateTask(ctx, task), ErrTaskAlreadyExists)
+
+	// Get
+	got, err := repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Title, got.Title)
+	assert.True(t, task.CreatedAt.Equal(got.CreatedAt))
+
+	// List
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	// Update
+	task.Title = "Updated Title"
+	task.Completed = true
+	require.NoError(t, repo.UpdateTask(ctx, task))
+	got, err = repo.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated Title", got.Title)
+	assert.True(t, got.Completed)
+
+	// Delete
+	require.NoError(t, repo.DeleteTask(ctx, task.ID, AnyVersion))
+	_, err = repo.GetTask(ctx, task.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, task.ID, AnyVersion), ErrTaskNotFound)
+	assert.ErrorIs(t, repo.UpdateTask(ctx, task), ErrTaskNotFound)
+}
+
+func TestSQLiteTaskRepository_ListOrderedByCreatedAt(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+	ctx := context.Background()
+	base := time.Now().UTC()
+	for i, title := range []string{"c", "a", "b"} {
+		task := newTestTask(title)
+		task.CreatedAt = base.Add(time.Duration(i) * time.Second)
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, []string{"c", "a", "b"}, []string{tasks[0].Title, tasks[1].Title, tasks[2].Title})
+}
+
+func TestSQLiteTaskRepository_PersistsAcrossReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.db")
+
+	repo, err := OpenSQLiteTaskRepository(ctx, path, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateTask(ctx, newTestTask("Durable")))
+	require.NoError(t, repo.Close())
+
+	repo, err = OpenSQLiteTaskRepository(ctx, path, zap.NewNop())
+	require.NoError(t, err)
+	defer repo.Close()
+	got, err := repo.GetTask(ctx, "task-Durable")
+	require.NoError(t, err)
+	assert.Equal(t, "Durable", got.Title)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"taskmanager/internal/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const userColumns = `id, name, email, created_at, updated_at`
+
+// CreateUser inserts a new user.
+func (r *SQLiteTaskRepository) CreateUser(ctx context.Context, user *model.User) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		user.ID, user.Name, user.Email, user.CreatedAt.UnixNano(), user.UpdatedAt.UnixNano(),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.logger.Warn("user already exists", zap.String("id", user.ID))
+			return ErrUserAlreadyExists
+		}
+		return fmt.Errorf("insert user: %w", err)
+	}
+	r.logger.Info("user created", zap.String("id", user.ID))
+	return nil
+}
+
+// GetUser retrieves a user by ID.
+func (r *SQLiteTaskRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Warn("user not found", zap.String("id", id))
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select user: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns all users ordered by ID.
+func (r *SQLiteTaskRepository) ListUsers(ctx context.Context) ([]*model.User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	return users, nil
+}
+
+// UpdateUser replaces the name, email and UpdatedAt of an existing user.
+func (r *SQLiteTaskRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users SET name = ?, email = ?, updated_at = ? WHERE id = ?`,
+		user.Name, user.Email, user.UpdatedAt.UnixNano(), user.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		r.logger.Warn("user not found for update", zap.String("id", user.ID))
+		return ErrUserNotFound
+	}
+	stored, err := r.GetUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	*user = *stored
+	r.logger.Info("user updated", zap.String("id", user.ID))
+	return nil
+}
+
+// DeleteUser removes a user.
+func (r *SQLiteTaskRepository) DeleteUser(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		r.logger.Warn("user not found for delete", zap.String("id", id))
+		return ErrUserNotFound
+	}
+	r.logger.Info("user deleted", zap.String("id", id))
+	return nil
+}
+
+func scanUser(s rowScanner) (*model.User, error) {
+	var (
+		user                 model.User
+		createdAt, updatedAt int64
+	)
+	if err := s.Scan(&user.ID, &user.Name, &user.Email, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	user.CreatedAt = time.Unix(0, createdAt).UTC()
+	user.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	return &user, nil
+}
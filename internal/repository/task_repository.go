@@ -3,29 +3,109 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"taskmanager/internal/apperror"
 	"taskmanager/internal/model"
 )
 
-// TaskReader defines read operations for tasks.
+// ErrTaskNotFound is returned when a task does not exist in the repository.
+var ErrTaskNotFound = apperror.NotFound("task not found")
+
+// ErrTaskAlreadyExists is returned when creating a task whose ID is already taken.
+var ErrTaskAlreadyExists = apperror.AlreadyExists("task already exists")
+
+// ErrVersionConflict is matched (via errors.Is) by every VersionConflictError.
+var ErrVersionConflict = apperror.Conflict("version conflict")
+
+// AnyVersion disables the version check of DeleteTask.
+const AnyVersion int64 = 0
+
+// VersionConflictError is returned when a write is based on a stale task version.
+type VersionConflictError struct {
+	ID       string
+	Expected int64
+	Actual   int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("task %s: expected version %d, current version is %d", e.ID, e.Expected, e.Actual)
+}
+
+// Is reports whether target is ErrVersionConflict.
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ErrorKind classifies the error as a conflict.
+func (e *VersionConflictError) ErrorKind() apperror.Kind {
+	return apperror.KindConflict
+}
+
+// TaskReader defines read operations for tasks. Tasks in the trash, those
+// with a DeletedAt, are only seen by GetTrashedTask.
 type TaskReader interface {
-	// GetTask retrieves a task by its ID.
+	// GetTask retrieves a live task by its ID.
 	GetTask(ctx context.Context, id string) (*model.Task, error)
-	// ListTasks returns all tasks.
+	// GetTrashedTask retrieves a task in the trash by its ID.
+	GetTrashedTask(ctx context.Context, id string) (*model.Task, error)
+	// ListTasks returns all live tasks ordered by CreatedAt ascending, ties broken by ID.
 	ListTasks(ctx context.Context) ([]*model.Task, error)
+	// CountTasks returns the number of live tasks.
+	CountTasks(ctx context.Context) (int, error)
+}
+
+// TaskQuerier defines filtered, sorted and paginated task listing.
+type TaskQuerier interface {
+	// QueryTasks returns the page of tasks selected by q. Filtering, ordering and
+	// paging happen in storage; q has been validated by the caller.
+	QueryTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error)
 }
 
 // TaskWriter defines write operations for tasks.
 type TaskWriter interface {
-	// CreateTask adds a new task.
+	// CreateTask adds a new task and sets task.Version to 1.
 	CreateTask(ctx context.Context, task *model.Task) error
-	// UpdateTask updates an existing task.
+	// UpdateTask replaces an existing task if task.Version matches the stored
+	// version, then sets task.Version to the new, incremented version. Setting
+	// or clearing DeletedAt moves the task into or out of the trash.
 	UpdateTask(ctx context.Context, task *model.Task) error
-	// DeleteTask removes a task by its ID.
-	DeleteTask(ctx context.Context, id string) error
+	// DeleteTask removes a task, live or trashed, by its ID if its stored
+	// version equals version, or unconditionally when version is AnyVersion.
+	DeleteTask(ctx context.Context, id string, version int64) error
+	// WriteTasks applies batch atomically: either every write succeeds, with
+	// the same checks and version updates as CreateTask, UpdateTask and
+	// DeleteTask, or none is applied.
+	WriteTasks(ctx context.Context, batch TaskBatch) error
+}
+
+// TaskBatch is a group of task writes applied together by WriteTasks.
+type TaskBatch struct {
+	// Create holds new tasks, written like by CreateTask.
+	Create []*model.Task
+	// Update holds changed tasks, written like by UpdateTask.
+	Update []*model.Task
+	// Delete holds tasks to remove like by DeleteTask, with their Version as
+	// the expected version.
+	Delete []*model.Task
 }
 
-// TaskRepository combines read and write operations for tasks.
+// TaskRepository combines read and write operations for tasks, the labels
+// they reference, the dependencies between them, their history, comments and
+// attachments, the users they are assigned to and the projects they belong to.
+//
+// Implementations must be safe for concurrent use, return ErrTaskNotFound,
+// ErrTaskAlreadyExists and a *VersionConflictError for the corresponding
+// conditions, reject task writes referencing unknown labels with UnknownLabel,
+// and never share task pointers with callers. The repotest package verifies this contract.
 type TaskRepository interface {
 	TaskReader
+	TaskQuerier
 	TaskWriter
+	LabelRepository
+	DependencyRepository
+	HistoryRepository
+	CommentRepository
+	AttachmentRepository
+	UserRepository
+	ProjectRepository
 }
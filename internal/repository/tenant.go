@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+)
+
+// ErrNoTenant is returned for operations whose context carries no tenant.
+var ErrNoTenant = apperror.Forbidden("request has no tenant")
+
+// ErrUnknownTenant is returned for operations in a tenant that is not hosted.
+var ErrUnknownTenant = apperror.Forbidden("unknown tenant")
+
+// TenantRepository is a TaskRepository that keeps the data of every tenant in
+// a repository of its own and runs each operation against the repository of
+// the tenant its context carries, see identity.WithTenant. Tenants share no
+// storage, so no operation can see or touch another tenant's tasks, labels,
+// users or projects; operations without a known tenant fail.
+type TenantRepository struct {
+	tenants map[string]TaskRepository
+}
+
+// NewTenantRepository returns a repository hosting the tenants given, mapped
+// to their storage.
+func NewTenantRepository(tenants map[string]TaskRepository) *TenantRepository {
+	return &TenantRepository{tenants: maps.Clone(tenants)}
+}
+
+// Tenants returns the hosted tenants in ascending order.
+func (r *TenantRepository) Tenants() []string {
+	return slices.Sorted(maps.Keys(r.tenants))
+}
+
+// repo returns the repository of the tenant of ctx.
+func (r *TenantRepository) repo(ctx context.Context) (TaskRepository, error) {
+	tenant := identity.Tenant(ctx)
+	if tenant == "" {
+		return nil, ErrNoTenant
+	}
+	repo, ok := r.tenants[tenant]
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	return repo, nil
+}
+
+// The TaskRepository methods run in the repository of the tenant of ctx.
+
+func (r *TenantRepository) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTask(ctx, id)
+}
+
+func (r *TenantRepository) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTrashedTask(ctx, id)
+}
+
+func (r *TenantRepository) ListTasks(ctx context.Context) ([]*model.Task, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListTasks(ctx)
+}
+
+func (r *TenantRepository) CountTasks(ctx context.Context) (int, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return repo.CountTasks(ctx)
+}
+
+func (r *TenantRepository) QueryTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.QueryTasks(ctx, q)
+}
+
+func (r *TenantRepository) CreateTask(ctx context.Context, task *model.Task) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.CreateTask(ctx, task)
+}
+
+func (r *TenantRepository) UpdateTask(ctx context.Context, task *model.Task) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateTask(ctx, task)
+}
+
+func (r *TenantRepository) DeleteTask(ctx context.Context, id string, version int64) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteTask(ctx, id, version)
+}
+
+func (r *TenantRepository) WriteTasks(ctx context.Context, batch TaskBatch) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.WriteTasks(ctx, batch)
+}
+
+func (r *TenantRepository) CreateLabel(ctx context.Context, label *model.Label) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.CreateLabel(ctx, label)
+}
+
+func (r *TenantRepository) GetLabel(ctx context.Context, name string) (*model.Label, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetLabel(ctx, name)
+}
+
+func (r *TenantRepository) ListLabels(ctx context.Context) ([]*model.Label, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListLabels(ctx)
+}
+
+func (r *TenantRepository) UpdateLabel(ctx context.Context, label *model.Label) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateLabel(ctx, label)
+}
+
+func (r *TenantRepository) DeleteLabel(ctx context.Context, name string) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteLabel(ctx, name)
+}
+
+func (r *TenantRepository) AddDependency(ctx context.Context, dep *model.Dependency) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.AddDependency(ctx, dep)
+}
+
+func (r *TenantRepository) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.RemoveDependency(ctx, taskID, blockerID)
+}
+
+func (r *TenantRepository) ListDependencies(ctx context.Context) ([]*model.Dependency, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDependencies(ctx)
+}
+
+func (r *TenantRepository) ListBlockers(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListBlockers(ctx, taskID)
+}
+
+func (r *TenantRepository) ListDependents(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListDependents(ctx, taskID)
+}
+
+func (r *TenantRepository) AppendHistory(ctx context.Context, entry *model.HistoryEntry) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.AppendHistory(ctx, entry)
+}
+
+func (r *TenantRepository) ListHistory(ctx context.Context, taskID string) ([]*model.HistoryEntry, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListHistory(ctx, taskID)
+}
+
+func (r *TenantRepository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.CreateComment(ctx, comment)
+}
+
+func (r *TenantRepository) GetComment(ctx context.Context, taskID, id string) (*model.Comment, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetComment(ctx, taskID, id)
+}
+
+func (r *TenantRepository) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateComment(ctx, comment)
+}
+
+func (r *TenantRepository) DeleteComment(ctx context.Context, taskID, id string) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteComment(ctx, taskID, id)
+}
+
+func (r *TenantRepository) ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListComments(ctx, q)
+}
+
+func (r *TenantRepository) CreateAttachment(ctx context.Context, attachment *model.Attachment) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.CreateAttachment(ctx, attachment)
+}
+
+func (r *TenantRepository) GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetAttachment(ctx, taskID, id)
+}
+
+func (r *TenantRepository) ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListAttachments(ctx, taskID)
+}
+
+func (r *TenantRepository) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteAttachment(ctx, taskID, id)
+}
+
+func (r *TenantRepository) ListAttachmentBlobs(ctx context.Context) ([]string, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListAttachmentBlobs(ctx)
+}
+
+func (r *TenantRepository) CreateUser(ctx context.Context, user *model.User) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.CreateUser(ctx, user)
+}
+
+func (r *TenantRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetUser(ctx, id)
+}
+
+func (r *TenantRepository) ListUsers(ctx context.Context) ([]*model.User, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListUsers(ctx)
+}
+
+func (r *TenantRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateUser(ctx, user)
+}
+
+func (r *TenantRepository) DeleteUser(ctx context.Context, id string) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteUser(ctx, id)
+}
+
+func (r *TenantRepository) CreateProject(ctx context.Context, project *model.Project) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.CreateProject(ctx, project)
+}
+
+func (r *TenantRepository) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetProject(ctx, id)
+}
+
+func (r *TenantRepository) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListProjects(ctx)
+}
+
+func (r *TenantRepository) UpdateProject(ctx context.Context, project *model.Project) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateProject(ctx, project)
+}
+
+func (r *TenantRepository) DeleteProject(ctx context.Context, id string) error {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteProject(ctx, id)
+}
+
+func (r *TenantRepository) NextTaskNumber(ctx context.Context, id string) (int64, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return repo.NextTaskNumber(ctx, id)
+}
+
+// TenantBlobStore is a BlobStore that keeps the contents of every tenant in a
+// store of its own, like TenantRepository does for the rest of its data.
+type TenantBlobStore struct {
+	tenants map[string]BlobStore
+}
+
+// NewTenantBlobStore returns a blob store hosting the tenants given, mapped to
+// their stores.
+func NewTenantBlobStore(tenants map[string]BlobStore) *TenantBlobStore {
+	return &TenantBlobStore{tenants: maps.Clone(tenants)}
+}
+
+// store returns the store of the tenant of ctx.
+func (s *TenantBlobStore) store(ctx context.Context) (BlobStore, error) {
+	tenant := identity.Tenant(ctx)
+	if tenant == "" {
+		return nil, ErrNoTenant
+	}
+	store, ok := s.tenants[tenant]
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	return store, nil
+}
+
+// The BlobStore methods run in the store of the tenant of ctx.
+
+func (s *TenantBlobStore) Put(ctx context.Context, r io.Reader, maxSize int64) (BlobInfo, error) {
+	store, err := s.store(ctx)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	return store.Put(ctx, r, maxSize)
+}
+
+func (s *TenantBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	store, err := s.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(ctx, key)
+}
+
+func (s *TenantBlobStore) Delete(ctx context.Context, key string) error {
+	store, err := s.store(ctx)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, key)
+}
+
+func (s *TenantBlobStore) List(ctx context.Context) ([]BlobInfo, error) {
+	store, err := s.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.List(ctx)
+}
@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTenantRepository_Isolation(t *testing.T) {
+	repo := repository.NewTenantRepository(map[string]repository.TaskRepository{
+		"acme":   repository.NewInMemoryTaskRepository(zap.NewNop()),
+		"globex": repository.NewInMemoryTaskRepository(zap.NewNop()),
+	})
+	assert.Equal(t, []string{"acme", "globex"}, repo.Tenants())
+	acme := identity.WithTenant(context.Background(), "acme")
+	globex := identity.WithTenant(context.Background(), "globex")
+	now := time.Now().UTC()
+	task := func(title string) *model.Task {
+		return &model.Task{ID: "t1", Title: title, Status: model.StatusTodo, CreatedAt: now, UpdatedAt: now}
+	}
+
+	require.NoError(t, repo.CreateTask(acme, task("Acme plan")))
+	_, err := repo.GetTask(globex, "t1")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	require.NoError(t, repo.CreateTask(globex, task("Globex plan")), "IDs are per tenant")
+	got, err := repo.GetTask(acme, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme plan", got.Title)
+
+	require.NoError(t, repo.CreateLabel(acme, &model.Label{Name: "secret", CreatedAt: now, UpdatedAt: now}))
+	labels, err := repo.ListLabels(globex)
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+	n, err := repo.CountTasks(acme)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = repo.ListTasks(context.Background())
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, err = repo.ListTasks(identity.WithTenant(context.Background(), "initech"))
+	assert.ErrorIs(t, err, repository.ErrUnknownTenant)
+	assert.ErrorIs(t, repo.DeleteTask(context.Background(), "t1", repository.AnyVersion), repository.ErrNoTenant)
+}
+
+func TestTenantBlobStore(t *testing.T) {
+	stores := map[string]repository.BlobStore{}
+	for _, tenant := range []string{"acme", "globex"} {
+		store, err := repository.NewFileBlobStore(t.TempDir(), zap.NewNop())
+		require.NoError(t, err)
+		stores[tenant] = store
+	}
+	blobs := repository.NewTenantBlobStore(stores)
+	acme := identity.WithTenant(context.Background(), "acme")
+	globex := identity.WithTenant(context.Background(), "globex")
+
+	info, err := blobs.Put(acme, strings.NewReader("plans"), 100)
+	require.NoError(t, err)
+	_, err = blobs.Open(globex, info.Key)
+	assert.ErrorIs(t, err, repository.ErrBlobNotFound)
+	listed, err := blobs.List(acme)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+	_, err = blobs.List(context.Background())
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+}
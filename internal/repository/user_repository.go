@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// ErrUserNotFound is returned when a user does not exist.
+var ErrUserNotFound = apperror.NotFound("user not found")
+
+// ErrUserAlreadyExists is returned when creating a user whose ID is taken.
+var ErrUserAlreadyExists = apperror.AlreadyExists("user already exists")
+
+// UserRepository stores the users tasks are assigned to. Whether a task's
+// assignee exists is checked by the service, not by storage, so tasks keep
+// naming users who have since been deleted.
+type UserRepository interface {
+	// CreateUser adds a new user.
+	CreateUser(ctx context.Context, user *model.User) error
+	// GetUser retrieves a user by ID.
+	GetUser(ctx context.Context, id string) (*model.User, error)
+	// ListUsers returns all users ordered by ID.
+	ListUsers(ctx context.Context) ([]*model.User, error)
+	// UpdateUser replaces the name, email and UpdatedAt of an existing user.
+	UpdateUser(ctx context.Context, user *model.User) error
+	// DeleteUser removes a user.
+	DeleteUser(ctx context.Context, id string) error
+}
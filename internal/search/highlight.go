@@ -0,0 +1,85 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Markers wrapped around matching words by Highlight and Snippet.
+const (
+	MarkStart = "<mark>"
+	MarkEnd   = "</mark>"
+)
+
+// Highlight returns text, HTML-escaped, with the words matching q wrapped in
+// MarkStart and MarkEnd. It reports whether anything matched.
+func Highlight(text string, q *Query) (string, bool) {
+	return mark(text, Tokenize(text), q, 0, len(text))
+}
+
+// Snippet returns an excerpt of text of roughly maxLen bytes around the first
+// word matching q, highlighted like Highlight and with an ellipsis where text
+// was cut. It returns false when nothing in text matches.
+func Snippet(text string, q *Query, maxLen int) (string, bool) {
+	tokens := Tokenize(text)
+	first := -1
+	for i, tok := range tokens {
+		if q.Matches(tok) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+	if len(text) <= maxLen {
+		return mark(text, tokens, q, 0, len(text))
+	}
+
+	// Start a quarter of the window before the match, on a word boundary.
+	start := tokens[first].Start
+	if start <= maxLen/4 {
+		start = 0
+	} else {
+		for i := first - 1; i >= 0 && tokens[first].Start-tokens[i].Start <= maxLen/4; i-- {
+			start = tokens[i].Start
+		}
+	}
+	end := tokens[first].End
+	for _, tok := range tokens[first+1:] {
+		if tok.End-start > maxLen {
+			break
+		}
+		end = tok.End
+	}
+	if len(text)-start <= maxLen {
+		end = len(text)
+	}
+
+	snippet, _ := mark(text, tokens, q, start, end)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet, true
+}
+
+// mark renders text[start:end] with the matching tokens inside it marked.
+func mark(text string, tokens []Token, q *Query, start, end int) (string, bool) {
+	var b strings.Builder
+	pos, matched := start, false
+	for _, tok := range tokens {
+		if tok.Start < start || tok.End > end || !q.Matches(tok) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:tok.Start]))
+		b.WriteString(MarkStart)
+		b.WriteString(html.EscapeString(text[tok.Start:tok.End]))
+		b.WriteString(MarkEnd)
+		pos, matched = tok.End, true
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	return b.String(), matched
+}
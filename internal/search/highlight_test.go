@@ -0,0 +1,46 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlight(t *testing.T) {
+	got, ok := Highlight("Reporting <b>bugs</b> & reports", ParseQuery("report"))
+	assert.True(t, ok)
+	assert.Equal(t, "<mark>Reporting</mark> &lt;b&gt;bugs&lt;/b&gt; &amp; <mark>reports</mark>", got)
+
+	got, ok = Highlight("Nothing here", ParseQuery("report"))
+	assert.False(t, ok)
+	assert.Equal(t, "Nothing here", got)
+}
+
+func TestHighlight_Prefix(t *testing.T) {
+	got, _ := Highlight("Deploy the deployment", ParseQuery("depl"))
+	assert.Equal(t, "<mark>Deploy</mark> the <mark>deployment</mark>", got)
+}
+
+func TestSnippet_Short(t *testing.T) {
+	got, ok := Snippet("Fix the login page", ParseQuery("login"), 100)
+	assert.True(t, ok)
+	assert.Equal(t, "Fix the <mark>login</mark> page", got)
+}
+
+func TestSnippet_Window(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "critical outage in production " + strings.Repeat("dolor sit ", 20)
+	got, ok := Snippet(text, ParseQuery("outage"), 60)
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(got, "…"), got)
+	assert.True(t, strings.HasSuffix(got, "…"), got)
+	assert.Contains(t, got, "<mark>outage</mark>")
+	plain := strings.NewReplacer(MarkStart, "", MarkEnd, "", "…", "").Replace(got)
+	assert.LessOrEqual(t, len(plain), 60)
+	assert.Contains(t, text, plain)
+}
+
+func TestSnippet_NoMatch(t *testing.T) {
+	_, ok := Snippet("Fix the login page", ParseQuery("logout"), 100)
+	assert.False(t, ok)
+}
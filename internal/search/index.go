@@ -0,0 +1,234 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"taskmanager/internal/model"
+)
+
+// field is an indexed task field.
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldDescription
+	numFields
+)
+
+// fieldWeights boosts matches in the title over matches in the description.
+var fieldWeights = [numFields]float64{fieldTitle: 2, fieldDescription: 1}
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Hit is a search result: a task ID and its relevance score.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// document records what the index holds for one task, so it can be removed.
+type document struct {
+	lengths [numFields]int
+	terms   map[string]bool
+	words   map[string]bool
+}
+
+// Index is an inverted index over task titles and descriptions. It is safe for
+// concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]*[numFields]int // term -> task ID -> frequency per field
+	totalLen [numFields]int
+	// vocabulary holds every indexed (unstemmed) word in sorted order for prefix
+	// lookups; wordDocs counts the documents containing each word.
+	vocabulary []string
+	wordDocs   map[string]int
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]*[numFields]int),
+		wordDocs: make(map[string]int),
+	}
+}
+
+// Len returns the number of indexed tasks.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Add indexes task, replacing any earlier version of it.
+func (x *Index) Add(task *model.Task) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(task.ID)
+	x.add(task)
+}
+
+// Remove drops the task with the given ID from the index.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+}
+
+// Rebuild replaces the contents of the index with tasks.
+func (x *Index) Rebuild(tasks []*model.Task) {
+	fresh := NewIndex()
+	for _, task := range tasks {
+		fresh.add(task)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.docs, x.postings, x.totalLen = fresh.docs, fresh.postings, fresh.totalLen
+	x.vocabulary, x.wordDocs = fresh.vocabulary, fresh.wordDocs
+}
+
+func (x *Index) add(task *model.Task) {
+	doc := &document{terms: make(map[string]bool), words: make(map[string]bool)}
+	for f, text := range [numFields]string{fieldTitle: task.Title, fieldDescription: task.Description} {
+		tokens := Tokenize(text)
+		doc.lengths[f] = len(tokens)
+		x.totalLen[f] += len(tokens)
+		for _, tok := range tokens {
+			docs := x.postings[tok.Term]
+			if docs == nil {
+				docs = make(map[string]*[numFields]int)
+				x.postings[tok.Term] = docs
+			}
+			freq := docs[task.ID]
+			if freq == nil {
+				freq = new([numFields]int)
+				docs[task.ID] = freq
+			}
+			freq[f]++
+			doc.terms[tok.Term] = true
+			doc.words[tok.Word] = true
+		}
+	}
+	for word := range doc.words {
+		if x.wordDocs[word] == 0 {
+			i := sort.SearchStrings(x.vocabulary, word)
+			x.vocabulary = append(x.vocabulary, "")
+			copy(x.vocabulary[i+1:], x.vocabulary[i:])
+			x.vocabulary[i] = word
+		}
+		x.wordDocs[word]++
+	}
+	x.docs[task.ID] = doc
+}
+
+func (x *Index) remove(id string) {
+	doc, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	for word := range doc.words {
+		x.wordDocs[word]--
+		if x.wordDocs[word] == 0 {
+			delete(x.wordDocs, word)
+			i := sort.SearchStrings(x.vocabulary, word)
+			x.vocabulary = append(x.vocabulary[:i], x.vocabulary[i+1:]...)
+		}
+	}
+	for f := range doc.lengths {
+		x.totalLen[f] -= doc.lengths[f]
+	}
+	delete(x.docs, id)
+}
+
+// Search returns the tasks matching q, best first, at most limit of them
+// (all when limit is 0). Ties are ordered by ID.
+func (x *Index) Search(q *Query, limit int) []Hit {
+	if q.Empty() {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var scores map[string]float64
+	for i := range q.tokens {
+		tokenScores := x.scoreToken(q, i)
+		if scores == nil {
+			scores = tokenScores
+			continue
+		}
+		// Every query word must match.
+		for id := range scores {
+			if s, ok := tokenScores[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// scoreToken scores every document matching the i-th query word, taking the
+// best of its stem match and any prefix matches.
+func (x *Index) scoreToken(q *Query, i int) map[string]float64 {
+	candidates := map[string]float64{q.tokens[i].Term: 1}
+	if prefix := q.tokens[i].Word; len(prefix) >= minPrefixLength {
+		for j := sort.SearchStrings(x.vocabulary, prefix); j < len(x.vocabulary) && strings.HasPrefix(x.vocabulary[j], prefix); j++ {
+			term := Stem(x.vocabulary[j])
+			candidates[term] = max(candidates[term], q.match(i, x.vocabulary[j], term))
+		}
+	}
+	scores := make(map[string]float64)
+	for term, weight := range candidates {
+		for id, freq := range x.postings[term] {
+			scores[id] = max(scores[id], weight*x.bm25(len(x.postings[term]), freq, x.docs[id]))
+		}
+	}
+	return scores
+}
+
+// bm25 scores one term in one document from the term's document frequency
+// and its per-field frequencies, weighting fields by fieldWeights.
+func (x *Index) bm25(df int, freq *[numFields]int, doc *document) float64 {
+	n := float64(len(x.docs))
+	idf := math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+	score := 0.0
+	for f := field(0); f < numFields; f++ {
+		if freq[f] == 0 {
+			continue
+		}
+		avg := float64(x.totalLen[f]) / n
+		tf := float64(freq[f])
+		norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.lengths[f])/avg))
+		score += fieldWeights[f] * idf * norm
+	}
+	return score
+}
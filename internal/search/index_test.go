@@ -0,0 +1,103 @@
+package search
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+func newTestIndex() *Index {
+	x := NewIndex()
+	x.Rebuild([]*model.Task{
+		{ID: "1", Title: "Write quarterly report", Description: "Summarise the numbers for finance"},
+		{ID: "2", Title: "Fix login bug", Description: "Users report that logging in fails on Safari"},
+		{ID: "3", Title: "Plan team offsite", Description: "Book venue; prepare the report template"},
+		{ID: "4", Title: "Deploy release", Description: "Deployment checklist"},
+	})
+	return x
+}
+
+func TestIndex_StemmedMatch(t *testing.T) {
+	x := newTestIndex()
+	// "reporting" stems to "report": title matches rank above description ones.
+	hits := x.Search(ParseQuery("reporting"), 0)
+	require.Len(t, hits, 3)
+	assert.Equal(t, "1", hits[0].ID)
+	assert.ElementsMatch(t, []string{"2", "3"}, hitIDs(hits[1:]))
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+}
+
+func TestIndex_AllWordsMustMatch(t *testing.T) {
+	x := newTestIndex()
+	assert.Equal(t, []string{"2"}, hitIDs(x.Search(ParseQuery("login report"), 0)))
+	assert.Empty(t, x.Search(ParseQuery("login offsite"), 0))
+}
+
+func TestIndex_PrefixMatch(t *testing.T) {
+	x := newTestIndex()
+	assert.Equal(t, []string{"4"}, hitIDs(x.Search(ParseQuery("deplo"), 0)))
+	assert.Equal(t, []string{"2"}, hitIDs(x.Search(ParseQuery("safa"), 0)))
+	// Single letters are not used as prefixes.
+	assert.Empty(t, x.Search(ParseQuery("s"), 0))
+}
+
+func TestIndex_EmptyQuery(t *testing.T) {
+	x := newTestIndex()
+	assert.Empty(t, x.Search(ParseQuery(""), 0))
+	assert.Empty(t, x.Search(ParseQuery("the of"), 0))
+}
+
+func TestIndex_Limit(t *testing.T) {
+	x := newTestIndex()
+	assert.Len(t, x.Search(ParseQuery("report"), 2), 2)
+}
+
+func TestIndex_AddReplacesAndRemove(t *testing.T) {
+	x := newTestIndex()
+	x.Add(&model.Task{ID: "2", Title: "Fix signup bug"})
+	assert.Empty(t, x.Search(ParseQuery("login"), 0))
+	assert.Equal(t, []string{"2"}, hitIDs(x.Search(ParseQuery("signup"), 0)))
+
+	x.Remove("4")
+	assert.Empty(t, x.Search(ParseQuery("deploy"), 0))
+	assert.Empty(t, x.Search(ParseQuery("deplo"), 0), "removed words leave the prefix vocabulary")
+	assert.Equal(t, 3, x.Len())
+
+	x.Remove("missing")
+	assert.Equal(t, 3, x.Len())
+}
+
+func TestIndex_ConcurrentUse(t *testing.T) {
+	x := NewIndex()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				id := fmt.Sprintf("%d-%d", i, j)
+				x.Add(&model.Task{ID: id, Title: "concurrent task " + id})
+				x.Search(ParseQuery("concurrent"), 10)
+				if j%2 == 0 {
+					x.Remove(id)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 8*25, x.Len())
+	assert.Len(t, x.Search(ParseQuery("concurrent"), 0), 8*25)
+}
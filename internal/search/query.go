@@ -0,0 +1,50 @@
+package search
+
+import "strings"
+
+// minPrefixLength is the shortest query word that also matches longer words
+// starting with it; shorter prefixes would match most of the vocabulary.
+const minPrefixLength = 2
+
+// prefixWeight scales the score of a prefix match relative to a term match.
+const prefixWeight = 0.5
+
+// Query is a parsed search query. A task matches when every query word matches
+// one of its words, either by stem ("reports" finds "reporting") or as a prefix
+// ("repo" finds "report").
+type Query struct {
+	tokens []Token
+}
+
+// ParseQuery tokenizes a query string the same way documents are indexed.
+func ParseQuery(q string) *Query {
+	return &Query{tokens: Tokenize(q)}
+}
+
+// Empty reports whether the query has no searchable words.
+func (q *Query) Empty() bool {
+	return len(q.tokens) == 0
+}
+
+// match reports how well a document word, with its stem term, matches the i-th
+// query word: 1 for the same stem, prefixWeight for a prefix and 0 otherwise.
+func (q *Query) match(i int, word, term string) float64 {
+	qt := q.tokens[i]
+	if term == qt.Term {
+		return 1
+	}
+	if len(qt.Word) >= minPrefixLength && strings.HasPrefix(word, qt.Word) {
+		return prefixWeight
+	}
+	return 0
+}
+
+// Matches reports whether tok matches any query word.
+func (q *Query) Matches(tok Token) bool {
+	for i := range q.tokens {
+		if q.match(i, tok.Word, tok.Term) > 0 {
+			return true
+		}
+	}
+	return false
+}
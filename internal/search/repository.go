@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+)
+
+// IndexedRepository is a TaskRepository that keeps an Index in step with every
+// successful write to the repository it wraps. Reads go straight through.
+type IndexedRepository struct {
+	repository.TaskRepository
+	index *Index
+	// mu serialises writes, so the index sees them in the order the repository
+	// applied them.
+	mu sync.Mutex
+}
+
+// NewIndexedRepository wraps repo so that its writes update index. Call Rebuild
+// to index the tasks repo already holds.
+func NewIndexedRepository(repo repository.TaskRepository, index *Index) *IndexedRepository {
+	return &IndexedRepository{TaskRepository: repo, index: index}
+}
+
+// Index returns the index maintained by the repository.
+func (r *IndexedRepository) Index() *Index {
+	return r.index
+}
+
+// Rebuild re-indexes every task in the repository.
+func (r *IndexedRepository) Rebuild(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tasks, err := r.TaskRepository.ListTasks(ctx)
+	if err != nil {
+		return err
+	}
+	r.index.Rebuild(tasks)
+	return nil
+}
+
+// CreateTask creates the task and indexes it.
+func (r *IndexedRepository) CreateTask(ctx context.Context, task *model.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.TaskRepository.CreateTask(ctx, task); err != nil {
+		return err
+	}
+	r.index.Add(task)
+	return nil
+}
+
+// UpdateTask updates the task and re-indexes it. Tasks moved to the trash are
+// removed from the index.
+func (r *IndexedRepository) UpdateTask(ctx context.Context, task *model.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.TaskRepository.UpdateTask(ctx, task); err != nil {
+		return err
+	}
+	if task.DeletedAt != nil {
+		r.index.Remove(task.ID)
+	} else {
+		r.index.Add(task)
+	}
+	return nil
+}
+
+// DeleteTask deletes the task and removes it from the index.
+func (r *IndexedRepository) DeleteTask(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.TaskRepository.DeleteTask(ctx, id, version); err != nil {
+		return err
+	}
+	r.index.Remove(id)
+	return nil
+}
+
+// WriteTasks applies the batch and re-indexes the tasks it wrote.
+func (r *IndexedRepository) WriteTasks(ctx context.Context, batch repository.TaskBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.TaskRepository.WriteTasks(ctx, batch); err != nil {
+		return err
+	}
+	for _, task := range batch.Create {
+		r.index.Add(task)
+	}
+	for _, task := range batch.Update {
+		if task.DeletedAt != nil {
+			r.index.Remove(task.ID)
+		} else {
+			r.index.Add(task)
+		}
+	}
+	for _, task := range batch.Delete {
+		r.index.Remove(task.ID)
+	}
+	return nil
+}
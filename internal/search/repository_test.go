@@ -0,0 +1,92 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/repository/repotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIndexedRepository_Conformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.TaskRepository {
+		return NewIndexedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()), NewIndex())
+	})
+}
+
+func TestIndexedRepository_TracksWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := NewIndexedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()), NewIndex())
+	search := func(q string) []string { return hitIDs(repo.Index().Search(ParseQuery(q), 0)) }
+
+	task := &model.Task{ID: "t1", Title: "Renew certificates"}
+	require.NoError(t, repo.CreateTask(ctx, task))
+	assert.Equal(t, []string{"t1"}, search("certificate"))
+
+	task.Title = "Rotate keys"
+	require.NoError(t, repo.UpdateTask(ctx, task))
+	assert.Empty(t, search("certificate"))
+	assert.Equal(t, []string{"t1"}, search("keys"))
+
+	// Trashed tasks are not searchable until they are restored.
+	deletedAt := time.Now()
+	task.DeletedAt = &deletedAt
+	require.NoError(t, repo.UpdateTask(ctx, task))
+	assert.Empty(t, search("keys"))
+	task.DeletedAt = nil
+	require.NoError(t, repo.UpdateTask(ctx, task))
+	assert.Equal(t, []string{"t1"}, search("keys"))
+
+	// A failed write leaves the index alone.
+	stale := &model.Task{ID: "t1", Title: "Stale write", Version: 3}
+	require.Error(t, repo.UpdateTask(ctx, stale))
+	assert.Empty(t, search("stale"))
+
+	require.NoError(t, repo.DeleteTask(ctx, "t1", repository.AnyVersion))
+	assert.Empty(t, search("keys"))
+	assert.Equal(t, 0, repo.Index().Len())
+}
+
+func TestIndexedRepository_TracksBatchWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := NewIndexedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()), NewIndex())
+	search := func(q string) []string { return hitIDs(repo.Index().Search(ParseQuery(q), 0)) }
+
+	a := &model.Task{ID: "a", Title: "Renew certificates"}
+	b := &model.Task{ID: "b", Title: "Rotate keys"}
+	require.NoError(t, repo.WriteTasks(ctx, repository.TaskBatch{Create: []*model.Task{a, b}}))
+	assert.Equal(t, []string{"a"}, search("certificate"))
+	assert.Equal(t, []string{"b"}, search("keys"))
+
+	deletedAt := time.Now()
+	a.Title = "Renew licences"
+	b.DeletedAt = &deletedAt
+	require.NoError(t, repo.WriteTasks(ctx, repository.TaskBatch{Update: []*model.Task{a, b}}))
+	assert.Empty(t, search("certificate"))
+	assert.Equal(t, []string{"a"}, search("licences"))
+	assert.Empty(t, search("keys"))
+
+	// A failed batch leaves the index alone.
+	stale := &model.Task{ID: "a", Title: "Stale write", Version: 1}
+	require.Error(t, repo.WriteTasks(ctx, repository.TaskBatch{Create: []*model.Task{{ID: "c", Title: "Stale"}}, Update: []*model.Task{stale}}))
+	assert.Empty(t, search("stale"))
+}
+
+func TestIndexedRepository_Rebuild(t *testing.T) {
+	ctx := context.Background()
+	inner := repository.NewInMemoryTaskRepository(zap.NewNop())
+	require.NoError(t, inner.CreateTask(ctx, &model.Task{ID: "a", Title: "Existing backlog item"}))
+	require.NoError(t, inner.CreateTask(ctx, &model.Task{ID: "b", Title: "Another item"}))
+
+	repo := NewIndexedRepository(inner, NewIndex())
+	assert.Equal(t, 0, repo.Index().Len())
+	require.NoError(t, repo.Rebuild(ctx))
+	assert.Equal(t, 2, repo.Index().Len())
+	assert.Equal(t, []string{"a"}, hitIDs(repo.Index().Search(ParseQuery("backlog"), 0)))
+}
@@ -0,0 +1,230 @@
+package search
+
+import "strings"
+
+// Stem reduces an English word to its stem using the Porter (1980) algorithm,
+// so that "connected", "connecting" and "connection" all index as "connect".
+// The word must be lower case; words with non-ASCII letters or shorter than
+// three characters are returned unchanged.
+func Stem(word string) string {
+	if len(word) < 3 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+type stemmer struct {
+	b []byte
+}
+
+// consonant reports whether b[i] is a consonant. y is a consonant at the start
+// of a word or after a vowel.
+func (s *stemmer) consonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.consonant(i-1)
+	}
+	return true
+}
+
+// measure counts the VC sequences in b[:n], the m of the algorithm.
+func (s *stemmer) measure(n int) int {
+	m, i := 0, 0
+	for i < n && s.consonant(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !s.consonant(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		for i < n && s.consonant(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+// hasVowel reports whether b[:n] contains a vowel.
+func (s *stemmer) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !s.consonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleConsonant reports whether b[:n] ends in a double consonant.
+func (s *stemmer) doubleConsonant(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.consonant(n-1)
+}
+
+// cvc reports whether b[:n] ends consonant-vowel-consonant where the last
+// consonant is not w, x or y, as in "hop" but not "snow".
+func (s *stemmer) cvc(n int) bool {
+	if n < 3 || !s.consonant(n-1) || s.consonant(n-2) || !s.consonant(n-3) {
+		return false
+	}
+	switch s.b[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func (s *stemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// replace swaps suffix for repl when the remaining stem has a measure greater
+// than minM. It reports whether the word ended in suffix at all.
+func (s *stemmer) replace(suffix, repl string, minM int) bool {
+	if !s.hasSuffix(suffix) {
+		return false
+	}
+	stem := len(s.b) - len(suffix)
+	if s.measure(stem) > minM {
+		s.b = append(s.b[:stem], repl...)
+	}
+	return true
+}
+
+func (s *stemmer) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.b = s.b[:len(s.b)-2]
+	case s.hasSuffix("ies"):
+		s.b = s.b[:len(s.b)-2]
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.b = s.b[:len(s.b)-1]
+	}
+}
+
+func (s *stemmer) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measure(len(s.b)-3) > 0 {
+			s.b = s.b[:len(s.b)-1]
+		}
+		return
+	}
+	var stem int
+	switch {
+	case s.hasSuffix("ed"):
+		stem = len(s.b) - 2
+	case s.hasSuffix("ing"):
+		stem = len(s.b) - 3
+	default:
+		return
+	}
+	if !s.hasVowel(stem) {
+		return
+	}
+	s.b = s.b[:stem]
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.b = append(s.b, 'e')
+	case s.doubleConsonant(len(s.b)):
+		switch s.b[len(s.b)-1] {
+		case 'l', 's', 'z':
+		default:
+			s.b = s.b[:len(s.b)-1]
+		}
+	case s.measure(len(s.b)) == 1 && s.cvc(len(s.b)):
+		s.b = append(s.b, 'e')
+	}
+}
+
+func (s *stemmer) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(len(s.b)-1) {
+		s.b[len(s.b)-1] = 'i'
+	}
+}
+
+var step2Suffixes = []struct{ suffix, repl string }{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+func (s *stemmer) step2() {
+	for _, r := range step2Suffixes {
+		if s.replace(r.suffix, r.repl, 0) {
+			return
+		}
+	}
+}
+
+var step3Suffixes = []struct{ suffix, repl string }{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+func (s *stemmer) step3() {
+	for _, r := range step3Suffixes {
+		if s.replace(r.suffix, r.repl, 0) {
+			return
+		}
+	}
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func (s *stemmer) step4() {
+	// Longest match first among suffixes sharing an ending, e.g. "ement"
+	// before "ment" before "ent".
+	best := ""
+	for _, suffix := range step4Suffixes {
+		if s.hasSuffix(suffix) && len(suffix) > len(best) {
+			best = suffix
+		}
+	}
+	if best == "" {
+		return
+	}
+	stem := len(s.b) - len(best)
+	if s.measure(stem) <= 1 {
+		return
+	}
+	if best == "ion" && (stem == 0 || (s.b[stem-1] != 's' && s.b[stem-1] != 't')) {
+		return
+	}
+	s.b = s.b[:stem]
+}
+
+func (s *stemmer) step5() {
+	if s.hasSuffix("e") {
+		stem := len(s.b) - 1
+		if m := s.measure(stem); m > 1 || (m == 1 && !s.cvc(stem)) {
+			s.b = s.b[:stem]
+		}
+	}
+	if s.hasSuffix("ll") && s.measure(len(s.b)) > 1 {
+		s.b = s.b[:len(s.b)-1]
+	}
+}
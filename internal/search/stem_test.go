@@ -0,0 +1,52 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	// Examples from Porter's paper.
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"troubled":       "troubl",
+		"sized":          "size",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"hissing":        "hiss",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"connection":     "connect",
+		"connected":      "connect",
+		"connecting":     "connect",
+		"adjustable":     "adjust",
+		"adjustment":     "adjust",
+		"controll":       "control",
+		"roll":           "roll",
+		"electrical":     "electr",
+		"hopefulness":    "hope",
+		"reports":        "report",
+		"reporting":      "report",
+	}
+	for word, want := range tests {
+		assert.Equal(t, want, Stem(word), word)
+	}
+}
+
+func TestStem_Untouched(t *testing.T) {
+	for _, word := range []string{"a", "is", "café", "v2", "naïve"} {
+		assert.Equal(t, word, Stem(word))
+	}
+}
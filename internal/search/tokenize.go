@@ -0,0 +1,56 @@
+// Package search provides full-text search over tasks: an in-memory inverted
+// index with English stemming, BM25 ranking and prefix matching, kept in step
+// with a repository by IndexedRepository.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is a word of a text with its byte offsets.
+type Token struct {
+	// Word is the lower-cased word as it appears in the text.
+	Word string
+	// Term is the stemmed form the word is indexed under.
+	Term       string
+	Start, End int
+}
+
+// stopWords are frequent English words that carry no meaning for search.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+// Tokenize splits text into words at every character that is not a letter or
+// digit, lower-cases and stems them, and drops stop words.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.ToLower(text[start:end])
+		if !stopWords[word] {
+			tokens = append(tokens, Token{Word: word, Term: Stem(word), Start: start, End: end})
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return tokens
+}
@@ -0,0 +1,36 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	text := "Fix the flaky Tests, in CI-pipeline (v2)!"
+	tokens := Tokenize(text)
+
+	var words, terms []string
+	for _, tok := range tokens {
+		words = append(words, tok.Word)
+		terms = append(terms, tok.Term)
+		assert.Equal(t, tok.Word, strings.ToLower(text[tok.Start:tok.End]), "offsets of %q", tok.Word)
+	}
+	assert.Equal(t, []string{"fix", "flaky", "tests", "ci", "pipeline", "v2"}, words)
+	assert.Equal(t, []string{"fix", "flaki", "test", "ci", "pipelin", "v2"}, terms)
+}
+
+func TestTokenize_Unicode(t *testing.T) {
+	tokens := Tokenize("Café — Straße")
+	if assert.Len(t, tokens, 2) {
+		assert.Equal(t, "café", tokens[0].Word)
+		assert.Equal(t, "straße", tokens[1].Word)
+		assert.Equal(t, len("Café — "), tokens[1].Start)
+	}
+}
+
+func TestTokenize_Empty(t *testing.T) {
+	assert.Empty(t, Tokenize(""))
+	assert.Empty(t, Tokenize("the and of --"))
+}
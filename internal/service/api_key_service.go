@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// APIKeyService issues, lists and revokes API keys and authenticates the keys
+// clients present.
+type APIKeyService interface {
+	// IssueKey validates and stores a new key. The returned key is the only
+	// place its secret ever appears. A principal confined to a tenant can
+	// only issue keys confined to the same tenant.
+	IssueKey(ctx context.Context, key *model.APIKey) (*model.IssuedAPIKey, error)
+	// ListKeys returns all keys ordered by creation time, then ID; to a
+	// principal confined to a tenant only the keys of that tenant.
+	ListKeys(ctx context.Context) ([]*model.APIKey, error)
+	// RevokeKey deletes a key; requests presenting it fail from then on. To
+	// a principal confined to a tenant, keys of other tenants do not exist.
+	RevokeKey(ctx context.Context, id string) error
+	// Authenticate returns the key a client presented as token, or an error
+	// matching ErrInvalidAPIKey.
+	Authenticate(ctx context.Context, token string) (*model.APIKey, error)
+}
+
+// ErrAPIKeyNotFound is returned when an API key does not exist.
+var ErrAPIKeyNotFound = repository.ErrAPIKeyNotFound
+
+// ErrInvalidAPIKey is matched by errors returned when a presented API key is
+// malformed, unknown or revoked.
+var ErrInvalidAPIKey = apperror.Unauthenticated("invalid API key")
+
+// ErrKeyOutsideTenant is returned when a principal confined to a tenant
+// issues a key that is not confined to the same tenant.
+var ErrKeyOutsideTenant = apperror.Forbidden("API key must be confined to your tenant")
+
+// Keys look like tm_<id>_<secret>: the ID finds the stored key, whose hash the
+// secret must match. IDs are 8 random bytes and secrets 32, both in hex.
+const (
+	apiKeyPrefix      = "tm_"
+	apiKeyIDBytes     = 8
+	apiKeySecretBytes = 32
+)
+
+// AdminKeyID is the ID of the bootstrap admin key, see NewAPIKeyService.
+const AdminKeyID = "admin"
+
+type apiKeyServiceImpl struct {
+	repo     repository.APIKeyRepository
+	adminKey string
+	logger   *zap.Logger
+}
+
+// NewAPIKeyService creates an APIKeyService on repo. A non-empty adminKey is
+// accepted as a key with the admin scope that is not stored, so the first
+// keys can be issued; it can be dropped from the configuration once they are.
+func NewAPIKeyService(repo repository.APIKeyRepository, adminKey string, logger *zap.Logger) APIKeyService {
+	return &apiKeyServiceImpl{repo: repo, adminKey: adminKey, logger: logger}
+}
+
+func (s *apiKeyServiceImpl) IssueKey(ctx context.Context, key *model.APIKey) (*model.IssuedAPIKey, error) {
+	if err := key.Validate(); err != nil {
+		s.logger.Warn("API key validation failed", zap.Error(err))
+		return nil, err
+	}
+	if tenant := confinedTenant(ctx); tenant != "" && key.Tenant != tenant {
+		s.logger.Warn("API key outside the issuer's tenant", zap.String("tenant", tenant), zap.String("key_tenant", key.Tenant))
+		return nil, ErrKeyOutsideTenant
+	}
+	id, secret := randomHex(apiKeyIDBytes), randomHex(apiKeySecretBytes)
+	key.ID = id
+	key.Hash = hashSecret(secret)
+	key.CreatedBy = identity.Actor(ctx)
+	key.CreatedAt = time.Now().UTC()
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		s.logger.Error("failed to store API key", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("API key issued", zap.String("id", id), zap.Strings("scopes", key.Scopes), zap.String("tenant", key.Tenant))
+	return &model.IssuedAPIKey{APIKey: key, Key: apiKeyPrefix + id + "_" + secret}, nil
+}
+
+func (s *apiKeyServiceImpl) ListKeys(ctx context.Context) ([]*model.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		s.logger.Error("failed to list API keys", zap.Error(err))
+		return nil, err
+	}
+	if tenant := confinedTenant(ctx); tenant != "" {
+		keys = slices.DeleteFunc(keys, func(key *model.APIKey) bool { return key.Tenant != tenant })
+	}
+	return keys, nil
+}
+
+func (s *apiKeyServiceImpl) RevokeKey(ctx context.Context, id string) error {
+	if tenant := confinedTenant(ctx); tenant != "" {
+		key, err := s.repo.GetAPIKey(ctx, id)
+		if err != nil {
+			return err
+		}
+		if key.Tenant != tenant {
+			s.logger.Warn("API key of another tenant", zap.String("id", id), zap.String("tenant", tenant))
+			return ErrAPIKeyNotFound
+		}
+	}
+	if err := s.repo.DeleteAPIKey(ctx, id); err != nil {
+		s.logger.Warn("failed to revoke API key", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	s.logger.Info("API key revoked", zap.String("id", id))
+	return nil
+}
+
+// Authenticate compares secrets in constant time, so response times do not
+// tell how much of a guessed secret is right.
+func (s *apiKeyServiceImpl) Authenticate(ctx context.Context, token string) (*model.APIKey, error) {
+	if s.adminKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminKey)) == 1 {
+		return &model.APIKey{ID: AdminKeyID, Name: "bootstrap admin key", Scopes: []string{model.ScopeAdmin}}, nil
+	}
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, apiKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(token, apiKeyPrefix) || id == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.GetAPIKey(ctx, id)
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		s.logger.Warn("unknown API key", zap.String("id", id))
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		s.logger.Error("failed to look up API key", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.Hash)) != 1 {
+		s.logger.Warn("API key secret mismatch", zap.String("id", id))
+		return nil, ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+// confinedTenant returns the tenant the principal of ctx is confined to, ""
+// if it may act in any tenant or the request is not authenticated.
+func confinedTenant(ctx context.Context) string {
+	if p := identity.PrincipalOf(ctx); p != nil {
+		return p.Tenant
+	}
+	return ""
+}
+
+// hashSecret returns the hex SHA-256 of secret. Secrets are random, not
+// passwords, so a fast unsalted hash is enough to keep them out of storage.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes in hex.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
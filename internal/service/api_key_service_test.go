@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newAPIKeyFixture(t *testing.T, adminKey string) APIKeyService {
+	t.Helper()
+	repo, err := repository.OpenFileAPIKeyRepository("", zap.NewNop())
+	require.NoError(t, err)
+	return NewAPIKeyService(repo, adminKey, zap.NewNop())
+}
+
+func TestAPIKeyService_IssueAndAuthenticate(t *testing.T) {
+	svc := newAPIKeyFixture(t, "")
+	ctx := identity.WithActor(context.Background(), "alice")
+
+	issued, err := svc.IssueKey(ctx, &model.APIKey{Name: "CI", Scopes: []string{model.ScopeTasksRead}, Tenant: "acme"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(issued.Key, "tm_"+issued.ID+"_"))
+	assert.Equal(t, "alice", issued.CreatedBy)
+	assert.False(t, issued.CreatedAt.IsZero())
+	assert.NotContains(t, issued.Key, issued.Hash, "only the hash is stored")
+
+	key, err := svc.Authenticate(ctx, issued.Key)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, key.ID)
+	assert.Equal(t, "acme", key.Tenant)
+	assert.True(t, key.HasScope(model.ScopeTasksRead))
+
+	for _, token := range []string{"", "tm_", issued.Key + "0", issued.Key[:len(issued.Key)-1], "tm_missing_secret", strings.TrimPrefix(issued.Key, "tm_")} {
+		_, err := svc.Authenticate(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, "token %q", token)
+		assert.Equal(t, apperror.KindUnauthenticated, apperror.KindOf(err))
+	}
+
+	_, err = svc.IssueKey(ctx, &model.APIKey{Name: "CI", Scopes: []string{"root"}})
+	assert.Equal(t, "scopes", apperror.FieldsOf(err)[0].Field)
+}
+
+func TestAPIKeyService_ListAndRevoke(t *testing.T) {
+	svc := newAPIKeyFixture(t, "")
+	ctx := context.Background()
+	a, err := svc.IssueKey(ctx, &model.APIKey{Name: "A", Scopes: []string{model.ScopeTasksWrite}})
+	require.NoError(t, err)
+	b, err := svc.IssueKey(ctx, &model.APIKey{Name: "B", Scopes: []string{model.ScopeAdmin}})
+	require.NoError(t, err)
+
+	keys, err := svc.ListKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	require.NoError(t, svc.RevokeKey(ctx, a.ID))
+	assert.ErrorIs(t, svc.RevokeKey(ctx, a.ID), ErrAPIKeyNotFound)
+	_, err = svc.Authenticate(ctx, a.Key)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "revoked keys stop working")
+	_, err = svc.Authenticate(ctx, b.Key)
+	require.NoError(t, err)
+	keys, err = svc.ListKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, b.ID, keys[0].ID)
+}
+
+func TestAPIKeyService_AdminKey(t *testing.T) {
+	svc := newAPIKeyFixture(t, "bootstrap-secret-that-is-long-enough")
+	key, err := svc.Authenticate(context.Background(), "bootstrap-secret-that-is-long-enough")
+	require.NoError(t, err)
+	assert.Equal(t, AdminKeyID, key.ID)
+	assert.Equal(t, []string{model.ScopeAdmin}, key.Scopes)
+
+	_, err = newAPIKeyFixture(t, "").Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "no admin key, no empty match")
+}
+
+func TestAPIKeyService_ConfinedPrincipal(t *testing.T) {
+	svc := newAPIKeyFixture(t, "")
+	ctx := context.Background()
+	globex, err := svc.IssueKey(ctx, &model.APIKey{Name: "Globex", Scopes: []string{model.ScopeTasksRead}, Tenant: "globex"})
+	require.NoError(t, err)
+	acme := identity.WithPrincipal(ctx, &identity.Principal{Subject: "apikey:a", Tenant: "acme", Scopes: []string{model.ScopeAdmin}})
+
+	_, err = svc.IssueKey(acme, &model.APIKey{Name: "X", Scopes: []string{model.ScopeAdmin}})
+	assert.ErrorIs(t, err, ErrKeyOutsideTenant)
+	_, err = svc.IssueKey(acme, &model.APIKey{Name: "X", Scopes: []string{model.ScopeAdmin}, Tenant: "globex"})
+	assert.ErrorIs(t, err, ErrKeyOutsideTenant)
+	own, err := svc.IssueKey(acme, &model.APIKey{Name: "X", Scopes: []string{model.ScopeAdmin}, Tenant: "acme"})
+	require.NoError(t, err)
+
+	keys, err := svc.ListKeys(acme)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, own.ID, keys[0].ID)
+	assert.ErrorIs(t, svc.RevokeKey(acme, globex.ID), ErrAPIKeyNotFound)
+	assert.ErrorIs(t, svc.RevokeKey(acme, "missing"), ErrAPIKeyNotFound)
+	require.NoError(t, svc.RevokeKey(acme, own.ID))
+	keys, err = svc.ListKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/idgen"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AttachmentService defines the business logic for files attached to tasks.
+// Like comments, attachments are only reachable while their task is live.
+type AttachmentService interface {
+	// AddAttachment stores the content read from r as file filename on taskID,
+	// attached by the actor of ctx. The content type is sniffed from the
+	// content; content over the size limit yields ErrAttachmentTooLarge.
+	AddAttachment(ctx context.Context, taskID, filename string, r io.Reader) (*model.Attachment, error)
+	GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error)
+	// OpenAttachment returns an attachment with a reader for its content, which
+	// the caller must close.
+	OpenAttachment(ctx context.Context, taskID, id string) (*model.Attachment, io.ReadCloser, error)
+	// ListAttachments returns the attachments of taskID, oldest first.
+	ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error)
+	// DeleteAttachment removes an attachment, and its content once no other
+	// attachment shares it.
+	DeleteAttachment(ctx context.Context, taskID, id string) error
+	// SweepBlobs deletes the stored contents that no attachment refers to and
+	// that were last stored longer than grace ago, and returns how many it
+	// deleted. This frees the files of purged tasks.
+	SweepBlobs(ctx context.Context, grace time.Duration) (int, error)
+}
+
+// ErrAttachmentNotFound is returned when an attachment does not exist.
+var ErrAttachmentNotFound = repository.ErrAttachmentNotFound
+
+// ErrAttachmentTooLarge is matched by errors returned for uploads over the
+// size limit.
+var ErrAttachmentTooLarge = repository.ErrBlobTooLarge
+
+// DefaultMaxAttachmentSize is the default size limit of an attachment in bytes.
+const DefaultMaxAttachmentSize = 10 << 20
+
+type attachmentServiceImpl struct {
+	repo    repository.TaskRepository
+	blobs   repository.BlobStore
+	maxSize int64
+	// mu orders recording and deleting attachments against sweeps, so a blob
+	// is never swept while an attachment is being recorded for it.
+	mu     sync.Mutex
+	logger *zap.Logger
+}
+
+// NewAttachmentService creates an AttachmentService that keeps metadata in repo
+// and contents of at most maxSize bytes in blobs.
+func NewAttachmentService(repo repository.TaskRepository, blobs repository.BlobStore, maxSize int64, logger *zap.Logger) AttachmentService {
+	return &attachmentServiceImpl{repo: repo, blobs: blobs, maxSize: maxSize, logger: logger}
+}
+
+func (s *attachmentServiceImpl) AddAttachment(ctx context.Context, taskID, filename string, r io.Reader) (*model.Attachment, error) {
+	if err := model.ValidateFilename(filename); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read upload: %w", err)
+	}
+	contentType := http.DetectContentType(head)
+	blob, err := s.blobs.Put(ctx, br, s.maxSize)
+	if err != nil {
+		s.logger.Warn("failed to store attachment", zap.String("task", taskID), zap.Error(err))
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A sweep may have removed a stale copy that Put found and reused.
+	content, err := s.blobs.Open(ctx, blob.Key)
+	if err != nil {
+		return nil, fmt.Errorf("stored attachment vanished, retry the upload: %w", err)
+	}
+	content.Close()
+	attachment := &model.Attachment{
+		ID:          idgen.GenerateAttachmentID(),
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        blob.Size,
+		SHA256:      blob.Key,
+		Uploader:    identity.Actor(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.CreateAttachment(ctx, attachment); err != nil {
+		s.logger.Warn("failed to create attachment", zap.String("task", taskID), zap.Error(err))
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *attachmentServiceImpl) GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error) {
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAttachment(ctx, taskID, id)
+}
+
+func (s *attachmentServiceImpl) OpenAttachment(ctx context.Context, taskID, id string) (*model.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachment(ctx, taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.blobs.Open(ctx, attachment.SHA256)
+	if err != nil {
+		s.logger.Error("attachment content missing", zap.String("task", taskID), zap.String("id", id), zap.Error(err))
+		return nil, nil, fmt.Errorf("open attachment %s: %w", id, err)
+	}
+	return attachment, content, nil
+}
+
+func (s *attachmentServiceImpl) ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error) {
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAttachments(ctx, taskID)
+}
+
+func (s *attachmentServiceImpl) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	attachment, err := s.GetAttachment(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.repo.DeleteAttachment(ctx, taskID, id); err != nil {
+		s.logger.Warn("failed to delete attachment", zap.String("task", taskID), zap.String("id", id), zap.Error(err))
+		return err
+	}
+	inUse, err := s.repo.ListAttachmentBlobs(ctx)
+	if err != nil {
+		// The next sweep removes the content.
+		s.logger.Warn("failed to check attachment content", zap.String("key", attachment.SHA256), zap.Error(err))
+		return nil
+	}
+	if _, shared := slices.BinarySearch(inUse, attachment.SHA256); !shared {
+		if err := s.blobs.Delete(ctx, attachment.SHA256); err != nil && !errors.Is(err, repository.ErrBlobNotFound) {
+			s.logger.Warn("failed to delete attachment content", zap.String("key", attachment.SHA256), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *attachmentServiceImpl) SweepBlobs(ctx context.Context, grace time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inUse, err := s.repo.ListAttachmentBlobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	blobs, err := s.blobs.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-grace)
+	swept := 0
+	for _, b := range blobs {
+		if _, used := slices.BinarySearch(inUse, b.Key); used || !b.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, b.Key); err != nil && !errors.Is(err, repository.ErrBlobNotFound) {
+			return swept, err
+		}
+		swept++
+	}
+	return swept, nil
+}
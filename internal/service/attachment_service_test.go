@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newAttachmentFixture(t *testing.T, maxSize int64) (AttachmentService, TaskService, repository.BlobStore) {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	blobs, err := repository.NewFileBlobStore(t.TempDir(), zap.NewNop())
+	require.NoError(t, err)
+	tasks := NewTaskService(repo, zap.NewNop())
+	for _, id := range []string{"t", "u"} {
+		_, err := tasks.CreateTask(context.Background(), &model.Task{ID: id, Title: id})
+		require.NoError(t, err)
+	}
+	return NewAttachmentService(repo, blobs, maxSize, zap.NewNop()), tasks, blobs
+}
+
+func blobKeys(t *testing.T, blobs repository.BlobStore) []string {
+	t.Helper()
+	infos, err := blobs.List(context.Background())
+	require.NoError(t, err)
+	keys := []string{}
+	for _, b := range infos {
+		keys = append(keys, b.Key)
+	}
+	return keys
+}
+
+func TestAttachmentService_AddAndOpen(t *testing.T) {
+	attachments, _, _ := newAttachmentFixture(t, 1024)
+	ctx := identity.WithActor(context.Background(), "alice")
+
+	a, err := attachments.AddAttachment(ctx, "t", "notes.txt", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", a.Filename)
+	assert.Equal(t, "text/plain; charset=utf-8", a.ContentType)
+	assert.Equal(t, int64(11), a.Size)
+	assert.Len(t, a.SHA256, 64)
+	assert.Equal(t, "alice", a.Uploader)
+
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16)
+	img, err := attachments.AddAttachment(ctx, "t", "logo.txt", strings.NewReader(png))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.ContentType, "the type is sniffed from the content, not the name")
+
+	got, content, err := attachments.OpenAttachment(ctx, "t", a.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, "hello world", string(data))
+	assert.Equal(t, a.SHA256, got.SHA256)
+
+	list, err := attachments.ListAttachments(ctx, "t")
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestAttachmentService_Rejects(t *testing.T) {
+	attachments, _, blobs := newAttachmentFixture(t, 8)
+	ctx := context.Background()
+
+	_, err := attachments.AddAttachment(ctx, "t", "big.log", strings.NewReader("123456789"))
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	_, err = attachments.AddAttachment(ctx, "t", "../etc/passwd", strings.NewReader("x"))
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = attachments.AddAttachment(ctx, "missing", "a.txt", strings.NewReader("x"))
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	assert.Empty(t, blobKeys(t, blobs))
+	_, err = attachments.GetAttachment(ctx, "t", "missing")
+	assert.ErrorIs(t, err, ErrAttachmentNotFound)
+}
+
+func TestAttachmentService_DeduplicatesAndDeletes(t *testing.T) {
+	attachments, _, blobs := newAttachmentFixture(t, 1024)
+	ctx := context.Background()
+
+	a, err := attachments.AddAttachment(ctx, "t", "one.txt", strings.NewReader("same"))
+	require.NoError(t, err)
+	b, err := attachments.AddAttachment(ctx, "u", "two.txt", strings.NewReader("same"))
+	require.NoError(t, err)
+	assert.Equal(t, a.SHA256, b.SHA256)
+	assert.Equal(t, []string{a.SHA256}, blobKeys(t, blobs), "identical content is stored once")
+
+	require.NoError(t, attachments.DeleteAttachment(ctx, "t", a.ID))
+	assert.Equal(t, []string{a.SHA256}, blobKeys(t, blobs), "shared content stays")
+	require.NoError(t, attachments.DeleteAttachment(ctx, "u", b.ID))
+	assert.Empty(t, blobKeys(t, blobs))
+	assert.ErrorIs(t, attachments.DeleteAttachment(ctx, "u", b.ID), ErrAttachmentNotFound)
+}
+
+func TestAttachmentService_SweepAfterPurge(t *testing.T) {
+	attachments, tasks, blobs := newAttachmentFixture(t, 1024)
+	ctx := context.Background()
+	_, err := attachments.AddAttachment(ctx, "t", "gone.txt", strings.NewReader("gone"))
+	require.NoError(t, err)
+	kept, err := attachments.AddAttachment(ctx, "u", "kept.txt", strings.NewReader("kept"))
+	require.NoError(t, err)
+
+	require.NoError(t, tasks.DeleteTask(ctx, "t", 0))
+	_, err = attachments.ListAttachments(ctx, "t")
+	assert.ErrorIs(t, err, ErrTaskNotFound, "attachments of trashed tasks are hidden")
+	n, err := attachments.SweepBlobs(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, n, "trashed tasks keep their files")
+
+	require.NoError(t, tasks.PurgeTask(ctx, "t", 0))
+	n, err = attachments.SweepBlobs(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, n, "recently stored contents are left alone")
+	n, err = attachments.SweepBlobs(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{kept.SHA256}, blobKeys(t, blobs))
+}
+
+func TestBlobSweeper_Sweep(t *testing.T) {
+	attachments, _, blobs := newAttachmentFixture(t, 1024)
+	ctx := context.Background()
+	_, err := blobs.Put(ctx, strings.NewReader("orphan"), 1024)
+	require.NoError(t, err)
+
+	sweeper := NewBlobSweeper(attachments, time.Nanosecond, zap.NewNop())
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, 1, sweeper.Sweep(ctx))
+	assert.Empty(t, blobKeys(t, blobs))
+}
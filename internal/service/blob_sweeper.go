@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BlobSweeper deletes stored attachment contents that no attachment refers to
+// any more, such as the files of purged tasks, checking at a fixed interval.
+type BlobSweeper struct {
+	attachments AttachmentService
+	interval    time.Duration
+	logger      *zap.Logger
+}
+
+// NewBlobSweeper creates a BlobSweeper that sweeps every interval. Contents
+// stored less than an interval ago are left alone, so uploads in progress are
+// never swept. interval must be positive.
+func NewBlobSweeper(attachments AttachmentService, interval time.Duration, logger *zap.Logger) *BlobSweeper {
+	return &BlobSweeper{attachments: attachments, interval: interval, logger: logger}
+}
+
+// Run sweeps once immediately and then every interval until ctx is done.
+// Failures are logged and retried at the next interval.
+func (s *BlobSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.Sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes the orphaned contents once and returns how many it deleted.
+func (s *BlobSweeper) Sweep(ctx context.Context) int {
+	n, err := s.attachments.SweepBlobs(ctx, s.interval)
+	if err != nil {
+		s.logger.Error("failed to sweep attachment blobs", zap.Int("swept", n), zap.Error(err))
+	} else if n > 0 {
+		s.logger.Info("swept attachment blobs", zap.Int("swept", n))
+	}
+	return n
+}
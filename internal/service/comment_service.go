@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/idgen"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CommentService defines the business logic for the discussion of tasks.
+// Comments can only be read and written while their task is live; they come
+// back with it from the trash.
+type CommentService interface {
+	// AddComment posts a comment with body on taskID, written by the actor of ctx.
+	AddComment(ctx context.Context, taskID, body string) (*model.Comment, error)
+	GetComment(ctx context.Context, taskID, id string) (*model.Comment, error)
+	// EditComment replaces the body of a comment and marks it as edited. Saving
+	// the same body again is not an edit.
+	EditComment(ctx context.Context, taskID, id, body string) (*model.Comment, error)
+	DeleteComment(ctx context.Context, taskID, id string) error
+	// ListComments returns a page of the comments on q.TaskID, oldest first. A
+	// zero q.Limit selects DefaultPageSize; larger limits are capped at MaxPageSize.
+	ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error)
+}
+
+// ErrCommentNotFound is returned when a comment does not exist.
+var ErrCommentNotFound = repository.ErrCommentNotFound
+
+type commentServiceImpl struct {
+	repo   repository.TaskRepository
+	logger *zap.Logger
+}
+
+// NewCommentService creates a CommentService on repo.
+func NewCommentService(repo repository.TaskRepository, logger *zap.Logger) CommentService {
+	return &commentServiceImpl{repo: repo, logger: logger}
+}
+
+func (s *commentServiceImpl) AddComment(ctx context.Context, taskID, body string) (*model.Comment, error) {
+	now := time.Now().UTC()
+	comment := &model.Comment{
+		ID:        idgen.GenerateCommentID(),
+		TaskID:    taskID,
+		Author:    identity.Actor(ctx),
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateComment(ctx, comment); err != nil {
+		s.logger.Warn("failed to create comment", zap.String("task", taskID), zap.Error(err))
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *commentServiceImpl) GetComment(ctx context.Context, taskID, id string) (*model.Comment, error) {
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetComment(ctx, taskID, id)
+}
+
+func (s *commentServiceImpl) EditComment(ctx context.Context, taskID, id, body string) (*model.Comment, error) {
+	comment, err := s.GetComment(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	if body == comment.Body {
+		return comment, nil
+	}
+	comment.Body = body
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+	comment.Edited = true
+	comment.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateComment(ctx, comment); err != nil {
+		s.logger.Warn("failed to update comment", zap.String("task", taskID), zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *commentServiceImpl) DeleteComment(ctx context.Context, taskID, id string) error {
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteComment(ctx, taskID, id); err != nil {
+		s.logger.Warn("failed to delete comment", zap.String("task", taskID), zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *commentServiceImpl) ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error) {
+	if q.Limit < 0 {
+		return nil, apperror.InvalidField("limit", "limit must not be negative")
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	q.Limit = min(q.Limit, MaxPageSize)
+	if _, err := s.repo.GetTask(ctx, q.TaskID); err != nil {
+		return nil, err
+	}
+	page, err := s.repo.ListComments(ctx, q)
+	if err != nil {
+		s.logger.Error("failed to list comments", zap.String("task", q.TaskID), zap.Error(err))
+		return nil, err
+	}
+	return page, nil
+}
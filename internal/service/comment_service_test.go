@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newCommentFixture(t *testing.T) (CommentService, TaskService) {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	tasks := NewTaskService(repo, zap.NewNop())
+	_, err := tasks.CreateTask(context.Background(), &model.Task{ID: "t", Title: "Discussed"})
+	require.NoError(t, err)
+	return NewCommentService(repo, zap.NewNop()), tasks
+}
+
+func TestCommentService_AddAndEdit(t *testing.T) {
+	comments, _ := newCommentFixture(t)
+	ctx := identity.WithActor(context.Background(), "alice")
+
+	c, err := comments.AddComment(ctx, "t", "Looks good")
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.ID)
+	assert.Equal(t, "alice", c.Author)
+	assert.False(t, c.Edited)
+
+	_, err = comments.AddComment(ctx, "t", "  ")
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = comments.AddComment(ctx, "t", strings.Repeat("x", model.MaxCommentLength+1))
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = comments.AddComment(ctx, "missing", "Hello")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	same, err := comments.EditComment(ctx, "t", c.ID, "Looks good")
+	require.NoError(t, err)
+	assert.False(t, same.Edited, "an unchanged body is not an edit")
+	edited, err := comments.EditComment(ctx, "t", c.ID, "Looks great")
+	require.NoError(t, err)
+	assert.True(t, edited.Edited)
+	assert.Equal(t, "alice", edited.Author)
+	assert.False(t, edited.UpdatedAt.Before(c.UpdatedAt))
+	_, err = comments.EditComment(ctx, "t", c.ID, "")
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = comments.EditComment(ctx, "t", "missing", "Hello")
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+
+	got, err := comments.GetComment(ctx, "t", c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Looks great", got.Body)
+
+	require.NoError(t, comments.DeleteComment(ctx, "t", c.ID))
+	assert.ErrorIs(t, comments.DeleteComment(ctx, "t", c.ID), ErrCommentNotFound)
+}
+
+func TestCommentService_ListComments(t *testing.T) {
+	comments, _ := newCommentFixture(t)
+	ctx := context.Background()
+	for range 3 {
+		_, err := comments.AddComment(ctx, "t", "Hello")
+		require.NoError(t, err)
+	}
+
+	page, err := comments.ListComments(ctx, model.CommentQuery{TaskID: "t", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Comments, 2)
+	require.NotNil(t, page.Next)
+	page, err = comments.ListComments(ctx, model.CommentQuery{TaskID: "t", After: page.Next})
+	require.NoError(t, err)
+	assert.Len(t, page.Comments, 1)
+	assert.Nil(t, page.Next)
+
+	_, err = comments.ListComments(ctx, model.CommentQuery{TaskID: "t", Limit: -1})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = comments.ListComments(ctx, model.CommentQuery{TaskID: "missing"})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestCommentService_FollowTaskThroughTrash(t *testing.T) {
+	comments, tasks := newCommentFixture(t)
+	ctx := context.Background()
+	c, err := comments.AddComment(ctx, "t", "Hello")
+	require.NoError(t, err)
+
+	require.NoError(t, tasks.DeleteTask(ctx, "t", 0))
+	_, err = comments.GetComment(ctx, "t", c.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound, "comments of trashed tasks are hidden")
+	_, err = comments.AddComment(ctx, "t", "Hello?")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	_, err = tasks.UndeleteTask(ctx, "t", 0)
+	require.NoError(t, err)
+	_, err = comments.GetComment(ctx, "t", c.ID)
+	require.NoError(t, err, "comments come back with their task")
+
+	require.NoError(t, tasks.DeleteTask(ctx, "t", 0))
+	require.NoError(t, tasks.PurgeTask(ctx, "t", 0))
+	_, err = tasks.CreateTask(ctx, &model.Task{ID: "t", Title: "Reused"})
+	require.NoError(t, err)
+	page, err := comments.ListComments(ctx, model.CommentQuery{TaskID: "t"})
+	require.NoError(t, err)
+	assert.Empty(t, page.Comments, "purging a task deletes its comments")
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DependencyService defines the business logic for "blocked by" relationships
+// between tasks. Refusing to complete a blocked task is part of TaskService.
+type DependencyService interface {
+	// AddDependency makes taskID blocked by blockerID. created is false if the
+	// dependency already existed, in which case the stored one is returned.
+	// A dependency that would close a cycle yields ErrDependencyCycle.
+	AddDependency(ctx context.Context, taskID, blockerID string) (dep *model.Dependency, created bool, err error)
+	// RemoveDependency deletes the dependency of taskID on blockerID.
+	RemoveDependency(ctx context.Context, taskID, blockerID string) error
+	// ListBlockers returns the tasks blocking taskID, ordered by ID.
+	ListBlockers(ctx context.Context, taskID string) ([]*model.Task, error)
+	// ListDependents returns the tasks blocked by taskID, ordered by ID.
+	ListDependents(ctx context.Context, taskID string) ([]*model.Task, error)
+	// TopologicalOrder returns the incomplete tasks ordered so that each comes
+	// after its incomplete blockers, older tasks first where there is a choice.
+	TopologicalOrder(ctx context.Context) ([]*model.Task, error)
+	// UnblockedTasks returns the incomplete tasks whose blockers are all
+	// completed, oldest first.
+	UnblockedTasks(ctx context.Context) ([]*model.Task, error)
+	// CriticalPath returns the chain of incomplete dependent tasks with the
+	// largest total estimate.
+	CriticalPath(ctx context.Context) (*model.CriticalPath, error)
+}
+
+// ErrDependencyCycle is matched by errors returned when a dependency would
+// close a cycle.
+var ErrDependencyCycle = repository.ErrDependencyCycle
+
+// ErrDependencyNotFound is returned when removing a dependency that does not exist.
+var ErrDependencyNotFound = repository.ErrDependencyNotFound
+
+// ErrTaskBlocked is matched by errors returned when completing a task whose
+// blockers are still open.
+var ErrTaskBlocked = apperror.Conflict("task is blocked")
+
+type dependencyServiceImpl struct {
+	repo   repository.TaskRepository
+	logger *zap.Logger
+}
+
+// NewDependencyService creates a DependencyService on repo.
+func NewDependencyService(repo repository.TaskRepository, logger *zap.Logger) DependencyService {
+	return &dependencyServiceImpl{repo: repo, logger: logger}
+}
+
+func (s *dependencyServiceImpl) AddDependency(ctx context.Context, taskID, blockerID string) (*model.Dependency, bool, error) {
+	dep := &model.Dependency{TaskID: taskID, BlockerID: blockerID, CreatedAt: time.Now().UTC()}
+	if err := dep.Validate(); err != nil {
+		return nil, false, err
+	}
+	task, err := s.repo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, false, err
+	}
+	blocker, err := s.repo.GetTask(ctx, blockerID)
+	if err != nil {
+		return nil, false, err
+	}
+	if task.Completed && !blocker.Completed {
+		return nil, false, apperror.Conflict("a completed task cannot be blocked by an open task")
+	}
+	err = s.repo.AddDependency(ctx, dep)
+	if errors.Is(err, repository.ErrDependencyAlreadyExists) {
+		blockers, err := s.repo.ListBlockers(ctx, taskID)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, d := range blockers {
+			if d.BlockerID == blockerID {
+				return d, false, nil
+			}
+		}
+		// Removed in the meantime; report the conflict as is.
+		return nil, false, repository.ErrDependencyAlreadyExists
+	}
+	if err != nil {
+		s.logger.Warn("failed to add dependency", zap.String("task", taskID), zap.String("blocker", blockerID), zap.Error(err))
+		return nil, false, err
+	}
+	return dep, true, nil
+}
+
+func (s *dependencyServiceImpl) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	if err := s.repo.RemoveDependency(ctx, taskID, blockerID); err != nil {
+		s.logger.Warn("failed to remove dependency", zap.String("task", taskID), zap.String("blocker", blockerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *dependencyServiceImpl) ListBlockers(ctx context.Context, taskID string) ([]*model.Task, error) {
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	deps, err := s.repo.ListBlockers(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(ctx, deps, func(d *model.Dependency) string { return d.BlockerID })
+}
+
+func (s *dependencyServiceImpl) ListDependents(ctx context.Context, taskID string) ([]*model.Task, error) {
+	if _, err := s.repo.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	deps, err := s.repo.ListDependents(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks(ctx, deps, func(d *model.Dependency) string { return d.TaskID })
+}
+
+// tasks loads the task at the end of each dependency selected by end. Tasks
+// deleted since the dependencies were listed are skipped.
+func (s *dependencyServiceImpl) tasks(ctx context.Context, deps []*model.Dependency, end func(*model.Dependency) string) ([]*model.Task, error) {
+	tasks := make([]*model.Task, 0, len(deps))
+	for _, d := range deps {
+		task, err := s.repo.GetTask(ctx, end(d))
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *dependencyServiceImpl) TopologicalOrder(ctx context.Context) ([]*model.Task, error) {
+	g, err := s.openGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.TopologicalOrder()
+}
+
+func (s *dependencyServiceImpl) UnblockedTasks(ctx context.Context) ([]*model.Task, error) {
+	g, err := s.openGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.Unblocked(), nil
+}
+
+func (s *dependencyServiceImpl) CriticalPath(ctx context.Context) (*model.CriticalPath, error) {
+	g, err := s.openGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.CriticalPath()
+}
+
+// openGraph builds the dependency graph of the incomplete tasks; completed
+// blockers no longer hold anything up.
+func (s *dependencyServiceImpl) openGraph(ctx context.Context) (*model.DependencyGraph, error) {
+	incomplete := false
+	page, err := s.repo.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{Completed: &incomplete}})
+	if err != nil {
+		s.logger.Error("failed to load tasks for dependency graph", zap.Error(err))
+		return nil, err
+	}
+	deps, err := s.repo.ListDependencies(ctx)
+	if err != nil {
+		s.logger.Error("failed to load dependencies", zap.Error(err))
+		return nil, err
+	}
+	return model.NewDependencyGraph(page.Tasks, deps), nil
+}
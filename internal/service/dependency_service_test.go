@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newDependencyFixture creates tasks a to e, one minute apart, with the given
+// estimates in minutes.
+func newDependencyFixture(t *testing.T, estimates map[string]int, opts ...Option) (DependencyService, TaskService) {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	clock := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	opts = append(opts, WithClock(func() time.Time { return clock }))
+	tasks := NewTaskService(repo, zap.NewNop(), opts...)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		_, err := tasks.CreateTask(context.Background(), &model.Task{ID: id, Title: id, EstimateMinutes: estimates[id]})
+		require.NoError(t, err)
+		clock = clock.Add(time.Minute)
+	}
+	return NewDependencyService(repo, zap.NewNop()), tasks
+}
+
+func addDependencies(t *testing.T, deps DependencyService, pairs ...[2]string) {
+	t.Helper()
+	for _, p := range pairs {
+		_, created, err := deps.AddDependency(context.Background(), p[0], p[1])
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+}
+
+func TestDependencyService_AddDependency(t *testing.T) {
+	deps, _ := newDependencyFixture(t, nil)
+	ctx := context.Background()
+	addDependencies(t, deps, [2]string{"b", "a"}, [2]string{"c", "b"})
+
+	dep, created, err := deps.AddDependency(ctx, "b", "a")
+	require.NoError(t, err)
+	assert.False(t, created, "adding twice is idempotent")
+	assert.Equal(t, "a", dep.BlockerID)
+
+	_, _, err = deps.AddDependency(ctx, "a", "c")
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	_, _, err = deps.AddDependency(ctx, "a", "a")
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, _, err = deps.AddDependency(ctx, "a", "missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	blockers, err := deps.ListBlockers(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, taskIDs(blockers))
+	dependents, err := deps.ListDependents(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, taskIDs(dependents))
+	_, err = deps.ListBlockers(ctx, "missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	require.NoError(t, deps.RemoveDependency(ctx, "c", "b"))
+	assert.ErrorIs(t, deps.RemoveDependency(ctx, "c", "b"), ErrDependencyNotFound)
+}
+
+func TestDependencyService_CompletedTaskCannotGainOpenBlocker(t *testing.T) {
+	deps, tasks := newDependencyFixture(t, nil)
+	ctx := context.Background()
+	done := true
+	_, err := tasks.PatchTask(ctx, "a", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+
+	_, _, err = deps.AddDependency(ctx, "a", "b")
+	assert.Equal(t, apperror.KindConflict, apperror.KindOf(err))
+	// A completed blocker is fine.
+	addDependencies(t, deps, [2]string{"b", "a"})
+}
+
+func TestDependencyService_BlockersPreventCompletion(t *testing.T) {
+	deps, tasks := newDependencyFixture(t, nil)
+	ctx := context.Background()
+	addDependencies(t, deps, [2]string{"c", "a"}, [2]string{"c", "b"})
+	done := true
+
+	_, err := tasks.PatchTask(ctx, "c", &model.TaskPatch{Completed: &done}, 0)
+	assert.ErrorIs(t, err, ErrTaskBlocked)
+	assert.ErrorContains(t, err, "waiting for a, b")
+
+	for _, id := range []string{"a", "b"} {
+		_, err = tasks.PatchTask(ctx, id, &model.TaskPatch{Completed: &done}, 0)
+		require.NoError(t, err)
+	}
+	_, err = tasks.PatchTask(ctx, "c", &model.TaskPatch{Completed: &done}, 0)
+	assert.NoError(t, err)
+}
+
+func TestDependencyService_CascadeCompletionChecksSubtaskBlockers(t *testing.T) {
+	deps, tasks := newDependencyFixture(t, nil, WithCascadePolicy(model.CascadeCascade, model.CascadeBlock))
+	ctx := context.Background()
+	for _, child := range []string{"b", "c"} {
+		parent := "a"
+		_, err := tasks.PatchTask(ctx, child, &model.TaskPatch{ParentID: &parent}, 0)
+		require.NoError(t, err)
+	}
+	// Blockers completed along with the parent do not count.
+	addDependencies(t, deps, [2]string{"c", "b"}, [2]string{"b", "e"})
+	done := true
+
+	_, err := tasks.PatchTask(ctx, "a", &model.TaskPatch{Completed: &done}, 0)
+	assert.ErrorIs(t, err, ErrTaskBlocked)
+	assert.ErrorContains(t, err, "waiting for e")
+
+	_, err = tasks.PatchTask(ctx, "e", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	_, err = tasks.PatchTask(ctx, "a", &model.TaskPatch{Completed: &done}, 0)
+	assert.NoError(t, err)
+}
+
+func TestDependencyService_GraphViews(t *testing.T) {
+	deps, tasks := newDependencyFixture(t, map[string]int{"a": 60, "b": 30, "c": 120, "d": 15})
+	ctx := context.Background()
+	// a -> b -> d and c -> d; e is independent.
+	addDependencies(t, deps, [2]string{"b", "a"}, [2]string{"d", "b"}, [2]string{"d", "c"})
+
+	order, err := deps.TopologicalOrder(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, taskIDs(order))
+
+	unblocked, err := deps.UnblockedTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c", "e"}, taskIDs(unblocked))
+
+	path, err := deps.CriticalPath(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 135, path.EstimateMinutes)
+	assert.Equal(t, []string{"c", "d"}, taskIDs(path.Tasks))
+
+	// Completed tasks drop out of every view.
+	done := true
+	_, err = tasks.PatchTask(ctx, "c", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	path, err = deps.CriticalPath(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 105, path.EstimateMinutes)
+	assert.Equal(t, []string{"a", "b", "d"}, taskIDs(path.Tasks))
+	unblocked, err = deps.UnblockedTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "e"}, taskIDs(unblocked))
+}
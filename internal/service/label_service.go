@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LabelService defines the business logic for managing labels. Attaching
+// labels to tasks is part of TaskService.
+type LabelService interface {
+	// CreateLabel validates and stores a new label, defaulting its color to
+	// model.DefaultLabelColor.
+	CreateLabel(ctx context.Context, label *model.Label) (*model.Label, error)
+	GetLabel(ctx context.Context, name string) (*model.Label, error)
+	// ListLabels returns all labels ordered by name.
+	ListLabels(ctx context.Context) ([]*model.Label, error)
+	// UpdateLabelColor changes the color of an existing label.
+	UpdateLabelColor(ctx context.Context, name, color string) (*model.Label, error)
+	// DeleteLabel deletes the label and detaches it from all tasks.
+	DeleteLabel(ctx context.Context, name string) error
+}
+
+// ErrLabelNotFound is returned when a label does not exist.
+var ErrLabelNotFound = repository.ErrLabelNotFound
+
+type labelServiceImpl struct {
+	repo   repository.LabelRepository
+	logger *zap.Logger
+}
+
+// NewLabelService creates a LabelService on repo.
+func NewLabelService(repo repository.LabelRepository, logger *zap.Logger) LabelService {
+	return &labelServiceImpl{repo: repo, logger: logger}
+}
+
+func (s *labelServiceImpl) CreateLabel(ctx context.Context, label *model.Label) (*model.Label, error) {
+	if label.Color == "" {
+		label.Color = model.DefaultLabelColor
+	}
+	if err := label.Validate(); err != nil {
+		s.logger.Warn("label validation failed", zap.Error(err))
+		return nil, err
+	}
+	now := time.Now().UTC()
+	label.CreatedAt = now
+	label.UpdatedAt = now
+	if err := s.repo.CreateLabel(ctx, label); err != nil {
+		s.logger.Warn("failed to create label", zap.String("name", label.Name), zap.Error(err))
+		return nil, err
+	}
+	return label, nil
+}
+
+func (s *labelServiceImpl) GetLabel(ctx context.Context, name string) (*model.Label, error) {
+	return s.repo.GetLabel(ctx, name)
+}
+
+func (s *labelServiceImpl) ListLabels(ctx context.Context) ([]*model.Label, error) {
+	labels, err := s.repo.ListLabels(ctx)
+	if err != nil {
+		s.logger.Error("failed to list labels", zap.Error(err))
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (s *labelServiceImpl) UpdateLabelColor(ctx context.Context, name, color string) (*model.Label, error) {
+	label := &model.Label{Name: name, Color: color, UpdatedAt: time.Now().UTC()}
+	if err := label.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateLabel(ctx, label); err != nil {
+		s.logger.Warn("failed to update label", zap.String("name", name), zap.Error(err))
+		return nil, err
+	}
+	return label, nil
+}
+
+func (s *labelServiceImpl) DeleteLabel(ctx context.Context, name string) error {
+	if err := s.repo.DeleteLabel(ctx, name); err != nil {
+		s.logger.Warn("failed to delete label", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newLabelFixture(t *testing.T) (LabelService, TaskService) {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	return NewLabelService(repo, zap.NewNop()), NewTaskService(repo, zap.NewNop())
+}
+
+func TestLabelService_CreateDefaultsColor(t *testing.T) {
+	labels, _ := newLabelFixture(t)
+	created, err := labels.CreateLabel(context.Background(), &model.Label{Name: "backend"})
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultLabelColor, created.Color)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	_, err = labels.CreateLabel(context.Background(), &model.Label{Name: "backend"})
+	assert.ErrorIs(t, err, repository.ErrLabelAlreadyExists)
+	_, err = labels.CreateLabel(context.Background(), &model.Label{Name: "bad;name", Color: "blue"})
+	assert.Len(t, apperror.FieldsOf(err), 2)
+}
+
+func TestLabelService_UpdateLabelColor(t *testing.T) {
+	labels, _ := newLabelFixture(t)
+	ctx := context.Background()
+	created, err := labels.CreateLabel(ctx, &model.Label{Name: "backend"})
+	require.NoError(t, err)
+
+	updated, err := labels.UpdateLabelColor(ctx, "backend", "#112233")
+	require.NoError(t, err)
+	assert.Equal(t, "#112233", updated.Color)
+	assert.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+
+	_, err = labels.UpdateLabelColor(ctx, "backend", "#12")
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = labels.UpdateLabelColor(ctx, "missing", "#112233")
+	assert.ErrorIs(t, err, ErrLabelNotFound)
+}
+
+func TestTaskService_AttachAndDetachLabel(t *testing.T) {
+	labels, tasks := newLabelFixture(t)
+	ctx := context.Background()
+	for _, name := range []string{"urgent", "backend"} {
+		_, err := labels.CreateLabel(ctx, &model.Label{Name: name})
+		require.NoError(t, err)
+	}
+	task, err := tasks.CreateTask(ctx, &model.Task{Title: "Task", Labels: []string{"urgent", "urgent"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, task.Labels, "labels are normalized on create")
+
+	task, err = tasks.AttachLabel(ctx, task.ID, "backend", task.Version)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "urgent"}, task.Labels)
+	assert.Equal(t, int64(2), task.Version)
+
+	again, err := tasks.AttachLabel(ctx, task.ID, "backend", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), again.Version, "attaching an attached label is a no-op")
+
+	_, err = tasks.AttachLabel(ctx, task.ID, "missing", 0)
+	assert.ErrorIs(t, err, ErrLabelNotFound)
+	_, err = tasks.AttachLabel(ctx, task.ID, "urgent", 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	task, err = tasks.DetachLabel(ctx, task.ID, "urgent", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, task.Labels)
+	task, err = tasks.DetachLabel(ctx, task.ID, "urgent", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), task.Version, "detaching a missing label is a no-op")
+
+	// A full replacement keeps the labels.
+	task, err = tasks.UpdateTask(ctx, task.ID, &model.Task{Title: "Renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, task.Labels)
+}
+
+func TestTaskService_CreateTask_UnknownLabel(t *testing.T) {
+	_, tasks := newLabelFixture(t)
+	_, err := tasks.CreateTask(context.Background(), &model.Task{Title: "Task", Labels: []string{"nope"}})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	assert.Equal(t, "labels", apperror.FieldsOf(err)[0].Field)
+	assert.ErrorContains(t, err, `"nope"`)
+}
+
+func TestLabelService_DeleteDetachesFromTasks(t *testing.T) {
+	labels, tasks := newLabelFixture(t)
+	ctx := context.Background()
+	_, err := labels.CreateLabel(ctx, &model.Label{Name: "backend"})
+	require.NoError(t, err)
+	task, err := tasks.CreateTask(ctx, &model.Task{Title: "Task", Labels: []string{"backend"}})
+	require.NoError(t, err)
+
+	require.NoError(t, labels.DeleteLabel(ctx, "backend"))
+	got, err := tasks.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Labels)
+	assert.ErrorIs(t, labels.DeleteLabel(ctx, "backend"), ErrLabelNotFound)
+}
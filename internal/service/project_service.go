@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ProjectService defines the business logic for managing projects. Their
+// tasks are managed by TaskService in the scope of InProject.
+type ProjectService interface {
+	// CreateProject validates and stores a new project. The labels its
+	// settings allow must exist and its ID prefix must be unused.
+	CreateProject(ctx context.Context, project *model.Project) (*model.Project, error)
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	// ListProjects returns all projects ordered by ID.
+	ListProjects(ctx context.Context) ([]*model.Project, error)
+	// UpdateProject applies patch to an existing project, checking its settings
+	// like CreateProject. New settings only apply to later task changes.
+	UpdateProject(ctx context.Context, id string, patch *model.ProjectPatch) (*model.Project, error)
+	// DeleteProject deletes a project without tasks, otherwise it returns an
+	// error matching ErrProjectNotEmpty.
+	DeleteProject(ctx context.Context, id string) error
+}
+
+// ErrProjectNotFound is returned when a project does not exist.
+var ErrProjectNotFound = repository.ErrProjectNotFound
+
+// ErrProjectNotEmpty is matched by errors returned when deleting a project
+// that still has tasks.
+var ErrProjectNotEmpty = repository.ErrProjectNotEmpty
+
+type projectServiceImpl struct {
+	repo   repository.TaskRepository
+	logger *zap.Logger
+	// settings serializes writes, so no two projects take the same ID prefix.
+	settings sync.Mutex
+}
+
+// NewProjectService creates a ProjectService on repo.
+func NewProjectService(repo repository.TaskRepository, logger *zap.Logger) ProjectService {
+	return &projectServiceImpl{repo: repo, logger: logger}
+}
+
+func (s *projectServiceImpl) CreateProject(ctx context.Context, project *model.Project) (*model.Project, error) {
+	project.Settings.Labels = model.NormalizeLabels(project.Settings.Labels)
+	if err := project.Validate(); err != nil {
+		s.logger.Warn("project validation failed", zap.Error(err))
+		return nil, err
+	}
+	s.settings.Lock()
+	defer s.settings.Unlock()
+	if err := s.checkSettings(ctx, project); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	project.LastTaskNumber = 0
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	if err := s.repo.CreateProject(ctx, project); err != nil {
+		s.logger.Warn("failed to create project", zap.String("id", project.ID), zap.Error(err))
+		return nil, err
+	}
+	return project, nil
+}
+
+func (s *projectServiceImpl) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	return s.repo.GetProject(ctx, id)
+}
+
+func (s *projectServiceImpl) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	projects, err := s.repo.ListProjects(ctx)
+	if err != nil {
+		s.logger.Error("failed to list projects", zap.Error(err))
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (s *projectServiceImpl) UpdateProject(ctx context.Context, id string, patch *model.ProjectPatch) (*model.Project, error) {
+	s.settings.Lock()
+	defer s.settings.Unlock()
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	patch.Apply(project)
+	project.Settings.Labels = model.NormalizeLabels(project.Settings.Labels)
+	if err := project.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkSettings(ctx, project); err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateProject(ctx, project); err != nil {
+		s.logger.Warn("failed to update project", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return project, nil
+}
+
+func (s *projectServiceImpl) DeleteProject(ctx context.Context, id string) error {
+	if err := s.repo.DeleteProject(ctx, id); err != nil {
+		s.logger.Warn("failed to delete project", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// checkSettings verifies that the labels project allows exist and that no
+// other project uses its ID prefix. Callers hold s.settings.
+func (s *projectServiceImpl) checkSettings(ctx context.Context, project *model.Project) error {
+	for _, name := range project.Settings.Labels {
+		if _, err := s.repo.GetLabel(ctx, name); err != nil {
+			if errors.Is(err, repository.ErrLabelNotFound) {
+				return apperror.InvalidField("settings.labels", fmt.Sprintf("unknown label %q", name))
+			}
+			return err
+		}
+	}
+	if project.Settings.IDPrefix == "" {
+		return nil
+	}
+	projects, err := s.repo.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range projects {
+		if other.ID != project.ID && other.Settings.IDPrefix == project.Settings.IDPrefix {
+			return apperror.InvalidField("settings.id_prefix",
+				fmt.Sprintf("id_prefix %s is used by project %s", project.Settings.IDPrefix, other.ID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newProjectFixture returns services on a project-scoped repository with the
+// labels bug and chore and the projects web, numbering tasks WEB-n and allowing
+// only bug, and ops, with a workflow of its own.
+func newProjectFixture(t *testing.T) (ProjectService, TaskService) {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewProjectScopedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()))
+	for _, name := range []string{"bug", "chore"} {
+		require.NoError(t, repo.CreateLabel(ctx, &model.Label{Name: name, Color: model.DefaultLabelColor}))
+	}
+	projects := NewProjectService(repo, zap.NewNop())
+	_, err := projects.CreateProject(ctx, &model.Project{ID: "web", Name: "Website",
+		Settings: model.ProjectSettings{IDPrefix: "WEB", Labels: []string{"bug"}}})
+	require.NoError(t, err)
+	_, err = projects.CreateProject(ctx, &model.Project{ID: "ops", Name: "Operations",
+		Settings: model.ProjectSettings{Workflow: &model.Workflow{
+			States:      []string{"open", "closed"},
+			Initial:     "open",
+			Done:        "closed",
+			Terminal:    []string{"closed"},
+			Transitions: map[string][]string{"open": {"closed"}, "closed": {"open"}},
+		}}})
+	require.NoError(t, err)
+	return projects, NewTaskService(repo, zap.NewNop())
+}
+
+func TestProjectService_CRUD(t *testing.T) {
+	projects, _ := newProjectFixture(t)
+	ctx := context.Background()
+
+	created, err := projects.CreateProject(ctx, &model.Project{ID: "api", Name: "API", LastTaskNumber: 99,
+		Settings: model.ProjectSettings{Labels: []string{"chore", "bug", "chore"}}})
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Zero(t, created.LastTaskNumber)
+	assert.Equal(t, []string{"bug", "chore"}, created.Settings.Labels)
+	_, err = projects.CreateProject(ctx, &model.Project{ID: "api", Name: "API"})
+	assert.ErrorIs(t, err, repository.ErrProjectAlreadyExists)
+	_, err = projects.CreateProject(ctx, &model.Project{ID: "bad id"})
+	assert.Len(t, apperror.FieldsOf(err), 2)
+
+	list, err := projects.ListProjects(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 3)
+
+	description := "The public API"
+	updated, err := projects.UpdateProject(ctx, "api", &model.ProjectPatch{Description: &description})
+	require.NoError(t, err)
+	assert.Equal(t, "API", updated.Name)
+	assert.Equal(t, description, updated.Description)
+	assert.Equal(t, []string{"bug", "chore"}, updated.Settings.Labels)
+	assert.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+	_, err = projects.UpdateProject(ctx, "missing", &model.ProjectPatch{Description: &description})
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	require.NoError(t, projects.DeleteProject(ctx, "api"))
+	_, err = projects.GetProject(ctx, "api")
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+}
+
+func TestProjectService_Settings(t *testing.T) {
+	projects, _ := newProjectFixture(t)
+	ctx := context.Background()
+
+	_, err := projects.CreateProject(ctx, &model.Project{ID: "api", Name: "API",
+		Settings: model.ProjectSettings{Labels: []string{"unknown"}}})
+	assert.Equal(t, "settings.labels", apperror.FieldsOf(err)[0].Field)
+	_, err = projects.CreateProject(ctx, &model.Project{ID: "api", Name: "API",
+		Settings: model.ProjectSettings{IDPrefix: "WEB"}})
+	assert.Equal(t, "settings.id_prefix", apperror.FieldsOf(err)[0].Field)
+	_, err = projects.UpdateProject(ctx, "ops", &model.ProjectPatch{Settings: &model.ProjectSettings{IDPrefix: "WEB"}})
+	assert.Equal(t, "settings.id_prefix", apperror.FieldsOf(err)[0].Field)
+
+	// A project keeps its own prefix when its other settings change.
+	updated, err := projects.UpdateProject(ctx, "web", &model.ProjectPatch{Settings: &model.ProjectSettings{IDPrefix: "WEB"}})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Settings.Labels)
+}
+
+func TestProjectService_DeleteWithTasks(t *testing.T) {
+	projects, tasks := newProjectFixture(t)
+	ctx := InProject(context.Background(), "web")
+	task, err := tasks.CreateTask(ctx, &model.Task{Title: "Fix header"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, projects.DeleteProject(ctx, "web"), ErrProjectNotEmpty)
+	require.NoError(t, tasks.DeleteTask(ctx, task.ID, 0))
+	assert.ErrorIs(t, projects.DeleteProject(ctx, "web"), ErrProjectNotEmpty, "trashed tasks count")
+	require.NoError(t, tasks.PurgeTask(ctx, task.ID, 0))
+	require.NoError(t, projects.DeleteProject(ctx, "web"))
+}
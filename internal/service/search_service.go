@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/search"
+
+	"go.uber.org/zap"
+)
+
+// Result size bounds for SearchTasks.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// snippetLength is the approximate length of description snippets in bytes.
+const snippetLength = 160
+
+// TaskSearchResult is a task matching a search, with its relevance score and
+// the matching fields highlighted (see search.Highlight).
+type TaskSearchResult struct {
+	Task       *model.Task       `json:"task"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchService defines full-text search over tasks.
+type SearchService interface {
+	// SearchTasks returns the tasks matching query, best first. A zero limit
+	// selects DefaultSearchLimit; larger limits are capped at MaxSearchLimit.
+	SearchTasks(ctx context.Context, query string, limit int) ([]*TaskSearchResult, error)
+}
+
+// searchServiceImpl answers searches from an index and loads the matching tasks
+// from the repository.
+type searchServiceImpl struct {
+	// indexOf returns the index to search in a request's context.
+	indexOf func(ctx context.Context) (*search.Index, error)
+	repo    repository.TaskReader
+	logger  *zap.Logger
+}
+
+// NewSearchService creates a SearchService over index, which must be kept in
+// step with repo (see search.IndexedRepository).
+func NewSearchService(index *search.Index, repo repository.TaskReader, logger *zap.Logger) SearchService {
+	indexOf := func(context.Context) (*search.Index, error) { return index, nil }
+	return &searchServiceImpl{indexOf: indexOf, repo: repo, logger: logger}
+}
+
+// NewTenantSearchService creates a SearchService that searches the index of
+// the request's tenant in indexes, each kept in step with the tenant's tasks in
+// repo (see repository.TenantRepository).
+func NewTenantSearchService(indexes map[string]*search.Index, repo repository.TaskReader, logger *zap.Logger) SearchService {
+	indexOf := func(ctx context.Context) (*search.Index, error) {
+		tenant := identity.Tenant(ctx)
+		if tenant == "" {
+			return nil, repository.ErrNoTenant
+		}
+		index, ok := indexes[tenant]
+		if !ok {
+			return nil, repository.ErrUnknownTenant
+		}
+		return index, nil
+	}
+	return &searchServiceImpl{indexOf: indexOf, repo: repo, logger: logger}
+}
+
+// SearchTasks runs query against the index.
+func (s *searchServiceImpl) SearchTasks(ctx context.Context, query string, limit int) ([]*TaskSearchResult, error) {
+	q := search.ParseQuery(query)
+	if q.Empty() {
+		return nil, apperror.InvalidField("q", "query must contain at least one searchable word")
+	}
+	if limit < 0 {
+		return nil, apperror.InvalidField("limit", "limit must not be negative")
+	}
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+	index, err := s.indexOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*TaskSearchResult{}
+	for _, hit := range index.Search(q, limit) {
+		task, err := s.repo.GetTask(ctx, hit.ID)
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			// Deleted between the search and the lookup, or in another project.
+			continue
+		}
+		if err != nil {
+			s.logger.Error("failed to load search hit", zap.String("id", hit.ID), zap.Error(err))
+			return nil, err
+		}
+		highlights := map[string]string{}
+		if title, ok := search.Highlight(task.Title, q); ok {
+			highlights["title"] = title
+		}
+		if snippet, ok := search.Snippet(task.Description, q, snippetLength); ok {
+			highlights["description"] = snippet
+		}
+		results = append(results, &TaskSearchResult{Task: task, Score: hit.Score, Highlights: highlights})
+	}
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"taskmanager/internal/search"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newSearchFixture(t *testing.T) (SearchService, *search.IndexedRepository) {
+	t.Helper()
+	ctx := context.Background()
+	repo := search.NewIndexedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()), search.NewIndex())
+	for _, task := range []*model.Task{
+		{ID: "1", Title: "Write quarterly report", Description: "Summarise the numbers"},
+		{ID: "2", Title: "Fix login bug", Description: "Users report that logging in fails"},
+		{ID: "3", Title: "Plan offsite"},
+	} {
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+	return NewSearchService(repo.Index(), repo, zap.NewNop()), repo
+}
+
+func TestSearchService_SearchTasks(t *testing.T) {
+	svc, _ := newSearchFixture(t)
+	results, err := svc.SearchTasks(context.Background(), "reports", 0)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "1", results[0].Task.ID)
+	assert.Equal(t, "Write quarterly <mark>report</mark>", results[0].Highlights["title"])
+	assert.NotContains(t, results[0].Highlights, "description")
+
+	assert.Equal(t, "2", results[1].Task.ID)
+	assert.Equal(t, "Users <mark>report</mark> that logging in fails", results[1].Highlights["description"])
+	assert.NotContains(t, results[1].Highlights, "title")
+	assert.Greater(t, results[0].Score, results[1].Score)
+}
+
+func TestSearchService_Limit(t *testing.T) {
+	svc, _ := newSearchFixture(t)
+	results, err := svc.SearchTasks(context.Background(), "report", 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestSearchService_InvalidInput(t *testing.T) {
+	svc, _ := newSearchFixture(t)
+	_, err := svc.SearchTasks(context.Background(), "the", 0)
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = svc.SearchTasks(context.Background(), "report", -1)
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+}
+
+func TestSearchService_NoResults(t *testing.T) {
+	svc, _ := newSearchFixture(t)
+	results, err := svc.SearchTasks(context.Background(), "nothing", 0)
+	require.NoError(t, err)
+	assert.NotNil(t, results)
+	assert.Empty(t, results)
+}
+
+func TestSearchService_Tenants(t *testing.T) {
+	indexes := map[string]*search.Index{}
+	repos := map[string]repository.TaskRepository{}
+	for _, tenant := range []string{"acme", "globex"} {
+		indexed := search.NewIndexedRepository(repository.NewInMemoryTaskRepository(zap.NewNop()), search.NewIndex())
+		indexes[tenant], repos[tenant] = indexed.Index(), indexed
+	}
+	repo := repository.NewTenantRepository(repos)
+	svc := NewTenantSearchService(indexes, repo, zap.NewNop())
+	acme := identity.WithTenant(context.Background(), "acme")
+	globex := identity.WithTenant(context.Background(), "globex")
+	require.NoError(t, repo.CreateTask(acme, &model.Task{ID: "1", Title: "Merger report"}))
+	require.NoError(t, repo.CreateTask(globex, &model.Task{ID: "1", Title: "Quarterly report"}))
+
+	results, err := svc.SearchTasks(acme, "report", 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Merger report", results[0].Task.Title)
+	results, err = svc.SearchTasks(globex, "merger", 0)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	_, err = svc.SearchTasks(context.Background(), "report", 0)
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ErrReassignForbidden is matched by errors returned when an actor who is
+// neither the assignee nor the creator of a task tries to reassign it.
+var ErrReassignForbidden = apperror.Forbidden("only the assignee or creator of a task can reassign it")
+
+// ErrReassignCompleted is matched by errors returned when reassigning a task
+// that is and stays completed.
+var ErrReassignCompleted = apperror.Conflict("completed tasks cannot be reassigned")
+
+// checkAssigneeExists rejects an assignee that is not a known user as invalid
+// input. An empty assignee leaves the task unassigned.
+func (s *taskServiceImpl) checkAssigneeExists(ctx context.Context, task *model.Task) error {
+	if task.Assignee == "" {
+		return nil
+	}
+	if _, err := s.repo.GetUser(ctx, task.Assignee); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return apperror.InvalidField("assignee", "unknown user "+task.Assignee)
+		}
+		s.logger.Error("failed to look up assignee", zap.String("user", task.Assignee), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// checkReassign enforces the reassignment rules when an update changes the
+// assignee of before to that of task:
+//
+//   - a task that is completed, and stays so, keeps its assignee;
+//   - an assigned task can only be reassigned by its assignee or its creator;
+//     requests without an actor are not restricted, and anyone can assign an
+//     unassigned task;
+//   - the new assignee must be a known user.
+func (s *taskServiceImpl) checkReassign(ctx context.Context, before, task *model.Task) error {
+	if task.Assignee == before.Assignee {
+		return nil
+	}
+	if before.Completed && task.Completed {
+		return ErrReassignCompleted
+	}
+	if actor := identity.Actor(ctx); actor != "" && before.Assignee != "" &&
+		actor != before.Assignee && actor != before.CreatedBy {
+		s.logger.Warn("reassignment refused", zap.String("id", task.ID), zap.String("actor", actor))
+		return ErrReassignForbidden
+	}
+	return s.checkAssigneeExists(ctx, task)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ErrWIPLimit is matched by errors returned when a task would enter a board
+// column that already holds as many tasks as its WIP limit allows.
+var ErrWIPLimit = apperror.Conflict("WIP limit reached")
+
+// Board returns the board of the project the context is scoped to, or without
+// a scope of q's project filter, or else of the tasks outside any project. It
+// has a column for every state of the project's workflow, or for the states
+// q filters on, holding the tasks that match q's filter in rank order. A
+// non-zero q.Limit caps the tasks listed per column, at most MaxPageSize; the
+// sort order and cursor of q are ignored.
+func (s *taskServiceImpl) Board(ctx context.Context, q model.TaskQuery) (*model.Board, error) {
+	projectID, scoped := repository.ProjectScope(ctx)
+	if !scoped && q.Filter.ProjectID != nil {
+		projectID = *q.Filter.ProjectID
+	}
+	project, err := s.projectOf(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	wf := s.workflowOf(project)
+	statuses := q.Filter.Statuses
+	limit := min(q.Limit, MaxPageSize)
+	q = model.TaskQuery{Filter: q.Filter, Sort: model.SortByRank}
+	q.Filter.ProjectID = &projectID
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	board := &model.Board{ProjectID: projectID, Columns: []*model.BoardColumn{}}
+	for _, status := range wf.States {
+		if len(statuses) > 0 && !slices.Contains(statuses, status) {
+			continue
+		}
+		q.Filter.Statuses = []string{status}
+		page, err := s.repo.QueryTasks(ctx, q)
+		if err != nil {
+			s.logger.Error("failed to load board column", zap.String("status", status), zap.Error(err))
+			return nil, err
+		}
+		column := &model.BoardColumn{Status: status, WIPLimit: wf.WIPLimit(status), Count: len(page.Tasks), Tasks: page.Tasks}
+		if limit > 0 && len(column.Tasks) > limit {
+			column.Tasks = column.Tasks[:limit]
+		}
+		board.Columns = append(board.Columns, column)
+	}
+	return board, nil
+}
+
+// MoveOnBoard places task id on the board as move says. A move to another
+// column is a status change like in PatchTask, following the workflow and its
+// WIP limits. Only the moved task is written: it gets a rank between those of
+// its new neighbours. A non-zero version must match the stored version.
+func (s *taskServiceImpl) MoveOnBoard(ctx context.Context, id string, move model.BoardMove, version int64) (*model.Task, error) {
+	if err := move.Validate(); err != nil {
+		return nil, apperror.InvalidField("after", err.Error())
+	}
+	return s.modifyTask(ctx, id, version, func(task *model.Task) error {
+		if move.Status != "" {
+			task.Status = move.Status
+		}
+		rank, err := s.boardRank(ctx, task, move)
+		if err != nil {
+			return err
+		}
+		task.Rank = rank
+		return nil
+	})
+}
+
+// boardRank returns the rank that puts task where move says in the column of
+// task.Status, or errUnchanged if it is there already.
+func (s *taskServiceImpl) boardRank(ctx context.Context, task *model.Task, move model.BoardMove) (string, error) {
+	page, err := s.repo.QueryTasks(ctx, model.TaskQuery{
+		Filter: model.TaskFilter{Statuses: []string{task.Status}, ProjectID: &task.ProjectID},
+		Sort:   model.SortByRank,
+	})
+	if err != nil {
+		return "", err
+	}
+	moved := false
+	column := slices.DeleteFunc(page.Tasks, func(t *model.Task) bool {
+		if t.ID == task.ID {
+			moved = true
+		}
+		return t.ID == task.ID
+	})
+	// lo and hi are the new neighbours above and below the task, if any.
+	var lo, hi *model.Task
+	switch {
+	case move.Before != "":
+		i := slices.IndexFunc(column, func(t *model.Task) bool { return t.ID == move.Before })
+		if i < 0 {
+			return "", apperror.InvalidField("before", fmt.Sprintf("task %q is not in column %s", move.Before, task.Status))
+		}
+		hi = column[i]
+		if i > 0 {
+			lo = column[i-1]
+		}
+	case move.After != "":
+		i := slices.IndexFunc(column, func(t *model.Task) bool { return t.ID == move.After })
+		if i < 0 {
+			return "", apperror.InvalidField("after", fmt.Sprintf("task %q is not in column %s", move.After, task.Status))
+		}
+		lo = column[i]
+		if i+1 < len(column) {
+			hi = column[i+1]
+		}
+	case len(column) > 0:
+		lo = column[len(column)-1]
+	}
+
+	current := task.RankKey()
+	if moved && (lo == nil || lo.RankKey() < current) && (hi == nil || current < hi.RankKey()) {
+		return "", errUnchanged
+	}
+	var loKey, hiKey string
+	if lo != nil {
+		loKey = lo.RankKey()
+	}
+	if hi == nil {
+		// At the bottom the task ranks as if created now, so tasks created
+		// later still join below it.
+		if now := model.TimeRank(s.now()); now > loKey {
+			return now, nil
+		}
+	} else {
+		hiKey = hi.RankKey()
+	}
+	rank, err := model.RankBetween(loKey, hiKey)
+	if err != nil {
+		s.logger.Warn("no rank between neighbours", zap.String("id", task.ID), zap.Error(err))
+		return "", apperror.Conflict(fmt.Sprintf("no room for task %s at that position; move one of its neighbours first", task.ID))
+	}
+	return rank, nil
+}
+
+// checkWIP fails with ErrWIPLimit if entering, the number of tasks per status
+// about to enter the board of project projectID, would take a column of wf
+// beyond its WIP limit.
+func (s *taskServiceImpl) checkWIP(ctx context.Context, wf *model.Workflow, projectID string, entering map[string]int) error {
+	for _, status := range wf.States {
+		limit, n := wf.WIPLimit(status), entering[status]
+		if limit == 0 || n == 0 {
+			continue
+		}
+		page, err := s.repo.QueryTasks(repository.WithoutProjectScope(ctx), model.TaskQuery{
+			Filter: model.TaskFilter{Statuses: []string{status}, ProjectID: &projectID},
+			Limit:  limit,
+		})
+		if err != nil {
+			return err
+		}
+		if len(page.Tasks)+n > limit {
+			return fmt.Errorf("%w: column %s holds %d of at most %d tasks", ErrWIPLimit, status, len(page.Tasks), limit)
+		}
+	}
+	return nil
+}
+
+// statusCounts counts the live tasks among tasks per status.
+func statusCounts(tasks ...*model.Task) map[string]int {
+	counts := make(map[string]int)
+	for _, t := range tasks {
+		if t.DeletedAt == nil {
+			counts[t.Status]++
+		}
+	}
+	return counts
+}
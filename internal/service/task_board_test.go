@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBoardFixture returns a service with the default workflow limited to two
+// tasks in progress and a clock that ticks a second on every reading.
+func newBoardFixture(t *testing.T) TaskService {
+	t.Helper()
+	wf := model.DefaultWorkflow()
+	wf.WIPLimits = map[string]int{model.StatusInProgress: 2}
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	return newHierarchyFixture(t, WithWorkflow(wf), WithClock(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}))
+}
+
+func columnIDs(board *model.Board, status string) []string {
+	for _, c := range board.Columns {
+		if c.Status == status {
+			ids := []string{}
+			for _, task := range c.Tasks {
+				ids = append(ids, task.ID)
+			}
+			return ids
+		}
+	}
+	return nil
+}
+
+func TestTaskBoard_Columns(t *testing.T) {
+	svc := newBoardFixture(t)
+	ctx := context.Background()
+	for _, task := range []*model.Task{
+		{ID: "a", Title: "A"}, {ID: "b", Title: "B"}, {ID: "c", Title: "C"},
+		{ID: "d", Title: "D", Status: model.StatusInProgress},
+	} {
+		_, err := svc.CreateTask(ctx, task)
+		require.NoError(t, err)
+	}
+
+	board, err := svc.Board(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	require.Len(t, board.Columns, 5)
+	assert.Equal(t, model.StatusTodo, board.Columns[0].Status)
+	assert.Equal(t, []string{"a", "b", "c"}, columnIDs(board, model.StatusTodo), "new tasks join the bottom")
+	assert.Equal(t, []string{"d"}, columnIDs(board, model.StatusInProgress))
+	assert.Equal(t, 2, board.Columns[1].WIPLimit)
+	assert.Empty(t, columnIDs(board, model.StatusDone))
+
+	board, err = svc.Board(ctx, model.TaskQuery{Limit: 2, Filter: model.TaskFilter{Statuses: []string{model.StatusTodo}}})
+	require.NoError(t, err)
+	require.Len(t, board.Columns, 1)
+	assert.Equal(t, []string{"a", "b"}, columnIDs(board, model.StatusTodo))
+	assert.Equal(t, 3, board.Columns[0].Count)
+
+	board, err = svc.Board(ctx, model.TaskQuery{Filter: model.TaskFilter{TitleContains: "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, columnIDs(board, model.StatusTodo))
+}
+
+func TestTaskBoard_Move(t *testing.T) {
+	svc := newBoardFixture(t)
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := svc.CreateTask(ctx, &model.Task{ID: id, Title: id})
+		require.NoError(t, err)
+	}
+	order := func() []string {
+		board, err := svc.Board(ctx, model.TaskQuery{})
+		require.NoError(t, err)
+		return columnIDs(board, model.StatusTodo)
+	}
+
+	moved, err := svc.MoveOnBoard(ctx, "c", model.BoardMove{Before: "a"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), moved.Version)
+	assert.Equal(t, []string{"c", "a", "b"}, order())
+	for _, id := range []string{"a", "b"} {
+		task, err := svc.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), task.Version, "only the moved task is written")
+	}
+
+	_, err = svc.MoveOnBoard(ctx, "a", model.BoardMove{After: "b"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, order())
+	_, err = svc.MoveOnBoard(ctx, "b", model.BoardMove{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "a", "b"}, order())
+	created, err := svc.CreateTask(ctx, &model.Task{ID: "d", Title: "d"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "a", "b", "d"}, order(), "tasks created later join below")
+
+	// A move to where the task already is writes nothing.
+	same, err := svc.MoveOnBoard(ctx, "d", model.BoardMove{After: "b"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, created.Version, same.Version)
+
+	// Between two neighbours, again and again.
+	for range 20 {
+		_, err = svc.MoveOnBoard(ctx, "d", model.BoardMove{After: "c"}, 0)
+		require.NoError(t, err)
+		_, err = svc.MoveOnBoard(ctx, "a", model.BoardMove{After: "c"}, 0)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"c", "a", "d", "b"}, order())
+
+	_, err = svc.MoveOnBoard(ctx, "a", model.BoardMove{Before: "missing"}, 0)
+	assert.Equal(t, "before", apperror.FieldsOf(err)[0].Field)
+	_, err = svc.MoveOnBoard(ctx, "a", model.BoardMove{Before: "b", After: "c"}, 0)
+	assert.Equal(t, "after", apperror.FieldsOf(err)[0].Field)
+	_, err = svc.MoveOnBoard(ctx, "a", model.BoardMove{}, 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+}
+
+func TestTaskBoard_MoveChangesStatus(t *testing.T) {
+	svc := newBoardFixture(t)
+	ctx := context.Background()
+	for _, task := range []*model.Task{{ID: "a", Title: "A"}, {ID: "x", Title: "X", Status: model.StatusInProgress}} {
+		_, err := svc.CreateTask(ctx, task)
+		require.NoError(t, err)
+	}
+
+	moved, err := svc.MoveOnBoard(ctx, "a", model.BoardMove{Status: model.StatusInProgress, Before: "x"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusInProgress, moved.Status)
+	board, err := svc.Board(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "x"}, columnIDs(board, model.StatusInProgress))
+	assert.Empty(t, columnIDs(board, model.StatusTodo))
+
+	// The neighbour must be in the target column and the workflow applies.
+	_, err = svc.MoveOnBoard(ctx, "a", model.BoardMove{Status: model.StatusDone, After: "x"}, 0)
+	assert.Equal(t, "after", apperror.FieldsOf(err)[0].Field)
+	_, err = svc.MoveOnBoard(ctx, "x", model.BoardMove{Status: model.StatusTodo}, 0)
+	require.NoError(t, err)
+	_, err = svc.MoveOnBoard(ctx, "x", model.BoardMove{Status: model.StatusInReview}, 0)
+	assert.Equal(t, "status", apperror.FieldsOf(err)[0].Field)
+
+	done, err := svc.MoveOnBoard(ctx, "a", model.BoardMove{Status: model.StatusDone}, 0)
+	require.NoError(t, err)
+	assert.True(t, done.Completed)
+}
+
+func TestTaskBoard_WIPLimits(t *testing.T) {
+	svc := newBoardFixture(t)
+	ctx := context.Background()
+	inProgress := model.StatusInProgress
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := svc.CreateTask(ctx, &model.Task{ID: id, Title: id})
+		require.NoError(t, err)
+	}
+	_, err := svc.PatchTask(ctx, "a", &model.TaskPatch{Status: &inProgress}, 0)
+	require.NoError(t, err)
+	_, err = svc.MoveOnBoard(ctx, "b", model.BoardMove{Status: inProgress}, 0)
+	require.NoError(t, err)
+
+	_, err = svc.PatchTask(ctx, "c", &model.TaskPatch{Status: &inProgress}, 0)
+	assert.ErrorIs(t, err, ErrWIPLimit)
+	assert.Equal(t, apperror.KindConflict, apperror.KindOf(err))
+	_, err = svc.MoveOnBoard(ctx, "c", model.BoardMove{Status: inProgress, Before: "a"}, 0)
+	assert.ErrorIs(t, err, ErrWIPLimit)
+	_, err = svc.CreateTask(ctx, &model.Task{Title: "Rush", Status: inProgress})
+	assert.ErrorIs(t, err, ErrWIPLimit)
+
+	// Tasks in a full column can still be reordered and edited.
+	_, err = svc.MoveOnBoard(ctx, "b", model.BoardMove{Before: "a"}, 0)
+	require.NoError(t, err)
+	title := "Renamed"
+	_, err = svc.PatchTask(ctx, "a", &model.TaskPatch{Title: &title}, 0)
+	require.NoError(t, err)
+
+	// A task leaving makes room; it cannot come back from the trash while
+	// the column is full again.
+	require.NoError(t, svc.DeleteTask(ctx, "a", 0))
+	_, err = svc.PatchTask(ctx, "c", &model.TaskPatch{Status: &inProgress}, 0)
+	require.NoError(t, err)
+	_, err = svc.UndeleteTask(ctx, "a", 0)
+	assert.ErrorIs(t, err, ErrWIPLimit)
+}
+
+func TestTaskBoard_WIPLimitCountsCascade(t *testing.T) {
+	wf := model.DefaultWorkflow()
+	wf.WIPLimits = map[string]int{model.StatusDone: 3}
+	svc := newHierarchyFixture(t, WithWorkflow(wf), WithCascadePolicy(model.CascadeCascade, model.CascadeBlock))
+	createTree(t, svc)
+	ctx := context.Background()
+	done := true
+
+	// Completing root takes a, b and a1 along into the done column.
+	_, err := svc.PatchTask(ctx, "root", &model.TaskPatch{Completed: &done}, 0)
+	assert.ErrorIs(t, err, ErrWIPLimit)
+	board, err := svc.Board(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, columnIDs(board, model.StatusDone))
+
+	_, err = svc.PatchTask(ctx, "a", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err, "a and a1 fit")
+}
+
+func TestTaskBoard_Projects(t *testing.T) {
+	projects, tasks := newProjectFixture(t)
+	ctx := context.Background()
+	ops := InProject(ctx, "ops")
+	wf := model.DefaultWorkflow()
+	wf.States, wf.Initial, wf.Done, wf.Terminal = []string{"open", "closed"}, "open", "closed", []string{"closed"}
+	wf.Transitions = map[string][]string{"open": {"closed"}, "closed": {"open"}}
+	wf.WIPLimits = map[string]int{"open": 1}
+	_, err := projects.UpdateProject(ctx, "ops", &model.ProjectPatch{Settings: &model.ProjectSettings{Workflow: wf}})
+	require.NoError(t, err)
+
+	opsTask, err := tasks.CreateTask(ops, &model.Task{Title: "Rotate keys"})
+	require.NoError(t, err)
+	_, err = tasks.CreateTask(ops, &model.Task{Title: "Renew certificates"})
+	assert.ErrorIs(t, err, ErrWIPLimit)
+	loose, err := tasks.CreateTask(ctx, &model.Task{Title: "Loose"})
+	require.NoError(t, err, "limits are per project")
+	_, err = tasks.MoveTask(ctx, loose.ID, "ops", 0)
+	assert.ErrorIs(t, err, ErrWIPLimit)
+	_, err = tasks.CopyTask(ctx, opsTask.ID, "ops")
+	assert.ErrorIs(t, err, ErrWIPLimit)
+
+	board, err := tasks.Board(ops, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, "ops", board.ProjectID)
+	require.Len(t, board.Columns, 2)
+	assert.Equal(t, []string{opsTask.ID}, columnIDs(board, "open"))
+	assert.Equal(t, 1, board.Columns[0].WIPLimit)
+
+	// Without a scope the board shows the tasks outside any project, or
+	// those of the project filtered on.
+	board, err = tasks.Board(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, board.ProjectID)
+	assert.Equal(t, []string{loose.ID}, columnIDs(board, model.StatusTodo))
+	project := "ops"
+	board, err = tasks.Board(ctx, model.TaskQuery{Filter: model.TaskFilter{ProjectID: &project}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{opsTask.ID}, columnIDs(board, "open"))
+	missing := "missing"
+	_, err = tasks.Board(ctx, model.TaskQuery{Filter: model.TaskFilter{ProjectID: &missing}})
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrHasSubtasks is matched by errors returned when the block cascade policy
+// refuses to complete or delete a task because of its subtasks.
+var ErrHasSubtasks = apperror.Conflict("task has subtasks")
+
+// ListChildren returns a page of the direct subtasks of task id, selected by q
+// like in ListTasks.
+func (s *taskServiceImpl) ListChildren(ctx context.Context, id string, q model.TaskQuery) (*model.TaskPage, error) {
+	if _, err := s.GetTask(ctx, id); err != nil {
+		return nil, err
+	}
+	q.Filter.ParentID = &id
+	return s.ListTasks(ctx, q)
+}
+
+// GetSubtree returns task id with its subtasks at every depth and their
+// roll-up progress.
+func (s *taskServiceImpl) GetSubtree(ctx context.Context, id string) (*model.TaskTree, error) {
+	root, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := s.descendants(ctx, id, false)
+	if err != nil {
+		s.logger.Error("failed to load subtree", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return model.BuildTaskTree(root, descendants), nil
+}
+
+// children returns every direct subtask of task id, the trashed ones if
+// trashed is set and the live ones otherwise.
+func (s *taskServiceImpl) children(ctx context.Context, id string, trashed bool) ([]*model.Task, error) {
+	page, err := s.repo.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{ParentID: &id, Trashed: trashed}})
+	if err != nil {
+		return nil, err
+	}
+	return page.Tasks, nil
+}
+
+// descendants returns the subtasks of task id at every depth, breadth first, so
+// every task comes after its parent. trashed selects like in children.
+func (s *taskServiceImpl) descendants(ctx context.Context, id string, trashed bool) ([]*model.Task, error) {
+	var all []*model.Task
+	seen := map[string]bool{id: true}
+	for queue := []string{id}; len(queue) > 0; queue = queue[1:] {
+		kids, err := s.children(ctx, queue[0], trashed)
+		if err != nil {
+			return nil, err
+		}
+		for _, kid := range kids {
+			if seen[kid.ID] {
+				continue
+			}
+			seen[kid.ID] = true
+			all = append(all, kid)
+			queue = append(queue, kid.ID)
+		}
+	}
+	return all, nil
+}
+
+// checkParent verifies that task's parent exists in the same project and is not
+// the task itself or one of its descendants. Callers hold s.hierarchy.
+func (s *taskServiceImpl) checkParent(ctx context.Context, task *model.Task) error {
+	seen := make(map[string]bool)
+	for id := task.ParentID; id != "" && !seen[id]; {
+		if id == task.ID {
+			return apperror.InvalidField("parent_id", "parent_id would make the task its own ancestor")
+		}
+		seen[id] = true
+		ancestor, err := s.repo.GetTask(ctx, id)
+		if errors.Is(err, repository.ErrTaskNotFound) && id == task.ParentID {
+			return apperror.InvalidField("parent_id", fmt.Sprintf("parent task %q does not exist", id))
+		}
+		if err != nil {
+			return err
+		}
+		if id == task.ParentID && ancestor.ProjectID != task.ProjectID {
+			return apperror.InvalidField("parent_id", fmt.Sprintf("parent task %q is in another project", id))
+		}
+		id = ancestor.ParentID
+	}
+	return nil
+}
+
+// planCompletion applies the completion policy to a task that is about to be
+// completed. It fails for the block policy if any subtask is incomplete, and if
+// the task, or a subtask completed along with it, has open blockers. The cascade
+// policy moves open subtasks to the task's new status, which wf must allow for
+// each of them, and the orphan policy detaches them. It returns the subtasks it
+// changed, to store along with the task, and each of them as it was before.
+func (s *taskServiceImpl) planCompletion(ctx context.Context, wf *model.Workflow, task *model.Task) (befores, changed []*model.Task, err error) {
+	descendants, err := s.descendants(ctx, task.ID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	var open []*model.Task
+	for _, d := range descendants {
+		if !d.Completed {
+			open = append(open, d)
+		}
+	}
+	completing := []*model.Task{task}
+	switch {
+	case len(open) > 0 && s.onComplete == model.CascadeBlock:
+		return nil, nil, fmt.Errorf("%w: %d incomplete subtasks", ErrHasSubtasks, len(open))
+	case s.onComplete == model.CascadeCascade:
+		for _, d := range open {
+			if from := statusOf(wf, d); from != task.Status {
+				if err := wf.CheckTransition(from, task.Status); err != nil {
+					return nil, nil, fmt.Errorf("subtask %s: %w", d.ID, err)
+				}
+			}
+		}
+		completing = append(completing, open...)
+	}
+	if err := s.checkBlockers(ctx, completing); err != nil {
+		return nil, nil, err
+	}
+	for _, d := range open {
+		if s.onComplete == model.CascadeCascade {
+			befores = append(befores, d.Clone())
+			d.Status = task.Status
+			d.Completed = true
+		} else if d.ParentID == task.ID {
+			// Orphan: incomplete children leave with their own subtrees.
+			befores = append(befores, d.Clone())
+			d.ParentID = ""
+		} else {
+			continue
+		}
+		d.UpdatedAt = task.UpdatedAt
+		changed = append(changed, d)
+	}
+	return befores, changed, nil
+}
+
+// checkBlockers fails with ErrTaskBlocked if any of tasks has an open blocker
+// outside tasks, which are about to be completed together.
+func (s *taskServiceImpl) checkBlockers(ctx context.Context, tasks []*model.Task) error {
+	completing := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		completing[t.ID] = true
+	}
+	var waiting []string
+	for _, t := range tasks {
+		deps, err := s.repo.ListBlockers(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+		for _, d := range deps {
+			if completing[d.BlockerID] || slices.Contains(waiting, d.BlockerID) {
+				continue
+			}
+			blocker, err := s.repo.GetTask(ctx, d.BlockerID)
+			if errors.Is(err, repository.ErrTaskNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if !blocker.Completed {
+				waiting = append(waiting, d.BlockerID)
+			}
+		}
+	}
+	if len(waiting) > 0 {
+		return fmt.Errorf("%w: waiting for %s", ErrTaskBlocked, strings.Join(waiting, ", "))
+	}
+	return nil
+}
+
+// planDeletion applies the deletion policy to the subtasks of task, which is
+// about to be moved to the trash at the given time. The cascade policy moves
+// its subtasks at every depth to the trash along with it, and the orphan policy
+// detaches its children. It returns the subtasks it changed, to store along
+// with the task, and each of them as it was before. Callers hold s.hierarchy.
+func (s *taskServiceImpl) planDeletion(ctx context.Context, task *model.Task, at time.Time) (befores, changed []*model.Task, err error) {
+	kids, err := s.children(ctx, task.ID, false)
+	if err != nil || len(kids) == 0 {
+		return nil, nil, err
+	}
+	switch s.onDelete {
+	case model.CascadeCascade:
+		if changed, err = s.descendants(ctx, task.ID, false); err != nil {
+			return nil, nil, err
+		}
+		for _, d := range changed {
+			befores = append(befores, d.Clone())
+			d.DeletedAt = &at
+			d.UpdatedAt = at
+		}
+		return befores, changed, nil
+	case model.CascadeOrphan:
+		for _, kid := range kids {
+			befores = append(befores, kid.Clone())
+			kid.ParentID = ""
+			kid.UpdatedAt = at
+		}
+		return befores, kids, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %d subtasks", ErrHasSubtasks, len(kids))
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newHierarchyFixture(t *testing.T, opts ...Option) TaskService {
+	t.Helper()
+	return NewTaskService(repository.NewInMemoryTaskRepository(zap.NewNop()), zap.NewNop(), opts...)
+}
+
+// failingBatches is a repository whose batch writes fail once fail is set.
+type failingBatches struct {
+	repository.TaskRepository
+	fail bool
+}
+
+func (r *failingBatches) WriteTasks(ctx context.Context, batch repository.TaskBatch) error {
+	if r.fail {
+		return errors.New("disk full")
+	}
+	return r.TaskRepository.WriteTasks(ctx, batch)
+}
+
+// createTree creates root with children a and b, and grandchild a1 below a.
+func createTree(t *testing.T, svc TaskService) {
+	t.Helper()
+	for _, task := range []*model.Task{
+		{ID: "root", Title: "Root"},
+		{ID: "a", Title: "A", ParentID: "root"},
+		{ID: "b", Title: "B", ParentID: "root"},
+		{ID: "a1", Title: "A1", ParentID: "a"},
+	} {
+		_, err := svc.CreateTask(context.Background(), task)
+		require.NoError(t, err)
+	}
+}
+
+func TestTaskHierarchy_CreateRequiresExistingParent(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	_, err := svc.CreateTask(context.Background(), &model.Task{Title: "Orphan", ParentID: "missing"})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	assert.Equal(t, "parent_id", apperror.FieldsOf(err)[0].Field)
+}
+
+func TestTaskHierarchy_RejectsCycles(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createTree(t, svc)
+	ctx := context.Background()
+
+	for _, parent := range []string{"a", "a1"} {
+		_, err := svc.PatchTask(ctx, "a", &model.TaskPatch{ParentID: &parent}, 0)
+		assert.Equal(t, apperror.KindValidation, apperror.KindOf(err), parent)
+	}
+	// Moving a subtree elsewhere is fine.
+	parent := "b"
+	moved, err := svc.PatchTask(ctx, "a", &model.TaskPatch{ParentID: &parent}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "b", moved.ParentID)
+}
+
+func TestTaskHierarchy_ListChildren(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createTree(t, svc)
+	ctx := context.Background()
+
+	page, err := svc.ListChildren(ctx, "root", model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, taskIDs(page.Tasks))
+
+	_, err = svc.ListChildren(ctx, "missing", model.TaskQuery{})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestTaskHierarchy_GetSubtree(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createTree(t, svc)
+	ctx := context.Background()
+	done := true
+	_, err := svc.PatchTask(ctx, "a1", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+
+	tree, err := svc.GetSubtree(ctx, "root")
+	require.NoError(t, err)
+	assert.Equal(t, model.Progress{Subtasks: 3, Completed: 1, Percent: 50}, tree.Progress)
+	require.Len(t, tree.Children, 2)
+	assert.Equal(t, "a", tree.Children[0].Task.ID)
+	assert.Equal(t, 100.0, tree.Children[0].Progress.Percent)
+}
+
+func TestTaskHierarchy_CompletePolicies(t *testing.T) {
+	ctx := context.Background()
+	done := true
+
+	svc := newHierarchyFixture(t)
+	createTree(t, svc)
+	_, err := svc.PatchTask(ctx, "root", &model.TaskPatch{Completed: &done}, 0)
+	assert.ErrorIs(t, err, ErrHasSubtasks)
+
+	svc = newHierarchyFixture(t, WithCascadePolicy(model.CascadeCascade, model.CascadeBlock))
+	createTree(t, svc)
+	_, err = svc.PatchTask(ctx, "root", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	tree, err := svc.GetSubtree(ctx, "root")
+	require.NoError(t, err)
+	assert.Equal(t, 3, tree.Progress.Completed)
+
+	svc = newHierarchyFixture(t, WithCascadePolicy(model.CascadeOrphan, model.CascadeBlock))
+	createTree(t, svc)
+	_, err = svc.PatchTask(ctx, "root", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	a, err := svc.GetTask(ctx, "a")
+	require.NoError(t, err)
+	assert.Empty(t, a.ParentID)
+	a1, err := svc.GetTask(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "a", a1.ParentID)
+}
+
+func TestTaskHierarchy_DeletePolicies(t *testing.T) {
+	ctx := context.Background()
+
+	svc := newHierarchyFixture(t)
+	createTree(t, svc)
+	assert.ErrorIs(t, svc.DeleteTask(ctx, "root", 0), ErrHasSubtasks)
+	require.NoError(t, svc.DeleteTask(ctx, "a1", 0))
+
+	svc = newHierarchyFixture(t, WithCascadePolicy(model.CascadeBlock, model.CascadeCascade))
+	createTree(t, svc)
+	assert.ErrorIs(t, svc.DeleteTask(ctx, "root", 7), ErrVersionConflict)
+	require.NoError(t, svc.DeleteTask(ctx, "root", 1))
+	page, err := svc.ListTasks(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+
+	svc = newHierarchyFixture(t, WithCascadePolicy(model.CascadeBlock, model.CascadeOrphan))
+	createTree(t, svc)
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+	top := ""
+	page, err = svc.ListTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{ParentID: &top}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, taskIDs(page.Tasks))
+}
+
+func taskIDs(tasks []*model.Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
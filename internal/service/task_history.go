@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// ErrRevisionNotFound is returned when restoring a revision a task does not have.
+var ErrRevisionNotFound = apperror.NotFound("revision not found")
+
+// History returns the change history of task id, oldest first. The history of
+// a deleted task remains available.
+func (s *taskServiceImpl) History(ctx context.Context, id string) ([]*model.HistoryEntry, error) {
+	entries, err := s.repo.ListHistory(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to list history", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	if len(entries) == 0 {
+		// Tasks stored before history was recorded have none.
+		if _, err := s.GetTask(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// RestoreTask returns task id to its state at revision by undoing the changes
+// recorded after it. A non-zero version must match the stored version. The
+// result is stored like any other update, so it must be valid and its status
+// reachable in the workflow; the series bookkeeping of a recurring task, its
+// project and its position on the board are kept. Revisions from before the
+// task was last purged cannot be restored.
+func (s *taskServiceImpl) RestoreTask(ctx context.Context, id string, revision, version int64) (*model.Task, error) {
+	entry := model.HistoryEntry{Action: model.HistoryRestore, RestoredRevision: revision}
+	return s.modifyTaskAs(ctx, id, version, entry, func(task *model.Task) error {
+		entries, err := s.repo.ListHistory(ctx, id)
+		if err != nil {
+			return err
+		}
+		if revision < 1 || revision > int64(len(entries)) {
+			return fmt.Errorf("%w: task %s has no revision %d", ErrRevisionNotFound, id, revision)
+		}
+		for _, e := range entries[revision-1:] {
+			// Deletions recorded before the trash existed were purges.
+			if e.Action == model.HistoryPurge || e.Action == model.HistoryDelete && e.Version == 0 {
+				return apperror.InvalidField("revision",
+					fmt.Sprintf("revision %d is not from the task's current lifetime", revision))
+			}
+		}
+		restored, err := task.RevertChanges(entries[revision:])
+		if err != nil {
+			return err
+		}
+		restored.Recurrence = model.ReplaceRecurrence(task.Recurrence, restored.Recurrence)
+		restored.DeletedAt = task.DeletedAt
+		restored.ProjectID = task.ProjectID
+		restored.Rank = task.Rank
+		changes, err := model.DiffTasks(task, restored)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return errUnchanged
+		}
+		*task = *restored
+		return nil
+	})
+}
+
+// record appends entry to the history of the task changed from before to
+// after, with the changed fields and the actor of ctx. before is nil for a
+// creation and after for a deletion. The change is stored by then, so a
+// failure is logged rather than failing a request that took effect.
+func (s *taskServiceImpl) record(ctx context.Context, entry model.HistoryEntry, before, after *model.Task) {
+	if after != nil {
+		entry.TaskID, entry.Version = after.ID, after.Version
+	} else {
+		entry.TaskID = before.ID
+	}
+	changes, err := model.DiffTasks(before, after)
+	if err != nil {
+		s.logger.Error("failed to record history", zap.String("id", entry.TaskID), zap.Error(err))
+		return
+	}
+	entry.Changes = changes
+	entry.Actor = identity.Actor(ctx)
+	entry.At = s.now().UTC()
+	if err := s.repo.AppendHistory(ctx, &entry); err != nil {
+		s.logger.Error("failed to record history", zap.String("id", entry.TaskID), zap.Error(err))
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTaskHistory_RecordsWrites(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := identity.WithActor(context.Background(), "alice")
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "Draft"})
+	require.NoError(t, err)
+	title := "Final"
+	_, err = svc.PatchTask(identity.WithActor(ctx, "bob"), "t", &model.TaskPatch{Title: &title}, 0)
+	require.NoError(t, err)
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Title: &title}, 0)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTask(ctx, "t", 0))
+
+	entries, err := svc.History(context.Background(), "t")
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, model.HistoryCreate, entries[0].Action)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Equal(t, int64(1), entries[0].Version)
+
+	assert.Equal(t, model.HistoryUpdate, entries[1].Action)
+	assert.Equal(t, "bob", entries[1].Actor)
+	assert.Equal(t, int64(2), entries[1].Revision)
+	require.Len(t, entries[1].Changes, 1)
+	assert.Equal(t, "title", entries[1].Changes[0].Field)
+	assert.JSONEq(t, `"Draft"`, string(entries[1].Changes[0].Before))
+	assert.JSONEq(t, `"Final"`, string(entries[1].Changes[0].After))
+
+	assert.Equal(t, int64(3), entries[2].Version, "a write without changes still makes a revision")
+	assert.Empty(t, entries[2].Changes)
+
+	assert.Equal(t, model.HistoryDelete, entries[3].Action)
+	assert.Equal(t, int64(4), entries[3].Version)
+	require.Len(t, entries[3].Changes, 1)
+	assert.Equal(t, "deleted_at", entries[3].Changes[0].Field)
+	assert.False(t, entries[3].At.IsZero())
+
+	_, err = svc.History(ctx, "missing")
+	assert.Equal(t, apperror.KindNotFound, apperror.KindOf(err))
+}
+
+// failingHistory is a repository that cannot append history entries.
+type failingHistory struct {
+	repository.TaskRepository
+}
+
+func (failingHistory) AppendHistory(context.Context, *model.HistoryEntry) error {
+	return errors.New("disk full")
+}
+
+func TestTaskHistory_FailureKeepsWrite(t *testing.T) {
+	svc := NewTaskService(failingHistory{repository.NewInMemoryTaskRepository(zap.NewNop())}, zap.NewNop())
+	ctx := context.Background()
+
+	// The task is stored by the time its history fails, so the write succeeds
+	// and a retry would only conflict with it.
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "Draft"})
+	require.NoError(t, err)
+	title := "Final"
+	task, err := svc.PatchTask(ctx, "t", &model.TaskPatch{Title: &title}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), task.Version)
+	require.NoError(t, svc.DeleteTask(ctx, "t", 2))
+	_, err = svc.UndeleteTask(ctx, "t", 3)
+	require.NoError(t, err)
+}
+
+func TestTaskHistory_RecordsCascades(t *testing.T) {
+	svc := newHierarchyFixture(t, WithCascadePolicy(model.CascadeBlock, model.CascadeCascade))
+	createTree(t, svc)
+	ctx := context.Background()
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+
+	for _, id := range []string{"root", "a", "a1"} {
+		entries, err := svc.History(ctx, id)
+		require.NoError(t, err)
+		require.Len(t, entries, 2, id)
+		assert.Equal(t, model.HistoryDelete, entries[1].Action, id)
+	}
+}
+
+func TestTaskHistory_Restore(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "One", Description: "first"})
+	require.NoError(t, err)
+	two, three, empty, done := "Two", "Three", "", true
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Title: &two, Description: &empty}, 0)
+	require.NoError(t, err)
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Title: &three, Completed: &done}, 0)
+	require.NoError(t, err)
+
+	task, err := svc.RestoreTask(ctx, "t", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "One", task.Title)
+	assert.Equal(t, "first", task.Description)
+	assert.False(t, task.Completed)
+	assert.Equal(t, model.StatusTodo, task.Status)
+	assert.Equal(t, int64(4), task.Version)
+
+	entries, err := svc.History(ctx, "t")
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, model.HistoryRestore, entries[3].Action)
+	assert.Equal(t, int64(1), entries[3].RestoredRevision)
+
+	// Restoring is itself a revision that can be undone.
+	task, err = svc.RestoreTask(ctx, "t", 3, task.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "Three", task.Title)
+	assert.True(t, task.Completed)
+
+	_, err = svc.RestoreTask(ctx, "t", 3, 0)
+	assert.NoError(t, err, "restoring the current state is a no-op")
+	entries, err = svc.History(ctx, "t")
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+
+	_, err = svc.RestoreTask(ctx, "t", 9, 0)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+	_, err = svc.RestoreTask(ctx, "t", 1, 1)
+	assert.Equal(t, apperror.KindConflict, apperror.KindOf(err))
+}
+
+func TestTaskHistory_RestoreAcrossDeletion(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "Old life"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTask(ctx, "t", 0))
+	require.NoError(t, svc.PurgeTask(ctx, "t", 0))
+	_, err = svc.CreateTask(ctx, &model.Task{ID: "t", Title: "New life"})
+	require.NoError(t, err)
+
+	_, err = svc.RestoreTask(ctx, "t", 1, 0)
+	assert.Equal(t, "revision", apperror.FieldsOf(err)[0].Field)
+	task, err := svc.RestoreTask(ctx, "t", 4, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "New life", task.Title)
+}
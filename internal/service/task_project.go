@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/idgen"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// InProject returns a context that confines task operations to project id:
+// tasks of other projects are not found, and new tasks are created in it.
+func InProject(ctx context.Context, id string) context.Context {
+	return repository.WithProjectScope(ctx, id)
+}
+
+// projectOf returns project id, or nil for the empty ID of tasks outside any
+// project.
+func (s *taskServiceImpl) projectOf(ctx context.Context, id string) (*model.Project, error) {
+	if id == "" {
+		return nil, nil
+	}
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		s.logger.Warn("project not found", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return project, nil
+}
+
+// targetProject is projectOf for a project named in a request body, where an
+// unknown project is invalid input.
+func (s *taskServiceImpl) targetProject(ctx context.Context, id string) (*model.Project, error) {
+	project, err := s.projectOf(ctx, id)
+	if errors.Is(err, repository.ErrProjectNotFound) {
+		return nil, apperror.InvalidField("project_id", fmt.Sprintf("unknown project %q", id))
+	}
+	return project, err
+}
+
+// newTaskID generates the ID of a new task in project: its next number if the
+// project has an ID prefix, a UUID otherwise.
+func (s *taskServiceImpl) newTaskID(ctx context.Context, project *model.Project) (string, error) {
+	if project == nil || project.Settings.IDPrefix == "" {
+		return idgen.GenerateTaskID(), nil
+	}
+	n, err := s.repo.NextTaskNumber(ctx, project.ID)
+	if err != nil {
+		s.logger.Error("failed to number task", zap.String("project", project.ID), zap.Error(err))
+		return "", err
+	}
+	return project.Settings.TaskID(n), nil
+}
+
+// checkProjectLabels fails if task carries a label that project does not
+// allow, unless it already carried it before; before is nil for a task new to
+// the project. Tasks outside any project may carry every label.
+func checkProjectLabels(project *model.Project, before, task *model.Task) error {
+	if project == nil {
+		return nil
+	}
+	for _, name := range task.Labels {
+		if !project.Settings.AllowsLabel(name) && (before == nil || !before.HasLabel(name)) {
+			return apperror.InvalidField("labels", fmt.Sprintf("label %q is not allowed in project %s", name, project.ID))
+		}
+	}
+	return nil
+}
+
+// enterProject puts task, which comes from the project with workflow from,
+// into project. A status the project's workflow does not know is replaced by
+// its done or initial state, depending on whether the task is completed.
+func (s *taskServiceImpl) enterProject(from *model.Workflow, project *model.Project, task *model.Task) error {
+	if err := checkProjectLabels(project, nil, task); err != nil {
+		return fmt.Errorf("task %s: %w", task.ID, err)
+	}
+	task.ProjectID = ""
+	if project != nil {
+		task.ProjectID = project.ID
+	}
+	wf := s.workflowOf(project)
+	switch status := statusOf(from, task); {
+	case wf.Has(status):
+		task.Status = status
+	case task.Completed:
+		task.Status = wf.Done
+	default:
+		task.Status = wf.Initial
+	}
+	task.Completed = wf.IsTerminal(task.Status)
+	return nil
+}
+
+// MoveTask moves task id and its subtasks, live and trashed, to project
+// projectID, or out of any project if it is empty. A non-zero version must
+// match the stored version. The task leaves its parent. The project's WIP
+// limits apply to the live tasks. All tasks are written at once, so either
+// all of them move or none does.
+func (s *taskServiceImpl) MoveTask(ctx context.Context, id, projectID string, version int64) (*model.Task, error) {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
+	task, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		s.logger.Warn("task not found for move", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		s.logger.Warn("stale version on move", zap.Error(err))
+		return nil, err
+	}
+	if task.ProjectID == projectID {
+		return task, nil
+	}
+	source, err := s.projectOf(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.targetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	subtree, err := s.subtree(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to load subtree", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	tasks := append([]*model.Task{task}, subtree...)
+	befores := make([]*model.Task, len(tasks))
+	now := s.now().UTC()
+	for i, t := range tasks {
+		befores[i] = t.Clone()
+		if err := s.enterProject(s.workflowOf(source), target, t); err != nil {
+			return nil, err
+		}
+		t.UpdatedAt = now
+	}
+	task.ParentID = ""
+	if err := s.checkWIP(ctx, s.workflowOf(target), projectID, statusCounts(tasks...)); err != nil {
+		return nil, err
+	}
+
+	// The tasks leave the scope of ctx, which would hide them.
+	if err := s.repo.WriteTasks(repository.WithoutProjectScope(ctx), repository.TaskBatch{Update: tasks}); err != nil {
+		s.logger.Error("failed to move task", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	for i, t := range tasks {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryUpdate}, befores[i], t)
+	}
+	s.logger.Info("task moved", zap.String("id", id), zap.String("project", projectID), zap.Int("tasks", len(tasks)))
+	return task, nil
+}
+
+// CopyTask copies task id and its live subtasks to project projectID, or
+// outside any project if it is empty, and returns the copy of the task. The
+// copies get new IDs, the request's actor as creator and no parent above the
+// copied task; a recurring copy starts a series of its own. The project's WIP
+// limits and the tenant's task quota apply. All copies are written at once.
+func (s *taskServiceImpl) CopyTask(ctx context.Context, id, projectID string) (*model.Task, error) {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
+	task, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		s.logger.Warn("task not found for copy", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	source, err := s.projectOf(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.targetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := s.descendants(ctx, id, false)
+	if err != nil {
+		s.logger.Error("failed to load subtree", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	originals := append([]*model.Task{task}, descendants...)
+	copies := make([]*model.Task, len(originals))
+	for i, original := range originals {
+		c := original.Clone()
+		if err := s.enterProject(s.workflowOf(source), target, c); err != nil {
+			return nil, err
+		}
+		copies[i] = c
+	}
+	if err := s.checkWIP(ctx, s.workflowOf(target), projectID, statusCounts(copies...)); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, len(copies)); err != nil {
+		return nil, err
+	}
+	// IDs are only taken once every copy fits into the project.
+	ids := make(map[string]string, len(copies))
+	now := s.now().UTC()
+	for i, c := range copies {
+		newID, err := s.newTaskID(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		ids[c.ID] = newID
+		c.ID, c.ParentID = newID, ids[c.ParentID]
+		c.Version = 0
+		c.CreatedBy = identity.Actor(ctx)
+		c.CreatedAt, c.UpdatedAt = now, now
+		// A nanosecond apart, so the copies keep their order on the board.
+		c.Rank = model.TimeRank(now.Add(time.Duration(i)))
+		if c.Recurrence != nil {
+			r := *c.Recurrence
+			r.NextID = ""
+			c.Recurrence = &r
+		}
+	}
+
+	if err := s.repo.WriteTasks(repository.WithoutProjectScope(ctx), repository.TaskBatch{Create: copies}); err != nil {
+		s.logger.Error("failed to copy task", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	for _, c := range copies {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryCreate}, nil, c)
+	}
+	s.logger.Info("task copied", zap.String("id", id), zap.String("copy", copies[0].ID),
+		zap.String("project", projectID), zap.Int("tasks", len(copies)))
+	return copies[0], nil
+}
+
+// subtree returns the subtasks of task id at every depth, live and trashed,
+// every task after its parent.
+func (s *taskServiceImpl) subtree(ctx context.Context, id string) ([]*model.Task, error) {
+	var all []*model.Task
+	seen := map[string]bool{id: true}
+	for queue := []string{id}; len(queue) > 0; queue = queue[1:] {
+		for _, trashed := range []bool{false, true} {
+			kids, err := s.children(ctx, queue[0], trashed)
+			if err != nil {
+				return nil, err
+			}
+			for _, kid := range kids {
+				if seen[kid.ID] {
+					continue
+				}
+				seen[kid.ID] = true
+				all = append(all, kid)
+				queue = append(queue, kid.ID)
+			}
+		}
+	}
+	return all, nil
+}
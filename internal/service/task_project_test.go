@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskProject_CreateInScope(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	web := InProject(context.Background(), "web")
+
+	first, err := tasks.CreateTask(web, &model.Task{Title: "Fix header", ProjectID: "ops"})
+	require.NoError(t, err)
+	assert.Equal(t, "WEB-1", first.ID)
+	assert.Equal(t, "web", first.ProjectID, "the scope wins over the body")
+	second, err := tasks.CreateTask(web, &model.Task{Title: "Fix footer"})
+	require.NoError(t, err)
+	assert.Equal(t, "WEB-2", second.ID)
+	explicit, err := tasks.CreateTask(web, &model.Task{ID: "custom", Title: "Custom"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", explicit.ID)
+
+	outside, err := tasks.CreateTask(context.Background(), &model.Task{Title: "Loose", ProjectID: "web"})
+	require.NoError(t, err)
+	assert.Empty(t, outside.ProjectID)
+	assert.NotContains(t, outside.ID, "WEB")
+
+	_, err = tasks.CreateTask(InProject(context.Background(), "missing"), &model.Task{Title: "Lost"})
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+}
+
+func TestTaskProject_Isolation(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	web := InProject(context.Background(), "web")
+	ops := InProject(context.Background(), "ops")
+	task, err := tasks.CreateTask(web, &model.Task{Title: "Fix header"})
+	require.NoError(t, err)
+	_, err = tasks.CreateTask(ops, &model.Task{Title: "Rotate keys"})
+	require.NoError(t, err)
+
+	_, err = tasks.GetTask(ops, task.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	title := "Hijacked"
+	_, err = tasks.PatchTask(ops, task.ID, &model.TaskPatch{Title: &title}, 0)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	assert.ErrorIs(t, tasks.DeleteTask(ops, task.ID, 0), ErrTaskNotFound)
+
+	page, err := tasks.ListTasks(web, model.TaskQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, task.ID, page.Tasks[0].ID)
+	page, err = tasks.ListTasks(context.Background(), model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Len(t, page.Tasks, 2, "without a scope every task is visible")
+
+	// Subtasks stay in their parent's project.
+	_, err = tasks.CreateTask(ops, &model.Task{Title: "Sub", ParentID: task.ID})
+	assert.Equal(t, "parent_id", apperror.FieldsOf(err)[0].Field)
+	_, err = tasks.CreateTask(context.Background(), &model.Task{Title: "Sub", ParentID: task.ID})
+	assert.Equal(t, "parent_id", apperror.FieldsOf(err)[0].Field)
+}
+
+func TestTaskProject_Settings(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	web := InProject(context.Background(), "web")
+	ops := InProject(context.Background(), "ops")
+
+	_, err := tasks.CreateTask(web, &model.Task{Title: "Chore", Labels: []string{"chore"}})
+	assert.Equal(t, "labels", apperror.FieldsOf(err)[0].Field)
+	task, err := tasks.CreateTask(web, &model.Task{Title: "Bug", Labels: []string{"bug"}})
+	require.NoError(t, err)
+	_, err = tasks.AttachLabel(web, task.ID, "chore", 0)
+	assert.Equal(t, "labels", apperror.FieldsOf(err)[0].Field)
+
+	// The project's workflow replaces the service's.
+	opsTask, err := tasks.CreateTask(ops, &model.Task{Title: "Rotate keys"})
+	require.NoError(t, err)
+	assert.Equal(t, "open", opsTask.Status)
+	_, err = tasks.CreateTask(ops, &model.Task{Title: "Bad", Status: model.StatusInProgress})
+	assert.Equal(t, "status", apperror.FieldsOf(err)[0].Field)
+	done := true
+	opsTask, err = tasks.PatchTask(ops, opsTask.ID, &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "closed", opsTask.Status)
+}
+
+func TestTaskProject_Move(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	ctx := identity.WithActor(context.Background(), "alice")
+	web := InProject(ctx, "web")
+	root, err := tasks.CreateTask(ctx, &model.Task{Title: "Root", Labels: []string{"bug"}})
+	require.NoError(t, err)
+	child, err := tasks.CreateTask(ctx, &model.Task{Title: "Child", ParentID: root.ID, Status: model.StatusInProgress})
+	require.NoError(t, err)
+	gone, err := tasks.CreateTask(ctx, &model.Task{Title: "Gone", ParentID: root.ID})
+	require.NoError(t, err)
+	require.NoError(t, tasks.DeleteTask(ctx, gone.ID, 0))
+
+	_, err = tasks.MoveTask(ctx, root.ID, "missing", 0)
+	assert.Equal(t, "project_id", apperror.FieldsOf(err)[0].Field)
+	_, err = tasks.MoveTask(ctx, root.ID, "web", root.Version+1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	moved, err := tasks.MoveTask(ctx, root.ID, "web", root.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "web", moved.ProjectID)
+	assert.Equal(t, root.Version+1, moved.Version)
+	got, err := tasks.GetTask(web, child.ID)
+	require.NoError(t, err, "subtasks move along")
+	assert.Equal(t, model.StatusInProgress, got.Status)
+	assert.Equal(t, root.ID, got.ParentID)
+	trashed, err := tasks.GetTrashedTask(web, gone.ID)
+	require.NoError(t, err, "trashed subtasks move along")
+	assert.Equal(t, "web", trashed.ProjectID)
+	_, err = tasks.GetTask(ctx, root.ID)
+	require.NoError(t, err, "unscoped reads still see the task")
+	entries, err := tasks.History(ctx, child.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "project_id", entries[len(entries)-1].Changes[0].Field)
+
+	// Statuses the target workflow lacks are reset; the move is all or nothing.
+	_, err = tasks.MoveTask(web, root.ID, "ops", 0)
+	require.NoError(t, err)
+	got, err = tasks.GetTask(ctx, child.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ops", got.ProjectID)
+	assert.Equal(t, "open", got.Status)
+	_, err = tasks.MoveTask(ctx, root.ID, "web", 0)
+	require.NoError(t, err)
+	_, err = tasks.AttachLabel(web, child.ID, "bug", 0)
+	require.NoError(t, err)
+
+	// A subtask moved on its own leaves its parent.
+	moved, err = tasks.MoveTask(web, child.ID, "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, moved.ProjectID)
+	assert.Empty(t, moved.ParentID)
+	_, err = tasks.GetTask(web, child.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestTaskProject_MoveChecksLabels(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	ctx := context.Background()
+	root, err := tasks.CreateTask(ctx, &model.Task{Title: "Root"})
+	require.NoError(t, err)
+	child, err := tasks.CreateTask(ctx, &model.Task{Title: "Child", ParentID: root.ID, Labels: []string{"chore"}})
+	require.NoError(t, err)
+
+	_, err = tasks.MoveTask(ctx, root.ID, "web", 0)
+	assert.Equal(t, "labels", apperror.FieldsOf(err)[0].Field)
+	for _, id := range []string{root.ID, child.ID} {
+		got, err := tasks.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Empty(t, got.ProjectID, "nothing moved")
+	}
+}
+
+func TestTaskProject_Copy(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	ctx := identity.WithActor(context.Background(), "alice")
+	web := InProject(ctx, "web")
+	root, err := tasks.CreateTask(ctx, &model.Task{Title: "Root", Status: model.StatusInProgress})
+	require.NoError(t, err)
+	child, err := tasks.CreateTask(ctx, &model.Task{Title: "Child", ParentID: root.ID})
+	require.NoError(t, err)
+	_, err = tasks.CreateTask(ctx, &model.Task{Title: "Grandchild", ParentID: child.ID})
+	require.NoError(t, err)
+
+	copied, err := tasks.CopyTask(identity.WithActor(ctx, "bob"), root.ID, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "WEB-1", copied.ID)
+	assert.Equal(t, "web", copied.ProjectID)
+	assert.Equal(t, "bob", copied.CreatedBy)
+	assert.Equal(t, model.StatusInProgress, copied.Status)
+	assert.Equal(t, int64(1), copied.Version)
+	tree, err := tasks.GetSubtree(web, copied.ID)
+	require.NoError(t, err)
+	require.Len(t, tree.Children, 1)
+	assert.Equal(t, "WEB-2", tree.Children[0].Task.ID)
+	require.Len(t, tree.Children[0].Children, 1)
+	assert.Equal(t, "WEB-3", tree.Children[0].Children[0].Task.ID)
+
+	// The originals stay where they are.
+	original, err := tasks.GetTask(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Empty(t, original.ProjectID)
+	assert.Equal(t, root.Version, original.Version)
+
+	// A copy into the same project is a duplicate.
+	again, err := tasks.CopyTask(web, copied.ID, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "WEB-4", again.ID)
+	entries, err := tasks.History(ctx, again.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.HistoryCreate, entries[0].Action)
+
+	_, err = tasks.CopyTask(ctx, root.ID, "missing")
+	assert.Equal(t, "project_id", apperror.FieldsOf(err)[0].Field)
+	_, err = tasks.CopyTask(InProject(ctx, "ops"), copied.ID, "")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestTaskProject_RestoreKeepsProject(t *testing.T) {
+	_, tasks := newProjectFixture(t)
+	ctx := context.Background()
+	task, err := tasks.CreateTask(ctx, &model.Task{Title: "Old title"})
+	require.NoError(t, err)
+	title := "New title"
+	_, err = tasks.PatchTask(ctx, task.ID, &model.TaskPatch{Title: &title}, 0)
+	require.NoError(t, err)
+	_, err = tasks.MoveTask(ctx, task.ID, "web", 0)
+	require.NoError(t, err)
+
+	restored, err := tasks.RestoreTask(InProject(ctx, "web"), task.ID, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Old title", restored.Title)
+	assert.Equal(t, "web", restored.ProjectID)
+	_, err = tasks.RestoreTask(ctx, task.ID, 99, 0)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+}
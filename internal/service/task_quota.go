@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+)
+
+// ErrTaskQuota is matched by errors returned when a write would take a tenant
+// beyond its quota of live tasks.
+var ErrTaskQuota = apperror.Conflict("task quota reached")
+
+// taskQuota returns the most live tasks the tenant of ctx may hold, 0 for no
+// limit.
+func (s *taskServiceImpl) taskQuota(ctx context.Context) int {
+	if quota, ok := s.quotas[identity.Tenant(ctx)]; ok {
+		return quota
+	}
+	return s.quota
+}
+
+// checkQuota fails with ErrTaskQuota if adding more live tasks would take the
+// tenant of ctx beyond its quota. Callers hold s.hierarchy.
+func (s *taskServiceImpl) checkQuota(ctx context.Context, adding int) error {
+	quota := s.taskQuota(ctx)
+	if quota == 0 || adding == 0 {
+		return nil
+	}
+	n, err := s.repo.CountTasks(ctx)
+	if err != nil {
+		return err
+	}
+	if n+adding > quota {
+		return fmt.Errorf("%w: %d of at most %d tasks in use", ErrTaskQuota, n, quota)
+	}
+	return nil
+}
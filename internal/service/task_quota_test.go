@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTaskService_Quotas(t *testing.T) {
+	repo := repository.NewTenantRepository(map[string]repository.TaskRepository{
+		"acme":   repository.NewInMemoryTaskRepository(zap.NewNop()),
+		"globex": repository.NewInMemoryTaskRepository(zap.NewNop()),
+	})
+	svc := NewTaskService(repo, zap.NewNop(), WithTaskQuotas(3, map[string]int{"globex": 1}))
+	acme := identity.WithTenant(context.Background(), "acme")
+	globex := identity.WithTenant(context.Background(), "globex")
+
+	_, err := svc.CreateTask(globex, &model.Task{ID: "g1", Title: "Only one"})
+	require.NoError(t, err)
+	_, err = svc.CreateTask(globex, &model.Task{Title: "One too many"})
+	assert.ErrorIs(t, err, ErrTaskQuota)
+	assert.Equal(t, apperror.KindConflict, apperror.KindOf(err))
+
+	for _, id := range []string{"a1", "a2"} {
+		_, err := svc.CreateTask(acme, &model.Task{ID: id, Title: id})
+		require.NoError(t, err, "quotas are per tenant")
+	}
+	_, err = svc.CreateTask(acme, &model.Task{ID: "a3", Title: "a3", ParentID: "a1"})
+	require.NoError(t, err)
+	_, err = svc.CopyTask(acme, "a2", "")
+	assert.ErrorIs(t, err, ErrTaskQuota)
+
+	// Trashed tasks do not count, but they count again when restored.
+	require.NoError(t, svc.DeleteTask(acme, "a2", 0))
+	_, err = svc.CopyTask(acme, "a3", "")
+	require.NoError(t, err)
+	_, err = svc.UndeleteTask(acme, "a2", 0)
+	assert.ErrorIs(t, err, ErrTaskQuota)
+	_, err = svc.CopyTask(acme, "a1", "")
+	assert.ErrorIs(t, err, ErrTaskQuota, "a task is copied with its subtasks")
+
+	_, err = svc.GetTask(globex, "a1")
+	assert.ErrorIs(t, err, ErrTaskNotFound, "tenants do not see each other's tasks")
+	_, err = svc.CreateTask(context.Background(), &model.Task{Title: "Nobody's"})
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+}
+
+func TestTaskService_QuotaWithoutTenants(t *testing.T) {
+	svc := newHierarchyFixture(t, WithTaskQuotas(1, nil))
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{Title: "First"})
+	require.NoError(t, err)
+	_, err = svc.CreateTask(ctx, &model.Task{Title: "Second"})
+	assert.ErrorIs(t, err, ErrTaskQuota)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// DefaultOccurrencesPreview is the number of occurrences Occurrences returns
+// when no limit is given.
+const DefaultOccurrencesPreview = 10
+
+// Occurrences previews up to limit occurrences of task id after its own. A
+// task that does not recur has none.
+func (s *taskServiceImpl) Occurrences(ctx context.Context, id string, limit int) ([]model.Occurrence, error) {
+	if limit == 0 {
+		limit = DefaultOccurrencesPreview
+	}
+	if limit < 0 || limit > model.MaxOccurrencesPreview {
+		return nil, apperror.InvalidField("limit", fmt.Sprintf("limit must be between 1 and %d", model.MaxOccurrencesPreview))
+	}
+	task, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := task.UpcomingOccurrences(limit)
+	if err != nil {
+		return nil, err
+	}
+	if occurrences == nil {
+		occurrences = []model.Occurrence{}
+	}
+	return occurrences, nil
+}
+
+// planRecurrence returns the task for the next occurrence of a recurring task
+// that is being done, and records its ID in the task's recurrence. It returns
+// nil when the task does not recur, its series has ended, its next occurrence
+// already exists, or it was closed in another terminal status than the done
+// state of its project's workflow, which ends the series. project is that of
+// the task, nil for none. The occurrence is exempt from WIP limits and task
+// quotas, so they never keep a task from being done.
+func (s *taskServiceImpl) planRecurrence(ctx context.Context, project *model.Project, task *model.Task) (*model.Task, error) {
+	wf := s.workflowOf(project)
+	if task.Recurrence == nil || task.Recurrence.NextID != "" || task.Status != wf.Done {
+		return nil, nil
+	}
+	next, err := task.NextOccurrence()
+	if err != nil || next == nil {
+		return nil, err
+	}
+	if next.ID, err = s.newTaskID(ctx, project); err != nil {
+		return nil, err
+	}
+	next.Status = wf.Initial
+	next.CreatedAt, next.UpdatedAt = task.UpdatedAt, task.UpdatedAt
+	next.Rank = model.TimeRank(next.CreatedAt)
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	r := *task.Recurrence
+	r.NextID = next.ID
+	task.Recurrence = &r
+	return next, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func createRecurring(t *testing.T, svc TaskService, rule string) *model.Task {
+	t.Helper()
+	due := time.Date(2025, 1, 6, 17, 0, 0, 0, time.UTC)
+	task, err := svc.CreateTask(context.Background(), &model.Task{
+		ID: "t", Title: "Weekly report", DueAt: &due,
+		Recurrence: &model.Recurrence{Rule: rule, Occurrence: 7, NextID: "bogus"},
+	})
+	require.NoError(t, err)
+	return task
+}
+
+func TestTaskRecurrence_CreateStartsSeries(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	task := createRecurring(t, svc, "FREQ=WEEKLY")
+	assert.Equal(t, &model.Recurrence{Rule: "FREQ=WEEKLY", Occurrence: 1}, task.Recurrence, "series bookkeeping is the server's")
+
+	_, err := svc.CreateTask(context.Background(), &model.Task{Title: "Undated", Recurrence: &model.Recurrence{Rule: "FREQ=DAILY"}})
+	assert.Equal(t, "recurrence", apperror.FieldsOf(err)[0].Field)
+}
+
+func TestTaskRecurrence_DoneSpawnsNextOccurrence(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createRecurring(t, svc, "FREQ=WEEKLY")
+	ctx := context.Background()
+	done, open := true, false
+
+	task, err := svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, task.Recurrence.NextID)
+	next, err := svc.GetTask(ctx, task.Recurrence.NextID)
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly report", next.Title)
+	assert.Equal(t, model.StatusTodo, next.Status)
+	assert.Equal(t, time.Date(2025, 1, 13, 17, 0, 0, 0, time.UTC), *next.DueAt)
+	assert.Equal(t, 2, next.Recurrence.Occurrence)
+
+	// Reopening and doing the task again does not duplicate the occurrence.
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &open}, 0)
+	require.NoError(t, err)
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	page, err := svc.ListTasks(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	assert.Len(t, page.Tasks, 2)
+}
+
+func TestTaskRecurrence_DoneWritesOneBatch(t *testing.T) {
+	repo := &failingBatches{TaskRepository: repository.NewInMemoryTaskRepository(zap.NewNop())}
+	svc := NewTaskService(repo, zap.NewNop(), WithCascadePolicy(model.CascadeCascade, model.CascadeBlock))
+	createRecurring(t, svc, "FREQ=WEEKLY")
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "sub", Title: "Gather numbers", ParentID: "t"})
+	require.NoError(t, err)
+	done := true
+
+	// The task, its cascaded subtask and its next occurrence are stored
+	// together or not at all.
+	repo.fail = true
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.Error(t, err)
+	page, err := svc.ListTasks(ctx, model.TaskQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 2)
+	for _, task := range page.Tasks {
+		assert.False(t, task.Completed, task.ID)
+	}
+
+	repo.fail = false
+	task, err := svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	sub, err := svc.GetTask(ctx, "sub")
+	require.NoError(t, err)
+	assert.True(t, sub.Completed)
+	_, err = svc.GetTask(ctx, task.Recurrence.NextID)
+	assert.NoError(t, err)
+}
+
+func TestTaskRecurrence_CancelEndsSeries(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createRecurring(t, svc, "FREQ=DAILY")
+	cancelled := model.StatusCancelled
+
+	task, err := svc.PatchTask(context.Background(), "t", &model.TaskPatch{Status: &cancelled}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, task.Recurrence.NextID)
+}
+
+func TestTaskRecurrence_CountEndsSeries(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createRecurring(t, svc, "FREQ=DAILY;COUNT=2")
+	ctx := context.Background()
+	done := true
+
+	task, err := svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	last, err := svc.PatchTask(ctx, task.Recurrence.NextID, &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, last.Recurrence.Occurrence)
+	assert.Empty(t, last.Recurrence.NextID)
+}
+
+func TestTaskRecurrence_Occurrences(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createRecurring(t, svc, "FREQ=MONTHLY;BYMONTHDAY=-1")
+	ctx := context.Background()
+
+	occurrences, err := svc.Occurrences(ctx, "t", 0)
+	require.NoError(t, err)
+	require.Len(t, occurrences, DefaultOccurrencesPreview)
+	assert.Equal(t, time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC), *occurrences[0].DueAt)
+	assert.Equal(t, time.Date(2025, 2, 28, 17, 0, 0, 0, time.UTC), *occurrences[1].DueAt)
+
+	_, err = svc.Occurrences(ctx, "t", model.MaxOccurrencesPreview+1)
+	assert.Equal(t, "limit", apperror.FieldsOf(err)[0].Field)
+	_, err = svc.Occurrences(ctx, "missing", 1)
+	assert.Equal(t, apperror.KindNotFound, apperror.KindOf(err))
+
+	_, err = svc.CreateTask(ctx, &model.Task{ID: "once", Title: "Once"})
+	require.NoError(t, err)
+	occurrences, err = svc.Occurrences(ctx, "once", 5)
+	require.NoError(t, err)
+	assert.Empty(t, occurrences)
+	assert.NotNil(t, occurrences)
+}
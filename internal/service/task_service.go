@@ -3,8 +3,12 @@ package service
 import (
 	"context"
 	"errors"
-	"sort"
-	"taskmanager/internal/idgen"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
 	"taskmanager/internal/model"
 	"taskmanager/internal/repository"
 	"time"
@@ -18,30 +22,133 @@ import (
 type taskServiceImpl struct {
 	repo   repository.TaskRepository
 	logger *zap.Logger
+	now    func() time.Time
+
+	// workflow is the state machine of task statuses; Completed follows it.
+	workflow *model.Workflow
+	// onComplete and onDelete decide what happens to the subtasks of a task
+	// that is completed or deleted.
+	onComplete, onDelete model.CascadePolicy
+	// quota caps the live tasks of a tenant unless quotas has an entry for
+	// it; 0 means no limit.
+	quota  int
+	quotas map[string]int
+	// hierarchy serializes writes whose checks span several tasks: parent
+	// changes, completions, deletions, moves into columns with a WIP limit
+	// and writes adding tasks under a quota.
+	hierarchy sync.Mutex
+}
+
+// Option configures optional behaviour of the task service.
+type Option func(*taskServiceImpl)
+
+// WithClock makes the service read the current time from now instead of
+// time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(s *taskServiceImpl) { s.now = now }
+}
+
+// WithCascadePolicy sets what completing (onComplete) or deleting (onDelete) a
+// task with subtasks does. Both default to model.CascadeBlock.
+func WithCascadePolicy(onComplete, onDelete model.CascadePolicy) Option {
+	return func(s *taskServiceImpl) { s.onComplete, s.onDelete = onComplete, onDelete }
+}
+
+// WithWorkflow makes task statuses follow wf instead of model.DefaultWorkflow.
+// wf must be valid.
+func WithWorkflow(wf *model.Workflow) Option {
+	return func(s *taskServiceImpl) { s.workflow = wf }
+}
+
+// WithTaskQuotas caps the live tasks of every tenant at quota, or at the
+// tenant's entry in perTenant. A quota of 0 means no limit, the default.
+func WithTaskQuotas(quota int, perTenant map[string]int) Option {
+	return func(s *taskServiceImpl) { s.quota, s.quotas = quota, maps.Clone(perTenant) }
 }
 
 // NewTaskService creates a new TaskService with the given repository and logger.
-func NewTaskService(repo repository.TaskRepository, logger *zap.Logger) TaskService {
-	return &taskServiceImpl{repo: repo, logger: logger}
+func NewTaskService(repo repository.TaskRepository, logger *zap.Logger, opts ...Option) TaskService {
+	s := &taskServiceImpl{
+		repo:       repo,
+		logger:     logger,
+		now:        time.Now,
+		workflow:   model.DefaultWorkflow(),
+		onComplete: model.CascadeBlock,
+		onDelete:   model.CascadeBlock,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// CreateTask validates and creates a new task.
+// CreateTask validates and creates a new task. Its creator is the request's
+// actor and its assignee, if any, must be a known user. The task belongs to the
+// project the context is scoped to, whose settings apply to it, and joins the
+// bottom of its board column if the column's WIP limit allows. The tenant's
+// task quota applies.
 func (s *taskServiceImpl) CreateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
-	// If ID is empty, generate a new one (now uses UUID)
+	task.ProjectID, _ = repository.ProjectScope(ctx)
+	project, err := s.projectOf(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	task.Labels = model.NormalizeLabels(task.Labels)
+	if err := checkProjectLabels(project, nil, task); err != nil {
+		return nil, err
+	}
+	// If ID is empty, generate a new one: the next number of a project with
+	// an ID prefix, a UUID otherwise.
 	if task.ID == "" {
-		task.ID = idgen.GenerateTaskID()
+		if task.ID, err = s.newTaskID(ctx, project); err != nil {
+			return nil, err
+		}
 	}
 	if err := task.Validate(); err != nil {
 		s.logger.Warn("validation failed", zap.Error(err))
 		return nil, err
 	}
-	now := time.Now().UTC()
+	if err := task.ValidateNewID(); err != nil {
+		s.logger.Warn("validation failed", zap.Error(err))
+		return nil, err
+	}
+	wf := s.workflowOf(project)
+	if err := initStatus(wf, task); err != nil {
+		return nil, err
+	}
+	task.Recurrence = model.ReplaceRecurrence(nil, task.Recurrence)
+	task.CreatedBy = identity.Actor(ctx)
+	if err := s.checkAssigneeExists(ctx, task); err != nil {
+		return nil, err
+	}
+	if task.ParentID != "" || wf.WIPLimit(task.Status) > 0 || s.taskQuota(ctx) > 0 {
+		s.hierarchy.Lock()
+		defer s.hierarchy.Unlock()
+	}
+	if task.ParentID != "" {
+		if err := s.checkParent(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkWIP(ctx, wf, task.ProjectID, statusCounts(task)); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, 1); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
 	task.CreatedAt = now
 	task.UpdatedAt = now
+	task.Rank = model.TimeRank(now)
 	if err := s.repo.CreateTask(ctx, task); err != nil {
+		if errors.Is(err, repository.ErrLabelNotFound) {
+			// An unknown label in the request body is invalid input.
+			return nil, apperror.InvalidField("labels", err.Error())
+		}
 		s.logger.Error("failed to create task", zap.Error(err))
 		return nil, err
 	}
+	s.record(ctx, model.HistoryEntry{Action: model.HistoryCreate}, nil, task)
 	return task, nil
 }
 
@@ -55,68 +162,298 @@ func (s *taskServiceImpl) GetTask(ctx context.Context, id string) (*model.Task,
 	return task, nil
 }
 
-// ListTasks returns all tasks.
-func (s *taskServiceImpl) ListTasks(ctx context.Context) ([]*model.Task, error) {
-	tasks, err := s.repo.ListTasks(ctx)
+// Page size bounds for ListTasks.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// ListTasks returns a page of tasks selected by q. A zero q.Limit selects
+// DefaultPageSize; larger limits are capped at MaxPageSize.
+func (s *taskServiceImpl) ListTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	q.Limit = min(q.Limit, MaxPageSize)
+	page, err := s.repo.QueryTasks(ctx, q)
 	if err != nil {
 		s.logger.Error("failed to list tasks", zap.Error(err))
 		return nil, err
 	}
-	// Sort tasks by CreatedAt ascending
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
-	})
-	return tasks, nil
+	return page, nil
+}
+
+// MaxUpcomingDays bounds the window of UpcomingTasks.
+const MaxUpcomingDays = 366
+
+// OverdueTasks lists incomplete tasks whose due time has passed, by default
+// most overdue first.
+func (s *taskServiceImpl) OverdueTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	return s.listDue(ctx, q, time.Time{}, s.now())
+}
+
+// DueTodayTasks lists incomplete tasks due on the current calendar day in loc,
+// including those whose time today has already passed.
+func (s *taskServiceImpl) DueTodayTasks(ctx context.Context, loc *time.Location, q model.TaskQuery) (*model.TaskPage, error) {
+	today := startOfDay(s.now(), loc)
+	return s.listDue(ctx, q, today, today.AddDate(0, 0, 1))
+}
+
+// UpcomingTasks lists incomplete tasks due from now until the end of the day
+// days calendar days after today in loc.
+func (s *taskServiceImpl) UpcomingTasks(ctx context.Context, loc *time.Location, days int, q model.TaskQuery) (*model.TaskPage, error) {
+	if days < 0 || days > MaxUpcomingDays {
+		return nil, apperror.InvalidField("days", fmt.Sprintf("days must be between 0 and %d", MaxUpcomingDays))
+	}
+	now := s.now()
+	return s.listDue(ctx, q, now, startOfDay(now, loc).AddDate(0, 0, days+1))
+}
+
+// listDue lists the incomplete tasks due in [from, to) with q's other
+// parameters, sorted by due date unless q says otherwise.
+func (s *taskServiceImpl) listDue(ctx context.Context, q model.TaskQuery, from, to time.Time) (*model.TaskPage, error) {
+	incomplete := false
+	q.Filter.Completed = &incomplete
+	q.Filter.DueAfter, q.Filter.DueBefore = from, to
+	if q.Sort == "" {
+		q.Sort = model.SortByDueAt
+	}
+	return s.ListTasks(ctx, q)
 }
 
-// UpdateTask updates an existing task by ID. Allows partial updates.
+// startOfDay returns midnight of t's calendar day in loc. AddDate on the result
+// steps whole calendar days, so days that are 23 or 25 hours long around DST
+// changes are handled.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// UpdateTask replaces the mutable fields of an existing task with those of update.
+// Fields missing from update are reset to their zero values; use PatchTask for
+// partial updates. Labels are kept; they change through AttachLabel and DetachLabel.
+// A missing status is derived from completed, like for clients that predate
+// statuses.
 func (s *taskServiceImpl) UpdateTask(ctx context.Context, id string, update *model.Task) (*model.Task, error) {
+	return s.modifyTask(ctx, id, update.Version, func(task *model.Task) error {
+		task.Title = update.Title
+		task.Description = update.Description
+		task.Status = update.Status
+		task.Completed = update.Completed
+		task.Priority = update.Priority
+		task.StartAt = update.StartAt
+		task.DueAt = update.DueAt
+		task.ParentID = update.ParentID
+		task.EstimateMinutes = update.EstimateMinutes
+		task.Assignee = update.Assignee
+		task.Recurrence = model.ReplaceRecurrence(task.Recurrence, update.Recurrence)
+		return nil
+	})
+}
+
+// PatchTask applies a partial update to an existing task. A non-zero version
+// must match the stored version.
+func (s *taskServiceImpl) PatchTask(ctx context.Context, id string, patch *model.TaskPatch, version int64) (*model.Task, error) {
+	return s.modifyTask(ctx, id, version, patch.Apply)
+}
+
+// AttachLabel adds the named label to a task. Attaching a label the task
+// already carries changes nothing.
+func (s *taskServiceImpl) AttachLabel(ctx context.Context, id, name string, version int64) (*model.Task, error) {
+	if err := model.ValidateLabelName(name); err != nil {
+		return nil, err
+	}
+	return s.modifyTask(ctx, id, version, func(task *model.Task) error {
+		if task.HasLabel(name) {
+			return errUnchanged
+		}
+		task.Labels = model.NormalizeLabels(append(task.Labels, name))
+		return nil
+	})
+}
+
+// DetachLabel removes the named label from a task. Detaching a label the task
+// does not carry changes nothing.
+func (s *taskServiceImpl) DetachLabel(ctx context.Context, id, name string, version int64) (*model.Task, error) {
+	return s.modifyTask(ctx, id, version, func(task *model.Task) error {
+		if !task.HasLabel(name) {
+			return errUnchanged
+		}
+		task.Labels = slices.DeleteFunc(task.Labels, func(l string) bool { return l == name })
+		return nil
+	})
+}
+
+// errUnchanged is returned by a modifyTask mutation that leaves the task as it
+// is; the task is then returned without a write.
+var errUnchanged = errors.New("task unchanged")
+
+// modifyTask loads a task, checks the caller's expected version, applies mutate,
+// validates and stores the result. A status change must follow the workflow of
+// the task's project and respect its WIP limits, added labels must be allowed
+// in it, a changed parent is checked for existence and cycles, and completing
+// the task applies the completion cascade policy. A new assignee must exist and
+// the reassignment rules of checkReassign hold. Doing a recurring task creates
+// its next occurrence. Every write is recorded in the task history.
+func (s *taskServiceImpl) modifyTask(ctx context.Context, id string, version int64, mutate func(*model.Task) error) (*model.Task, error) {
+	return s.modifyTaskAs(ctx, id, version, model.HistoryEntry{Action: model.HistoryUpdate}, mutate)
+}
+
+// modifyTaskAs is modifyTask recording the change as entry.
+func (s *taskServiceImpl) modifyTaskAs(ctx context.Context, id string, version int64, entry model.HistoryEntry, mutate func(*model.Task) error) (*model.Task, error) {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
 	task, err := s.repo.GetTask(ctx, id)
 	if err != nil {
 		s.logger.Warn("task not found for update", zap.String("id", id), zap.Error(err))
 		return nil, err
 	}
-	// If update.Title is explicitly set to empty string, that's a validation error
-	if update.Title == "" {
-		err := errors.New("title cannot be empty")
-		s.logger.Warn("validation failed on update", zap.Error(err))
+	// A non-zero version is the version the caller based its edit on.
+	if err := checkVersion(task, version); err != nil {
+		s.logger.Warn("stale version on update", zap.Error(err))
 		return nil, err
 	}
-
-	// Only update fields that are set (partial update)
-	if update.Title != "" {
-		task.Title = update.Title
-	}
-	if update.Description != "" {
-		task.Description = update.Description
+	before := task.Clone()
+	if err := mutate(task); err != nil {
+		if err == errUnchanged {
+			return task, nil
+		}
+		s.logger.Warn("update rejected", zap.String("id", id), zap.Error(err))
+		return nil, err
 	}
-	// Only update Completed if explicitly set (cannot distinguish false from unset in Go, so always update)
-	task.Completed = update.Completed
-	task.UpdatedAt = time.Now().UTC()
+	task.UpdatedAt = s.now().UTC()
 
-	// Validate before calling repo.UpdateTask
+	// Validate before writing the task
 	if err := task.Validate(); err != nil {
 		s.logger.Warn("validation failed on update", zap.Error(err))
 		return nil, err
 	}
+	project, err := s.projectOf(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProjectLabels(project, before, task); err != nil {
+		return nil, err
+	}
+	wf := s.workflowOf(project)
+	if err := moveStatus(wf, before, task); err != nil {
+		s.logger.Warn("status change rejected", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	if task.ParentID != before.ParentID && task.ParentID != "" {
+		if err := s.checkParent(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkReassign(ctx, before, task); err != nil {
+		return nil, err
+	}
+	// The task, the subtasks its completion changes and its next occurrence
+	// are stored together.
+	batch := repository.TaskBatch{Update: []*model.Task{task}}
+	befores := []*model.Task{before}
+	completing := task.Completed && !before.Completed
+	if completing {
+		cascaded, changed, err := s.planCompletion(ctx, wf, task)
+		if err != nil {
+			return nil, err
+		}
+		befores = append(befores, cascaded...)
+		batch.Update = append(batch.Update, changed...)
+	}
+	// The subtasks completed along with the task enter its column too.
+	var moving []*model.Task
+	for i, t := range batch.Update {
+		if t.Status != befores[i].Status {
+			moving = append(moving, t)
+		}
+	}
+	if err := s.checkWIP(ctx, wf, task.ProjectID, statusCounts(moving...)); err != nil {
+		s.logger.Warn("status change rejected", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	if completing {
+		next, err := s.planRecurrence(ctx, project, task)
+		if err != nil {
+			return nil, err
+		}
+		if next != nil {
+			batch.Create = []*model.Task{next}
+		}
+	}
 
-	if err := s.repo.UpdateTask(ctx, task); err != nil {
-		s.logger.Error("failed to update task", zap.Error(err))
+	if err := s.repo.WriteTasks(ctx, batch); err != nil {
+		s.logger.Error("failed to update task", zap.String("id", id), zap.Error(err))
 		return nil, err
 	}
+	s.record(ctx, entry, before, task)
+	for i, t := range batch.Update[1:] {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryUpdate}, befores[i+1], t)
+	}
+	for _, t := range batch.Create {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryCreate}, nil, t)
+	}
 	return task, nil
 }
 
-// DeleteTask deletes a task by ID.
-func (s *taskServiceImpl) DeleteTask(ctx context.Context, id string) error {
-	if err := s.repo.DeleteTask(ctx, id); err != nil {
+// DeleteTask moves a task to the trash, from where UndeleteTask takes it back.
+// A non-zero version must match the stored version. Subtasks are handled
+// according to the deletion cascade policy; the cascade policy trashes them
+// along with the task.
+func (s *taskServiceImpl) DeleteTask(ctx context.Context, id string, version int64) error {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
+	task, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		s.logger.Warn("task not found for delete", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if err := checkVersion(task, version); err != nil {
+		s.logger.Warn("stale version on delete", zap.Error(err))
+		return err
+	}
+	now := s.now().UTC()
+	befores, changed, err := s.planDeletion(ctx, task, now)
+	if err != nil {
+		s.logger.Warn("failed to delete subtasks", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	befores = append(befores, task.Clone())
+	task.DeletedAt = &now
+	task.UpdatedAt = now
+	// The task and the subtasks its deletion changes are stored together.
+	tasks := append(changed, task)
+	if err := s.repo.WriteTasks(ctx, repository.TaskBatch{Update: tasks}); err != nil {
 		s.logger.Warn("failed to delete task", zap.String("id", id), zap.Error(err))
 		return err
 	}
+	for i, t := range tasks {
+		entry := model.HistoryEntry{Action: model.HistoryUpdate}
+		if t.DeletedAt != nil {
+			entry.Action = model.HistoryDelete
+		}
+		s.record(ctx, entry, befores[i], t)
+	}
+	return nil
+}
+
+// checkVersion fails with a version conflict unless version is zero or the
+// version of task.
+func checkVersion(task *model.Task, version int64) error {
+	if version != 0 && version != task.Version {
+		return &repository.VersionConflictError{ID: task.ID, Expected: version, Actual: task.Version}
+	}
 	return nil
 }
 
 // generateTaskID is a stub for generating a unique string ID (to be improved in later tasks)
 
 // ErrTaskNotFound is returned when a task is not found.
-var ErrTaskNotFound = errors.New("task not found")
+var ErrTaskNotFound = repository.ErrTaskNotFound
+
+// ErrVersionConflict is matched by errors returned when a write is based on a
+// stale task version.
+var ErrVersionConflict = repository.ErrVersionConflict
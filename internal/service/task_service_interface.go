@@ -3,13 +3,85 @@ package service
 import (
 	"context"
 	"taskmanager/internal/model"
+	"time"
 )
 
 // TaskService defines the business logic interface for tasks.
 type TaskService interface {
+	// CreateTask stores a new task created by the request's actor in the
+	// project of ctx, see InProject. An unknown assignee is invalid input.
 	CreateTask(ctx context.Context, task *model.Task) (*model.Task, error)
 	GetTask(ctx context.Context, id string) (*model.Task, error)
-	ListTasks(ctx context.Context) ([]*model.Task, error)
+	// ListTasks returns the page of tasks selected by q; see model.TaskQuery.
+	ListTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error)
+	// OverdueTasks lists incomplete tasks past their due time. The due-date views
+	// take q's other filters, sort order and paging and default to sorting by due date.
+	OverdueTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error)
+	// DueTodayTasks lists incomplete tasks due on today's date in loc.
+	DueTodayTasks(ctx context.Context, loc *time.Location, q model.TaskQuery) (*model.TaskPage, error)
+	// UpcomingTasks lists incomplete tasks due between now and the end of the day
+	// days days from today in loc.
+	UpcomingTasks(ctx context.Context, loc *time.Location, days int, q model.TaskQuery) (*model.TaskPage, error)
+	// UpdateTask replaces the task's mutable fields with those of update. A non-zero
+	// update.Version must match the current version, otherwise an error matching
+	// ErrVersionConflict is returned. Changing the assignee of an assigned task is
+	// reserved to its assignee and creator (ErrReassignForbidden), and completed
+	// tasks keep theirs (ErrReassignCompleted).
 	UpdateTask(ctx context.Context, id string, update *model.Task) (*model.Task, error)
-	DeleteTask(ctx context.Context, id string) error
+	// PatchTask applies a partial update; version is checked like in UpdateTask.
+	PatchTask(ctx context.Context, id string, patch *model.TaskPatch, version int64) (*model.Task, error)
+	// AttachLabel adds an existing label to the task; version is checked like in
+	// UpdateTask. An unknown label yields an error matching ErrLabelNotFound.
+	AttachLabel(ctx context.Context, id, name string, version int64) (*model.Task, error)
+	// DetachLabel removes a label from the task; version is checked like in UpdateTask.
+	DetachLabel(ctx context.Context, id, name string, version int64) (*model.Task, error)
+	// DeleteTask moves the task to the trash; a non-zero version must match the
+	// current version. Its subtasks are handled according to the configured
+	// cascade policy; the block policy yields an error matching ErrHasSubtasks.
+	// Trashed tasks are hidden unless a query sets model.TaskFilter.Trashed.
+	DeleteTask(ctx context.Context, id string, version int64) error
+	// GetTrashedTask retrieves a task in the trash.
+	GetTrashedTask(ctx context.Context, id string) (*model.Task, error)
+	// UndeleteTask takes the task, and the subtasks deleted with it, out of the
+	// trash; version is checked like in DeleteTask. A live task yields an error
+	// matching ErrNotInTrash, a subtask of a trashed task one matching
+	// ErrParentInTrash.
+	UndeleteTask(ctx context.Context, id string, version int64) (*model.Task, error)
+	// PurgeTask permanently deletes a task in the trash and its subtasks;
+	// version is checked like in DeleteTask.
+	PurgeTask(ctx context.Context, id string, version int64) error
+	// PurgeTrash permanently deletes the tasks trashed more than retention ago
+	// and returns how many it deleted.
+	PurgeTrash(ctx context.Context, retention time.Duration) (int, error)
+	// ListChildren returns a page of the direct subtasks of task id, selected by q
+	// like in ListTasks.
+	ListChildren(ctx context.Context, id string, q model.TaskQuery) (*model.TaskPage, error)
+	// GetSubtree returns task id with all its subtasks and their roll-up progress.
+	GetSubtree(ctx context.Context, id string) (*model.TaskTree, error)
+	// Occurrences previews up to limit (default DefaultOccurrencesPreview)
+	// upcoming occurrences of a recurring task.
+	Occurrences(ctx context.Context, id string, limit int) ([]model.Occurrence, error)
+	// MoveTask moves task id with its subtasks to project projectID, or out of
+	// any project if it is empty; version is checked like in UpdateTask.
+	// Statuses the project's workflow lacks are reset and its label rules apply.
+	MoveTask(ctx context.Context, id, projectID string, version int64) (*model.Task, error)
+	// CopyTask copies task id with its live subtasks to project projectID, or
+	// outside any project if it is empty, and returns the copied task.
+	CopyTask(ctx context.Context, id, projectID string) (*model.Task, error)
+	// Board returns the tasks matching q's filter in columns by status, in
+	// rank order, for the project of the context's scope or of q's filter.
+	Board(ctx context.Context, q model.TaskQuery) (*model.Board, error)
+	// MoveOnBoard places task id in a column and between two of its tasks;
+	// version is checked like in UpdateTask. Columns keep their WIP limits.
+	MoveOnBoard(ctx context.Context, id string, move model.BoardMove, version int64) (*model.Task, error)
+	// Workflow returns the state machine that task statuses follow outside
+	// projects with a workflow of their own.
+	Workflow() *model.Workflow
+	// History returns the recorded changes of task id, oldest first; it stays
+	// available after the task is deleted.
+	History(ctx context.Context, id string) ([]*model.HistoryEntry, error)
+	// RestoreTask returns task id to its state at revision; version is checked
+	// like in UpdateTask. An unknown revision yields an error matching
+	// ErrRevisionNotFound.
+	RestoreTask(ctx context.Context, id string, revision, version int64) (*model.Task, error)
 }
@@ -11,7 +11,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 
+	"taskmanager/internal/apperror"
 	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
 	// ...existing code...
 )
 
@@ -28,19 +30,189 @@ func (m *MockTaskRepository) GetTask(ctx context.Context, id string) (*model.Tas
 	args := m.Called(ctx, id)
 	return args.Get(0).(*model.Task), args.Error(1)
 }
+func (m *MockTaskRepository) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Task), args.Error(1)
+}
 func (m *MockTaskRepository) ListTasks(ctx context.Context) ([]*model.Task, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]*model.Task), args.Error(1)
 }
+func (m *MockTaskRepository) CountTasks(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockTaskRepository) QueryTasks(ctx context.Context, q model.TaskQuery) (*model.TaskPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(*model.TaskPage), args.Error(1)
+}
 func (m *MockTaskRepository) UpdateTask(ctx context.Context, task *model.Task) error {
 	args := m.Called(ctx, task)
 	return args.Error(0)
 }
-func (m *MockTaskRepository) DeleteTask(ctx context.Context, id string) error {
+func (m *MockTaskRepository) DeleteTask(ctx context.Context, id string, version int64) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) CreateLabel(ctx context.Context, label *model.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) GetLabel(ctx context.Context, name string) (*model.Label, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(*model.Label), args.Error(1)
+}
+func (m *MockTaskRepository) ListLabels(ctx context.Context) ([]*model.Label, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*model.Label), args.Error(1)
+}
+func (m *MockTaskRepository) UpdateLabel(ctx context.Context, label *model.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) DeleteLabel(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) AddDependency(ctx context.Context, dep *model.Dependency) error {
+	args := m.Called(ctx, dep)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	args := m.Called(ctx, taskID, blockerID)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) ListDependencies(ctx context.Context) ([]*model.Dependency, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*model.Dependency), args.Error(1)
+}
+func (m *MockTaskRepository) ListBlockers(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*model.Dependency), args.Error(1)
+}
+func (m *MockTaskRepository) ListDependents(ctx context.Context, taskID string) ([]*model.Dependency, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*model.Dependency), args.Error(1)
+}
+func (m *MockTaskRepository) AppendHistory(ctx context.Context, entry *model.HistoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+func (m *MockTaskRepository) ListHistory(ctx context.Context, taskID string) ([]*model.HistoryEntry, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*model.HistoryEntry), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateComment(ctx context.Context, comment *model.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetComment(ctx context.Context, taskID, id string) (*model.Comment, error) {
+	args := m.Called(ctx, taskID, id)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockTaskRepository) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteComment(ctx context.Context, taskID, id string) error {
+	args := m.Called(ctx, taskID, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ListComments(ctx context.Context, q model.CommentQuery) (*model.CommentPage, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(*model.CommentPage), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateAttachment(ctx context.Context, attachment *model.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetAttachment(ctx context.Context, taskID, id string) (*model.Attachment, error) {
+	args := m.Called(ctx, taskID, id)
+	return args.Get(0).(*model.Attachment), args.Error(1)
+}
+
+func (m *MockTaskRepository) ListAttachments(ctx context.Context, taskID string) ([]*model.Attachment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]*model.Attachment), args.Error(1)
+}
+
+func (m *MockTaskRepository) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	args := m.Called(ctx, taskID, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) ListAttachmentBlobs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTaskRepository) CreateUser(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockTaskRepository) ListUsers(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockTaskRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteUser(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) WriteTasks(ctx context.Context, batch repository.TaskBatch) error {
+	args := m.Called(ctx, batch)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) CreateProject(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*model.Project), args.Error(1)
+}
+
+func (m *MockTaskRepository) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*model.Project), args.Error(1)
+}
+
+func (m *MockTaskRepository) UpdateProject(ctx context.Context, project *model.Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) DeleteProject(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) NextTaskNumber(ctx context.Context, id string) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestTaskService_CreateTask_Success(t *testing.T) {
 	repo := new(MockTaskRepository)
 	logger := zap.NewNop()
@@ -52,6 +224,9 @@ func TestTaskService_CreateTask_Success(t *testing.T) {
 		Completed:   false,
 	}
 	repo.On("CreateTask", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
+	repo.On("AppendHistory", ctx, mock.MatchedBy(func(e *model.HistoryEntry) bool {
+		return e.Action == model.HistoryCreate && e.TaskID == task.ID
+	})).Return(nil)
 	created, err := ts.CreateTask(ctx, task)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, created.ID)
@@ -107,13 +282,33 @@ func TestTaskService_ListTasks(t *testing.T) {
 	ts := NewTaskService(repo, logger)
 	ctx := context.Background()
 	tasks := []*model.Task{{ID: "task-1", Title: "A", Completed: false}}
-	repo.On("ListTasks", ctx).Return(tasks, nil)
-	got, err := ts.ListTasks(ctx)
+	repo.On("QueryTasks", ctx, model.TaskQuery{Limit: DefaultPageSize}).Return(&model.TaskPage{Tasks: tasks}, nil)
+	got, err := ts.ListTasks(ctx, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, got.Tasks, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_ListTasks_CapsLimit(t *testing.T) {
+	repo := new(MockTaskRepository)
+	ts := NewTaskService(repo, zap.NewNop())
+	ctx := context.Background()
+	repo.On("QueryTasks", ctx, model.TaskQuery{Sort: model.SortByTitle, Limit: MaxPageSize}).Return(&model.TaskPage{}, nil)
+	_, err := ts.ListTasks(ctx, model.TaskQuery{Sort: model.SortByTitle, Limit: MaxPageSize * 10})
 	assert.NoError(t, err)
-	assert.Len(t, got, 1)
 	repo.AssertExpectations(t)
 }
 
+func TestTaskService_ListTasks_InvalidQuery(t *testing.T) {
+	repo := new(MockTaskRepository)
+	ts := NewTaskService(repo, zap.NewNop())
+	cursor := (&model.TaskQuery{}).CursorAt(&model.Task{ID: "a"})
+	_, err := ts.ListTasks(context.Background(), model.TaskQuery{Sort: model.SortByTitle, After: cursor})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	assert.Equal(t, "cursor", apperror.FieldsOf(err)[0].Field)
+	repo.AssertNotCalled(t, "QueryTasks", mock.Anything, mock.Anything)
+}
+
 func TestTaskService_UpdateTask_Success(t *testing.T) {
 	repo := new(MockTaskRepository)
 	logger := zap.NewNop()
@@ -123,7 +318,10 @@ func TestTaskService_UpdateTask_Success(t *testing.T) {
 	existing := &model.Task{ID: id, Title: "Old", Completed: false}
 	update := &model.Task{Title: "New", Completed: true}
 	repo.On("GetTask", ctx, id).Return(existing, nil)
-	repo.On("UpdateTask", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
+	repo.On("QueryTasks", ctx, mock.Anything).Return(&model.TaskPage{}, nil)
+	repo.On("ListBlockers", ctx, id).Return([]*model.Dependency{}, nil)
+	repo.On("WriteTasks", ctx, mock.AnythingOfType("repository.TaskBatch")).Return(nil)
+	repo.On("AppendHistory", ctx, mock.AnythingOfType("*model.HistoryEntry")).Return(nil)
 	got, err := ts.UpdateTask(ctx, id, update)
 	assert.NoError(t, err)
 	assert.Equal(t, "New", got.Title)
@@ -166,8 +364,15 @@ func TestTaskService_DeleteTask_Success(t *testing.T) {
 	ts := NewTaskService(repo, logger)
 	ctx := context.Background()
 	id := "task-1"
-	repo.On("DeleteTask", ctx, id).Return(nil)
-	err := ts.DeleteTask(ctx, id)
+	repo.On("QueryTasks", ctx, mock.Anything).Return(&model.TaskPage{}, nil)
+	repo.On("GetTask", ctx, id).Return(&model.Task{ID: id, Title: "Doomed", Version: 1}, nil)
+	repo.On("WriteTasks", ctx, mock.MatchedBy(func(batch repository.TaskBatch) bool {
+		return len(batch.Update) == 1 && batch.Update[0].ID == id && batch.Update[0].DeletedAt != nil
+	})).Return(nil)
+	repo.On("AppendHistory", ctx, mock.MatchedBy(func(e *model.HistoryEntry) bool {
+		return e.Action == model.HistoryDelete && e.TaskID == id
+	})).Return(nil)
+	err := ts.DeleteTask(ctx, id, 0)
 	assert.NoError(t, err)
 	repo.AssertExpectations(t)
 }
@@ -178,9 +383,9 @@ func TestTaskService_DeleteTask_NotFound(t *testing.T) {
 	ts := NewTaskService(repo, logger)
 	ctx := context.Background()
 	id := "task-1"
-	repo.On("DeleteTask", ctx, id).Return(errors.New("task not found"))
-	err := ts.DeleteTask(ctx, id)
-	assert.Error(t, err)
+	repo.On("GetTask", ctx, id).Return((*model.Task)(nil), repository.ErrTaskNotFound)
+	err := ts.DeleteTask(ctx, id, 0)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
 	repo.AssertExpectations(t)
 }
 
@@ -197,9 +402,209 @@ func TestTaskService_CreateTask_WithUserSuppliedID(t *testing.T) {
 		Completed:   false,
 	}
 	repo.On("CreateTask", ctx, mock.AnythingOfType("*model.Task")).Return(nil)
+	repo.On("AppendHistory", ctx, mock.AnythingOfType("*model.HistoryEntry")).Return(nil)
 	created, err := ts.CreateTask(ctx, task)
 	assert.NoError(t, err)
 	assert.Equal(t, userID, created.ID)
 	assert.Equal(t, task.Title, created.Title)
 	repo.AssertExpectations(t)
 }
+
+func TestTaskService_ReservedIDOnlyOnCreate(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	ts := NewTaskService(repo, zap.NewNop())
+	ctx := context.Background()
+	_, err := ts.CreateTask(ctx, &model.Task{ID: "board", Title: "Board review"})
+	assert.Equal(t, "id", apperror.FieldsOf(err)[0].Field)
+
+	// A task stored before the board view took its ID can still be changed.
+	assert.NoError(t, repo.CreateTask(ctx, &model.Task{ID: "board", Title: "Board review", Status: model.StatusTodo}))
+	done := true
+	got, err := ts.PatchTask(ctx, "board", &model.TaskPatch{Completed: &done}, 0)
+	assert.NoError(t, err)
+	assert.True(t, got.Completed)
+}
+
+func TestTaskService_UpdateTask_StaleVersion(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := zap.NewNop()
+	ts := NewTaskService(repo, logger)
+	ctx := context.Background()
+	id := "task-1"
+	existing := &model.Task{ID: id, Title: "Old", Version: 3}
+	update := &model.Task{Title: "New", Version: 2}
+	repo.On("GetTask", ctx, id).Return(existing, nil)
+	got, err := ts.UpdateTask(ctx, id, update)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Nil(t, got)
+	repo.AssertNotCalled(t, "WriteTasks", mock.Anything, mock.Anything)
+}
+
+func TestTaskService_UpdateTask_ConcurrentWriteConflict(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := zap.NewNop()
+	ts := NewTaskService(repo, logger)
+	ctx := context.Background()
+	id := "task-1"
+	existing := &model.Task{ID: id, Title: "Old", Version: 3}
+	repo.On("GetTask", ctx, id).Return(existing, nil)
+	repo.On("WriteTasks", ctx, mock.AnythingOfType("repository.TaskBatch")).
+		Return(&repository.VersionConflictError{ID: id, Expected: 3, Actual: 4})
+	got, err := ts.UpdateTask(ctx, id, &model.Task{Title: "New"})
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Nil(t, got)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_UpdateTask_FullReplacement(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := zap.NewNop()
+	ts := NewTaskService(repo, logger)
+	ctx := context.Background()
+	id := "task-1"
+	due := time.Date(2025, 3, 10, 17, 0, 0, 0, time.UTC)
+	existing := &model.Task{ID: id, Title: "Old", Description: "Old description", Completed: true,
+		Priority: model.PriorityHigh, DueAt: &due}
+	repo.On("GetTask", ctx, id).Return(existing, nil)
+	repo.On("WriteTasks", ctx, mock.AnythingOfType("repository.TaskBatch")).Return(nil)
+	repo.On("AppendHistory", ctx, mock.AnythingOfType("*model.HistoryEntry")).Return(nil)
+	got, err := ts.UpdateTask(ctx, id, &model.Task{Title: "New"})
+	assert.NoError(t, err)
+	assert.Equal(t, "New", got.Title)
+	assert.Empty(t, got.Description)
+	assert.False(t, got.Completed)
+	assert.Equal(t, model.PriorityNone, got.Priority)
+	assert.Nil(t, got.DueAt)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_PatchTask_KeepsUnsetFields(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := zap.NewNop()
+	ts := NewTaskService(repo, logger)
+	ctx := context.Background()
+	id := "task-1"
+	existing := &model.Task{ID: id, Title: "Old", Description: "Keep me", Completed: true, Version: 2}
+	repo.On("GetTask", ctx, id).Return(existing, nil)
+	repo.On("WriteTasks", ctx, mock.AnythingOfType("repository.TaskBatch")).Return(nil)
+	repo.On("AppendHistory", ctx, mock.AnythingOfType("*model.HistoryEntry")).Return(nil)
+	title := "New"
+	got, err := ts.PatchTask(ctx, id, &model.TaskPatch{Title: &title}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "New", got.Title)
+	assert.Equal(t, "Keep me", got.Description)
+	assert.True(t, got.Completed)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_PatchTask_ValidationError(t *testing.T) {
+	repo := new(MockTaskRepository)
+	logger := zap.NewNop()
+	ts := NewTaskService(repo, logger)
+	ctx := context.Background()
+	id := "task-1"
+	repo.On("GetTask", ctx, id).Return(&model.Task{ID: id, Title: "Old"}, nil)
+	empty := ""
+	got, err := ts.PatchTask(ctx, id, &model.TaskPatch{Title: &empty}, 0)
+	assert.ErrorContains(t, err, "title is required")
+	assert.Nil(t, got)
+	repo.AssertNotCalled(t, "WriteTasks", mock.Anything, mock.Anything)
+}
+
+// newDueFixture returns a service over an in-memory repository whose clock is
+// fixed at now, seeded with tasks due at the given times.
+func newDueFixture(t *testing.T, now time.Time, due map[string]time.Time) TaskService {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	ts := NewTaskService(repo, zap.NewNop(), WithClock(func() time.Time { return now }))
+	for id, at := range due {
+		at := at
+		_, err := ts.CreateTask(context.Background(), &model.Task{ID: id, Title: id, DueAt: &at})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := ts.CreateTask(context.Background(), &model.Task{ID: "undated", Title: "undated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func pageIDs(page *model.TaskPage) []string {
+	ids := []string{}
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestTaskService_DueViews(t *testing.T) {
+	// 23:30 on 10 March in UTC-5 is already 11 March in UTC.
+	zone := time.FixedZone("UTC-5", -5*3600)
+	now := time.Date(2025, 3, 10, 23, 30, 0, 0, zone)
+	ts := newDueFixture(t, now, map[string]time.Time{
+		"yesterday":     time.Date(2025, 3, 9, 12, 0, 0, 0, zone),
+		"earlier-today": time.Date(2025, 3, 10, 8, 0, 0, 0, zone),
+		"later-today":   time.Date(2025, 3, 10, 23, 45, 0, 0, zone),
+		"tomorrow":      time.Date(2025, 3, 11, 9, 0, 0, 0, zone),
+		"in-3-days":     time.Date(2025, 3, 13, 23, 59, 0, 0, zone),
+		"in-4-days":     time.Date(2025, 3, 14, 0, 0, 0, 0, zone),
+	})
+	ctx := context.Background()
+
+	page, err := ts.OverdueTasks(ctx, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"yesterday", "earlier-today"}, pageIDs(page))
+
+	page, err = ts.DueTodayTasks(ctx, zone, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"earlier-today", "later-today"}, pageIDs(page))
+
+	page, err = ts.DueTodayTasks(ctx, time.UTC, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"later-today", "tomorrow"}, pageIDs(page), "today in UTC is 11 March")
+
+	page, err = ts.UpcomingTasks(ctx, zone, 3, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"later-today", "tomorrow", "in-3-days"}, pageIDs(page))
+
+	page, err = ts.UpcomingTasks(ctx, zone, 3, model.TaskQuery{Sort: model.SortByTitle})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in-3-days", "later-today", "tomorrow"}, pageIDs(page), "caller's sort wins")
+}
+
+func TestTaskService_DueViewsSkipCompleted(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	ts := newDueFixture(t, now, map[string]time.Time{"late": now.Add(-time.Hour)})
+	ctx := context.Background()
+	done := true
+	_, err := ts.PatchTask(ctx, "late", &model.TaskPatch{Completed: &done}, 0)
+	assert.NoError(t, err)
+
+	page, err := ts.OverdueTasks(ctx, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+}
+
+func TestTaskService_DueTodayAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	// 9 March 2025 is 23 hours long in New York.
+	now := time.Date(2025, 3, 9, 12, 0, 0, 0, loc)
+	ts := newDueFixture(t, now, map[string]time.Time{
+		"last-minute": time.Date(2025, 3, 9, 23, 59, 0, 0, loc),
+		"next-day":    time.Date(2025, 3, 10, 0, 0, 0, 0, loc),
+	})
+	page, err := ts.DueTodayTasks(context.Background(), loc, model.TaskQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"last-minute"}, pageIDs(page))
+}
+
+func TestTaskService_UpcomingTasks_InvalidDays(t *testing.T) {
+	ts := NewTaskService(new(MockTaskRepository), zap.NewNop())
+	_, err := ts.UpcomingTasks(context.Background(), time.UTC, MaxUpcomingDays+1, model.TaskQuery{})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+}
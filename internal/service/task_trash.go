@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrNotInTrash is matched by errors returned when undeleting or purging a
+// task that has not been deleted.
+var ErrNotInTrash = apperror.Conflict("task is not in the trash")
+
+// ErrParentInTrash is matched by errors returned when undeleting a subtask
+// whose parent is still in the trash.
+var ErrParentInTrash = apperror.Conflict("parent task is in the trash")
+
+// GetTrashedTask retrieves a task in the trash by ID.
+func (s *taskServiceImpl) GetTrashedTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := s.repo.GetTrashedTask(ctx, id)
+	if err != nil {
+		s.logger.Warn("task not found in trash", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return task, nil
+}
+
+// UndeleteTask takes task id out of the trash, together with the subtasks that
+// were deleted along with it. A non-zero version must match the stored version.
+// A subtask cannot come back before its parent; if the parent has been purged
+// it comes back as a top-level task. The tasks return to their board columns,
+// which must have room for them, and count towards the tenant's task quota.
+func (s *taskServiceImpl) UndeleteTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
+	task, err := s.trashedTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		s.logger.Warn("stale version on undelete", zap.Error(err))
+		return nil, err
+	}
+	orphaned, err := s.parentPurged(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	together, err := s.trashedWith(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	tasks := append([]*model.Task{task}, together...)
+	project, err := s.projectOf(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	returning := make(map[string]int)
+	for _, t := range tasks {
+		returning[t.Status]++
+	}
+	if err := s.checkWIP(ctx, s.workflowOf(project), task.ProjectID, returning); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, len(tasks)); err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	befores := make([]*model.Task, len(tasks))
+	for i, t := range tasks {
+		befores[i] = t.Clone()
+		if t == task && orphaned {
+			t.ParentID = ""
+		}
+		t.DeletedAt = nil
+		t.UpdatedAt = now
+	}
+	if err := s.repo.WriteTasks(ctx, repository.TaskBatch{Update: tasks}); err != nil {
+		s.logger.Error("failed to undelete task", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	for i, t := range tasks {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryUndelete}, befores[i], t)
+	}
+	return task, nil
+}
+
+// PurgeTask permanently deletes task id, which must be in the trash, along
+// with the subtasks deleted with it. A non-zero version must match the stored
+// version.
+func (s *taskServiceImpl) PurgeTask(ctx context.Context, id string, version int64) error {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
+	task, err := s.trashedTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(task, version); err != nil {
+		s.logger.Warn("stale version on purge", zap.Error(err))
+		return err
+	}
+	_, err = s.purge(ctx, task)
+	return err
+}
+
+// PurgeTrash permanently deletes the tasks that have been in the trash for
+// longer than retention and returns how many it deleted.
+func (s *taskServiceImpl) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	s.hierarchy.Lock()
+	defer s.hierarchy.Unlock()
+	cutoff := s.now().UTC().Add(-retention)
+	page, err := s.repo.QueryTasks(ctx, model.TaskQuery{Filter: model.TaskFilter{Trashed: true, DeletedBefore: cutoff}})
+	if err != nil {
+		s.logger.Error("failed to list expired trash", zap.Error(err))
+		return 0, err
+	}
+	purged := 0
+	for _, task := range page.Tasks {
+		// Purging a task purges its subtasks, so some of the listed tasks may
+		// be gone already.
+		task, err := s.repo.GetTrashedTask(ctx, task.ID)
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		n, err := s.purge(ctx, task)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// purge permanently deletes the trashed task and the subtasks deleted with it,
+// all at once, and returns how many tasks it deleted. Subtasks trashed on
+// their own stay in the trash as top-level tasks. Callers hold s.hierarchy.
+func (s *taskServiceImpl) purge(ctx context.Context, task *model.Task) (int, error) {
+	together, err := s.trashedWith(ctx, task)
+	if err != nil {
+		return 0, err
+	}
+	doomed := append([]*model.Task{task}, together...)
+	now := s.now().UTC()
+	var befores, kept []*model.Task
+	for _, t := range doomed {
+		kids, err := s.children(ctx, t.ID, true)
+		if err != nil {
+			return 0, err
+		}
+		for _, kid := range kids {
+			if kid.DeletedAt.Equal(*task.DeletedAt) {
+				continue
+			}
+			befores = append(befores, kid.Clone())
+			kid.ParentID = ""
+			kid.UpdatedAt = now
+			kept = append(kept, kid)
+		}
+	}
+	if err := s.repo.WriteTasks(ctx, repository.TaskBatch{Update: kept, Delete: doomed}); err != nil {
+		s.logger.Error("failed to purge task", zap.String("id", task.ID), zap.Error(err))
+		return 0, err
+	}
+	for i, kid := range kept {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryUpdate}, befores[i], kid)
+	}
+	for _, t := range doomed {
+		s.record(ctx, model.HistoryEntry{Action: model.HistoryPurge}, t, nil)
+	}
+	return len(doomed), nil
+}
+
+// trashedWith returns the subtasks of the trashed task that were deleted along
+// with it, breadth first. They share the task's deletion time.
+func (s *taskServiceImpl) trashedWith(ctx context.Context, task *model.Task) ([]*model.Task, error) {
+	var together []*model.Task
+	for queue := []string{task.ID}; len(queue) > 0; queue = queue[1:] {
+		kids, err := s.children(ctx, queue[0], true)
+		if err != nil {
+			return nil, err
+		}
+		for _, kid := range kids {
+			if kid.DeletedAt.Equal(*task.DeletedAt) {
+				together = append(together, kid)
+				queue = append(queue, kid.ID)
+			}
+		}
+	}
+	return together, nil
+}
+
+// parentPurged reports whether the parent of the trashed task is gone for
+// good. It fails with ErrParentInTrash while the parent is in the trash.
+func (s *taskServiceImpl) parentPurged(ctx context.Context, task *model.Task) (bool, error) {
+	if task.ParentID == "" {
+		return false, nil
+	}
+	_, err := s.repo.GetTask(ctx, task.ParentID)
+	if !errors.Is(err, repository.ErrTaskNotFound) {
+		return false, err
+	}
+	_, err = s.repo.GetTrashedTask(ctx, task.ParentID)
+	switch {
+	case err == nil:
+		return false, fmt.Errorf("%w: undelete %s first", ErrParentInTrash, task.ParentID)
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// trashedTask returns task id from the trash, failing with ErrNotInTrash if
+// the task is live.
+func (s *taskServiceImpl) trashedTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := s.repo.GetTrashedTask(ctx, id)
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		if _, liveErr := s.repo.GetTask(ctx, id); liveErr == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNotInTrash, id)
+		}
+	}
+	if err != nil {
+		s.logger.Warn("task not found in trash", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func listIDs(t *testing.T, svc TaskService, trashed bool) []string {
+	t.Helper()
+	page, err := svc.ListTasks(context.Background(), model.TaskQuery{Filter: model.TaskFilter{Trashed: trashed}})
+	require.NoError(t, err)
+	ids := []string{}
+	for _, task := range page.Tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestTaskTrash_DeleteAndUndelete(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "Precious"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeleteTask(ctx, "t", 0))
+	_, err = svc.GetTask(ctx, "t")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{}, 0)
+	assert.ErrorIs(t, err, ErrTaskNotFound, "trashed tasks cannot be edited")
+	assert.ErrorIs(t, svc.DeleteTask(ctx, "t", 0), ErrTaskNotFound)
+	assert.Empty(t, listIDs(t, svc, false))
+	assert.Equal(t, []string{"t"}, listIDs(t, svc, true))
+	trashed, err := svc.GetTrashedTask(ctx, "t")
+	require.NoError(t, err)
+	require.NotNil(t, trashed.DeletedAt)
+
+	_, err = svc.UndeleteTask(ctx, "t", 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	task, err := svc.UndeleteTask(ctx, "t", trashed.Version)
+	require.NoError(t, err)
+	assert.Nil(t, task.DeletedAt)
+	assert.Equal(t, "Precious", task.Title)
+	assert.Equal(t, []string{"t"}, listIDs(t, svc, false))
+
+	_, err = svc.UndeleteTask(ctx, "t", 0)
+	assert.ErrorIs(t, err, ErrNotInTrash)
+	assert.ErrorIs(t, svc.PurgeTask(ctx, "t", 0), ErrNotInTrash)
+	_, err = svc.UndeleteTask(ctx, "missing", 0)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	entries, err := svc.History(ctx, "t")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, model.HistoryDelete, entries[1].Action)
+	assert.Equal(t, model.HistoryUndelete, entries[2].Action)
+	// Revisions from before a deletion can still be restored.
+	_, err = svc.RestoreTask(ctx, "t", 1, 0)
+	assert.NoError(t, err)
+}
+
+func TestTaskTrash_CascadeTrashesAndRestoresTogether(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	svc := newHierarchyFixture(t, WithCascadePolicy(model.CascadeBlock, model.CascadeCascade),
+		WithClock(func() time.Time { return now }))
+	createTree(t, svc)
+	ctx := context.Background()
+
+	// b goes to the trash on its own before its parent.
+	require.NoError(t, svc.DeleteTask(ctx, "b", 0))
+	now = now.Add(time.Hour)
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+	assert.Empty(t, listIDs(t, svc, false))
+
+	_, err := svc.UndeleteTask(ctx, "a1", 0)
+	assert.ErrorIs(t, err, ErrParentInTrash)
+
+	_, err = svc.UndeleteTask(ctx, "root", 0)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"root", "a", "a1"}, listIDs(t, svc, false))
+	assert.Equal(t, []string{"b"}, listIDs(t, svc, true), "b was deleted separately")
+
+	b, err := svc.UndeleteTask(ctx, "b", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "root", b.ParentID)
+}
+
+func TestTaskTrash_DeleteWritesOneBatch(t *testing.T) {
+	for _, policy := range []model.CascadePolicy{model.CascadeCascade, model.CascadeOrphan} {
+		repo := &failingBatches{TaskRepository: repository.NewInMemoryTaskRepository(zap.NewNop())}
+		svc := NewTaskService(repo, zap.NewNop(), WithCascadePolicy(model.CascadeBlock, policy))
+		createTree(t, svc)
+		ctx := context.Background()
+
+		// The task and the subtasks its deletion changes are stored together
+		// or not at all.
+		repo.fail = true
+		require.Error(t, svc.DeleteTask(ctx, "root", 0), policy)
+		assert.ElementsMatch(t, []string{"root", "a", "a1", "b"}, listIDs(t, svc, false), policy)
+		a, err := svc.GetTask(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "root", a.ParentID, policy)
+	}
+}
+
+func TestTaskTrash_UndeleteWritesOneBatch(t *testing.T) {
+	repo := &failingBatches{TaskRepository: repository.NewInMemoryTaskRepository(zap.NewNop())}
+	svc := NewTaskService(repo, zap.NewNop(), WithCascadePolicy(model.CascadeBlock, model.CascadeCascade))
+	createTree(t, svc)
+	ctx := context.Background()
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+
+	// The task and the subtasks deleted with it come back together or not
+	// at all.
+	repo.fail = true
+	_, err := svc.UndeleteTask(ctx, "root", 0)
+	require.Error(t, err)
+	assert.Empty(t, listIDs(t, svc, false))
+
+	repo.fail = false
+	_, err = svc.UndeleteTask(ctx, "root", 0)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"root", "a", "a1", "b"}, listIDs(t, svc, false))
+}
+
+func TestTaskTrash_Purge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	svc := newHierarchyFixture(t, WithCascadePolicy(model.CascadeBlock, model.CascadeCascade),
+		WithClock(func() time.Time { return now }))
+	createTree(t, svc)
+	ctx := context.Background()
+	require.NoError(t, svc.DeleteTask(ctx, "b", 0))
+	now = now.Add(time.Hour)
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+
+	assert.ErrorIs(t, svc.PurgeTask(ctx, "root", 1), ErrVersionConflict)
+	require.NoError(t, svc.PurgeTask(ctx, "root", 0))
+	for _, id := range []string{"root", "a", "a1"} {
+		_, err := svc.GetTrashedTask(ctx, id)
+		assert.ErrorIs(t, err, ErrTaskNotFound, id)
+		entries, err := svc.History(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, model.HistoryPurge, entries[len(entries)-1].Action, id)
+	}
+
+	b, err := svc.GetTrashedTask(ctx, "b")
+	require.NoError(t, err)
+	assert.Empty(t, b.ParentID, "subtasks deleted on their own stay in the trash without their parent")
+	b, err = svc.UndeleteTask(ctx, "b", 0)
+	require.NoError(t, err)
+	assert.Empty(t, b.ParentID)
+}
+
+func TestTaskTrash_PurgeWritesOneBatch(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	repo := &failingBatches{TaskRepository: repository.NewInMemoryTaskRepository(zap.NewNop())}
+	svc := NewTaskService(repo, zap.NewNop(), WithCascadePolicy(model.CascadeBlock, model.CascadeCascade),
+		WithClock(func() time.Time { return now }))
+	createTree(t, svc)
+	ctx := context.Background()
+	require.NoError(t, svc.DeleteTask(ctx, "b", 0))
+	now = now.Add(time.Hour)
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+
+	// A subtree is purged at once or not at all.
+	now = now.Add(time.Minute)
+	repo.fail = true
+	n, err := svc.PurgeTrash(ctx, 0)
+	require.Error(t, err)
+	assert.Zero(t, n)
+	assert.ElementsMatch(t, []string{"root", "a", "a1", "b"}, listIDs(t, svc, true))
+	b, err := svc.GetTrashedTask(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "root", b.ParentID)
+
+	repo.fail = false
+	n, err = svc.PurgeTrash(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Empty(t, listIDs(t, svc, true))
+}
+
+func TestTaskTrash_PurgeTrash(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	svc := newHierarchyFixture(t, WithCascadePolicy(model.CascadeBlock, model.CascadeCascade),
+		WithClock(func() time.Time { return now }))
+	createTree(t, svc)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "recent", Title: "Recent"})
+	require.NoError(t, err)
+	_, err = svc.CreateTask(ctx, &model.Task{ID: "live", Title: "Live"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeleteTask(ctx, "root", 0))
+	now = now.Add(20 * 24 * time.Hour)
+	require.NoError(t, svc.DeleteTask(ctx, "recent", 0))
+	now = now.Add(15 * 24 * time.Hour)
+
+	n, err := svc.PurgeTrash(ctx, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, []string{"recent"}, listIDs(t, svc, true))
+	assert.Equal(t, []string{"live"}, listIDs(t, svc, false))
+
+	n, err = svc.PurgeTrash(ctx, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestTaskTrash_BlockPolicy(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	createTree(t, svc)
+	ctx := context.Background()
+	assert.ErrorIs(t, svc.DeleteTask(ctx, "a", 0), ErrHasSubtasks)
+	require.NoError(t, svc.DeleteTask(ctx, "a1", 0))
+	require.NoError(t, svc.DeleteTask(ctx, "a", 0), "trashed subtasks do not block")
+
+	_, err := svc.CreateTask(ctx, &model.Task{Title: "New", ParentID: "a"})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err), "a trashed task cannot get subtasks")
+}
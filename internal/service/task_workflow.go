@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+)
+
+// Workflow returns the state machine that task statuses follow outside
+// projects with a workflow of their own.
+func (s *taskServiceImpl) Workflow() *model.Workflow {
+	return s.workflow
+}
+
+// workflowOf returns the workflow of the tasks of project: its own, if it has
+// one, or the service's. project is nil for tasks outside any project.
+func (s *taskServiceImpl) workflowOf(project *model.Project) *model.Workflow {
+	if project != nil && project.Settings.Workflow != nil {
+		return project.Settings.Workflow
+	}
+	return s.workflow
+}
+
+// initStatus sets the status of a new task and derives Completed from it. A
+// task created without a status starts in the initial state, or in the done
+// state if it is created completed. Any state of wf may be given.
+func initStatus(wf *model.Workflow, task *model.Task) error {
+	switch {
+	case task.Status == "" && task.Completed:
+		task.Status = wf.Done
+	case task.Status == "":
+		task.Status = wf.Initial
+	case !wf.Has(task.Status):
+		return apperror.InvalidField("status", fmt.Sprintf("unknown status %q; the workflow states are %s",
+			task.Status, strings.Join(wf.States, ", ")))
+	}
+	task.Completed = wf.IsTerminal(task.Status)
+	return nil
+}
+
+// moveStatus works out the status an update moves task to from before and
+// checks the transition against wf. A changed status wins; otherwise
+// flipping Completed moves the task to the done state or reopens it in the
+// initial state. Completed is then derived from the new status.
+func moveStatus(wf *model.Workflow, before, task *model.Task) error {
+	from := statusOf(wf, before)
+	to := from
+	switch {
+	case task.Status != "" && task.Status != before.Status:
+		to = task.Status
+	case task.Completed != before.Completed && task.Completed:
+		to = wf.Done
+	case task.Completed != before.Completed:
+		to = wf.Initial
+	}
+	if to != from {
+		if err := wf.CheckTransition(from, to); err != nil {
+			return err
+		}
+	}
+	task.Status = to
+	task.Completed = wf.IsTerminal(to)
+	return nil
+}
+
+// statusOf returns the status of a stored task, deriving one for tasks stored
+// before statuses existed.
+func statusOf(wf *model.Workflow, task *model.Task) string {
+	switch {
+	case task.Status != "":
+		return task.Status
+	case task.Completed:
+		return wf.Done
+	default:
+		return wf.Initial
+	}
+}
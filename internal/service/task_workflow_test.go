@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskWorkflow_CreateSetsStatus(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+
+	task, err := svc.CreateTask(ctx, &model.Task{Title: "New"})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusTodo, task.Status)
+	assert.False(t, task.Completed)
+
+	task, err = svc.CreateTask(ctx, &model.Task{Title: "Legacy", Completed: true})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusDone, task.Status)
+
+	task, err = svc.CreateTask(ctx, &model.Task{Title: "Dropped", Status: model.StatusCancelled})
+	require.NoError(t, err)
+	assert.True(t, task.Completed, "terminal statuses are completed")
+
+	_, err = svc.CreateTask(ctx, &model.Task{Title: "Unknown", Status: "blocked"})
+	assert.Equal(t, "status", apperror.FieldsOf(err)[0].Field)
+}
+
+func TestTaskWorkflow_Transitions(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "T"})
+	require.NoError(t, err)
+	status := func(s string) *model.TaskPatch { return &model.TaskPatch{Status: &s} }
+
+	_, err = svc.PatchTask(ctx, "t", status(model.StatusInReview), 0)
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	assert.ErrorContains(t, err, "allowed next states: in_progress, done, cancelled")
+
+	task, err := svc.PatchTask(ctx, "t", status(model.StatusInProgress), 0)
+	require.NoError(t, err)
+	assert.False(t, task.Completed)
+	task, err = svc.PatchTask(ctx, "t", status(model.StatusInReview), 0)
+	require.NoError(t, err)
+	task, err = svc.PatchTask(ctx, "t", status(model.StatusDone), 0)
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+
+	_, err = svc.PatchTask(ctx, "t", status(model.StatusCancelled), 0)
+	assert.ErrorContains(t, err, "allowed next states: todo")
+}
+
+func TestTaskWorkflow_CompletedFlag(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "T", Status: model.StatusInProgress})
+	require.NoError(t, err)
+	done, open := true, false
+
+	task, err := svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusDone, task.Status)
+
+	task, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &open}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusTodo, task.Status, "reopened tasks start over")
+
+	// A full replacement without a status keeps it unless completed changes.
+	task, err = svc.UpdateTask(ctx, "t", &model.Task{Title: "Renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusTodo, task.Status)
+	// The status wins over a contradicting completed flag.
+	task, err = svc.UpdateTask(ctx, "t", &model.Task{Title: "T", Status: model.StatusInProgress, Completed: true})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusInProgress, task.Status)
+	assert.False(t, task.Completed)
+}
+
+func TestTaskWorkflow_CustomWorkflow(t *testing.T) {
+	wf := &model.Workflow{
+		States:      []string{"open", "closed"},
+		Initial:     "open",
+		Done:        "closed",
+		Terminal:    []string{"closed"},
+		Transitions: map[string][]string{"open": {"closed"}},
+	}
+	svc := newHierarchyFixture(t, WithWorkflow(wf))
+	ctx := context.Background()
+	assert.Same(t, wf, svc.Workflow())
+
+	task, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "T"})
+	require.NoError(t, err)
+	assert.Equal(t, "open", task.Status)
+	done, open := true, false
+	task, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "closed", task.Status)
+	_, err = svc.PatchTask(ctx, "t", &model.TaskPatch{Completed: &open}, 0)
+	assert.ErrorContains(t, err, "allowed next states: none")
+}
+
+func TestTaskWorkflow_CascadeFollowsParentStatus(t *testing.T) {
+	svc := newHierarchyFixture(t, WithCascadePolicy(model.CascadeCascade, model.CascadeBlock))
+	createTree(t, svc)
+	ctx := context.Background()
+	cancelled := model.StatusCancelled
+
+	_, err := svc.PatchTask(ctx, "root", &model.TaskPatch{Status: &cancelled}, 0)
+	require.NoError(t, err)
+	for _, id := range []string{"a", "b", "a1"} {
+		task, err := svc.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCancelled, task.Status, id)
+		assert.True(t, task.Completed, id)
+	}
+}
+
+func TestTaskWorkflow_CascadeChecksSubtaskTransitions(t *testing.T) {
+	wf := model.DefaultWorkflow()
+	wf.Transitions[model.StatusTodo] = []string{model.StatusInProgress}
+	svc := newHierarchyFixture(t, WithWorkflow(wf), WithCascadePolicy(model.CascadeCascade, model.CascadeBlock))
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "root", Title: "Root", Status: model.StatusInProgress})
+	require.NoError(t, err)
+	_, err = svc.CreateTask(ctx, &model.Task{ID: "child", Title: "Child", ParentID: "root"})
+	require.NoError(t, err)
+
+	done := true
+	_, err = svc.PatchTask(ctx, "root", &model.TaskPatch{Completed: &done}, 0)
+	assert.ErrorContains(t, err, `subtask child: cannot move from "todo" to "done"`)
+	root, err := svc.GetTask(ctx, "root")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusInProgress, root.Status, "nothing is written")
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TrashReaper purges the tasks that have been in the trash for longer than a
+// retention period, checking at a fixed interval.
+type TrashReaper struct {
+	tasks     TaskService
+	retention time.Duration
+	interval  time.Duration
+	logger    *zap.Logger
+}
+
+// NewTrashReaper creates a TrashReaper that purges the trash of tasks every
+// interval. interval must be positive.
+func NewTrashReaper(tasks TaskService, retention, interval time.Duration, logger *zap.Logger) *TrashReaper {
+	return &TrashReaper{tasks: tasks, retention: retention, interval: interval, logger: logger}
+}
+
+// Run reaps once immediately and then every interval until ctx is done.
+// Failures are logged and retried at the next interval.
+func (r *TrashReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.Reap(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap purges the expired tasks once and returns how many it purged.
+func (r *TrashReaper) Reap(ctx context.Context) int {
+	n, err := r.tasks.PurgeTrash(ctx, r.retention)
+	if err != nil {
+		r.logger.Error("failed to purge trash", zap.Int("purged", n), zap.Error(err))
+	} else if n > 0 {
+		r.logger.Info("purged trash", zap.Int("purged", n), zap.Duration("retention", r.retention))
+	}
+	return n
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"taskmanager/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTrashReaper_Reap(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	svc := newHierarchyFixture(t, WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+	for _, id := range []string{"old", "new"} {
+		_, err := svc.CreateTask(ctx, &model.Task{ID: id, Title: id})
+		require.NoError(t, err)
+	}
+	require.NoError(t, svc.DeleteTask(ctx, "old", 0))
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, svc.DeleteTask(ctx, "new", 0))
+	now = now.Add(30 * time.Minute)
+
+	reaper := NewTrashReaper(svc, time.Hour, time.Minute, zap.NewNop())
+	assert.Equal(t, 1, reaper.Reap(ctx))
+	assert.Equal(t, []string{"new"}, listIDs(t, svc, true))
+	assert.Zero(t, reaper.Reap(ctx))
+}
+
+func TestTrashReaper_RunStopsWithContext(t *testing.T) {
+	svc := newHierarchyFixture(t)
+	ctx := context.Background()
+	_, err := svc.CreateTask(ctx, &model.Task{ID: "t", Title: "Gone"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTask(ctx, "t", 0))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewTrashReaper(svc, 0, time.Hour, zap.NewNop()).Run(runCtx)
+	}()
+	assert.Eventually(t, func() bool { return len(listIDs(t, svc, true)) == 0 }, time.Second, 5*time.Millisecond,
+		"Run reaps immediately")
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// UserService defines the business logic for managing users and the tasks
+// assigned to them. Assigning tasks is part of TaskService.
+type UserService interface {
+	// CreateUser validates and stores a new user.
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	GetUser(ctx context.Context, id string) (*model.User, error)
+	// ListUsers returns all users ordered by ID.
+	ListUsers(ctx context.Context) ([]*model.User, error)
+	// UpdateUser applies patch to an existing user.
+	UpdateUser(ctx context.Context, id string, patch *model.UserPatch) (*model.User, error)
+	// DeleteUser deletes a user who has no open tasks assigned, otherwise it
+	// returns an error matching ErrUserHasOpenTasks.
+	DeleteUser(ctx context.Context, id string) error
+	// ListTasks returns a page of the tasks assigned to user id, selected by q
+	// like in TaskService.ListTasks.
+	ListTasks(ctx context.Context, id string, q model.TaskQuery) (*model.TaskPage, error)
+}
+
+// ErrUserNotFound is returned when a user does not exist.
+var ErrUserNotFound = repository.ErrUserNotFound
+
+// ErrUserHasOpenTasks is matched by errors returned when deleting a user who
+// still has incomplete tasks assigned.
+var ErrUserHasOpenTasks = apperror.Conflict("user has open tasks assigned")
+
+type userServiceImpl struct {
+	repo   repository.TaskRepository
+	tasks  TaskService
+	logger *zap.Logger
+}
+
+// NewUserService creates a UserService on repo that lists tasks through tasks.
+func NewUserService(repo repository.TaskRepository, tasks TaskService, logger *zap.Logger) UserService {
+	return &userServiceImpl{repo: repo, tasks: tasks, logger: logger}
+}
+
+func (s *userServiceImpl) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	if err := user.Validate(); err != nil {
+		s.logger.Warn("user validation failed", zap.Error(err))
+		return nil, err
+	}
+	now := time.Now().UTC()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		s.logger.Warn("failed to create user", zap.String("id", user.ID), zap.Error(err))
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userServiceImpl) GetUser(ctx context.Context, id string) (*model.User, error) {
+	return s.repo.GetUser(ctx, id)
+}
+
+func (s *userServiceImpl) ListUsers(ctx context.Context) ([]*model.User, error) {
+	users, err := s.repo.ListUsers(ctx)
+	if err != nil {
+		s.logger.Error("failed to list users", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *userServiceImpl) UpdateUser(ctx context.Context, id string, patch *model.UserPatch) (*model.User, error) {
+	user, err := s.repo.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	patch.Apply(user)
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		s.logger.Warn("failed to update user", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser refuses to delete a user with open tasks, who would leave them
+// assigned to nobody who can act on them. Completed and trashed tasks keep
+// naming the deleted user.
+func (s *userServiceImpl) DeleteUser(ctx context.Context, id string) error {
+	if _, err := s.repo.GetUser(ctx, id); err != nil {
+		return err
+	}
+	open := false
+	page, err := s.repo.QueryTasks(ctx, model.TaskQuery{
+		Filter: model.TaskFilter{Assignee: &id, Completed: &open},
+		Limit:  1,
+	})
+	if err != nil {
+		s.logger.Error("failed to look up assigned tasks", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if len(page.Tasks) > 0 {
+		return ErrUserHasOpenTasks
+	}
+	if err := s.repo.DeleteUser(ctx, id); err != nil {
+		s.logger.Warn("failed to delete user", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *userServiceImpl) ListTasks(ctx context.Context, id string, q model.TaskQuery) (*model.TaskPage, error) {
+	if _, err := s.repo.GetUser(ctx, id); err != nil {
+		return nil, err
+	}
+	q.Filter.Assignee = &id
+	return s.tasks.ListTasks(ctx, q)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"taskmanager/internal/apperror"
+	"taskmanager/internal/identity"
+	"taskmanager/internal/model"
+	"taskmanager/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newUserFixture(t *testing.T, ids ...string) (UserService, TaskService) {
+	t.Helper()
+	repo := repository.NewInMemoryTaskRepository(zap.NewNop())
+	tasks := NewTaskService(repo, zap.NewNop())
+	users := NewUserService(repo, tasks, zap.NewNop())
+	for _, id := range ids {
+		_, err := users.CreateUser(context.Background(), &model.User{ID: id, Name: id})
+		require.NoError(t, err)
+	}
+	return users, tasks
+}
+
+func TestUserService_CRUD(t *testing.T) {
+	users, _ := newUserFixture(t)
+	ctx := context.Background()
+
+	created, err := users.CreateUser(ctx, &model.User{ID: "alice", Name: "Alice"})
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	_, err = users.CreateUser(ctx, &model.User{ID: "alice", Name: "Alice"})
+	assert.ErrorIs(t, err, repository.ErrUserAlreadyExists)
+	_, err = users.CreateUser(ctx, &model.User{ID: "bad id"})
+	assert.Len(t, apperror.FieldsOf(err), 2)
+
+	email := "alice@example.com"
+	updated, err := users.UpdateUser(ctx, "alice", &model.UserPatch{Email: &email})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", updated.Name)
+	assert.Equal(t, email, updated.Email)
+	assert.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+
+	bad := "nope"
+	_, err = users.UpdateUser(ctx, "alice", &model.UserPatch{Email: &bad})
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	_, err = users.UpdateUser(ctx, "missing", &model.UserPatch{Email: &email})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	require.NoError(t, users.DeleteUser(ctx, "alice"))
+	_, err = users.GetUser(ctx, "alice")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserService_DeleteWithOpenTasks(t *testing.T) {
+	users, tasks := newUserFixture(t, "alice")
+	ctx := context.Background()
+	task, err := tasks.CreateTask(ctx, &model.Task{Title: "Task", Assignee: "alice"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, users.DeleteUser(ctx, "alice"), ErrUserHasOpenTasks)
+
+	done := true
+	_, err = tasks.PatchTask(ctx, task.ID, &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	require.NoError(t, users.DeleteUser(ctx, "alice"), "completed tasks do not block deletion")
+	assert.ErrorIs(t, users.DeleteUser(ctx, "alice"), ErrUserNotFound)
+}
+
+func TestUserService_ListTasks(t *testing.T) {
+	users, tasks := newUserFixture(t, "alice", "bob")
+	ctx := context.Background()
+	for _, assignee := range []string{"alice", "bob", "alice", ""} {
+		_, err := tasks.CreateTask(ctx, &model.Task{Title: "Task", Assignee: assignee})
+		require.NoError(t, err)
+	}
+
+	page, err := users.ListTasks(ctx, "alice", model.TaskQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 2)
+	for _, task := range page.Tasks {
+		assert.Equal(t, "alice", task.Assignee)
+	}
+
+	_, err = users.ListTasks(ctx, "carol", model.TaskQuery{})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestTaskService_Assignee(t *testing.T) {
+	_, tasks := newUserFixture(t, "alice", "bob", "carol")
+	ctx := identity.WithActor(context.Background(), "alice")
+
+	_, err := tasks.CreateTask(ctx, &model.Task{Title: "Task", Assignee: "dave"})
+	assert.Equal(t, "assignee", apperror.FieldsOf(err)[0].Field, "unknown assignees are invalid")
+
+	task, err := tasks.CreateTask(ctx, &model.Task{Title: "Task", Assignee: "bob", CreatedBy: "mallory"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", task.CreatedBy, "the creator is the actor")
+
+	bob := identity.WithActor(context.Background(), "bob")
+	carol := identity.WithActor(context.Background(), "carol")
+	assign := func(ctx context.Context, assignee string) (*model.Task, error) {
+		return tasks.PatchTask(ctx, task.ID, &model.TaskPatch{Assignee: &assignee}, 0)
+	}
+	_, err = assign(carol, "carol")
+	assert.ErrorIs(t, err, ErrReassignForbidden)
+	assert.Equal(t, apperror.KindForbidden, apperror.KindOf(err))
+
+	task, err = assign(bob, "carol")
+	require.NoError(t, err, "the assignee can hand a task on")
+	assert.Equal(t, "carol", task.Assignee)
+	task, err = assign(ctx, "")
+	require.NoError(t, err, "the creator can unassign a task")
+
+	_, err = assign(carol, "dave")
+	assert.Equal(t, apperror.KindValidation, apperror.KindOf(err))
+	task, err = assign(carol, "carol")
+	require.NoError(t, err, "anyone can take an unassigned task")
+
+	done, open, next := true, false, "bob"
+	task, err = tasks.PatchTask(carol, task.ID, &model.TaskPatch{Completed: &done}, 0)
+	require.NoError(t, err)
+	_, err = assign(carol, "bob")
+	assert.ErrorIs(t, err, ErrReassignCompleted)
+	task, err = tasks.PatchTask(carol, task.ID, &model.TaskPatch{Completed: &open, Assignee: &next}, 0)
+	require.NoError(t, err, "reopening a task frees its assignee")
+	assert.Equal(t, "bob", task.Assignee)
+	assert.Equal(t, "alice", task.CreatedBy)
+}